@@ -0,0 +1,147 @@
+/**
+ * Binance签名请求
+ * 使用服务器时钟生成时间戳，时间戳被拒绝（-1021）时立即重新校准并重试一次
+ */
+
+import { createHmac } from 'crypto';
+import { ServerClock } from '@pixiu/adapter-base';
+import { BaseMonitor, WeightedRateLimiter, HttpPolicy, EXCHANGE_RATE_LIMITS } from '@pixiu/shared-core';
+
+export interface BinanceSignerOptions {
+  apiKey: string;
+  apiSecret: string;
+  /** REST接口地址，签名请求使用其域名 */
+  restUrl?: string;
+  /** 请求有效期（毫秒），默认5000，交易所上限60000 */
+  recvWindow?: number;
+  /** 请求超时（毫秒），默认10秒 */
+  timeout?: number;
+  /** 服务器时钟，未指定时通过 /v3/time 校准 */
+  clock?: ServerClock;
+  /** 共享的限流器 */
+  rateLimiter?: WeightedRateLimiter;
+  /** 共享的请求策略，指定后忽略rateLimiter */
+  httpPolicy?: HttpPolicy;
+  /** 注册请求策略的熔断与重试指标 */
+  monitor?: BaseMonitor;
+}
+
+export type SignedParams = Record<string, string | number | boolean | undefined>;
+
+/** 时间戳超出recvWindow */
+const TIMESTAMP_OUTSIDE_RECV_WINDOW = -1021;
+
+/**
+ * Binance接口错误，code为交易所错误码
+ */
+export class BinanceApiError extends Error {
+  constructor(message: string, readonly status: number, readonly code?: number) {
+    super(message);
+    this.name = 'BinanceApiError';
+  }
+}
+
+/**
+ * 经请求策略查询服务器时间（毫秒），计入限流并受熔断保护
+ */
+export async function fetchBinanceServerTime(httpPolicy: HttpPolicy, restUrl: string, timeout: number): Promise<number> {
+  const response = await httpPolicy.fetch(`${restUrl.replace(/\/+$/, '')}/v3/time`, {
+    endpoint: 'GET /v3/time',
+    signal: AbortSignal.timeout(timeout)
+  });
+  if (!response.ok) {
+    throw new BinanceApiError(`Binance GET /v3/time failed: HTTP ${response.status}`, response.status);
+  }
+  return ((await response.json()) as any).serverTime;
+}
+
+export class BinanceSigner {
+  readonly clock: ServerClock;
+  private readonly origin: string;
+  private readonly recvWindow: number;
+  private readonly timeout: number;
+  private readonly httpPolicy: HttpPolicy;
+
+  constructor(private readonly options: BinanceSignerOptions) {
+    const restUrl = (options.restUrl ?? 'https://api.binance.com/api').replace(/\/+$/, '');
+    this.origin = new URL(restUrl).origin;
+    this.recvWindow = options.recvWindow ?? 5000;
+    this.timeout = options.timeout ?? 10000;
+    if (!(this.recvWindow > 0 && this.recvWindow <= 60000)) {
+      throw new Error(`recvWindow must be between 1 and 60000 ms, got ${options.recvWindow}`);
+    }
+
+    this.httpPolicy = options.httpPolicy ?? new HttpPolicy({
+      name: 'binance',
+      monitor: options.monitor,
+      rateLimiter: options.rateLimiter ?? new WeightedRateLimiter({ buckets: EXCHANGE_RATE_LIMITS.binance })
+    });
+    this.clock = options.clock ?? new ServerClock({
+      fetchServerTime: () => fetchBinanceServerTime(this.httpPolicy, restUrl, this.timeout)
+    });
+  }
+
+  /**
+   * 生成带时间戳与签名的查询字符串
+   */
+  sign(params: SignedParams = {}): string {
+    const query = new URLSearchParams();
+    for (const [key, value] of Object.entries(params)) {
+      if (value !== undefined) {
+        query.set(key, String(value));
+      }
+    }
+    query.set('recvWindow', String(this.recvWindow));
+    query.set('timestamp', String(this.clock.now()));
+
+    const payload = query.toString();
+    const signature = createHmac('sha256', this.options.apiSecret).update(payload).digest('hex');
+    return `${payload}&signature=${signature}`;
+  }
+
+  /**
+   * 发送签名请求，path为完整路径，如 /api/v3/account 或 /sapi/v1/account/apiRestrictions
+   */
+  async request<T = any>(
+    method: 'GET' | 'POST' | 'PUT' | 'DELETE',
+    path: string,
+    params: SignedParams = {},
+    weight = 1
+  ): Promise<T> {
+    if (!this.clock.isCalibrated()) {
+      await this.clock.calibrate();
+    }
+
+    try {
+      return await this.send<T>(method, path, params, weight);
+    } catch (error) {
+      if ((error as BinanceApiError).code !== TIMESTAMP_OUTSIDE_RECV_WINDOW) {
+        throw error;
+      }
+      await this.clock.calibrate(true);
+      return this.send<T>(method, path, params, weight);
+    }
+  }
+
+  private async send<T>(method: string, path: string, params: SignedParams, weight: number): Promise<T> {
+    // 签名包含时间戳，重试会复用过期的签名，因此只使用熔断与限流
+    const response = await this.httpPolicy.fetch(`${this.origin}${path}?${this.sign(params)}`, {
+      method,
+      headers: { 'X-MBX-APIKEY': this.options.apiKey },
+      signal: AbortSignal.timeout(this.timeout),
+      endpoint: `${method} ${path}`,
+      weight,
+      idempotent: false
+    });
+
+    const body: any = await response.json().catch(() => ({}));
+    if (!response.ok) {
+      throw new BinanceApiError(
+        `Binance ${method} ${path} failed: HTTP ${response.status} ${body.msg ?? ''}`.trim(),
+        response.status,
+        body.code
+      );
+    }
+    return body;
+  }
+}
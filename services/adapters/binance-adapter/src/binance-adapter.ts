@@ -13,12 +13,20 @@ import {
   SubscriptionConfig,
   MarketData,
   ConnectionManager,
+  AdapterCapabilitiesDeclaration,
   TradeData,
   TickerData,
   KlineData,
-  DepthData
+  DepthData,
+  OrderBook,
+  OrderBookSnapshot,
+  normalizeSymbol
 } from '@pixiu/adapter-base';
+import { HttpPolicy } from '@pixiu/shared-core';
 import { BinanceConnectionManager, BinanceCombinedStreamConfig } from './connection/binance-connection-manager';
+import { BinanceRestContext } from './rest/binance-rest-context';
+import { BinanceSigner } from './auth/binance-signer';
+import { BinanceUserDataStream } from './user-data/binance-user-data-stream';
 
 export interface BinanceConfig extends AdapterConfig {
   /** 订阅配置 */
@@ -27,14 +35,38 @@ export interface BinanceConfig extends AdapterConfig {
   binance?: {
     /** 是否使用测试网 */
     testnet?: boolean;
-    /** 是否启用数据压缩 */
+    /** 是否协商permessage-deflate压缩 */
     enableCompression?: boolean;
     /** 批量订阅大小 */
     batchSize?: number;
     /** 是否自动管理组合流 */
     autoManageStreams?: boolean;
+    /** 单个连接的最大流数量，超出后分片到新连接，默认1024 */
+    maxStreamsPerConnection?: number;
+    /** 最大WebSocket连接数，默认5 */
+    maxConnections?: number;
     /** 组合流配置（内部使用） */
     combinedStream?: BinanceCombinedStreamConfig;
+    /** 订单簿维护配置 */
+    orderBook?: {
+      /** 订阅深度数据时是否维护本地订单簿 */
+      enabled?: boolean;
+      /** REST快照档位数 */
+      snapshotLimit?: number;
+    };
+    /** REST每分钟请求权重上限 */
+    restWeightLimit?: number;
+    /** 签名请求有效期（毫秒） */
+    recvWindow?: number;
+    /** 与其他组件共享的REST上下文，未指定时按配置创建 */
+    restContext?: BinanceRestContext;
+    /** 用户数据流，需要配置auth.apiKey */
+    userDataStream?: {
+      /** 是否在连接后启动用户数据流，默认false */
+      enabled?: boolean;
+      /** listenKey保活间隔（毫秒），默认30分钟 */
+      keepaliveInterval?: number;
+    };
   };
 }
 
@@ -43,13 +75,16 @@ export class BinanceAdapter extends BaseAdapter {
   
   private streamId = 0;
   private streamMap = new Map<string, string>(); // subscription -> stream name
+  private orderBooks = new Map<string, OrderBook>(); // symbol -> order book
   private binanceConnectionManager?: BinanceConnectionManager;
+  private restContext?: BinanceRestContext;
+  private userDataStream?: BinanceUserDataStream;
 
   /**
    * 创建连接管理器
    */
   protected async createConnectionManager(): Promise<ConnectionManager> {
-    this.binanceConnectionManager = new BinanceConnectionManager();
+    this.binanceConnectionManager = new BinanceConnectionManager((this.config as BinanceConfig).binance);
     return this.binanceConnectionManager;
   }
 
@@ -79,7 +114,11 @@ export class BinanceAdapter extends BaseAdapter {
         ...config.binance,
         combinedStream: {
           streams: initialStreams,
-          autoManage: config.binance?.autoManageStreams ?? true
+          autoManage: config.binance?.autoManageStreams ?? true,
+          maxStreams: config.binance?.maxStreamsPerConnection
+        },
+        connectionPool: {
+          maxConnections: config.binance?.maxConnections
         }
       }
     };
@@ -128,6 +167,10 @@ export class BinanceAdapter extends BaseAdapter {
     const streamName = this.buildStreamName(symbol, dataType);
     
     this.streamMap.set(subscriptionId, streamName);
+
+    if (dataType === DataType.DEPTH) {
+      this.ensureOrderBook(symbol);
+    }
     
     // 使用BinanceConnectionManager添加流
     if (this.binanceConnectionManager) {
@@ -136,7 +179,7 @@ export class BinanceAdapter extends BaseAdapter {
 
     return {
       id: subscriptionId,
-      symbol: this.normalizeSymbol(symbol),
+      symbol: normalizeSymbol(symbol),
       dataType,
       subscribedAt: Date.now(),
       active: true
@@ -150,6 +193,10 @@ export class BinanceAdapter extends BaseAdapter {
     const streamName = this.streamMap.get(subscription.id);
     if (streamName) {
       this.streamMap.delete(subscription.id);
+
+      if (subscription.dataType === DataType.DEPTH) {
+        this.removeOrderBook(subscription.symbol);
+      }
       
       // 使用BinanceConnectionManager移除流
       if (this.binanceConnectionManager) {
@@ -160,6 +207,33 @@ export class BinanceAdapter extends BaseAdapter {
 
 
 
+  /**
+   * 恢复订阅
+   * 组合流URL已包含全部活跃流，重连后只需确认流仍由连接管理器维护
+   */
+  protected async restoreSubscription(subscription: SubscriptionInfo): Promise<void> {
+    const streamName = this.streamMap.get(subscription.id);
+    if (streamName && this.binanceConnectionManager) {
+      await this.binanceConnectionManager.addStream(streamName);
+    }
+  }
+
+  /**
+   * 声明Binance适配器能力
+   * 用户数据流需要提供API密钥且未在配置中关闭，交易接口尚未接入
+   */
+  protected describeCapabilities(): AdapterCapabilitiesDeclaration {
+    const binance = (this.config as BinanceConfig | undefined)?.binance;
+    return {
+      dataTypes: Object.values(DataType),
+      websocket: true,
+      userDataStream: binance?.userDataStream?.enabled !== false && !!this.config?.auth?.apiKey,
+      combinedStreams: true,
+      maxSubscriptionsPerConnection: binance?.maxStreamsPerConnection ?? 1024,
+      orderBookChecksum: false
+    };
+  }
+
   /**
    * 解析Binance消息
    */
@@ -186,7 +260,7 @@ export class BinanceAdapter extends BaseAdapter {
     
     // 根据data.e字段识别事件类型（参考实验代码）
     const eventType = data.e;
-    const symbol = this.normalizeSymbol(data.s);
+    const symbol = normalizeSymbol(data.s);
     
     let dataType: DataType;
     let parsedData: any;
@@ -208,6 +282,12 @@ export class BinanceAdapter extends BaseAdapter {
         parsedData = this.parseTickerData(data);
         break;
       
+      case 'depthUpdate':
+        dataType = DataType.DEPTH;
+        parsedData = this.parseDepthData(data);
+        this.updateOrderBook(symbol, data);
+        break;
+      
       default:
         // 未知事件类型，忽略
         return null;
@@ -274,7 +354,240 @@ export class BinanceAdapter extends BaseAdapter {
     return {
       bids: data.b?.map((bid: string[]) => [parseFloat(bid[0]), parseFloat(bid[1])]) || [],
       asks: data.a?.map((ask: string[]) => [parseFloat(ask[0]), parseFloat(ask[1])]) || [],
-      updateTime: data.E
+      updateTime: data.E,
+      firstUpdateId: data.U,
+      finalUpdateId: data.u
+    };
+  }
+
+  /**
+   * 获取本地维护的订单簿
+   */
+  getOrderBook(symbol: string): OrderBook | undefined {
+    return this.orderBooks.get(normalizeSymbol(symbol));
+  }
+
+  /**
+   * 连接到交易所，配置了API密钥时开始定时校准服务器时钟，启用时启动用户数据流
+   * 注入的REST上下文由创建方启动和停止
+   */
+  async connect(): Promise<void> {
+    await super.connect();
+    if (this.getSigner() && !this.hasInjectedRestContext()) {
+      // 校准失败不影响行情连接，签名请求会在时钟未校准时重新校准
+      this.getRestContext().start().catch(error => this.emitBackgroundError(error));
+    }
+    // 用户数据流启动失败不影响行情连接
+    this.getUserDataStream()?.start().catch(error => this.emitBackgroundError(error));
+  }
+
+  /**
+   * 断开连接，停止用户数据流与服务器时钟校准
+   */
+  async disconnect(): Promise<void> {
+    await this.userDataStream?.stop().catch(error => this.emitBackgroundError(error));
+    if (!this.hasInjectedRestContext()) {
+      this.restContext?.stop();
+    }
+    await super.disconnect();
+  }
+
+  /**
+   * 销毁适配器，注入的REST上下文由创建方销毁
+   */
+  async destroy(): Promise<void> {
+    await this.userDataStream?.stop().catch(() => undefined);
+    this.userDataStream?.removeAllListeners();
+    this.userDataStream = undefined;
+    await super.destroy();
+    if (!this.hasInjectedRestContext()) {
+      this.restContext?.destroy();
+    }
+    this.restContext = undefined;
+  }
+
+  private hasInjectedRestContext(): boolean {
+    return (this.config as BinanceConfig | undefined)?.binance?.restContext !== undefined;
+  }
+
+  /**
+   * 获取REST共享上下文，未注入时按配置创建
+   * 配置了代理池时与WebSocket共用出口代理
+   */
+  getRestContext(): BinanceRestContext {
+    if (!this.restContext) {
+      const binance = (this.config as BinanceConfig).binance;
+      this.restContext = binance?.restContext ?? new BinanceRestContext({
+        restUrl: this.config.endpoints.rest,
+        weightLimit: binance?.restWeightLimit,
+        proxyPool: this.getProxyPool(),
+        monitor: this.monitor
+      });
+    }
+    return this.restContext;
+  }
+
+  /**
+   * 获取签名器，未配置API密钥时返回undefined
+   */
+  getSigner(): BinanceSigner | undefined {
+    const auth = this.config?.auth;
+    if (!auth?.apiKey || !auth.apiSecret) {
+      return undefined;
+    }
+    return this.getRestContext().getSigner({
+      apiKey: auth.apiKey,
+      apiSecret: auth.apiSecret,
+      recvWindow: (this.config as BinanceConfig).binance?.recvWindow
+    });
+  }
+
+  /**
+   * 获取用户数据流，未启用或未配置API密钥时返回undefined
+   *
+   * 事件经适配器转发：
+   * - userData(payload) 全部用户数据事件
+   * - resyncRequired(reason) 期间可能丢失事件，需要通过REST重新查询订单与余额
+   */
+  getUserDataStream(): BinanceUserDataStream | undefined {
+    const options = (this.config as BinanceConfig | undefined)?.binance?.userDataStream;
+    const apiKey = this.config?.auth?.apiKey;
+    if (!options?.enabled || !apiKey) {
+      return undefined;
+    }
+
+    if (!this.userDataStream) {
+      const stream = this.getRestContext().createUserDataStream({
+        apiKey,
+        // 用户数据流使用单流地址 /ws/<listenKey>
+        wsUrl: `${new URL(this.config.endpoints.ws).origin}/ws`,
+        keepaliveInterval: options.keepaliveInterval,
+        connection: this.config.connection
+      });
+      stream.on('event', payload => this.emit('userData', payload));
+      stream.on('resyncRequired', reason => this.emit('resyncRequired', reason));
+      stream.on('error', error => this.emitBackgroundError(error));
+      this.userDataStream = stream;
+    }
+    return this.userDataStream;
+  }
+
+  /**
+   * 转发不影响行情连接的后台错误，没有监听者时忽略，避免未处理的error事件
+   */
+  private emitBackgroundError(error: Error): void {
+    if (this.listenerCount('error') > 0) {
+      this.emit('error', error);
+    }
+  }
+
+  /**
+   * 为交易对创建订单簿
+   */
+  private ensureOrderBook(symbol: string): void {
+    if ((this.config as BinanceConfig).binance?.orderBook?.enabled === false) {
+      return;
+    }
+
+    const normalizedSymbol = normalizeSymbol(symbol);
+    if (this.orderBooks.has(normalizedSymbol)) {
+      return;
+    }
+
+    const book = new OrderBook({
+      symbol: normalizedSymbol,
+      snapshotProvider: () => this.fetchDepthSnapshot(symbol)
+    });
+    book.on('resyncFailed', (error: Error) => this.emit('error', error));
+    this.orderBooks.set(normalizedSymbol, book);
+  }
+
+  /**
+   * 移除交易对的订单簿
+   */
+  private removeOrderBook(symbol: string): void {
+    const book = this.orderBooks.get(symbol);
+    if (book) {
+      book.reset();
+      book.removeAllListeners();
+      this.orderBooks.delete(symbol);
+    }
+  }
+
+  /**
+   * 将深度增量应用到订单簿
+   */
+  private updateOrderBook(symbol: string, data: any): void {
+    const book = this.orderBooks.get(symbol);
+    if (!book) {
+      return;
+    }
+
+    book.applyDelta({
+      firstUpdateId: data.U,
+      finalUpdateId: data.u,
+      prevFinalUpdateId: data.pu,
+      bids: data.b || [],
+      asks: data.a || [],
+      timestamp: data.E
+    });
+  }
+
+  /**
+   * 通过REST获取深度快照
+   */
+  private async fetchDepthSnapshot(symbol: string): Promise<OrderBookSnapshot> {
+    const limit = (this.config as BinanceConfig).binance?.orderBook?.snapshotLimit ?? 1000;
+    const query = `symbol=${symbol.replace('/', '').toUpperCase()}&limit=${limit}`;
+    const response = await this.getRestPolicy().fetch(`${this.config.endpoints.rest}/v3/depth?${query}`, {
+      endpoint: 'GET /v3/depth',
+      weight: this.getDepthWeight(limit)
+    });
+    this.recordRestWeight();
+
+    if (!response.ok) {
+      throw new Error(`Failed to fetch depth snapshot for ${symbol}: HTTP ${response.status}`);
+    }
+
+    const body: any = await response.json();
+    return {
+      lastUpdateId: body.lastUpdateId,
+      bids: body.bids,
+      asks: body.asks,
+      timestamp: Date.now()
+    };
+  }
+
+  /**
+   * 获取REST请求策略，429/418由策略按Retry-After暂停限流器
+   */
+  private getRestPolicy(): HttpPolicy {
+    return this.getRestContext().httpPolicy;
+  }
+
+  /**
+   * 深度快照请求权重，随档位数递增
+   */
+  private getDepthWeight(limit: number): number {
+    if (limit <= 100) return 5;
+    if (limit <= 500) return 25;
+    if (limit <= 1000) return 50;
+    return 250;
+  }
+
+  /**
+   * 记录REST请求权重使用情况
+   */
+  private recordRestWeight(): void {
+    const usage = this.restContext?.rateLimiter.getUsage().find(bucket => bucket.name === 'weight');
+    if (!usage) {
+      return;
+    }
+
+    this.metrics.restRateLimit = {
+      used: usage.used,
+      limit: usage.limit,
+      updatedAt: Date.now()
     };
   }
 
@@ -307,27 +620,6 @@ export class BinanceAdapter extends BaseAdapter {
     }
   }
 
-  /**
-   * 标准化交易对名称
-   */
-  private normalizeSymbol(symbol: string): string {
-    // Binance使用BTCUSDT格式，转换为BTC/USDT标准格式
-    if (symbol.includes('/')) {
-      return symbol.toUpperCase();
-    }
-    
-    // 简单的启发式方法来分割交易对
-    const commonQuotes = ['USDT', 'BUSD', 'BTC', 'ETH', 'BNB', 'USDC'];
-    for (const quote of commonQuotes) {
-      if (symbol.toUpperCase().endsWith(quote)) {
-        const base = symbol.slice(0, -quote.length).toUpperCase();
-        return `${base}/${quote}`;
-      }
-    }
-    
-    return symbol.toUpperCase();
-  }
-
   /**
    * 映射时间间隔
    */
@@ -3,7 +3,7 @@
  * 扩展BaseConnectionManager以支持Binance特定的连接功能
  */
 
-import { BaseConnectionManager, ConnectionConfig, ConnectionState } from '@pixiu/adapter-base';
+import { BaseConnectionManager, ConnectionConfig, ConnectionManager, ConnectionState } from '@pixiu/adapter-base';
 import { EventEmitter } from 'events';
 
 export interface BinanceCombinedStreamConfig {
@@ -11,7 +11,7 @@ export interface BinanceCombinedStreamConfig {
   streams: string[];
   /** 是否自动管理流 */
   autoManage?: boolean;
-  /** 最大流数量限制 */
+  /** 单个连接的最大流数量，超出后自动分片到新连接 */
   maxStreams?: number;
   /** 批量操作延迟 (ms) */
   batchDelay?: number;
@@ -22,13 +22,13 @@ export interface BinanceConnectionConfig extends ConnectionConfig {
   binance?: {
     /** 是否使用测试网 */
     testnet?: boolean;
-    /** 是否启用数据压缩 */
+    /** 是否协商permessage-deflate压缩，未设置connection级enableCompression时生效 */
     enableCompression?: boolean;
     /** 组合流配置 */
     combinedStream?: BinanceCombinedStreamConfig;
     /** 连接池配置 */
     connectionPool?: {
-      /** 最大连接数 */
+      /** 最大连接数（含主连接），限制流分片数量，默认5 */
       maxConnections?: number;
       /** 连接超时 */
       connectionTimeout?: number;
@@ -50,6 +50,8 @@ export interface BinanceConnectionConfig extends ConnectionConfig {
 export interface BinanceConnectionMetrics {
   /** 活跃流数量 */
   activeStreams: number;
+  /** 承载流的WebSocket连接数 */
+  connections: number;
   /** 流变更次数 */
   streamChanges: number;
   /** 重连次数 */
@@ -64,15 +66,30 @@ export interface BinanceConnectionMetrics {
   };
 }
 
+/**
+ * 主连接已满时承载溢出流的额外连接
+ */
+interface StreamShard {
+  manager: ConnectionManager;
+  streams: Set<string>;
+  /** 按流变更主动重连或关闭期间不转发断开事件 */
+  updating?: boolean;
+}
+
 export class BinanceConnectionManager extends BaseConnectionManager {
+  /** 主连接承载的流 */
   private activeStreams = new Set<string>();
+  private shards: StreamShard[] = [];
+  /** 分片连接沿用的连接配置，url为未拼接流的端点 */
+  private shardConfig?: ConnectionConfig;
   private combinedStreamUrl?: string;
   private binanceConfig?: BinanceConnectionConfig['binance'];
   private connectionPool = new Map<string, any>();
   private streamBatchTimer?: NodeJS.Timeout;
-  private pendingStreamOperations: Array<{ type: 'add' | 'remove'; stream: string }> = [];
+  private pendingStreamOperations: Array<{ type: 'add' | 'remove'; stream: string; shard?: StreamShard }> = [];
   private binanceMetrics: BinanceConnectionMetrics = {
     activeStreams: 0,
+    connections: 1,
     streamChanges: 0,
     reconnectCount: 0,
     messageLatency: 0,
@@ -83,53 +100,92 @@ export class BinanceConnectionManager extends BaseConnectionManager {
     }
   };
 
+  /**
+   * @param defaults 连接配置未携带binance选项时使用的默认值，由适配器传入
+   */
+  constructor(private readonly defaults?: BinanceConnectionConfig['binance']) {
+    super();
+  }
+
+  /**
+   * 获取连接状态，由主连接与所有分片连接汇总
+   * 主连接正常时任一分片重连中即为重连中，分片未连接即为该分片的状态
+   */
+  getState(): ConnectionState {
+    const state = super.getState();
+    if (state !== ConnectionState.CONNECTED) {
+      return state;
+    }
+
+    const shardStates = this.shards.map(shard => shard.manager.getState());
+    if (shardStates.includes(ConnectionState.RECONNECTING)) {
+      return ConnectionState.RECONNECTING;
+    }
+    return shardStates.find(shardState => shardState !== ConnectionState.CONNECTED) ?? state;
+  }
+
   /**
    * 获取Binance特定指标
    */
   getBinanceMetrics(): BinanceConnectionMetrics {
-    this.binanceMetrics.activeStreams = this.activeStreams.size;
+    this.binanceMetrics.activeStreams = this.getActiveStreams().length;
+    this.binanceMetrics.connections = 1 + this.shards.length;
     return { ...this.binanceMetrics };
   }
 
   /**
    * 添加流到组合流
+   * 主连接达到maxStreams后分配到已有分片的空位，都已满时新建分片连接
    */
   async addStream(streamName: string): Promise<void> {
-    if (this.activeStreams.has(streamName)) {
+    if (this.hasStream(streamName)) {
       return;
     }
 
-    // 检查流数量限制
-    const maxStreams = this.binanceConfig?.combinedStream?.maxStreams ?? 1024;
-    if (this.activeStreams.size >= maxStreams) {
-      throw new Error(`Maximum stream limit (${maxStreams}) reached`);
+    const maxStreams = this.getMaxStreams();
+    let shard: StreamShard | undefined;
+
+    if (this.activeStreams.size < maxStreams) {
+      this.activeStreams.add(streamName);
+    } else {
+      shard = this.shards.find(candidate => candidate.streams.size < maxStreams);
+      if (!shard) {
+        const maxConnections = this.getMaxConnections();
+        if (1 + this.shards.length >= maxConnections) {
+          throw new Error(`Maximum stream limit (${maxStreams * maxConnections}) reached`);
+        }
+        shard = this.createShard();
+      }
+      shard.streams.add(streamName);
     }
 
-    this.activeStreams.add(streamName);
     this.binanceMetrics.streamOperations.additions++;
     this.binanceMetrics.streamChanges++;
     this.emit('streamAdded', streamName);
     
     if (this.binanceConfig?.combinedStream?.autoManage) {
-      await this.scheduleStreamUpdate('add', streamName);
+      await this.scheduleStreamUpdate('add', streamName, shard);
     }
   }
 
   /**
    * 从组合流中移除流
+   * 移除后不重新平衡，主连接的空位留给后续新增的流
    */
   async removeStream(streamName: string): Promise<void> {
-    if (!this.activeStreams.has(streamName)) {
+    const shard = this.shards.find(candidate => candidate.streams.has(streamName));
+    if (shard) {
+      shard.streams.delete(streamName);
+    } else if (!this.activeStreams.delete(streamName)) {
       return;
     }
 
-    this.activeStreams.delete(streamName);
     this.binanceMetrics.streamOperations.removals++;
     this.binanceMetrics.streamChanges++;
     this.emit('streamRemoved', streamName);
     
     if (this.binanceConfig?.combinedStream?.autoManage) {
-      await this.scheduleStreamUpdate('remove', streamName);
+      await this.scheduleStreamUpdate('remove', streamName, shard);
     }
   }
 
@@ -137,14 +193,107 @@ export class BinanceConnectionManager extends BaseConnectionManager {
    * 获取活跃的流列表
    */
   getActiveStreams(): string[] {
-    return Array.from(this.activeStreams);
+    return this.getStreamsByConnection().flat();
+  }
+
+  /**
+   * 按连接分组的流列表，第一组为主连接
+   */
+  getStreamsByConnection(): string[][] {
+    return [this.activeStreams, ...this.shards.map(shard => shard.streams)].map(streams => Array.from(streams));
+  }
+
+  /**
+   * 创建分片连接管理器
+   */
+  protected createShardManager(): ConnectionManager {
+    return new BaseConnectionManager();
+  }
+
+  private hasStream(streamName: string): boolean {
+    return this.activeStreams.has(streamName) || this.shards.some(shard => shard.streams.has(streamName));
+  }
+
+  private getMaxStreams(): number {
+    return this.binanceConfig?.combinedStream?.maxStreams ?? 1024;
+  }
+
+  private getMaxConnections(): number {
+    return this.binanceConfig?.connectionPool?.maxConnections ?? 5;
+  }
+
+  /**
+   * 新建分片，分片的消息、错误与连接状态变化经主连接管理器转发
+   * 分片断线即视为整体断线，全部连接恢复后才转发reconnected，由适配器恢复订阅
+   */
+  private createShard(): StreamShard {
+    const manager = this.createShardManager();
+    const shard: StreamShard = { manager, streams: new Set() };
+    manager.on('message', message => this.emit('message', message));
+    manager.on('error', error => this.emit('error', error));
+    manager.on('disconnected', reason => {
+      if (!shard.updating) {
+        this.emit('disconnected', reason);
+      }
+    });
+    manager.on('reconnecting', attempt => this.emit('reconnecting', attempt));
+    manager.on('reconnected', () => {
+      if (this.getState() === ConnectionState.CONNECTED) {
+        this.emit('reconnected');
+      }
+    });
+
+    this.shards.push(shard);
+    return shard;
+  }
+
+  /**
+   * 主动断开或关闭分片，期间不转发断开事件
+   */
+  private async closeShard(shard: StreamShard, destroy = false): Promise<void> {
+    shard.updating = true;
+    try {
+      await (destroy ? shard.manager.destroy() : shard.manager.disconnect());
+    } finally {
+      shard.updating = false;
+    }
+  }
+
+  /**
+   * 按分片当前的流重新连接，流已清空的分片直接关闭
+   */
+  private async reconnectShard(shard: StreamShard): Promise<void> {
+    shard.updating = true;
+    try {
+      if (shard.manager.isConnected()) {
+        await shard.manager.disconnect();
+      }
+
+      if (shard.streams.size === 0) {
+        this.shards = this.shards.filter(candidate => candidate !== shard);
+        await shard.manager.destroy();
+        return;
+      }
+
+      // 主连接尚未建立时只记录分片，随主连接一起连接
+      if (!this.shardConfig) {
+        return;
+      }
+
+      await shard.manager.connect({
+        ...this.shardConfig,
+        url: this.buildCombinedStreamUrl(Array.from(shard.streams), this.shardConfig.url)
+      });
+    } finally {
+      shard.updating = false;
+    }
   }
 
   /**
    * 批量流操作调度器
    */
-  private async scheduleStreamUpdate(type: 'add' | 'remove', streamName: string): Promise<void> {
-    this.pendingStreamOperations.push({ type, stream: streamName });
+  private async scheduleStreamUpdate(type: 'add' | 'remove', streamName: string, shard?: StreamShard): Promise<void> {
+    this.pendingStreamOperations.push({ type, stream: streamName, shard });
     
     if (this.streamBatchTimer) {
       clearTimeout(this.streamBatchTimer);
@@ -164,8 +313,15 @@ export class BinanceConnectionManager extends BaseConnectionManager {
       return;
     }
 
+    const operations = this.pendingStreamOperations;
+    const shards = new Set(operations.map(operation => operation.shard).filter(Boolean) as StreamShard[]);
+
     try {
-      await this.reconnectWithStreams();
+      // 分片的变更不影响主连接，只重连涉及的连接
+      if (operations.some(operation => !operation.shard)) {
+        await this.reconnectWithStreams();
+      }
+      await Promise.all(Array.from(shards).map(shard => this.reconnectShard(shard)));
       this.binanceMetrics.streamOperations.modifications++;
       this.pendingStreamOperations = [];
     } catch (error) {
@@ -188,9 +344,9 @@ export class BinanceConnectionManager extends BaseConnectionManager {
     // 更新URL并重连
     const newConfig = { ...currentConfig, url: newUrl };
     
-    // 断开现有连接
+    // 断开现有连接，分片连接保持不变
     if (this.isConnected()) {
-      await this.disconnect();
+      await super.disconnect();
     }
     
     // 重新连接
@@ -200,6 +356,7 @@ export class BinanceConnectionManager extends BaseConnectionManager {
 
   /**
    * 构建Binance组合流URL
+   * 即使只有一个流也使用组合流地址，保证消息始终带有 {stream, data} 外层结构
    */
   private buildCombinedStreamUrl(streams: string[], baseUrl: string): string {
     // 移除可能的WebSocket路径
@@ -207,13 +364,11 @@ export class BinanceConnectionManager extends BaseConnectionManager {
     
     if (streams.length === 0) {
       return `${cleanBaseUrl}/ws`;
-    } else if (streams.length === 1) {
-      return `${cleanBaseUrl}/ws/${streams[0]}`;
-    } else {
-      // 组合流格式: wss://stream.binance.com:9443/stream?streams=btcusdt@trade/ethusdt@trade
-      const streamParam = streams.join('/');
-      return `${cleanBaseUrl}/stream?streams=${streamParam}`;
     }
+
+    // 组合流格式: wss://stream.binance.com:9443/stream?streams=btcusdt@trade/ethusdt@trade
+    const streamParam = streams.join('/');
+    return `${cleanBaseUrl}/stream?streams=${streamParam}`;
   }
 
   /**
@@ -237,26 +392,14 @@ export class BinanceConnectionManager extends BaseConnectionManager {
   }
 
   /**
-   * 智能重连策略
+   * 重新连接
+   * 退避延迟由BaseConnectionManager按reconnectStrategy统一计算
+   * 主连接已在重连时BaseConnectionManager直接返回，不计入重连次数
    */
   async reconnect(): Promise<void> {
-    const strategy = this.binanceConfig?.reconnectStrategy;
-    if (strategy) {
-      // 计算指数退避延迟
-      const baseDelay = this.getConfig().retryInterval;
-      const backoffBase = strategy.backoffBase ?? 2;
-      const maxDelay = strategy.maxRetryInterval ?? 30000;
-      
-      let delay = Math.min(baseDelay * Math.pow(backoffBase, this.binanceMetrics.reconnectCount), maxDelay);
-      
-      // 添加抖动以避免雷群效应
-      if (strategy.jitter) {
-        delay = delay * (0.5 + Math.random() * 0.5);
-      }
-      
-      await new Promise(resolve => setTimeout(resolve, delay));
+    if (super.getState() !== ConnectionState.RECONNECTING) {
+      this.binanceMetrics.reconnectCount++;
     }
-    
     return super.reconnect();
   }
 
@@ -281,7 +424,8 @@ export class BinanceConnectionManager extends BaseConnectionManager {
     );
     
     const binanceHealth = {
-      activeStreams: this.activeStreams.size,
+      activeStreams: this.getActiveStreams().length,
+      connections: 1 + this.shards.length,
       metrics: this.getBinanceMetrics(),
       connectionPool: {
         size: this.connectionPool.size,
@@ -290,7 +434,7 @@ export class BinanceConnectionManager extends BaseConnectionManager {
     };
     
     return {
-      healthy: baseHealth.healthy && this.activeStreams.size > 0,
+      healthy: baseHealth.healthy && this.activeStreams.size > 0 && this.shards.every(shard => shard.manager.isConnected()),
       details: {
         ...baseHealth,
         binance: binanceHealth
@@ -302,21 +446,57 @@ export class BinanceConnectionManager extends BaseConnectionManager {
    * 重写连接方法以支持Binance特定的URL构建
    */
   async connect(config: BinanceConnectionConfig): Promise<void> {
+    if (!config.binance && this.defaults) {
+      config = { ...config, binance: this.defaults };
+    }
     this.binanceConfig = config.binance;
     
-    // 如果配置了组合流，构建组合流URL
+    // Binance特定的重连策略作为通用重连策略的默认值
+    if (!config.reconnectStrategy && config.binance?.reconnectStrategy) {
+      config = { ...config, reconnectStrategy: config.binance.reconnectStrategy };
+    }
+    
+    if (config.binance?.enableCompression !== undefined && config.enableCompression === undefined) {
+      config = { ...config, enableCompression: config.binance.enableCompression };
+    }
+    this.shardConfig = config;
+    
+    // 如果配置了组合流，构建组合流URL，超出单连接上限的流分片到额外连接
     if (config.binance?.combinedStream?.streams.length) {
-      this.activeStreams = new Set(config.binance.combinedStream.streams);
+      const maxStreams = this.getMaxStreams();
+      const [primary, ...overflow] = chunk(Array.from(new Set(config.binance.combinedStream.streams)), maxStreams);
+      if (1 + overflow.length > this.getMaxConnections()) {
+        throw new Error(`Maximum stream limit (${maxStreams * this.getMaxConnections()}) reached`);
+      }
+
+      const previousShards = this.shards;
+      this.shards = [];
+      await Promise.all(previousShards.map(shard => this.closeShard(shard, true)));
+
+      this.activeStreams = new Set(primary);
+      for (const streams of overflow) {
+        this.createShard().streams = new Set(streams);
+      }
+
       const finalConfig = {
         ...config,
-        url: this.buildCombinedStreamUrl(Array.from(this.activeStreams), config.url)
+        url: this.buildCombinedStreamUrl(primary, config.url)
       };
-      return await super.connect(finalConfig);
+      await super.connect(finalConfig);
+      await Promise.all(this.shards.map(shard => this.reconnectShard(shard)));
     } else {
       return await super.connect(config);
     }
   }
 
+  /**
+   * 断开主连接与所有分片连接
+   */
+  async disconnect(): Promise<void> {
+    await Promise.all(this.shards.map(shard => this.closeShard(shard)));
+    return super.disconnect();
+  }
+
   /**
    * 优雅关闭连接
    */
@@ -326,12 +506,15 @@ export class BinanceConnectionManager extends BaseConnectionManager {
       clearTimeout(this.streamBatchTimer);
     }
     
-    // 清理连接池
+    // 清理连接池与分片连接
     this.connectionPool.clear();
+    await Promise.all(this.shards.map(shard => this.closeShard(shard, true)));
+    this.shards = [];
     
     // 重置指标
     this.binanceMetrics = {
       activeStreams: 0,
+      connections: 1,
       streamChanges: 0,
       reconnectCount: 0,
       messageLatency: 0,
@@ -344,4 +527,12 @@ export class BinanceConnectionManager extends BaseConnectionManager {
     
     return super.destroy();
   }
-}
\ No newline at end of file
+}
+
+function chunk<T>(items: T[], size: number): T[][] {
+  const chunks: T[][] = [];
+  for (let i = 0; i < items.length; i += size) {
+    chunks.push(items.slice(i, i + size));
+  }
+  return chunks;
+}
@@ -0,0 +1,130 @@
+/**
+ * Binance历史数据源
+ * 通过REST接口分页获取历史K线和归集成交
+ */
+
+import {
+  HistoricalDataSource,
+  HistoricalKlineQuery,
+  HistoricalTradeQuery,
+  KlineData,
+  TradeData
+} from '@pixiu/adapter-base';
+import { BaseMonitor, WeightedRateLimiter, HttpPolicy, EXCHANGE_RATE_LIMITS } from '@pixiu/shared-core';
+
+export interface BinanceHistoricalDataSourceOptions {
+  /** REST接口地址 */
+  restUrl?: string;
+  /** 共享的限流器，未指定时创建独立限流器 */
+  rateLimiter?: WeightedRateLimiter;
+  /** 共享的请求策略，指定后忽略rateLimiter */
+  httpPolicy?: HttpPolicy;
+  /** 注册请求策略的熔断与重试指标 */
+  monitor?: BaseMonitor;
+}
+
+/** 按时间范围查询归集成交时，Binance要求跨度不超过1小时 */
+const AGG_TRADES_MAX_WINDOW = 60 * 60 * 1000;
+
+export class BinanceHistoricalDataSource implements HistoricalDataSource {
+  public readonly exchange = 'binance';
+  public readonly maxPageSize = 1000;
+
+  private readonly restUrl: string;
+  private readonly httpPolicy: HttpPolicy;
+
+  constructor(options: BinanceHistoricalDataSourceOptions = {}) {
+    this.restUrl = options.restUrl ?? 'https://api.binance.com/api';
+    // 批量下载可以等待，允许更多次重试
+    this.httpPolicy = options.httpPolicy ?? new HttpPolicy({
+      name: this.exchange,
+      retry: { maxRetries: 5 },
+      monitor: options.monitor,
+      rateLimiter: options.rateLimiter ?? new WeightedRateLimiter({ buckets: EXCHANGE_RATE_LIMITS.binance })
+    });
+  }
+
+  /**
+   * 获取一页历史K线
+   */
+  async fetchKlines(query: HistoricalKlineQuery): Promise<KlineData[]> {
+    const params = new URLSearchParams({
+      symbol: this.normalizeSymbol(query.symbol),
+      interval: query.interval,
+      startTime: query.startTime.toString(),
+      limit: Math.min(query.limit ?? this.maxPageSize, this.maxPageSize).toString()
+    });
+    if (query.endTime !== undefined) {
+      params.set('endTime', query.endTime.toString());
+    }
+
+    const rows: any[] = await this.request('/v3/klines', params, 2);
+    return rows.map((row): KlineData => ({
+      open: parseFloat(row[1]),
+      high: parseFloat(row[2]),
+      low: parseFloat(row[3]),
+      close: parseFloat(row[4]),
+      volume: parseFloat(row[5]),
+      openTime: row[0],
+      closeTime: row[6],
+      interval: query.interval
+    }));
+  }
+
+  /**
+   * 获取一页历史归集成交
+   * 按时间查询时会跳过没有成交的时间窗口，未指定起始时间时从结束时间前1小时开始
+   */
+  async fetchTrades(query: HistoricalTradeQuery): Promise<TradeData[]> {
+    const endTime = query.endTime ?? Date.now();
+    const params = new URLSearchParams({
+      symbol: this.normalizeSymbol(query.symbol),
+      limit: Math.min(query.limit ?? this.maxPageSize, this.maxPageSize).toString()
+    });
+
+    let rows: any[] = [];
+    if (query.fromId !== undefined) {
+      params.set('fromId', query.fromId);
+      rows = await this.request('/v3/aggTrades', params, 4);
+    } else {
+      let windowStart = query.startTime ?? endTime - AGG_TRADES_MAX_WINDOW + 1;
+      while (rows.length === 0 && windowStart <= endTime) {
+        const windowEnd = Math.min(endTime, windowStart + AGG_TRADES_MAX_WINDOW - 1);
+        params.set('startTime', windowStart.toString());
+        params.set('endTime', windowEnd.toString());
+        rows = await this.request('/v3/aggTrades', params, 4);
+        windowStart = windowEnd + 1;
+      }
+    }
+
+    return rows
+      .filter(row => row.T <= endTime)
+      .map((row): TradeData => ({
+        id: row.a.toString(),
+        price: parseFloat(row.p),
+        quantity: parseFloat(row.q),
+        side: row.m ? 'sell' : 'buy',
+        timestamp: row.T
+      }));
+  }
+
+  /**
+   * 发送GET请求，限流与重试由请求策略处理
+   */
+  private async request(path: string, params: URLSearchParams, weight: number): Promise<any> {
+    const response = await this.httpPolicy.fetch(`${this.restUrl}${path}?${params.toString()}`, {
+      endpoint: `GET ${path}`,
+      weight
+    });
+
+    if (!response.ok) {
+      throw new Error(`Binance request ${path} failed: HTTP ${response.status} ${await response.text()}`);
+    }
+
+    return response.json();
+  }
+
+  private normalizeSymbol(symbol: string): string {
+    return symbol.replace('/', '').toUpperCase();
+  }
+}
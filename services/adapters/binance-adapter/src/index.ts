@@ -5,6 +5,11 @@
 
 export * from './binance-adapter';
 export * from './connection/binance-connection-manager';
+export * from './history/binance-historical-data-source';
+export * from './auth/binance-signer';
+export * from './rest/binance-rest-context';
+export * from './instruments/binance-instrument-provider';
+export * from './user-data/binance-user-data-stream';
 
 // 重新导出基础类型，方便使用
 export {
@@ -0,0 +1,77 @@
+/**
+ * Binance品种元数据源
+ * 通过exchangeInfo接口获取现货交易对的价格、数量步长与最小下单金额
+ */
+
+import { InstrumentInfo, InstrumentProvider } from '@pixiu/adapter-base';
+import { BaseMonitor, WeightedRateLimiter, HttpPolicy, EXCHANGE_RATE_LIMITS } from '@pixiu/shared-core';
+
+export interface BinanceInstrumentProviderOptions {
+  /** REST接口地址 */
+  restUrl?: string;
+  /** 共享的限流器，未指定时创建独立限流器 */
+  rateLimiter?: WeightedRateLimiter;
+  /** 共享的请求策略，指定后忽略rateLimiter */
+  httpPolicy?: HttpPolicy;
+  /** 注册请求策略的熔断与重试指标 */
+  monitor?: BaseMonitor;
+}
+
+/** exchangeInfo全量查询的请求权重 */
+const EXCHANGE_INFO_WEIGHT = 20;
+
+export class BinanceInstrumentProvider implements InstrumentProvider {
+  public readonly exchange = 'binance';
+
+  private readonly restUrl: string;
+  private readonly httpPolicy: HttpPolicy;
+
+  constructor(options: BinanceInstrumentProviderOptions = {}) {
+    this.restUrl = options.restUrl ?? 'https://api.binance.com/api';
+    this.httpPolicy = options.httpPolicy ?? new HttpPolicy({
+      name: this.exchange,
+      monitor: options.monitor,
+      rateLimiter: options.rateLimiter ?? new WeightedRateLimiter({ buckets: EXCHANGE_RATE_LIMITS.binance })
+    });
+  }
+
+  /**
+   * 获取全部现货交易对
+   */
+  async fetchInstruments(): Promise<InstrumentInfo[]> {
+    const response = await this.httpPolicy.fetch(`${this.restUrl}/v3/exchangeInfo`, { weight: EXCHANGE_INFO_WEIGHT });
+
+    if (!response.ok) {
+      throw new Error(`Binance request /v3/exchangeInfo failed: HTTP ${response.status} ${await response.text()}`);
+    }
+
+    const body: any = await response.json();
+    return (body.symbols || []).map((symbol: any) => this.parseSymbol(symbol));
+  }
+
+  /**
+   * 解析交易对及其过滤器
+   */
+  private parseSymbol(symbol: any): InstrumentInfo {
+    const filters = new Map<string, any>((symbol.filters || []).map((filter: any) => [filter.filterType, filter]));
+    const priceFilter = filters.get('PRICE_FILTER');
+    const lotSize = filters.get('LOT_SIZE');
+    // 新版接口使用NOTIONAL，旧版为MIN_NOTIONAL
+    const notional = filters.get('NOTIONAL') ?? filters.get('MIN_NOTIONAL');
+
+    return {
+      exchange: this.exchange,
+      symbol: `${symbol.baseAsset}/${symbol.quoteAsset}`,
+      exchangeSymbol: symbol.symbol,
+      base: symbol.baseAsset,
+      quote: symbol.quoteAsset,
+      type: 'spot',
+      tickSize: parseFloat(priceFilter?.tickSize ?? '0'),
+      lotSize: parseFloat(lotSize?.stepSize ?? '0'),
+      minQuantity: lotSize ? parseFloat(lotSize.minQty) : undefined,
+      minNotional: notional ? parseFloat(notional.minNotional) : undefined,
+      contractMultiplier: 1,
+      active: symbol.status === 'TRADING'
+    };
+  }
+}
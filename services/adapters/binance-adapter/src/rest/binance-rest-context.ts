@@ -0,0 +1,133 @@
+/**
+ * Binance REST共享上下文
+ * 同一交易所的行情快照、签名请求、品种与历史数据、用户数据流和服务器时间探测共用一个限流器，
+ * 权重统一记账，时钟只在一处定时校准
+ */
+
+import { ServerClock } from '@pixiu/adapter-base';
+import { BaseMonitor, WeightedRateLimiter, HttpPolicy, ProxyPool, ProxyPoolOptions, EXCHANGE_RATE_LIMITS } from '@pixiu/shared-core';
+import { BinanceSigner, fetchBinanceServerTime } from '../auth/binance-signer';
+import { BinanceInstrumentProvider } from '../instruments/binance-instrument-provider';
+import { BinanceHistoricalDataSource } from '../history/binance-historical-data-source';
+import { BinanceUserDataStream, BinanceUserDataStreamOptions } from '../user-data/binance-user-data-stream';
+
+export interface BinanceRestContextOptions {
+  /** REST接口地址 */
+  restUrl?: string;
+  /** 每分钟请求权重上限，默认使用交易所规则 */
+  weightLimit?: number;
+  /** 出口代理池，传入选项时由上下文创建并管理其生命周期 */
+  proxyPool?: ProxyPool | ProxyPoolOptions;
+  /** 请求超时（毫秒），默认10秒 */
+  timeout?: number;
+  /** 注册请求策略的熔断与重试指标 */
+  monitor?: BaseMonitor;
+}
+
+export interface BinanceCredentials {
+  apiKey: string;
+  apiSecret: string;
+  /** 请求有效期（毫秒），默认5000 */
+  recvWindow?: number;
+}
+
+export class BinanceRestContext {
+  readonly restUrl: string;
+  readonly rateLimiter: WeightedRateLimiter;
+  readonly httpPolicy: HttpPolicy;
+  readonly clock: ServerClock;
+  private readonly timeout: number;
+  private readonly monitor?: BaseMonitor;
+  private readonly ownedProxyPool?: ProxyPool;
+  private readonly signers = new Map<string, BinanceSigner>();
+
+  constructor(options: BinanceRestContextOptions = {}) {
+    this.restUrl = (options.restUrl ?? 'https://api.binance.com/api').replace(/\/+$/, '');
+    this.timeout = options.timeout ?? 10000;
+    this.monitor = options.monitor;
+    this.rateLimiter = new WeightedRateLimiter({
+      buckets: EXCHANGE_RATE_LIMITS.binance.map(bucket =>
+        bucket.name === 'weight' && options.weightLimit ? { ...bucket, limit: options.weightLimit } : bucket
+      )
+    });
+    if (options.proxyPool && !(options.proxyPool instanceof ProxyPool)) {
+      this.ownedProxyPool = new ProxyPool(options.proxyPool);
+    }
+    this.httpPolicy = new HttpPolicy({
+      name: 'binance',
+      rateLimiter: this.rateLimiter,
+      monitor: options.monitor,
+      proxyPool: this.ownedProxyPool ?? (options.proxyPool as ProxyPool | undefined)
+    });
+    this.clock = new ServerClock({
+      fetchServerTime: () => fetchBinanceServerTime(this.httpPolicy, this.restUrl, this.timeout)
+    });
+  }
+
+  /**
+   * 获取密钥对应的签名器，同一密钥复用同一个签名器，所有签名器共用本上下文的时钟
+   */
+  getSigner(credentials: BinanceCredentials): BinanceSigner {
+    let signer = this.signers.get(credentials.apiKey);
+    if (!signer) {
+      signer = new BinanceSigner({
+        ...credentials,
+        restUrl: this.restUrl,
+        timeout: this.timeout,
+        clock: this.clock,
+        httpPolicy: this.httpPolicy
+      });
+      this.signers.set(credentials.apiKey, signer);
+    }
+    return signer;
+  }
+
+  /**
+   * 创建共用请求策略的品种数据源
+   */
+  createInstrumentProvider(): BinanceInstrumentProvider {
+    return new BinanceInstrumentProvider({ restUrl: this.restUrl, httpPolicy: this.httpPolicy });
+  }
+
+  /**
+   * 创建共用限流器的历史数据源，批量下载使用其自身的重试策略
+   */
+  createHistoricalDataSource(): BinanceHistoricalDataSource {
+    return new BinanceHistoricalDataSource({ restUrl: this.restUrl, rateLimiter: this.rateLimiter, monitor: this.monitor });
+  }
+
+  /**
+   * 创建共用请求策略的用户数据流
+   */
+  createUserDataStream(
+    options: Omit<BinanceUserDataStreamOptions, 'restUrl' | 'rateLimiter' | 'httpPolicy' | 'monitor'>
+  ): BinanceUserDataStream {
+    return new BinanceUserDataStream({ ...options, restUrl: this.restUrl, httpPolicy: this.httpPolicy });
+  }
+
+  /**
+   * 开始定时校准服务器时钟
+   */
+  start(): Promise<void> {
+    this.ownedProxyPool?.start();
+    return this.clock.start();
+  }
+
+  /**
+   * 停止定时校准，签名请求在时钟未校准时仍会按需校准
+   */
+  stop(): void {
+    this.clock.stop();
+    this.ownedProxyPool?.stop();
+  }
+
+  /**
+   * 停止校准并拒绝所有排队请求
+   */
+  destroy(): void {
+    this.stop();
+    this.rateLimiter.destroy();
+    this.ownedProxyPool?.destroy();
+    this.signers.clear();
+  }
+}
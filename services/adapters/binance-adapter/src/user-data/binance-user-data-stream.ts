@@ -0,0 +1,242 @@
+/**
+ * Binance用户数据流
+ * 管理listenKey的创建、保活与续期，断线或续期后提示调用方通过REST对账
+ *
+ * 事件：
+ * - event(payload) 全部用户数据事件
+ * - executionReport / outboundAccountPosition / balanceUpdate / listStatus(payload) 按事件类型分发
+ * - renewed(listenKey) listenKey已更换并切换到新的数据流
+ * - resyncRequired(reason) 期间可能丢失事件，需要重新查询订单与余额
+ * - error(error)
+ */
+
+import { EventEmitter } from 'events';
+import { BaseConnectionManager, ConnectionConfig, ConnectionManager } from '@pixiu/adapter-base';
+import { BaseMonitor, WeightedRateLimiter, HttpPolicy, EXCHANGE_RATE_LIMITS } from '@pixiu/shared-core';
+
+export interface BinanceUserDataStreamOptions {
+  /** API密钥，listenKey接口只需要X-MBX-APIKEY，不需要签名 */
+  apiKey: string;
+  /** REST接口地址 */
+  restUrl?: string;
+  /** WebSocket地址 */
+  wsUrl?: string;
+  /** 保活间隔（毫秒），listenKey 60分钟过期，默认30分钟 */
+  keepaliveInterval?: number;
+  /** 连接配置 */
+  connection?: Partial<Omit<ConnectionConfig, 'url'>>;
+  /** 共享的限流器 */
+  rateLimiter?: WeightedRateLimiter;
+  /** 共享的请求策略，指定后忽略rateLimiter */
+  httpPolicy?: HttpPolicy;
+  /** 注册请求策略的熔断与重试指标 */
+  monitor?: BaseMonitor;
+  /** 连接管理器，测试时替换 */
+  connectionManager?: ConnectionManager;
+}
+
+/** userDataStream接口的请求权重 */
+const USER_DATA_STREAM_WEIGHT = 2;
+
+/** listenKey不存在或已过期 */
+const INVALID_LISTEN_KEY = -1125;
+
+export class BinanceUserDataStream extends EventEmitter {
+  private readonly restUrl: string;
+  private readonly wsUrl: string;
+  private readonly keepaliveInterval: number;
+  private readonly httpPolicy: HttpPolicy;
+  private readonly connectionManager: ConnectionManager;
+  private listenKey?: string;
+  private keepaliveTimer?: NodeJS.Timeout;
+  private renewing?: Promise<void>;
+  private running = false;
+
+  constructor(private readonly options: BinanceUserDataStreamOptions) {
+    super();
+    this.restUrl = options.restUrl ?? 'https://api.binance.com/api';
+    this.wsUrl = options.wsUrl ?? 'wss://stream.binance.com:9443/ws';
+    this.keepaliveInterval = options.keepaliveInterval ?? 30 * 60 * 1000;
+    this.httpPolicy = options.httpPolicy ?? new HttpPolicy({
+      name: 'binance',
+      monitor: options.monitor,
+      rateLimiter: options.rateLimiter ?? new WeightedRateLimiter({ buckets: EXCHANGE_RATE_LIMITS.binance })
+    });
+    this.connectionManager = options.connectionManager ?? new BaseConnectionManager();
+
+    this.connectionManager.on('message', (message: any) => this.handleMessage(message));
+    // 重连使用原listenKey，先确认其仍然有效
+    this.connectionManager.on('reconnected', () => {
+      this.emit('resyncRequired', 'reconnected');
+      this.keepalive().catch(error => this.emit('error', error));
+    });
+    this.connectionManager.on('error', (error: Error) => this.emit('error', error));
+  }
+
+  /**
+   * 当前listenKey
+   */
+  getListenKey(): string | undefined {
+    return this.listenKey;
+  }
+
+  /**
+   * 创建listenKey并连接数据流
+   */
+  async start(): Promise<void> {
+    if (this.running) {
+      return;
+    }
+
+    this.listenKey = await this.createListenKey();
+    await this.connectionManager.connect(this.buildConnectionConfig(this.listenKey));
+    this.running = true;
+
+    this.keepaliveTimer = setInterval(() => {
+      this.keepalive().catch(error => this.emit('error', error));
+    }, this.keepaliveInterval);
+  }
+
+  /**
+   * 断开数据流并删除listenKey
+   */
+  async stop(): Promise<void> {
+    this.running = false;
+    if (this.keepaliveTimer) {
+      clearInterval(this.keepaliveTimer);
+      this.keepaliveTimer = undefined;
+    }
+
+    await this.connectionManager.disconnect();
+
+    if (this.listenKey) {
+      const listenKey = this.listenKey;
+      this.listenKey = undefined;
+      try {
+        await this.request('DELETE', listenKey);
+      } catch (error) {
+        // 删除失败不影响关闭，listenKey会在60分钟后自动过期
+        this.emit('error', error);
+      }
+    }
+  }
+
+  /**
+   * 延长listenKey有效期，listenKey已失效时自动续期
+   */
+  async keepalive(): Promise<void> {
+    if (!this.running || !this.listenKey) {
+      return;
+    }
+
+    try {
+      await this.request('PUT', this.listenKey);
+    } catch (error) {
+      if ((error as BinanceUserDataStreamError).code === INVALID_LISTEN_KEY) {
+        await this.renew('keepaliveRejected');
+        return;
+      }
+      throw error;
+    }
+  }
+
+  /**
+   * 重新获取listenKey，发生变化时切换到新的数据流
+   * 并发调用共享同一次续期
+   */
+  renew(reason: string): Promise<void> {
+    if (!this.renewing) {
+      this.renewing = this.doRenew(reason).finally(() => {
+        this.renewing = undefined;
+      });
+    }
+    return this.renewing;
+  }
+
+  private async doRenew(reason: string): Promise<void> {
+    if (!this.running) {
+      return;
+    }
+
+    const listenKey = await this.createListenKey();
+    if (listenKey !== this.listenKey) {
+      this.listenKey = listenKey;
+      await this.connectionManager.disconnect();
+      await this.connectionManager.connect(this.buildConnectionConfig(listenKey));
+      this.emit('renewed', listenKey);
+    }
+
+    this.emit('resyncRequired', reason);
+  }
+
+  /**
+   * 处理用户数据事件
+   */
+  private handleMessage(message: any): void {
+    if (!message || typeof message !== 'object' || typeof message.e !== 'string') {
+      return;
+    }
+
+    if (message.e === 'listenKeyExpired') {
+      this.renew('listenKeyExpired').catch(error => this.emit('error', error));
+      return;
+    }
+
+    this.emit('event', message);
+    this.emit(message.e, message);
+  }
+
+  /**
+   * 创建listenKey，已存在有效listenKey时交易所返回同一个值
+   */
+  private async createListenKey(): Promise<string> {
+    const body = await this.request('POST');
+    return body.listenKey;
+  }
+
+  /**
+   * 调用userDataStream接口
+   */
+  private async request(method: 'POST' | 'PUT' | 'DELETE', listenKey?: string): Promise<any> {
+    const query = listenKey ? `?listenKey=${encodeURIComponent(listenKey)}` : '';
+    // 创建listenKey时已有有效key会原样返回，可以安全重试
+    const response = await this.httpPolicy.fetch(`${this.restUrl}/v3/userDataStream${query}`, {
+      method,
+      headers: { 'X-MBX-APIKEY': this.options.apiKey },
+      endpoint: `${method} /v3/userDataStream`,
+      weight: USER_DATA_STREAM_WEIGHT,
+      idempotent: true
+    });
+
+    const body: any = await response.json().catch(() => ({}));
+    if (!response.ok) {
+      throw new BinanceUserDataStreamError(
+        `Binance ${method} /v3/userDataStream failed: HTTP ${response.status} ${body.msg ?? ''}`.trim(),
+        body.code
+      );
+    }
+    return body;
+  }
+
+  private buildConnectionConfig(listenKey: string): ConnectionConfig {
+    return {
+      timeout: 10000,
+      maxRetries: 10,
+      retryInterval: 1000,
+      heartbeatInterval: 30000,
+      heartbeatTimeout: 10000,
+      ...this.options.connection,
+      url: `${this.wsUrl}/${listenKey}`
+    };
+  }
+}
+
+/**
+ * userDataStream接口错误，code为交易所错误码
+ */
+export class BinanceUserDataStreamError extends Error {
+  constructor(message: string, readonly code?: number) {
+    super(message);
+    this.name = 'BinanceUserDataStreamError';
+  }
+}
@@ -0,0 +1,83 @@
+/**
+ * Binance签名请求单元测试
+ */
+
+import { createHmac } from 'crypto';
+import { ServerClock } from '@pixiu/adapter-base';
+import { globalCache } from '@pixiu/shared-core';
+import { BinanceApiError, BinanceSigner } from '../../src';
+
+describe('BinanceSigner', () => {
+  const originalFetch = global.fetch;
+  let offsets: number[];
+  let clock: ServerClock;
+
+  const respond = (status: number, body: any) => ({
+    ok: status >= 200 && status < 300,
+    status,
+    headers: new Headers(),
+    json: async () => body
+  });
+
+  beforeEach(() => {
+    offsets = [0];
+    clock = new ServerClock({ fetchServerTime: async () => Date.now() + offsets.shift()! });
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  it('应该使用服务器时间与recvWindow签名', async () => {
+    offsets = [-10000];
+    await clock.calibrate();
+    const signer = new BinanceSigner({ apiKey: 'key', apiSecret: 'secret', recvWindow: 10000, clock });
+
+    const query = signer.sign({ symbol: 'BTCUSDT', limit: 10, fromId: undefined });
+    const params = new URLSearchParams(query);
+    const payload = query.slice(0, query.indexOf('&signature='));
+
+    expect(payload).toMatch(/^symbol=BTCUSDT&limit=10&recvWindow=10000&timestamp=\d+$/);
+    expect(Math.abs(Number(params.get('timestamp')) - (Date.now() - 10000))).toBeLessThan(50);
+    expect(params.get('signature')).toBe(createHmac('sha256', 'secret').update(payload).digest('hex'));
+  });
+
+  it('时间戳被拒绝时应该重新校准并重试一次', async () => {
+    offsets = [0, 3000];
+    const signer = new BinanceSigner({ apiKey: 'key', apiSecret: 'secret', clock });
+    const timestamps: number[] = [];
+    global.fetch = jest.fn(async (input: any, init: any) => {
+      const url = new URL(input);
+      timestamps.push(Number(url.searchParams.get('timestamp')));
+      expect(url.origin + url.pathname).toBe('https://api.binance.com/api/v3/account');
+      expect(init.headers).toEqual({ 'X-MBX-APIKEY': 'key' });
+      return timestamps.length === 1
+        ? respond(400, { code: -1021, msg: 'Timestamp for this request is outside of the recvWindow.' })
+        : respond(200, { balances: [] });
+    }) as any;
+
+    await expect(signer.request('GET', '/api/v3/account', {}, 20)).resolves.toEqual({ balances: [] });
+
+    expect(timestamps).toHaveLength(2);
+    expect(timestamps[1] - timestamps[0]).toBeGreaterThan(2900);
+  });
+
+  it('其他错误应该直接抛出', async () => {
+    const signer = new BinanceSigner({ apiKey: 'key', apiSecret: 'secret', clock });
+    global.fetch = jest.fn().mockResolvedValue(respond(401, { code: -2015, msg: 'Invalid API-key, IP, or permissions for action.' })) as any;
+
+    const error = await signer.request('GET', '/sapi/v1/account/apiRestrictions').catch(err => err);
+
+    expect(error).toBeInstanceOf(BinanceApiError);
+    expect(error.code).toBe(-2015);
+    expect(global.fetch).toHaveBeenCalledTimes(1);
+  });
+
+  it('应该拒绝超出上限的recvWindow', () => {
+    expect(() => new BinanceSigner({ apiKey: 'key', apiSecret: 'secret', recvWindow: 90000 })).toThrow('recvWindow must be between 1 and 60000 ms');
+  });
+});
@@ -12,7 +12,8 @@ enum DataType {
   TRADE = 'trade',
   TICKER = 'ticker',
   KLINE_1M = 'kline_1m',
-  KLINE_5M = 'kline_5m'
+  KLINE_5M = 'kline_5m',
+  DEPTH = 'depth'
 }
 
 enum AdapterStatus {
@@ -236,6 +237,45 @@ describe('BinanceAdapter', () => {
       });
     });
 
+    it('应该能够解析深度增量并更新订单簿', () => {
+      (adapter as any).ensureOrderBook('BTC/USDT');
+      const book = adapter.getOrderBook('BTC/USDT')!;
+      book.applySnapshot({
+        lastUpdateId: 100,
+        bids: [['49999.00', '1.0']],
+        asks: [['50001.00', '1.0']]
+      });
+
+      const mockMessage = {
+        stream: 'btcusdt@depth',
+        data: {
+          e: 'depthUpdate',
+          E: 1234567890,
+          s: 'BTCUSDT',
+          U: 101,
+          u: 102,
+          b: [['50000.00', '2.0']],
+          a: [['50001.00', '0.00000000']]
+        }
+      };
+
+      const result = (adapter as any).parseMessage(mockMessage);
+
+      expect(result).toMatchObject({
+        symbol: 'BTC/USDT',
+        type: DataType.DEPTH,
+        data: {
+          bids: [[50000, 2]],
+          asks: [[50001, 0]],
+          firstUpdateId: 101,
+          finalUpdateId: 102
+        }
+      });
+      expect(book.getLastUpdateId()).toBe(102);
+      expect(book.bestBid()?.price).toBe(50000);
+      expect(book.bestAsk()).toBeUndefined();
+    });
+
     it('应该忽略无效消息', () => {
       const invalidMessage = { invalid: 'message' };
       
@@ -245,6 +285,54 @@ describe('BinanceAdapter', () => {
     });
   });
 
+  describe('能力声明', () => {
+    it('应该声明支持的行情数据类型和组合流', () => {
+      const capabilities = adapter.getCapabilities();
+
+      expect(capabilities.dataTypes).toEqual(expect.arrayContaining([
+        DataType.TRADE,
+        DataType.TICKER,
+        DataType.KLINE_1M,
+        DataType.KLINE_5M
+      ]));
+      expect(capabilities.websocket).toBe(true);
+      expect(capabilities.combinedStreams).toBe(true);
+      expect(capabilities.maxSubscriptionsPerConnection).toBe(1024);
+    });
+
+    it('单连接订阅上限应该跟随maxStreamsPerConnection', async () => {
+      await adapter.initialize({ ...mockConfig, binance: { maxStreamsPerConnection: 200 } });
+
+      expect(adapter.getCapabilities().maxSubscriptionsPerConnection).toBe(200);
+    });
+
+    it('配置API密钥时应该支持用户数据流，尚未接入的交易能力声明为不支持', async () => {
+      expect(adapter.getCapabilities().userDataStream).toBe(false);
+
+      await adapter.initialize({ ...mockConfig, auth: { apiKey: 'key', apiSecret: 'secret' } });
+      const capabilities = adapter.getCapabilities();
+
+      expect(capabilities.userDataStream).toBe(true);
+      expect(capabilities.trading).toEqual({
+        spot: false,
+        margin: false,
+        futures: false,
+        oco: false,
+        postOnly: false
+      });
+    });
+
+    it('关闭用户数据流时不应该声明支持', async () => {
+      await adapter.initialize({
+        ...mockConfig,
+        auth: { apiKey: 'key', apiSecret: 'secret' },
+        binance: { userDataStream: { enabled: false } }
+      });
+
+      expect(adapter.getCapabilities().userDataStream).toBe(false);
+    });
+  });
+
   describe('工厂函数', () => {
     it('应该能够通过工厂函数创建适配器', () => {
       const adapter = createBinanceAdapter(mockConfig);
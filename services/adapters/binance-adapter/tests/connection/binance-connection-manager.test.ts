@@ -136,7 +136,7 @@ describe('BinanceConnectionManager', () => {
       
       process.nextTick(() => {
         const ws = (connectionManager as any).ws as MockWebSocket;
-        expect(ws.url).toBe('wss://stream.binance.com:9443/ws/btcusdt@trade');
+        expect(ws.url).toBe('wss://stream.binance.com:9443/stream?streams=btcusdt@trade');
         ws.mockConnect();
       });
       
@@ -0,0 +1,181 @@
+/**
+ * BinanceConnectionManager流分片测试
+ * 验证超出单连接流上限时自动分片到额外连接
+ */
+
+import { BaseConnectionManager, ConnectionState } from '@pixiu/adapter-base';
+import { BinanceConnectionManager, BinanceConnectionConfig } from '../../src/connection/binance-connection-manager';
+
+describe('BinanceConnectionManager流分片', () => {
+  let connectionManager: BinanceConnectionManager;
+  let connectedUrls: string[];
+  let connectSpy: jest.SpyInstance;
+
+  const createConfig = (streams: string[], maxConnections = 5): BinanceConnectionConfig => ({
+    url: 'wss://stream.binance.com:9443/ws',
+    timeout: 5000,
+    maxRetries: 3,
+    retryInterval: 1000,
+    heartbeatInterval: 30000,
+    heartbeatTimeout: 10000,
+    binance: {
+      enableCompression: true,
+      combinedStream: { streams, autoManage: true, maxStreams: 2, batchDelay: 0 },
+      connectionPool: { maxConnections }
+    }
+  });
+
+  const flushBatch = () => new Promise(resolve => setTimeout(resolve, 10));
+
+  beforeEach(() => {
+    connectedUrls = [];
+    connectSpy = jest.spyOn(BaseConnectionManager.prototype, 'connect').mockImplementation(async function (config) {
+      connectedUrls.push(config.url);
+    });
+    connectionManager = new BinanceConnectionManager();
+  });
+
+  afterEach(async () => {
+    await connectionManager.destroy();
+    connectSpy.mockRestore();
+  });
+
+  it('初始流超过单连接上限时应该分片到多个连接', async () => {
+    await connectionManager.connect(createConfig(['a@trade', 'b@trade', 'c@trade', 'd@trade', 'e@trade']));
+
+    expect(connectionManager.getStreamsByConnection()).toEqual([
+      ['a@trade', 'b@trade'],
+      ['c@trade', 'd@trade'],
+      ['e@trade']
+    ]);
+    expect(connectedUrls).toEqual([
+      'wss://stream.binance.com:9443/stream?streams=a@trade/b@trade',
+      'wss://stream.binance.com:9443/stream?streams=c@trade/d@trade',
+      'wss://stream.binance.com:9443/stream?streams=e@trade'
+    ]);
+    expect(connectionManager.getBinanceMetrics().connections).toBe(3);
+  });
+
+  it('只有一个流的分片也应该使用组合流地址', async () => {
+    await connectionManager.connect(createConfig(['a@trade', 'b@trade']));
+    connectedUrls = [];
+
+    await connectionManager.addStream('c@trade');
+    await flushBatch();
+
+    expect(connectionManager.getStreamsByConnection()).toEqual([['a@trade', 'b@trade'], ['c@trade']]);
+    expect(connectedUrls).toEqual(['wss://stream.binance.com:9443/stream?streams=c@trade']);
+  });
+
+  it('应该把binance.enableCompression传递给每个连接', async () => {
+    await connectionManager.connect(createConfig(['a@trade', 'b@trade', 'c@trade']));
+
+    expect(connectSpy.mock.calls.map(call => call[0].enableCompression)).toEqual([true, true]);
+  });
+
+  it('新增流只应重连有空位的分片', async () => {
+    await connectionManager.connect(createConfig(['a@trade', 'b@trade', 'c@trade']));
+    connectedUrls = [];
+
+    await connectionManager.addStream('d@trade');
+    await flushBatch();
+
+    expect(connectedUrls).toEqual(['wss://stream.binance.com:9443/stream?streams=c@trade/d@trade']);
+  });
+
+  it('分片的流全部移除后应该关闭该分片', async () => {
+    await connectionManager.connect(createConfig(['a@trade', 'b@trade', 'c@trade']));
+
+    await connectionManager.removeStream('c@trade');
+    await flushBatch();
+
+    expect(connectionManager.getStreamsByConnection()).toEqual([['a@trade', 'b@trade']]);
+    expect(connectionManager.getBinanceMetrics().connections).toBe(1);
+  });
+
+  it('分片消息应该经主连接管理器转发', async () => {
+    await connectionManager.connect(createConfig(['a@trade', 'b@trade', 'c@trade']));
+    const messages: any[] = [];
+    connectionManager.on('message', message => messages.push(message));
+
+    const shard = (connectionManager as any).shards[0].manager;
+    shard.emit('message', { stream: 'c@trade', data: {} });
+
+    expect(messages).toEqual([{ stream: 'c@trade', data: {} }]);
+  });
+
+  describe('分片连接状态', () => {
+    let primaryState: jest.SpyInstance;
+
+    beforeEach(() => {
+      primaryState = jest.spyOn(BaseConnectionManager.prototype, 'getState').mockReturnValue(ConnectionState.CONNECTED);
+    });
+
+    afterEach(() => {
+      primaryState.mockRestore();
+    });
+
+    it('分片断线与重连应该经主连接管理器转发，全部恢复后才转发reconnected', async () => {
+      await connectionManager.connect(createConfig(['a@trade', 'b@trade', 'c@trade', 'd@trade', 'e@trade']));
+      const events: string[] = [];
+      connectionManager.on('disconnected', reason => events.push(`disconnected:${reason}`));
+      connectionManager.on('reconnecting', attempt => events.push(`reconnecting:${attempt}`));
+      connectionManager.on('reconnected', () => events.push('reconnected'));
+      const [first, second] = (connectionManager as any).shards.map((shard: any) => shard.manager);
+      const firstState = jest.spyOn(first, 'getState').mockReturnValue(ConnectionState.RECONNECTING);
+      const secondState = jest.spyOn(second, 'getState').mockReturnValue(ConnectionState.DISCONNECTED);
+
+      first.emit('disconnected', 'socket hang up');
+      first.emit('reconnecting', 1);
+      expect(connectionManager.getState()).toBe(ConnectionState.RECONNECTING);
+
+      firstState.mockReturnValue(ConnectionState.CONNECTED);
+      first.emit('reconnected');
+      expect(connectionManager.getState()).toBe(ConnectionState.DISCONNECTED);
+
+      secondState.mockReturnValue(ConnectionState.CONNECTED);
+      second.emit('reconnected');
+
+      expect(connectionManager.getState()).toBe(ConnectionState.CONNECTED);
+      expect(events).toEqual(['disconnected:socket hang up', 'reconnecting:1', 'reconnected']);
+    });
+
+    it('流变更引起的分片重连不应该转发断开事件', async () => {
+      await connectionManager.connect(createConfig(['a@trade', 'b@trade', 'c@trade']));
+      const shard = (connectionManager as any).shards[0].manager;
+      jest.spyOn(shard, 'isConnected').mockReturnValue(true);
+      jest.spyOn(shard, 'disconnect').mockImplementation(async () => {
+        shard.emit('disconnected', 'Normal closure');
+      });
+      const disconnected = jest.fn();
+      connectionManager.on('disconnected', disconnected);
+
+      await connectionManager.addStream('d@trade');
+      await flushBatch();
+
+      expect(disconnected).not.toHaveBeenCalled();
+    });
+
+    it('主连接已在重连时不应该重复计入重连次数', async () => {
+      await connectionManager.connect(createConfig(['a@trade', 'b@trade', 'c@trade']));
+      const primaryState = jest.spyOn(BaseConnectionManager.prototype, 'getState').mockReturnValue(ConnectionState.RECONNECTING);
+      const reconnect = jest.spyOn(BaseConnectionManager.prototype, 'reconnect').mockResolvedValue(undefined);
+
+      await connectionManager.reconnect();
+      expect(connectionManager.getBinanceMetrics().reconnectCount).toBe(0);
+
+      primaryState.mockReturnValue(ConnectionState.CONNECTED);
+      await connectionManager.reconnect();
+      expect(connectionManager.getBinanceMetrics().reconnectCount).toBe(1);
+
+      primaryState.mockRestore();
+      reconnect.mockRestore();
+    });
+  });
+
+  it('所有连接都已满时应该拒绝新增流', async () => {
+    await connectionManager.connect(createConfig(['a@trade', 'b@trade', 'c@trade', 'd@trade'], 2));
+
+    await expect(connectionManager.addStream('e@trade')).rejects.toThrow('Maximum stream limit (4) reached');
+  });
+});
@@ -0,0 +1,41 @@
+/**
+ * BinanceHistoricalDataSource单元测试
+ */
+
+import { globalCache } from '@pixiu/shared-core';
+import { BinanceHistoricalDataSource } from '../../src';
+
+describe('BinanceHistoricalDataSource', () => {
+  const originalFetch = global.fetch;
+
+  const respond = (body: any) => ({
+    ok: true,
+    status: 200,
+    headers: new Headers(),
+    json: async () => body
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  it('未指定起始时间时应该只查询结束时间前1小时', async () => {
+    const urls: URL[] = [];
+    global.fetch = jest.fn(async (input: any) => {
+      urls.push(new URL(String(input)));
+      return respond([]);
+    }) as any;
+    const source = new BinanceHistoricalDataSource();
+    const endTime = Date.UTC(2026, 0, 1);
+
+    expect(await source.fetchTrades({ symbol: 'BTC/USDT', endTime })).toEqual([]);
+
+    expect(urls).toHaveLength(1);
+    expect(urls[0].searchParams.get('startTime')).toBe(String(endTime - 60 * 60 * 1000 + 1));
+    expect(urls[0].searchParams.get('endTime')).toBe(String(endTime));
+  });
+});
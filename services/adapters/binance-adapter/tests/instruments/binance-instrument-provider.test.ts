@@ -0,0 +1,101 @@
+/**
+ * Binance品种元数据源单元测试
+ */
+
+import { InstrumentRegistry } from '@pixiu/adapter-base';
+import { globalCache } from '@pixiu/shared-core';
+import { BinanceInstrumentProvider } from '../../src';
+
+describe('BinanceInstrumentProvider', () => {
+  const originalFetch = global.fetch;
+
+  const exchangeInfo = {
+    symbols: [
+      {
+        symbol: 'BTCUSDT',
+        status: 'TRADING',
+        baseAsset: 'BTC',
+        quoteAsset: 'USDT',
+        filters: [
+          { filterType: 'PRICE_FILTER', minPrice: '0.01000000', maxPrice: '1000000.00000000', tickSize: '0.01000000' },
+          { filterType: 'LOT_SIZE', minQty: '0.00001000', maxQty: '9000.00000000', stepSize: '0.00001000' },
+          { filterType: 'NOTIONAL', minNotional: '5.00000000', applyMinToMarket: true }
+        ]
+      },
+      {
+        symbol: 'LUNAUSDT',
+        status: 'BREAK',
+        baseAsset: 'LUNA',
+        quoteAsset: 'USDT',
+        filters: [
+          { filterType: 'PRICE_FILTER', tickSize: '0.00010000' },
+          { filterType: 'LOT_SIZE', minQty: '0.01000000', stepSize: '0.01000000' },
+          { filterType: 'MIN_NOTIONAL', minNotional: '10.00000000' }
+        ]
+      }
+    ]
+  };
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  it('应该将exchangeInfo过滤器映射为品种元数据', async () => {
+    global.fetch = jest.fn().mockResolvedValue({
+      ok: true,
+      status: 200,
+      headers: new Headers(),
+      json: async () => exchangeInfo
+    }) as any;
+
+    const instruments = await new BinanceInstrumentProvider({ restUrl: 'https://example.com/api' }).fetchInstruments();
+
+    expect(global.fetch).toHaveBeenCalledWith('https://example.com/api/v3/exchangeInfo');
+    expect(instruments[0]).toEqual({
+      exchange: 'binance',
+      symbol: 'BTC/USDT',
+      exchangeSymbol: 'BTCUSDT',
+      base: 'BTC',
+      quote: 'USDT',
+      type: 'spot',
+      tickSize: 0.01,
+      lotSize: 0.00001,
+      minQuantity: 0.00001,
+      minNotional: 5,
+      contractMultiplier: 1,
+      active: true
+    });
+    expect(instruments[1]).toMatchObject({ minNotional: 10, active: false });
+  });
+
+  it('应该可以作为注册中心的数据源', async () => {
+    global.fetch = jest.fn().mockResolvedValue({
+      ok: true,
+      status: 200,
+      headers: new Headers(),
+      json: async () => exchangeInfo
+    }) as any;
+
+    const registry = new InstrumentRegistry();
+    registry.addProvider(new BinanceInstrumentProvider());
+    await registry.load('binance');
+
+    expect(registry.roundQuantity('binance', 'BTCUSDT', 0.0123456)).toBe(0.01234);
+    expect(registry.validateOrder('binance', 'LUNA/USDT', 0.5, 100)).toContain('Instrument LUNA/USDT is not trading');
+  });
+
+  it('请求失败时应该抛出错误', async () => {
+    global.fetch = jest.fn().mockResolvedValue({
+      ok: false,
+      status: 500,
+      headers: new Headers(),
+      text: async () => 'Internal error'
+    }) as any;
+
+    await expect(new BinanceInstrumentProvider().fetchInstruments()).rejects.toThrow('HTTP 500');
+  });
+});
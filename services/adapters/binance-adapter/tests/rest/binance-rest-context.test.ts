@@ -0,0 +1,130 @@
+/**
+ * BinanceRestContext单元测试
+ */
+
+import { BaseAdapter } from '@pixiu/adapter-base';
+import { globalCache } from '@pixiu/shared-core';
+import { BinanceAdapter, BinanceRestContext } from '../../src';
+
+describe('BinanceRestContext', () => {
+  const originalFetch = global.fetch;
+
+  const respond = (status: number, body: any, headers: Record<string, string> = {}) => ({
+    ok: status >= 200 && status < 300,
+    status,
+    headers: new Headers(headers),
+    json: async () => body
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  it('同一密钥应该复用签名器，所有签名器共用时钟', () => {
+    const context = new BinanceRestContext();
+
+    const signer = context.getSigner({ apiKey: 'key', apiSecret: 'secret' });
+    const other = context.getSigner({ apiKey: 'other', apiSecret: 'secret' });
+
+    expect(context.getSigner({ apiKey: 'key', apiSecret: 'secret' })).toBe(signer);
+    expect(other).not.toBe(signer);
+    expect(signer.clock).toBe(context.clock);
+    expect(other.clock).toBe(context.clock);
+    context.destroy();
+  });
+
+  it('服务器时间探测应该经请求策略发送并计入限流', async () => {
+    const context = new BinanceRestContext({ restUrl: 'https://api.binance.com/api/' });
+    global.fetch = jest.fn(async (input: any) => {
+      expect(String(input)).toBe('https://api.binance.com/api/v3/time');
+      return respond(200, { serverTime: Date.now() + 2000 }, { 'x-mbx-used-weight-1m': '7' });
+    }) as any;
+
+    const offset = await context.clock.calibrate();
+
+    expect(Math.abs(offset - 2000)).toBeLessThan(50);
+    expect(context.rateLimiter.getUsage().find(bucket => bucket.name === 'weight')!.used).toBe(7);
+    context.destroy();
+  });
+
+  it('品种数据源与历史数据源应该共用上下文的权重记账', async () => {
+    const context = new BinanceRestContext();
+    global.fetch = jest.fn(async (input: any) => respond(200, String(input).includes('exchangeInfo') ? { symbols: [] } : [])) as any;
+
+    await context.createInstrumentProvider().fetchInstruments();
+    await context.createHistoricalDataSource().fetchKlines({ symbol: 'BTCUSDT', interval: '1m', startTime: 0 });
+
+    expect(context.rateLimiter.getUsage().find(bucket => bucket.name === 'weight')!.used).toBe(22);
+    context.destroy();
+  });
+
+  describe('适配器生命周期', () => {
+    const config = (auth?: { apiKey: string; apiSecret: string }, restContext?: BinanceRestContext) => ({
+      exchange: 'binance',
+      endpoints: { ws: 'wss://stream.binance.com:9443/ws', rest: 'https://api.binance.com/api' },
+      connection: { timeout: 1000, maxRetries: 0, retryInterval: 100, heartbeatInterval: 1000 },
+      auth,
+      binance: { restContext, recvWindow: 8000 }
+    });
+
+    it('配置API密钥时应该在连接后启动自己创建的上下文，断开后停止', async () => {
+      const start = jest.spyOn(BinanceRestContext.prototype, 'start').mockResolvedValue(undefined);
+      const stop = jest.spyOn(BinanceRestContext.prototype, 'stop');
+      const connect = jest.spyOn(BaseAdapter.prototype, 'connect').mockResolvedValue(undefined);
+      const disconnect = jest.spyOn(BaseAdapter.prototype, 'disconnect').mockResolvedValue(undefined);
+      const adapter = new BinanceAdapter();
+      await adapter.initialize(config({ apiKey: 'key', apiSecret: 'secret' }));
+
+      await adapter.connect();
+      expect(start).toHaveBeenCalledTimes(1);
+
+      await adapter.disconnect();
+      expect(stop).toHaveBeenCalledTimes(1);
+      await adapter.destroy();
+      start.mockRestore();
+      stop.mockRestore();
+      connect.mockRestore();
+      disconnect.mockRestore();
+    });
+
+    it('注入的上下文应该由创建方启动和停止', async () => {
+      const context = new BinanceRestContext();
+      const start = jest.spyOn(context, 'start');
+      const stop = jest.spyOn(context, 'stop');
+      const connect = jest.spyOn(BaseAdapter.prototype, 'connect').mockResolvedValue(undefined);
+      const disconnect = jest.spyOn(BaseAdapter.prototype, 'disconnect').mockResolvedValue(undefined);
+      const adapter = new BinanceAdapter();
+      await adapter.initialize(config({ apiKey: 'key', apiSecret: 'secret' }, context));
+
+      await adapter.connect();
+      expect(adapter.getSigner()).toBe(context.getSigner({ apiKey: 'key', apiSecret: 'secret' }));
+      await adapter.disconnect();
+      await adapter.destroy();
+
+      expect(start).not.toHaveBeenCalled();
+      expect(stop).not.toHaveBeenCalled();
+      connect.mockRestore();
+      disconnect.mockRestore();
+      context.destroy();
+    });
+
+    it('未配置API密钥时不应该启动时钟', async () => {
+      const start = jest.spyOn(BinanceRestContext.prototype, 'start');
+      const connect = jest.spyOn(BaseAdapter.prototype, 'connect').mockResolvedValue(undefined);
+      const adapter = new BinanceAdapter();
+      await adapter.initialize(config());
+
+      await adapter.connect();
+
+      expect(start).not.toHaveBeenCalled();
+      expect(adapter.getSigner()).toBeUndefined();
+      await adapter.destroy();
+      start.mockRestore();
+      connect.mockRestore();
+    });
+  });
+});
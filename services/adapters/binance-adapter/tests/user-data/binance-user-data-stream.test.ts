@@ -0,0 +1,196 @@
+/**
+ * Binance用户数据流单元测试
+ */
+
+import { EventEmitter } from 'events';
+import { BaseAdapter } from '@pixiu/adapter-base';
+import { globalCache } from '@pixiu/shared-core';
+import { BinanceAdapter, BinanceRestContext, BinanceUserDataStream } from '../../src';
+
+describe('BinanceUserDataStream', () => {
+  const originalFetch = global.fetch;
+  let fetchMock: jest.Mock;
+  let connection: EventEmitter & { connect: jest.Mock; disconnect: jest.Mock };
+  let stream: BinanceUserDataStream;
+
+  const respond = (status: number, body: any) => ({
+    ok: status >= 200 && status < 300,
+    status,
+    headers: new Headers(),
+    json: async () => body
+  });
+
+  const flush = () => new Promise(resolve => setImmediate(resolve));
+
+  beforeEach(() => {
+    fetchMock = jest.fn().mockResolvedValue(respond(200, { listenKey: 'key-1' }));
+    global.fetch = fetchMock as any;
+
+    connection = Object.assign(new EventEmitter(), {
+      connect: jest.fn().mockResolvedValue(undefined),
+      disconnect: jest.fn().mockResolvedValue(undefined)
+    });
+
+    stream = new BinanceUserDataStream({
+      apiKey: 'test-key',
+      keepaliveInterval: 50,
+      connectionManager: connection as any
+    });
+    stream.on('error', () => undefined);
+  });
+
+  afterEach(async () => {
+    await stream.stop();
+    global.fetch = originalFetch;
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  it('启动时应该创建listenKey并连接数据流', async () => {
+    await stream.start();
+
+    const [url, init] = fetchMock.mock.calls[0];
+    expect(url).toBe('https://api.binance.com/api/v3/userDataStream');
+    expect(init).toMatchObject({ method: 'POST', headers: { 'X-MBX-APIKEY': 'test-key' } });
+    expect(connection.connect.mock.calls[0][0].url).toBe('wss://stream.binance.com:9443/ws/key-1');
+    expect(stream.getListenKey()).toBe('key-1');
+  });
+
+  it('应该定时延长listenKey有效期', async () => {
+    await stream.start();
+    await new Promise(resolve => setTimeout(resolve, 120));
+
+    const keepalives = fetchMock.mock.calls.filter(([, init]) => init.method === 'PUT');
+    expect(keepalives.length).toBeGreaterThanOrEqual(2);
+    expect(keepalives[0][0]).toBe('https://api.binance.com/api/v3/userDataStream?listenKey=key-1');
+  });
+
+  it('保活被拒绝时应该续期并切换数据流', async () => {
+    const renewed: string[] = [];
+    const resyncs: string[] = [];
+    stream.on('renewed', key => renewed.push(key));
+    stream.on('resyncRequired', reason => resyncs.push(reason));
+
+    await stream.start();
+    fetchMock
+      .mockResolvedValueOnce(respond(400, { code: -1125, msg: 'This listenKey does not exist.' }))
+      .mockResolvedValueOnce(respond(200, { listenKey: 'key-2' }));
+
+    await stream.keepalive();
+
+    expect(renewed).toEqual(['key-2']);
+    expect(resyncs).toEqual(['keepaliveRejected']);
+    expect(connection.disconnect).toHaveBeenCalledTimes(1);
+    expect(connection.connect.mock.calls[1][0].url).toBe('wss://stream.binance.com:9443/ws/key-2');
+  });
+
+  it('收到listenKeyExpired时应该续期', async () => {
+    const resyncs: string[] = [];
+    stream.on('resyncRequired', reason => resyncs.push(reason));
+
+    await stream.start();
+    fetchMock.mockResolvedValueOnce(respond(200, { listenKey: 'key-2' }));
+    connection.emit('message', { e: 'listenKeyExpired', E: 1700000000000, listenKey: 'key-1' });
+    connection.emit('message', { e: 'listenKeyExpired', E: 1700000000001, listenKey: 'key-1' });
+    await flush();
+
+    expect(fetchMock.mock.calls.filter(([, init]) => init.method === 'POST')).toHaveLength(2);
+    expect(stream.getListenKey()).toBe('key-2');
+    expect(resyncs).toEqual(['listenKeyExpired']);
+  });
+
+  it('重连后应该提示对账并确认listenKey有效', async () => {
+    const resyncs: string[] = [];
+    stream.on('resyncRequired', reason => resyncs.push(reason));
+
+    await stream.start();
+    connection.emit('reconnected');
+    await flush();
+
+    expect(resyncs).toEqual(['reconnected']);
+    expect(fetchMock.mock.calls[1][1].method).toBe('PUT');
+  });
+
+  it('应该按事件类型分发用户数据', async () => {
+    const reports: any[] = [];
+    const all: any[] = [];
+    stream.on('executionReport', event => reports.push(event));
+    stream.on('event', event => all.push(event));
+
+    await stream.start();
+    connection.emit('message', { e: 'executionReport', s: 'BTCUSDT', X: 'FILLED' });
+    connection.emit('message', { e: 'balanceUpdate', a: 'USDT', d: '100.00' });
+
+    expect(reports).toEqual([{ e: 'executionReport', s: 'BTCUSDT', X: 'FILLED' }]);
+    expect(all).toHaveLength(2);
+  });
+
+  it('停止时应该断开连接并删除listenKey', async () => {
+    await stream.start();
+    await stream.stop();
+
+    const [url, init] = fetchMock.mock.calls[fetchMock.mock.calls.length - 1];
+    expect(init.method).toBe('DELETE');
+    expect(url).toContain('listenKey=key-1');
+    expect(connection.disconnect).toHaveBeenCalled();
+    expect(stream.getListenKey()).toBeUndefined();
+  });
+
+  describe('适配器接入', () => {
+    const config = (userDataStream?: { enabled?: boolean }, auth?: { apiKey: string; apiSecret: string }) => ({
+      exchange: 'binance',
+      endpoints: { ws: 'wss://stream.binance.com:9443/stream', rest: 'https://api.binance.com/api' },
+      connection: { timeout: 1000, maxRetries: 0, retryInterval: 100, heartbeatInterval: 1000 },
+      auth,
+      binance: { restContext: context, userDataStream }
+    });
+    let context: BinanceRestContext;
+    let connect: jest.SpyInstance;
+
+    beforeEach(() => {
+      context = new BinanceRestContext();
+      jest.spyOn(context, 'start').mockResolvedValue(undefined);
+      jest.spyOn(context, 'createUserDataStream').mockImplementation(options =>
+        new BinanceUserDataStream({ ...options, connectionManager: connection as any })
+      );
+      connect = jest.spyOn(BaseAdapter.prototype, 'connect').mockResolvedValue(undefined);
+    });
+
+    afterEach(() => {
+      connect.mockRestore();
+      context.destroy();
+    });
+
+    it('启用后应该随连接启动并转发用户数据事件', async () => {
+      const adapter = new BinanceAdapter();
+      await adapter.initialize(config({ enabled: true }, { apiKey: 'test-key', apiSecret: 'secret' }));
+      const events: any[] = [];
+      adapter.on('userData', event => events.push(event));
+
+      expect(adapter.getCapabilities().userDataStream).toBe(true);
+      await adapter.connect();
+      await flush();
+      expect(connection.connect.mock.calls[0][0].url).toBe('wss://stream.binance.com:9443/ws/key-1');
+
+      connection.emit('message', { e: 'balanceUpdate', a: 'USDT', d: '100.00' });
+      expect(events).toEqual([{ e: 'balanceUpdate', a: 'USDT', d: '100.00' }]);
+
+      await adapter.destroy();
+      expect(fetchMock.mock.calls[fetchMock.mock.calls.length - 1][1].method).toBe('DELETE');
+    });
+
+    it('未启用或未配置API密钥时不应该启动', async () => {
+      for (const adapterConfig of [config(undefined, { apiKey: 'test-key', apiSecret: 'secret' }), config({ enabled: true })]) {
+        const adapter = new BinanceAdapter();
+        await adapter.initialize(adapterConfig);
+        await adapter.connect();
+
+        expect(adapter.getUserDataStream()).toBeUndefined();
+        await adapter.destroy();
+      }
+      expect(connection.connect).not.toHaveBeenCalled();
+    });
+  });
+});
@@ -0,0 +1,459 @@
+/**
+ * Bybit交易所适配器实现
+ * 基于adapter-base框架，接入Bybit v5公共WebSocket行情频道
+ */
+
+import {
+  BaseAdapter,
+  BaseConnectionManager,
+  AdapterConfig,
+  DataType,
+  SubscriptionInfo,
+  SubscriptionConfig,
+  MarketData,
+  ConnectionManager,
+  AdapterCapabilitiesDeclaration,
+  TradeData,
+  TickerData,
+  KlineData,
+  DepthData,
+  OrderBook,
+  HeartbeatMessage,
+  formatSymbol,
+  parseSymbol
+} from '@pixiu/adapter-base';
+
+/** Bybit v5产品类别，决定WebSocket端点与交易对格式 */
+export type BybitCategory = 'spot' | 'linear';
+
+export interface BybitConfig extends AdapterConfig {
+  /** 订阅配置 */
+  subscription?: SubscriptionConfig;
+  /** Bybit特定配置 */
+  bybit?: {
+    /** 产品类别，默认spot */
+    category?: BybitCategory;
+    /** 是否使用测试网端点 */
+    testnet?: boolean;
+    /** 订单簿深度档位（spot支持1/50/200，linear支持1/50/200/500），默认50 */
+    depthLevel?: number;
+    /** 订阅深度数据时是否维护本地订单簿 */
+    orderBook?: boolean;
+  };
+}
+
+/** 数据类型对应的K线周期 */
+const KLINE_INTERVALS: Partial<Record<DataType, { bybit: string; interval: string }>> = {
+  [DataType.KLINE_1M]: { bybit: '1', interval: '1m' },
+  [DataType.KLINE_5M]: { bybit: '5', interval: '5m' },
+  [DataType.KLINE_1H]: { bybit: '60', interval: '1h' },
+  [DataType.KLINE_1D]: { bybit: 'D', interval: '1d' }
+};
+
+export class BybitAdapter extends BaseAdapter {
+  public readonly exchange = 'bybit';
+
+  private subscriptionId = 0;
+  private topicMap = new Map<string, string>(); // subscription -> topic
+  private orderBooks = new Map<string, OrderBook>(); // symbol -> order book
+  private tickers = new Map<string, Record<string, string>>(); // symbol -> 合并后的ticker字段
+
+  /**
+   * 创建连接管理器
+   */
+  protected async createConnectionManager(): Promise<ConnectionManager> {
+    return new BaseConnectionManager();
+  }
+
+  /**
+   * 初始化方法
+   */
+  async initialize(config: BybitConfig): Promise<void> {
+    if (!config?.endpoints?.ws) {
+      throw new Error('WebSocket endpoint (endpoints.ws) is required');
+    }
+
+    await super.initialize(config);
+  }
+
+  /**
+   * 获取产品类别
+   */
+  getCategory(): BybitCategory {
+    return (this.config as BybitConfig).bybit?.category ?? 'spot';
+  }
+
+  /**
+   * 创建订阅
+   */
+  protected async createSubscription(symbol: string, dataType: DataType): Promise<SubscriptionInfo> {
+    const subscriptionId = `${symbol}:${dataType}:${++this.subscriptionId}`;
+    const exchangeSymbol = BybitAdapter.toExchangeSymbol(symbol);
+    const topic = `${this.mapTopic(dataType)}.${exchangeSymbol}`;
+
+    this.topicMap.set(subscriptionId, topic);
+
+    if (dataType === DataType.DEPTH) {
+      this.ensureOrderBook(exchangeSymbol, topic);
+    }
+
+    await this.sendOperation('subscribe', topic);
+
+    return {
+      id: subscriptionId,
+      symbol: this.normalizeSymbol(exchangeSymbol),
+      dataType,
+      subscribedAt: Date.now(),
+      active: true
+    };
+  }
+
+  /**
+   * 移除订阅，同一主题仍有其他订阅时不发送退订
+   */
+  protected async removeSubscription(subscription: SubscriptionInfo): Promise<void> {
+    const topic = this.topicMap.get(subscription.id);
+    if (!topic) {
+      return;
+    }
+    this.topicMap.delete(subscription.id);
+
+    if (Array.from(this.topicMap.values()).includes(topic)) {
+      return;
+    }
+
+    if (subscription.dataType === DataType.DEPTH) {
+      this.removeOrderBook(subscription.symbol);
+    }
+    await this.sendOperation('unsubscribe', topic);
+  }
+
+  /**
+   * 恢复订阅，沿用原订阅记录重新发送订阅请求
+   */
+  protected async restoreSubscription(subscription: SubscriptionInfo): Promise<void> {
+    const topic = this.topicMap.get(subscription.id);
+    if (topic) {
+      await this.sendOperation('subscribe', topic);
+    }
+  }
+
+  /**
+   * 声明Bybit适配器能力
+   * 私有频道与下单接口尚未接入
+   */
+  protected describeCapabilities(): AdapterCapabilitiesDeclaration {
+    return {
+      dataTypes: [
+        DataType.TRADE,
+        DataType.TICKER,
+        DataType.KLINE_1M,
+        DataType.KLINE_5M,
+        DataType.KLINE_1H,
+        DataType.KLINE_1D,
+        DataType.DEPTH
+      ],
+      websocket: true,
+      userDataStream: false,
+      combinedStreams: true,
+      orderBookChecksum: false
+    };
+  }
+
+  /**
+   * Bybit以{"op":"ping"}保活，公共频道的响应为op为ping、ret_msg为pong，私有频道为op为pong
+   */
+  protected describeHeartbeat(): HeartbeatMessage {
+    return {
+      ping: { op: 'ping' },
+      isPong: message => message?.op === 'pong' || (message?.op === 'ping' && message.ret_msg === 'pong')
+    };
+  }
+
+  /**
+   * 解析Bybit消息
+   */
+  protected parseMessage(message: any): MarketData | MarketData[] | null {
+    if (!message || typeof message !== 'object') {
+      return null;
+    }
+
+    if (message.op === 'subscribe' && message.success === false) {
+      this.emit('error', new Error(`Bybit subscribe failed: ${message.ret_msg}`));
+      return null;
+    }
+
+    if (typeof message.topic !== 'string' || !message.data) {
+      // 订阅确认等控制消息，心跳响应已由连接管理器消费
+      return null;
+    }
+
+    const [channel, ...rest] = message.topic.split('.');
+    const exchangeSymbol = rest[rest.length - 1];
+    const timestamp = message.ts;
+
+    switch (channel) {
+      case 'publicTrade':
+        return message.data.map((trade: any) => {
+          const data = this.parseTradeData(trade);
+          return this.createMarketData(trade.s, DataType.TRADE, data.timestamp, data);
+        });
+
+      case 'tickers':
+        return this.createMarketData(exchangeSymbol, DataType.TICKER, timestamp, this.parseTickerData(exchangeSymbol, message));
+
+      case 'kline': {
+        const dataType = this.klineDataType(rest[0]);
+        if (!dataType) {
+          return null;
+        }
+        return message.data.map((kline: any) =>
+          this.createMarketData(exchangeSymbol, dataType, kline.start, this.parseKlineData(kline, KLINE_INTERVALS[dataType]!.interval))
+        );
+      }
+
+      case 'orderbook':
+        this.updateOrderBook(exchangeSymbol, message);
+        return this.createMarketData(exchangeSymbol, DataType.DEPTH, timestamp, this.parseDepthData(message.data, timestamp));
+
+      default:
+        return null;
+    }
+  }
+
+  /**
+   * 获取本地维护的订单簿
+   */
+  getOrderBook(symbol: string): OrderBook | undefined {
+    return this.orderBooks.get(this.normalizeSymbol(BybitAdapter.toExchangeSymbol(symbol)));
+  }
+
+  /**
+   * 销毁适配器
+   */
+  async destroy(): Promise<void> {
+    this.tickers.clear();
+    await super.destroy();
+  }
+
+  /**
+   * 构造行情数据
+   */
+  private createMarketData(exchangeSymbol: string, type: DataType, timestamp: number, data: any): MarketData {
+    return {
+      exchange: this.exchange,
+      symbol: this.normalizeSymbol(exchangeSymbol),
+      type,
+      timestamp,
+      data,
+      receivedAt: Date.now()
+    };
+  }
+
+  /**
+   * 解析成交数据
+   */
+  private parseTradeData(trade: any): TradeData {
+    return {
+      id: String(trade.i),
+      price: parseFloat(trade.p),
+      quantity: parseFloat(trade.v),
+      side: trade.S === 'Sell' ? 'sell' : 'buy',
+      timestamp: trade.T
+    };
+  }
+
+  /**
+   * 解析ticker数据
+   * linear的delta消息只包含变化字段，需要与之前的快照合并；spot的ticker不含最优买卖价，此时为0
+   */
+  private parseTickerData(exchangeSymbol: string, message: any): TickerData {
+    const merged = message.type === 'delta'
+      ? { ...this.tickers.get(exchangeSymbol), ...message.data }
+      : { ...message.data };
+    this.tickers.set(exchangeSymbol, merged);
+
+    return {
+      lastPrice: parseFloat(merged.lastPrice),
+      bidPrice: parseFloat(merged.bid1Price ?? '0'),
+      askPrice: parseFloat(merged.ask1Price ?? '0'),
+      change24h: parseFloat(merged.price24hPcnt) * 100,
+      volume24h: parseFloat(merged.volume24h),
+      high24h: parseFloat(merged.highPrice24h),
+      low24h: parseFloat(merged.lowPrice24h)
+    };
+  }
+
+  /**
+   * 解析K线数据
+   */
+  private parseKlineData(kline: any, interval: string): KlineData {
+    return {
+      open: parseFloat(kline.open),
+      high: parseFloat(kline.high),
+      low: parseFloat(kline.low),
+      close: parseFloat(kline.close),
+      volume: parseFloat(kline.volume),
+      openTime: kline.start,
+      closeTime: kline.end,
+      interval
+    };
+  }
+
+  /**
+   * 解析深度数据
+   */
+  private parseDepthData(data: any, timestamp: number): DepthData {
+    return {
+      bids: (data.b || []).map((level: string[]) => [parseFloat(level[0]), parseFloat(level[1])]),
+      asks: (data.a || []).map((level: string[]) => [parseFloat(level[0]), parseFloat(level[1])]),
+      updateTime: timestamp,
+      firstUpdateId: data.u,
+      finalUpdateId: data.u
+    };
+  }
+
+  /**
+   * 将orderbook主题数据应用到订单簿
+   * 更新ID u 逐条递增；服务重启时会推送 u 为1的快照
+   */
+  private updateOrderBook(exchangeSymbol: string, message: any): void {
+    const book = this.orderBooks.get(this.normalizeSymbol(exchangeSymbol));
+    if (!book) {
+      return;
+    }
+
+    const { b = [], a = [], u } = message.data;
+    if (message.type === 'snapshot') {
+      book.applySnapshot({ lastUpdateId: u, bids: b, asks: a, timestamp: message.ts });
+    } else if (book.isSynced()) {
+      book.applyDelta({ firstUpdateId: u, finalUpdateId: u, bids: b, asks: a, timestamp: message.ts });
+    }
+  }
+
+  /**
+   * 为交易对创建订单簿，缺口时重新订阅以获取新快照
+   */
+  private ensureOrderBook(exchangeSymbol: string, topic: string): void {
+    if ((this.config as BybitConfig).bybit?.orderBook === false) {
+      return;
+    }
+
+    const symbol = this.normalizeSymbol(exchangeSymbol);
+    if (this.orderBooks.has(symbol)) {
+      return;
+    }
+
+    const book = new OrderBook({ symbol });
+    book.on('resyncRequired', () => {
+      this.sendOperation('unsubscribe', topic)
+        .then(() => this.sendOperation('subscribe', topic))
+        .catch(error => this.emit('error', error));
+    });
+    this.orderBooks.set(symbol, book);
+  }
+
+  /**
+   * 移除交易对的订单簿
+   */
+  private removeOrderBook(symbol: string): void {
+    const book = this.orderBooks.get(symbol);
+    if (book) {
+      book.reset();
+      book.removeAllListeners();
+      this.orderBooks.delete(symbol);
+    }
+  }
+
+  /**
+   * 发送订阅/退订操作
+   */
+  private async sendOperation(op: 'subscribe' | 'unsubscribe', topic: string): Promise<void> {
+    if (!this.connectionManager) {
+      throw new Error('Connection manager not initialized');
+    }
+
+    await this.connectionManager.send({ op, args: [topic] });
+  }
+
+  /**
+   * 映射数据类型到主题前缀
+   */
+  private mapTopic(dataType: DataType): string {
+    switch (dataType) {
+      case DataType.TRADE:
+        return 'publicTrade';
+      case DataType.TICKER:
+        return 'tickers';
+      case DataType.DEPTH:
+        return `orderbook.${(this.config as BybitConfig).bybit?.depthLevel ?? 50}`;
+      default: {
+        const kline = KLINE_INTERVALS[dataType];
+        if (kline) {
+          return `kline.${kline.bybit}`;
+        }
+        throw new Error(`Unsupported data type: ${dataType}`);
+      }
+    }
+  }
+
+  /**
+   * 根据Bybit K线周期获取数据类型
+   */
+  private klineDataType(interval: string): DataType | undefined {
+    return (Object.keys(KLINE_INTERVALS) as DataType[]).find(dataType => KLINE_INTERVALS[dataType]!.bybit === interval);
+  }
+
+  /**
+   * Bybit交易对转换为标准格式
+   * spot: BTCUSDT -> BTC/USDT，linear: BTCUSDT -> BTC/USDT:USDT
+   */
+  private normalizeSymbol(exchangeSymbol: string): string {
+    const parsed = parseSymbol(exchangeSymbol);
+    if (!parsed) {
+      return exchangeSymbol;
+    }
+
+    return formatSymbol(this.getCategory() === 'linear' ? { ...parsed, settle: parsed.quote } : parsed);
+  }
+
+  /**
+   * 标准交易对转换为Bybit格式，如 BTC/USDT:USDT -> BTCUSDT
+   */
+  public static toExchangeSymbol(symbol: string): string {
+    return symbol.toUpperCase().split(':')[0].replace(/[/-]/g, '');
+  }
+}
+
+/**
+ * 创建Bybit适配器工厂函数
+ */
+export function createBybitAdapter(config?: Partial<BybitConfig>): BybitAdapter {
+  const adapter = new BybitAdapter();
+
+  if (config) {
+    const category = config.bybit?.category ?? 'spot';
+    const host = config.bybit?.testnet ? 'stream-testnet.bybit.com' : 'stream.bybit.com';
+    const defaultConfig: BybitConfig = {
+      ...config,
+      exchange: 'bybit',
+      endpoints: {
+        ws: `wss://${host}/v5/public/${category}`,
+        rest: config.bybit?.testnet ? 'https://api-testnet.bybit.com' : 'https://api.bybit.com',
+        ...config.endpoints
+      },
+      connection: {
+        timeout: 10000,
+        maxRetries: 5,
+        retryInterval: 2000,
+        // Bybit建议每20秒发送一次{"op":"ping"}
+        heartbeatInterval: 20000,
+        ...config.connection
+      }
+    };
+
+    adapter.initialize(defaultConfig);
+  }
+
+  return adapter;
+}
@@ -0,0 +1,179 @@
+/**
+ * Bybit适配器单元测试
+ */
+
+import { globalCache } from '@pixiu/shared-core';
+import { BybitAdapter, DataType } from '../src';
+
+describe('BybitAdapter', () => {
+  let adapter: BybitAdapter;
+  let send: jest.Mock;
+
+  const createConfig = (category: 'spot' | 'linear') => ({
+    exchange: 'bybit',
+    endpoints: {
+      ws: `wss://stream.bybit.com/v5/public/${category}`,
+      rest: 'https://api.bybit.com'
+    },
+    connection: {
+      timeout: 10000,
+      maxRetries: 3,
+      retryInterval: 1000,
+      heartbeatInterval: 20000
+    },
+    bybit: { category }
+  });
+
+  const setup = async (category: 'spot' | 'linear') => {
+    adapter = new BybitAdapter();
+    await adapter.initialize(createConfig(category) as any);
+    send = jest.fn().mockResolvedValue(undefined);
+    (adapter as any).connectionManager = { send };
+  };
+
+  afterEach(async () => {
+    (adapter as any).connectionManager = undefined;
+    await adapter.destroy();
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  describe('订阅', () => {
+    it('应该按数据类型发送主题订阅', async () => {
+      await setup('spot');
+
+      await (adapter as any).createSubscription('BTC/USDT', DataType.TRADE);
+      await (adapter as any).createSubscription('BTCUSDT', DataType.KLINE_1H);
+      await (adapter as any).createSubscription('ETH/USDT', DataType.DEPTH);
+
+      expect(send.mock.calls.map(call => call[0])).toEqual([
+        { op: 'subscribe', args: ['publicTrade.BTCUSDT'] },
+        { op: 'subscribe', args: ['kline.60.BTCUSDT'] },
+        { op: 'subscribe', args: ['orderbook.50.ETHUSDT'] }
+      ]);
+    });
+
+    it('linear类别应该输出带结算币种的交易对', async () => {
+      await setup('linear');
+
+      const subscription = await (adapter as any).createSubscription('BTC/USDT:USDT', DataType.TICKER);
+
+      expect(adapter.getCategory()).toBe('linear');
+      expect(subscription.symbol).toBe('BTC/USDT:USDT');
+      expect(send).toHaveBeenCalledWith({ op: 'subscribe', args: ['tickers.BTCUSDT'] });
+    });
+  });
+
+  describe('消息解析', () => {
+    it('应该解析批量成交', async () => {
+      await setup('spot');
+
+      const trades = (adapter as any).parseMessage({
+        topic: 'publicTrade.BTCUSDT',
+        type: 'snapshot',
+        ts: 1672304486868,
+        data: [
+          { T: 1672304486865, s: 'BTCUSDT', S: 'Buy', v: '0.001', p: '16578.50', i: '20f43950-d8dd-5b31-9112-a178eb6023af' },
+          { T: 1672304486866, s: 'BTCUSDT', S: 'Sell', v: '0.002', p: '16578.00', i: '20f43950-d8dd-5b31-9112-a178eb6023b0' }
+        ]
+      });
+
+      expect(trades).toHaveLength(2);
+      expect(trades[0]).toMatchObject({
+        exchange: 'bybit',
+        symbol: 'BTC/USDT',
+        type: DataType.TRADE,
+        data: { price: 16578.5, quantity: 0.001, side: 'buy', timestamp: 1672304486865 }
+      });
+      expect(trades[1].data.side).toBe('sell');
+    });
+
+    it('应该合并linear ticker的增量消息', async () => {
+      await setup('linear');
+
+      (adapter as any).parseMessage({
+        topic: 'tickers.BTCUSDT',
+        type: 'snapshot',
+        ts: 1,
+        data: {
+          symbol: 'BTCUSDT', lastPrice: '100', bid1Price: '99.9', ask1Price: '100.1', price24hPcnt: '0.05',
+          volume24h: '1000', highPrice24h: '105', lowPrice24h: '95'
+        }
+      });
+      const ticker = (adapter as any).parseMessage({
+        topic: 'tickers.BTCUSDT',
+        type: 'delta',
+        ts: 2,
+        data: { symbol: 'BTCUSDT', lastPrice: '101', bid1Price: '100.9' }
+      });
+
+      expect(ticker.symbol).toBe('BTC/USDT:USDT');
+      expect(ticker.data).toMatchObject({ lastPrice: 101, bidPrice: 100.9, askPrice: 100.1, high24h: 105 });
+      expect(ticker.data.change24h).toBeCloseTo(5);
+    });
+
+    it('应该按主题周期解析K线', async () => {
+      await setup('spot');
+
+      const [kline] = (adapter as any).parseMessage({
+        topic: 'kline.5.BTCUSDT',
+        type: 'snapshot',
+        ts: 1672324988882,
+        data: [{
+          start: 1672324800000, end: 1672325099999, interval: '5',
+          open: '16649.5', close: '16677', high: '16677', low: '16608', volume: '2.081', confirm: false
+        }]
+      });
+
+      expect(kline.type).toBe(DataType.KLINE_5M);
+      expect(kline.data).toMatchObject({ openTime: 1672324800000, closeTime: 1672325099999, interval: '5m', close: 16677 });
+    });
+
+    it('应该以op ping保活并识别公共与私有频道的pong响应', async () => {
+      await setup('spot');
+      const heartbeat = (adapter as any).describeHeartbeat();
+
+      expect(heartbeat.ping).toEqual({ op: 'ping' });
+      expect(heartbeat.isPong({ success: true, ret_msg: 'pong', conn_id: 'c1', op: 'ping' })).toBe(true);
+      expect(heartbeat.isPong({ op: 'pong', args: ['1675418560633'], conn_id: 'c1' })).toBe(true);
+      expect(heartbeat.isPong({ success: true, ret_msg: '', op: 'subscribe' })).toBe(false);
+    });
+  });
+
+  describe('订单簿', () => {
+    const orderbookMessage = (type: 'snapshot' | 'delta', u: number, b: string[][], a: string[][]) => ({
+      topic: 'orderbook.50.BTCUSDT',
+      type,
+      ts: 1687940967466,
+      data: { s: 'BTCUSDT', b, a, u, seq: u }
+    });
+
+    beforeEach(async () => {
+      await setup('spot');
+      await (adapter as any).createSubscription('BTC/USDT', DataType.DEPTH);
+      (adapter as any).parseMessage(orderbookMessage('snapshot', 100, [['100', '1']], [['101', '2']]));
+      send.mockClear();
+    });
+
+    it('应该基于快照与增量维护订单簿', () => {
+      (adapter as any).parseMessage(orderbookMessage('delta', 101, [['100', '0'], ['99.5', '3']], []));
+
+      const book = adapter.getOrderBook('BTCUSDT')!;
+      expect(book.bestBid()?.price).toBe(99.5);
+      expect(book.getLastUpdateId()).toBe(101);
+    });
+
+    it('更新ID不连续时应该重新订阅', async () => {
+      (adapter as any).parseMessage(orderbookMessage('delta', 105, [['99', '1']], []));
+      await new Promise(resolve => setTimeout(resolve, 0));
+
+      expect(adapter.getOrderBook('BTC/USDT')!.isSynced()).toBe(false);
+      expect(send.mock.calls.map(call => call[0])).toEqual([
+        { op: 'unsubscribe', args: ['orderbook.50.BTCUSDT'] },
+        { op: 'subscribe', args: ['orderbook.50.BTCUSDT'] }
+      ]);
+    });
+  });
+});
@@ -0,0 +1,461 @@
+/**
+ * Coinbase Advanced Trade交易所适配器实现
+ * 基于adapter-base框架，接入Advanced Trade WebSocket公共行情频道
+ */
+
+import { createSign, randomBytes } from 'crypto';
+import {
+  BaseAdapter,
+  BaseConnectionManager,
+  AdapterConfig,
+  DataType,
+  SubscriptionInfo,
+  SubscriptionConfig,
+  MarketData,
+  ConnectionManager,
+  AdapterCapabilitiesDeclaration,
+  TradeData,
+  TickerData,
+  KlineData,
+  DepthData,
+  OrderBook,
+  OrderBookLevelInput,
+  normalizeSymbol
+} from '@pixiu/adapter-base';
+
+export interface CoinbaseConfig extends AdapterConfig {
+  /** 订阅配置 */
+  subscription?: SubscriptionConfig;
+  /** Coinbase特定配置 */
+  coinbase?: {
+    /** 订阅深度数据时是否维护本地订单簿 */
+    orderBook?: boolean;
+    /** JWT有效期（秒），默认120 */
+    jwtTtl?: number;
+  };
+}
+
+/** Advanced Trade WebSocket频道 */
+type CoinbaseChannel = 'ticker' | 'market_trades' | 'level2' | 'candles';
+
+/** Advanced Trade K线频道固定为5分钟周期 */
+const CANDLE_INTERVAL_MS = 5 * 60 * 1000;
+
+export class CoinbaseAdapter extends BaseAdapter {
+  public readonly exchange = 'coinbase';
+
+  private subscriptionId = 0;
+  private channelMap = new Map<string, { channel: CoinbaseChannel; productId: string }>(); // subscription -> channel
+  private orderBooks = new Map<string, OrderBook>(); // symbol -> order book
+  private lastSequence = -1;
+
+  /**
+   * 创建连接管理器
+   */
+  protected async createConnectionManager(): Promise<ConnectionManager> {
+    return new BaseConnectionManager();
+  }
+
+  /**
+   * 初始化方法
+   */
+  async initialize(config: CoinbaseConfig): Promise<void> {
+    if (!config?.endpoints?.ws) {
+      throw new Error('WebSocket endpoint (endpoints.ws) is required');
+    }
+
+    await super.initialize(config);
+
+    // 每次建立连接后重新订阅心跳频道，并重置连接级序列号
+    this.on('connected', () => {
+      this.lastSequence = -1;
+      this.sendChannelRequest('subscribe', 'heartbeats', []).catch(error => this.emit('error', error));
+    });
+  }
+
+  /**
+   * 创建订阅
+   */
+  protected async createSubscription(symbol: string, dataType: DataType): Promise<SubscriptionInfo> {
+    const subscriptionId = `${symbol}:${dataType}:${++this.subscriptionId}`;
+    const channel = this.mapChannel(dataType);
+    const productId = CoinbaseAdapter.toProductId(symbol);
+
+    this.channelMap.set(subscriptionId, { channel, productId });
+
+    if (dataType === DataType.DEPTH) {
+      this.ensureOrderBook(productId);
+    }
+
+    await this.sendChannelRequest('subscribe', channel, [productId]);
+
+    return {
+      id: subscriptionId,
+      symbol: CoinbaseAdapter.fromProductId(productId),
+      dataType,
+      subscribedAt: Date.now(),
+      active: true
+    };
+  }
+
+  /**
+   * 移除订阅，同一频道与交易对仍有其他订阅时不发送退订
+   */
+  protected async removeSubscription(subscription: SubscriptionInfo): Promise<void> {
+    const entry = this.channelMap.get(subscription.id);
+    if (!entry) {
+      return;
+    }
+    this.channelMap.delete(subscription.id);
+
+    const stillUsed = Array.from(this.channelMap.values())
+      .some(other => other.channel === entry.channel && other.productId === entry.productId);
+    if (stillUsed) {
+      return;
+    }
+
+    if (entry.channel === 'level2') {
+      this.removeOrderBook(subscription.symbol);
+    }
+    await this.sendChannelRequest('unsubscribe', entry.channel, [entry.productId]);
+  }
+
+  /**
+   * 恢复订阅，沿用原订阅记录重新发送订阅请求
+   */
+  protected async restoreSubscription(subscription: SubscriptionInfo): Promise<void> {
+    const entry = this.channelMap.get(subscription.id);
+    if (entry) {
+      await this.sendChannelRequest('subscribe', entry.channel, [entry.productId]);
+    }
+  }
+
+  /**
+   * 声明Coinbase适配器能力
+   * 当前仅实现公共行情频道，user频道与下单接口尚未接入
+   */
+  protected describeCapabilities(): AdapterCapabilitiesDeclaration {
+    return {
+      dataTypes: [DataType.TRADE, DataType.TICKER, DataType.KLINE_5M, DataType.DEPTH],
+      websocket: true,
+      userDataStream: false,
+      combinedStreams: true,
+      orderBookChecksum: false
+    };
+  }
+
+  /**
+   * 解析Coinbase消息
+   */
+  protected parseMessage(message: any): MarketData[] | null {
+    if (!message || typeof message.channel !== 'string' || !Array.isArray(message.events)) {
+      return null;
+    }
+
+    this.checkSequence(message.sequence_num);
+    const timestamp = Date.parse(message.timestamp) || Date.now();
+
+    switch (message.channel) {
+      case 'ticker':
+        return message.events.flatMap((event: any) =>
+          (event.tickers || []).map((ticker: any) =>
+            this.createMarketData(ticker.product_id, DataType.TICKER, timestamp, this.parseTickerData(ticker))
+          )
+        );
+
+      case 'market_trades':
+        return message.events.flatMap((event: any) =>
+          (event.trades || []).map((trade: any) => {
+            const data = this.parseTradeData(trade);
+            return this.createMarketData(trade.product_id, DataType.TRADE, data.timestamp, data);
+          })
+        );
+
+      case 'candles':
+        return message.events.flatMap((event: any) =>
+          (event.candles || []).map((candle: any) => {
+            const data = this.parseKlineData(candle);
+            return this.createMarketData(candle.product_id, DataType.KLINE_5M, data.openTime, data);
+          })
+        );
+
+      case 'l2_data':
+        return message.events.map((event: any) => {
+          const data = this.parseDepthData(event, timestamp);
+          this.updateOrderBook(event, message.sequence_num, timestamp);
+          return this.createMarketData(event.product_id, DataType.DEPTH, timestamp, data);
+        });
+
+      default:
+        // subscriptions、heartbeats等控制消息
+        return null;
+    }
+  }
+
+  /**
+   * 获取本地维护的订单簿
+   */
+  getOrderBook(symbol: string): OrderBook | undefined {
+    return this.orderBooks.get(CoinbaseAdapter.fromProductId(CoinbaseAdapter.toProductId(symbol)));
+  }
+
+  /**
+   * 构造行情数据
+   */
+  private createMarketData(productId: string, type: DataType, timestamp: number, data: any): MarketData {
+    return {
+      exchange: this.exchange,
+      symbol: CoinbaseAdapter.fromProductId(productId),
+      type,
+      timestamp,
+      data,
+      receivedAt: Date.now()
+    };
+  }
+
+  /**
+   * 解析成交数据
+   */
+  private parseTradeData(trade: any): TradeData {
+    return {
+      id: String(trade.trade_id),
+      price: parseFloat(trade.price),
+      quantity: parseFloat(trade.size),
+      side: trade.side === 'SELL' ? 'sell' : 'buy',
+      timestamp: Date.parse(trade.time)
+    };
+  }
+
+  /**
+   * 解析ticker数据
+   */
+  private parseTickerData(ticker: any): TickerData {
+    return {
+      lastPrice: parseFloat(ticker.price),
+      bidPrice: parseFloat(ticker.best_bid),
+      askPrice: parseFloat(ticker.best_ask),
+      change24h: parseFloat(ticker.price_percent_chg_24_h),
+      volume24h: parseFloat(ticker.volume_24_h),
+      high24h: parseFloat(ticker.high_24_h),
+      low24h: parseFloat(ticker.low_24_h)
+    };
+  }
+
+  /**
+   * 解析K线数据，start为秒级时间戳
+   */
+  private parseKlineData(candle: any): KlineData {
+    const openTime = parseInt(candle.start, 10) * 1000;
+    return {
+      open: parseFloat(candle.open),
+      high: parseFloat(candle.high),
+      low: parseFloat(candle.low),
+      close: parseFloat(candle.close),
+      volume: parseFloat(candle.volume),
+      openTime,
+      closeTime: openTime + CANDLE_INTERVAL_MS - 1,
+      interval: '5m'
+    };
+  }
+
+  /**
+   * 解析深度数据
+   */
+  private parseDepthData(event: any, timestamp: number): DepthData {
+    const { bids, asks } = this.splitLevels(event.updates || []);
+    return {
+      bids: bids.map(([price, quantity]) => [parseFloat(price as string), parseFloat(quantity as string)]),
+      asks: asks.map(([price, quantity]) => [parseFloat(price as string), parseFloat(quantity as string)]),
+      updateTime: timestamp
+    };
+  }
+
+  /**
+   * 按方向拆分level2档位更新
+   */
+  private splitLevels(updates: any[]): { bids: OrderBookLevelInput[]; asks: OrderBookLevelInput[] } {
+    const bids: OrderBookLevelInput[] = [];
+    const asks: OrderBookLevelInput[] = [];
+    for (const update of updates) {
+      const level: OrderBookLevelInput = [update.price_level, update.new_quantity];
+      if (update.side === 'bid') {
+        bids.push(level);
+      } else {
+        asks.push(level);
+      }
+    }
+    return { bids, asks };
+  }
+
+  /**
+   * 检查连接级序列号，出现缺口说明有消息丢失，需要重建全部订单簿
+   */
+  private checkSequence(sequence: unknown): void {
+    if (typeof sequence !== 'number') {
+      return;
+    }
+
+    const expected = this.lastSequence + 1;
+    this.lastSequence = sequence;
+    if (expected > 0 && sequence > expected) {
+      for (const book of this.orderBooks.values()) {
+        book.resync();
+      }
+    }
+  }
+
+  /**
+   * 将level2事件应用到订单簿
+   */
+  private updateOrderBook(event: any, sequence: number, timestamp: number): void {
+    const book = this.orderBooks.get(CoinbaseAdapter.fromProductId(event.product_id));
+    if (!book) {
+      return;
+    }
+
+    const { bids, asks } = this.splitLevels(event.updates || []);
+    if (event.type === 'snapshot') {
+      book.applySnapshot({ lastUpdateId: sequence ?? 0, bids, asks, timestamp });
+    } else if (book.isSynced()) {
+      book.applyDelta({ bids, asks, timestamp });
+    }
+  }
+
+  /**
+   * 为交易对创建订单簿
+   * Coinbase没有REST增量对齐机制，需要重建时重新订阅level2以获取新快照
+   */
+  private ensureOrderBook(productId: string): void {
+    if ((this.config as CoinbaseConfig).coinbase?.orderBook === false) {
+      return;
+    }
+
+    const symbol = CoinbaseAdapter.fromProductId(productId);
+    if (this.orderBooks.has(symbol)) {
+      return;
+    }
+
+    const book = new OrderBook({ symbol });
+    book.on('resyncRequired', () => {
+      this.sendChannelRequest('unsubscribe', 'level2', [productId])
+        .then(() => this.sendChannelRequest('subscribe', 'level2', [productId]))
+        .catch(error => this.emit('error', error));
+    });
+    this.orderBooks.set(symbol, book);
+  }
+
+  /**
+   * 移除交易对的订单簿
+   */
+  private removeOrderBook(symbol: string): void {
+    const book = this.orderBooks.get(symbol);
+    if (book) {
+      book.reset();
+      book.removeAllListeners();
+      this.orderBooks.delete(symbol);
+    }
+  }
+
+  /**
+   * 发送频道订阅/退订请求，配置了API密钥时附带JWT
+   */
+  private async sendChannelRequest(
+    type: 'subscribe' | 'unsubscribe',
+    channel: CoinbaseChannel | 'heartbeats',
+    productIds: string[]
+  ): Promise<void> {
+    if (!this.connectionManager) {
+      throw new Error('Connection manager not initialized');
+    }
+
+    const request: Record<string, any> = { type, channel, product_ids: productIds };
+    const auth = this.config.auth;
+    if (auth?.apiKey && auth.apiSecret) {
+      request.jwt = CoinbaseAdapter.createJwt(auth.apiKey, auth.apiSecret, undefined, Date.now(),
+        (this.config as CoinbaseConfig).coinbase?.jwtTtl);
+    }
+
+    await this.connectionManager.send(request);
+  }
+
+  /**
+   * 映射数据类型到频道
+   */
+  private mapChannel(dataType: DataType): CoinbaseChannel {
+    switch (dataType) {
+      case DataType.TRADE:
+        return 'market_trades';
+      case DataType.TICKER:
+        return 'ticker';
+      case DataType.DEPTH:
+        return 'level2';
+      case DataType.KLINE_5M:
+        return 'candles';
+      default:
+        throw new Error(`Unsupported data type: ${dataType}`);
+    }
+  }
+
+  /**
+   * 标准交易对转换为Coinbase产品ID，如 BTC/USD -> BTC-USD
+   */
+  public static toProductId(symbol: string): string {
+    return symbol.toUpperCase().replace('/', '-');
+  }
+
+  /**
+   * Coinbase产品ID转换为标准交易对，如 BTC-USD -> BTC/USD
+   */
+  public static fromProductId(productId: string): string {
+    return normalizeSymbol(productId);
+  }
+
+  /**
+   * 生成CDP API密钥的ES256 JWT
+   * WebSocket订阅不需要uri；REST请求的uri格式为 "GET api.coinbase.com/api/v3/brokerage/accounts"
+   */
+  public static createJwt(keyName: string, privateKey: string, uri?: string, now: number = Date.now(), ttl: number = 120): string {
+    const issuedAt = Math.floor(now / 1000);
+    const header = { alg: 'ES256', kid: keyName, nonce: randomBytes(16).toString('hex'), typ: 'JWT' };
+    const payload = { iss: 'cdp', sub: keyName, nbf: issuedAt, exp: issuedAt + ttl, ...(uri ? { uri } : {}) };
+
+    const encode = (value: object) => Buffer.from(JSON.stringify(value)).toString('base64url');
+    const signingInput = `${encode(header)}.${encode(payload)}`;
+
+    // 环境变量中的PEM常以字面量\n保存换行
+    const key = privateKey.replace(/\\n/g, '\n');
+    const signature = createSign('SHA256').update(signingInput).sign({ key, dsaEncoding: 'ieee-p1363' });
+
+    return `${signingInput}.${signature.toString('base64url')}`;
+  }
+}
+
+/**
+ * 创建Coinbase适配器工厂函数
+ */
+export function createCoinbaseAdapter(config?: Partial<CoinbaseConfig>): CoinbaseAdapter {
+  const adapter = new CoinbaseAdapter();
+
+  if (config) {
+    const defaultConfig: CoinbaseConfig = {
+      ...config,
+      exchange: 'coinbase',
+      endpoints: {
+        ws: 'wss://advanced-trade-ws.coinbase.com',
+        rest: 'https://api.coinbase.com',
+        ...config.endpoints
+      },
+      connection: {
+        timeout: 10000,
+        maxRetries: 5,
+        retryInterval: 2000,
+        heartbeatInterval: 30000,
+        ...config.connection
+      }
+    };
+
+    adapter.initialize(defaultConfig);
+  }
+
+  return adapter;
+}
@@ -0,0 +1,219 @@
+/**
+ * Coinbase适配器单元测试
+ */
+
+import { createVerify, generateKeyPairSync } from 'crypto';
+import { CoinbaseAdapter, DataType } from '../src';
+import { globalCache } from '@pixiu/shared-core';
+
+describe('CoinbaseAdapter', () => {
+  let adapter: CoinbaseAdapter;
+  let send: jest.Mock;
+
+  const config = {
+    exchange: 'coinbase',
+    endpoints: {
+      ws: 'wss://advanced-trade-ws.coinbase.com',
+      rest: 'https://api.coinbase.com'
+    },
+    connection: {
+      timeout: 10000,
+      maxRetries: 3,
+      retryInterval: 1000,
+      heartbeatInterval: 30000
+    }
+  };
+
+  const l2Message = (sequence: number, type: 'snapshot' | 'update', updates: any[]) => ({
+    channel: 'l2_data',
+    timestamp: '2024-01-01T00:00:00.000Z',
+    sequence_num: sequence,
+    events: [{ type, product_id: 'BTC-USD', updates }]
+  });
+
+  beforeEach(async () => {
+    adapter = new CoinbaseAdapter();
+    await adapter.initialize({ ...config } as any);
+    send = jest.fn().mockResolvedValue(undefined);
+    (adapter as any).connectionManager = { send };
+  });
+
+  afterEach(async () => {
+    (adapter as any).connectionManager = undefined;
+    await adapter.destroy();
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  describe('订阅', () => {
+    it('应该按数据类型发送频道订阅', async () => {
+      await (adapter as any).createSubscription('BTC/USD', DataType.TRADE);
+      await (adapter as any).createSubscription('eth-usd', DataType.KLINE_5M);
+
+      expect(send.mock.calls.map(call => call[0])).toEqual([
+        { type: 'subscribe', channel: 'market_trades', product_ids: ['BTC-USD'] },
+        { type: 'subscribe', channel: 'candles', product_ids: ['ETH-USD'] }
+      ]);
+    });
+
+    it('应该拒绝不支持的数据类型', async () => {
+      await expect((adapter as any).createSubscription('BTC/USD', DataType.KLINE_1M)).rejects.toThrow('Unsupported data type');
+      expect(adapter.getCapabilities().dataTypes).not.toContain(DataType.KLINE_1M);
+    });
+
+    it('同一频道仍有订阅时不应发送退订', async () => {
+      const first = await (adapter as any).createSubscription('BTC/USD', DataType.TICKER);
+      const second = await (adapter as any).createSubscription('BTC/USD', DataType.TICKER);
+      send.mockClear();
+
+      await (adapter as any).removeSubscription(first);
+      expect(send).not.toHaveBeenCalled();
+
+      await (adapter as any).removeSubscription(second);
+      expect(send).toHaveBeenCalledWith({ type: 'unsubscribe', channel: 'ticker', product_ids: ['BTC-USD'] });
+    });
+
+    it('配置API密钥时应该附带JWT', async () => {
+      const { privateKey } = generateKeyPairSync('ec', { namedCurve: 'prime256v1' });
+      (adapter as any).config.auth = {
+        apiKey: 'organizations/org/apiKeys/key',
+        apiSecret: privateKey.export({ type: 'sec1', format: 'pem' }).toString()
+      };
+
+      await (adapter as any).createSubscription('BTC/USD', DataType.TRADE);
+
+      expect(send.mock.calls[0][0].jwt.split('.')).toHaveLength(3);
+    });
+  });
+
+  describe('消息解析', () => {
+    it('应该解析批量成交', () => {
+      const result = (adapter as any).parseMessage({
+        channel: 'market_trades',
+        timestamp: '2024-01-01T00:00:01.000Z',
+        sequence_num: 0,
+        events: [{
+          type: 'update',
+          trades: [
+            { trade_id: '1', product_id: 'BTC-USD', price: '42000.5', size: '0.1', side: 'BUY', time: '2024-01-01T00:00:00.500Z' },
+            { trade_id: '2', product_id: 'BTC-USD', price: '42000.0', size: '0.2', side: 'SELL', time: '2024-01-01T00:00:00.600Z' }
+          ]
+        }]
+      });
+
+      expect(result).toHaveLength(2);
+      expect(result[0]).toMatchObject({
+        exchange: 'coinbase',
+        symbol: 'BTC/USD',
+        type: DataType.TRADE,
+        data: { id: '1', price: 42000.5, quantity: 0.1, side: 'buy' }
+      });
+      expect(result[1].data.side).toBe('sell');
+    });
+
+    it('应该解析ticker', () => {
+      const [ticker] = (adapter as any).parseMessage({
+        channel: 'ticker',
+        timestamp: '2024-01-01T00:00:00.000Z',
+        events: [{
+          type: 'update',
+          tickers: [{
+            product_id: 'ETH-USD', price: '2300', best_bid: '2299.9', best_ask: '2300.1',
+            price_percent_chg_24_h: '1.5', volume_24_h: '1000', high_24_h: '2350', low_24_h: '2250'
+          }]
+        }]
+      });
+
+      expect(ticker.symbol).toBe('ETH/USD');
+      expect(ticker.data).toEqual({
+        lastPrice: 2300, bidPrice: 2299.9, askPrice: 2300.1,
+        change24h: 1.5, volume24h: 1000, high24h: 2350, low24h: 2250
+      });
+    });
+
+    it('应该解析5分钟K线', () => {
+      const [kline] = (adapter as any).parseMessage({
+        channel: 'candles',
+        timestamp: '2024-01-01T00:05:00.000Z',
+        events: [{
+          type: 'update',
+          candles: [{ product_id: 'BTC-USD', start: '1704067200', open: '1', high: '3', low: '0.5', close: '2', volume: '10' }]
+        }]
+      });
+
+      expect(kline.data).toMatchObject({
+        openTime: 1704067200000,
+        closeTime: 1704067200000 + 300000 - 1,
+        interval: '5m',
+        close: 2
+      });
+    });
+
+    it('应该忽略控制消息', () => {
+      expect((adapter as any).parseMessage({ channel: 'subscriptions', events: [] })).toBeNull();
+      expect((adapter as any).parseMessage({ type: 'error', message: 'bad request' })).toBeNull();
+    });
+  });
+
+  describe('订单簿', () => {
+    beforeEach(async () => {
+      await (adapter as any).createSubscription('BTC/USD', DataType.DEPTH);
+      (adapter as any).parseMessage(l2Message(0, 'snapshot', [
+        { side: 'bid', price_level: '100', new_quantity: '1' },
+        { side: 'offer', price_level: '101', new_quantity: '2' }
+      ]));
+    });
+
+    it('应该基于快照与增量维护订单簿', () => {
+      (adapter as any).parseMessage(l2Message(1, 'update', [
+        { side: 'bid', price_level: '100.5', new_quantity: '3' },
+        { side: 'offer', price_level: '101', new_quantity: '0' }
+      ]));
+
+      const book = adapter.getOrderBook('BTC-USD')!;
+      expect(book.bestBid()?.price).toBe(100.5);
+      expect(book.bestAsk()).toBeUndefined();
+    });
+
+    it('序列号出现缺口时应该重新订阅level2', async () => {
+      send.mockClear();
+
+      (adapter as any).parseMessage(l2Message(5, 'update', [
+        { side: 'bid', price_level: '99', new_quantity: '1' }
+      ]));
+      await new Promise(resolve => setTimeout(resolve, 0));
+
+      expect(adapter.getOrderBook('BTC/USD')!.isSynced()).toBe(false);
+      expect(send.mock.calls.map(call => call[0])).toEqual([
+        { type: 'unsubscribe', channel: 'level2', product_ids: ['BTC-USD'] },
+        { type: 'subscribe', channel: 'level2', product_ids: ['BTC-USD'] }
+      ]);
+    });
+  });
+
+  describe('JWT', () => {
+    it('应该生成可用公钥验证的ES256令牌', () => {
+      const { privateKey, publicKey } = generateKeyPairSync('ec', { namedCurve: 'prime256v1' });
+      const pem = privateKey.export({ type: 'sec1', format: 'pem' }).toString().replace(/\n/g, '\\n');
+
+      const token = CoinbaseAdapter.createJwt('key-name', pem, 'GET api.coinbase.com/api/v3/brokerage/accounts', 1700000000000);
+      const [header, payload, signature] = token.split('.');
+
+      expect(JSON.parse(Buffer.from(header, 'base64url').toString())).toMatchObject({ alg: 'ES256', kid: 'key-name', typ: 'JWT' });
+      expect(JSON.parse(Buffer.from(payload, 'base64url').toString())).toEqual({
+        iss: 'cdp',
+        sub: 'key-name',
+        nbf: 1700000000,
+        exp: 1700000120,
+        uri: 'GET api.coinbase.com/api/v3/brokerage/accounts'
+      });
+
+      const verified = createVerify('SHA256')
+        .update(`${header}.${payload}`)
+        .verify({ key: publicKey, dsaEncoding: 'ieee-p1363' }, Buffer.from(signature, 'base64url'));
+      expect(verified).toBe(true);
+    });
+  });
+});
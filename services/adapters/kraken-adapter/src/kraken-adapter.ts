@@ -0,0 +1,517 @@
+/**
+ * Kraken交易所适配器实现
+ * 基于adapter-base框架，接入Kraken v2 WebSocket公共行情频道
+ */
+
+import { createHash, createHmac } from 'crypto';
+import {
+  BaseAdapter,
+  BaseConnectionManager,
+  AdapterConfig,
+  DataType,
+  SubscriptionInfo,
+  SubscriptionConfig,
+  MarketData,
+  ConnectionManager,
+  AdapterCapabilitiesDeclaration,
+  TradeData,
+  TickerData,
+  KlineData,
+  DepthData,
+  OrderBook,
+  OrderBookLevelInput,
+  krakenChecksum,
+  normalizeSymbol
+} from '@pixiu/adapter-base';
+
+/** 交易对价格与数量精度 */
+export interface KrakenPrecision {
+  price: number;
+  qty: number;
+}
+
+export interface KrakenConfig extends AdapterConfig {
+  /** 订阅配置 */
+  subscription?: SubscriptionConfig;
+  /** Kraken特定配置 */
+  kraken?: {
+    /** 订单簿深度（10/25/100/500/1000），默认10 */
+    depth?: number;
+    /** 订阅深度数据时是否维护本地订单簿 */
+    orderBook?: boolean;
+    /** 预置的交易对精度，未配置时从instrument频道获取 */
+    precision?: Record<string, KrakenPrecision>;
+  };
+}
+
+/** v2频道订阅参数 */
+interface KrakenChannelParams {
+  channel: 'trade' | 'ticker' | 'ohlc' | 'book';
+  symbol: string;
+  interval?: number;
+  depth?: number;
+}
+
+/** 数据类型对应的K线周期（分钟） */
+const OHLC_INTERVALS: Partial<Record<DataType, { minutes: number; interval: string }>> = {
+  [DataType.KLINE_1M]: { minutes: 1, interval: '1m' },
+  [DataType.KLINE_5M]: { minutes: 5, interval: '5m' },
+  [DataType.KLINE_1H]: { minutes: 60, interval: '1h' },
+  [DataType.KLINE_1D]: { minutes: 1440, interval: '1d' }
+};
+
+export class KrakenAdapter extends BaseAdapter {
+  public readonly exchange = 'kraken';
+
+  private subscriptionId = 0;
+  private paramsMap = new Map<string, KrakenChannelParams>(); // subscription -> channel params
+  private orderBooks = new Map<string, OrderBook>(); // symbol -> order book
+  private precisions = new Map<string, KrakenPrecision>(); // symbol -> precision
+  private instrumentSubscribed = false;
+  private lastNonce = 0;
+
+  /**
+   * 创建连接管理器
+   */
+  protected async createConnectionManager(): Promise<ConnectionManager> {
+    return new BaseConnectionManager();
+  }
+
+  /**
+   * 初始化方法
+   */
+  async initialize(config: KrakenConfig): Promise<void> {
+    if (!config?.endpoints?.ws) {
+      throw new Error('WebSocket endpoint (endpoints.ws) is required');
+    }
+
+    await super.initialize(config);
+
+    for (const [symbol, precision] of Object.entries(config.kraken?.precision ?? {})) {
+      this.precisions.set(KrakenAdapter.normalizeSymbol(symbol), precision);
+    }
+
+    // 新连接上需要重新订阅instrument频道
+    this.on('connected', () => {
+      this.instrumentSubscribed = false;
+    });
+  }
+
+  /**
+   * 创建订阅
+   */
+  protected async createSubscription(symbol: string, dataType: DataType): Promise<SubscriptionInfo> {
+    const subscriptionId = `${symbol}:${dataType}:${++this.subscriptionId}`;
+    const params = this.mapParams(KrakenAdapter.normalizeSymbol(symbol), dataType);
+
+    this.paramsMap.set(subscriptionId, params);
+
+    if (params.channel === 'book') {
+      this.ensureOrderBook(params);
+      await this.ensurePrecision(params.symbol);
+    }
+
+    await this.sendMethod('subscribe', params);
+
+    return {
+      id: subscriptionId,
+      symbol: params.symbol,
+      dataType,
+      subscribedAt: Date.now(),
+      active: true
+    };
+  }
+
+  /**
+   * 移除订阅，同一频道参数仍有其他订阅时不发送退订
+   */
+  protected async removeSubscription(subscription: SubscriptionInfo): Promise<void> {
+    const params = this.paramsMap.get(subscription.id);
+    if (!params) {
+      return;
+    }
+    this.paramsMap.delete(subscription.id);
+
+    const key = JSON.stringify(params);
+    if (Array.from(this.paramsMap.values()).some(other => JSON.stringify(other) === key)) {
+      return;
+    }
+
+    if (params.channel === 'book') {
+      this.removeOrderBook(params.symbol);
+    }
+    await this.sendMethod('unsubscribe', params);
+  }
+
+  /**
+   * 恢复订阅，沿用原订阅记录重新发送订阅请求
+   */
+  protected async restoreSubscription(subscription: SubscriptionInfo): Promise<void> {
+    const params = this.paramsMap.get(subscription.id);
+    if (!params) {
+      return;
+    }
+
+    if (params.channel === 'book') {
+      await this.ensurePrecision(params.symbol);
+    }
+    await this.sendMethod('subscribe', params);
+  }
+
+  /**
+   * 声明Kraken适配器能力
+   */
+  protected describeCapabilities(): AdapterCapabilitiesDeclaration {
+    return {
+      dataTypes: [
+        DataType.TRADE,
+        DataType.TICKER,
+        DataType.KLINE_1M,
+        DataType.KLINE_5M,
+        DataType.KLINE_1H,
+        DataType.KLINE_1D,
+        DataType.DEPTH
+      ],
+      websocket: true,
+      userDataStream: false,
+      combinedStreams: true,
+      orderBookChecksum: true
+    };
+  }
+
+  /**
+   * 解析Kraken消息
+   */
+  protected parseMessage(message: any): MarketData[] | null {
+    if (!message || typeof message !== 'object') {
+      return null;
+    }
+
+    if (message.method && message.success === false) {
+      this.emit('error', new Error(`Kraken ${message.method} failed: ${message.error}`));
+      return null;
+    }
+
+    if (typeof message.channel !== 'string' || !message.data) {
+      return null;
+    }
+
+    switch (message.channel) {
+      case 'trade':
+        return message.data.map((trade: any) => {
+          const data = this.parseTradeData(trade);
+          return this.createMarketData(trade.symbol, DataType.TRADE, data.timestamp, data);
+        });
+
+      case 'ticker':
+        return message.data.map((ticker: any) =>
+          this.createMarketData(ticker.symbol, DataType.TICKER, Date.now(), this.parseTickerData(ticker))
+        );
+
+      case 'ohlc':
+        return message.data
+          .map((candle: any) => {
+            const dataType = this.ohlcDataType(candle.interval);
+            if (!dataType) {
+              return null;
+            }
+            const data = this.parseKlineData(candle, OHLC_INTERVALS[dataType]!);
+            return this.createMarketData(candle.symbol, dataType, data.openTime, data);
+          })
+          .filter((item: MarketData | null): item is MarketData => item !== null);
+
+      case 'book':
+        return message.data.map((book: any) => {
+          const timestamp = book.timestamp ? Date.parse(book.timestamp) : Date.now();
+          this.updateOrderBook(message.type, book, timestamp);
+          return this.createMarketData(book.symbol, DataType.DEPTH, timestamp, this.parseDepthData(book, timestamp));
+        });
+
+      case 'instrument':
+        this.updatePrecisions(message.data.pairs || []);
+        return null;
+
+      default:
+        // heartbeat、status等频道
+        return null;
+    }
+  }
+
+  /**
+   * 获取本地维护的订单簿
+   */
+  getOrderBook(symbol: string): OrderBook | undefined {
+    return this.orderBooks.get(KrakenAdapter.normalizeSymbol(symbol));
+  }
+
+  /**
+   * 生成严格递增的REST请求nonce
+   * 同一毫秒内多次调用时在上一次的基础上递增
+   */
+  nextNonce(now: number = Date.now()): number {
+    this.lastNonce = Math.max(now * 1000, this.lastNonce + 1);
+    return this.lastNonce;
+  }
+
+  /**
+   * 构造行情数据
+   */
+  private createMarketData(symbol: string, type: DataType, timestamp: number, data: any): MarketData {
+    return {
+      exchange: this.exchange,
+      symbol: KrakenAdapter.normalizeSymbol(symbol),
+      type,
+      timestamp,
+      data,
+      receivedAt: Date.now()
+    };
+  }
+
+  /**
+   * 解析成交数据
+   */
+  private parseTradeData(trade: any): TradeData {
+    return {
+      id: String(trade.trade_id),
+      price: trade.price,
+      quantity: trade.qty,
+      side: trade.side === 'sell' ? 'sell' : 'buy',
+      timestamp: Date.parse(trade.timestamp)
+    };
+  }
+
+  /**
+   * 解析ticker数据
+   */
+  private parseTickerData(ticker: any): TickerData {
+    return {
+      lastPrice: ticker.last,
+      bidPrice: ticker.bid,
+      askPrice: ticker.ask,
+      change24h: ticker.change_pct,
+      volume24h: ticker.volume,
+      high24h: ticker.high,
+      low24h: ticker.low
+    };
+  }
+
+  /**
+   * 解析K线数据
+   */
+  private parseKlineData(candle: any, interval: { minutes: number; interval: string }): KlineData {
+    const openTime = Date.parse(candle.interval_begin);
+    return {
+      open: candle.open,
+      high: candle.high,
+      low: candle.low,
+      close: candle.close,
+      volume: candle.volume,
+      openTime,
+      closeTime: openTime + interval.minutes * 60 * 1000 - 1,
+      interval: interval.interval
+    };
+  }
+
+  /**
+   * 解析深度数据
+   */
+  private parseDepthData(book: any, timestamp: number): DepthData {
+    return {
+      bids: (book.bids || []).map((level: any) => [level.price, level.qty]),
+      asks: (book.asks || []).map((level: any) => [level.price, level.qty]),
+      updateTime: timestamp
+    };
+  }
+
+  /**
+   * 将book频道数据应用到订单簿
+   * v2的价格和数量为JSON数值，需按交易对精度还原字符串后参与校验和计算；
+   * 精度未知时跳过校验
+   */
+  private updateOrderBook(type: string, book: any, timestamp: number): void {
+    const symbol = KrakenAdapter.normalizeSymbol(book.symbol);
+    const orderBook = this.orderBooks.get(symbol);
+    if (!orderBook) {
+      return;
+    }
+
+    const precision = this.precisions.get(symbol);
+    const bids = this.formatLevels(book.bids || [], precision);
+    const asks = this.formatLevels(book.asks || [], precision);
+
+    if (type === 'snapshot') {
+      orderBook.applySnapshot({ lastUpdateId: 0, bids, asks, timestamp });
+    } else if (orderBook.isSynced()) {
+      orderBook.applyDelta({
+        bids,
+        asks,
+        checksum: precision ? book.checksum : undefined,
+        timestamp
+      });
+    }
+  }
+
+  /**
+   * 按精度格式化档位
+   */
+  private formatLevels(levels: any[], precision?: KrakenPrecision): OrderBookLevelInput[] {
+    return levels.map(level => precision
+      ? [level.price.toFixed(precision.price), level.qty.toFixed(precision.qty)]
+      : [level.price, level.qty]);
+  }
+
+  /**
+   * 记录instrument频道推送的交易对精度
+   */
+  private updatePrecisions(pairs: any[]): void {
+    for (const pair of pairs) {
+      this.precisions.set(KrakenAdapter.normalizeSymbol(pair.symbol), {
+        price: pair.price_precision,
+        qty: pair.qty_precision
+      });
+    }
+  }
+
+  /**
+   * 精度未知时订阅instrument频道，快照会先于book快照到达
+   */
+  private async ensurePrecision(symbol: string): Promise<void> {
+    if (this.precisions.has(symbol) || this.instrumentSubscribed) {
+      return;
+    }
+
+    this.instrumentSubscribed = true;
+    await this.sendRequest('subscribe', { channel: 'instrument' });
+  }
+
+  /**
+   * 为交易对创建订单簿，校验失败时重新订阅以获取新快照
+   */
+  private ensureOrderBook(params: KrakenChannelParams): void {
+    if ((this.config as KrakenConfig).kraken?.orderBook === false || this.orderBooks.has(params.symbol)) {
+      return;
+    }
+
+    // 校验和仅针对订阅深度内的档位，需同步截断
+    const book = new OrderBook({
+      symbol: params.symbol,
+      maxDepth: params.depth,
+      checksum: krakenChecksum,
+      checksumDepth: 10
+    });
+    book.on('resyncRequired', () => {
+      this.sendMethod('unsubscribe', params)
+        .then(() => this.sendMethod('subscribe', params))
+        .catch(error => this.emit('error', error));
+    });
+    this.orderBooks.set(params.symbol, book);
+  }
+
+  /**
+   * 移除交易对的订单簿
+   */
+  private removeOrderBook(symbol: string): void {
+    const book = this.orderBooks.get(symbol);
+    if (book) {
+      book.reset();
+      book.removeAllListeners();
+      this.orderBooks.delete(symbol);
+    }
+  }
+
+  /**
+   * 发送频道订阅/退订请求
+   */
+  private async sendMethod(method: 'subscribe' | 'unsubscribe', params: KrakenChannelParams): Promise<void> {
+    const { symbol, ...rest } = params;
+    await this.sendRequest(method, { ...rest, symbol: [symbol] });
+  }
+
+  /**
+   * 发送v2请求
+   */
+  private async sendRequest(method: 'subscribe' | 'unsubscribe', params: Record<string, any>): Promise<void> {
+    if (!this.connectionManager) {
+      throw new Error('Connection manager not initialized');
+    }
+
+    await this.connectionManager.send({ method, params });
+  }
+
+  /**
+   * 映射数据类型到频道参数
+   */
+  private mapParams(symbol: string, dataType: DataType): KrakenChannelParams {
+    switch (dataType) {
+      case DataType.TRADE:
+        return { channel: 'trade', symbol };
+      case DataType.TICKER:
+        return { channel: 'ticker', symbol };
+      case DataType.DEPTH:
+        return { channel: 'book', symbol, depth: (this.config as KrakenConfig).kraken?.depth ?? 10 };
+      default: {
+        const ohlc = OHLC_INTERVALS[dataType];
+        if (ohlc) {
+          return { channel: 'ohlc', symbol, interval: ohlc.minutes };
+        }
+        throw new Error(`Unsupported data type: ${dataType}`);
+      }
+    }
+  }
+
+  /**
+   * 根据K线周期（分钟）获取数据类型
+   */
+  private ohlcDataType(minutes: number): DataType | undefined {
+    return (Object.keys(OHLC_INTERVALS) as DataType[]).find(dataType => OHLC_INTERVALS[dataType]!.minutes === minutes);
+  }
+
+  /**
+   * 标准化交易对
+   * 支持v2格式 BTC/USD、资产别名 XBT/USD 以及REST旧格式 XXBTZUSD
+   */
+  public static normalizeSymbol(symbol: string): string {
+    return normalizeSymbol(symbol);
+  }
+
+  /**
+   * 计算REST私有接口的API-Sign
+   * HMAC-SHA512(path + SHA256(nonce + postData))，密钥为base64解码后的API secret
+   */
+  public static sign(path: string, nonce: number | string, postData: string, secret: string): string {
+    const digest = createHash('sha256').update(`${nonce}${postData}`).digest();
+    return createHmac('sha512', Buffer.from(secret, 'base64'))
+      .update(Buffer.concat([Buffer.from(path), digest]))
+      .digest('base64');
+  }
+}
+
+/**
+ * 创建Kraken适配器工厂函数
+ */
+export function createKrakenAdapter(config?: Partial<KrakenConfig>): KrakenAdapter {
+  const adapter = new KrakenAdapter();
+
+  if (config) {
+    const defaultConfig: KrakenConfig = {
+      ...config,
+      exchange: 'kraken',
+      endpoints: {
+        ws: 'wss://ws.kraken.com/v2',
+        rest: 'https://api.kraken.com',
+        ...config.endpoints
+      },
+      connection: {
+        timeout: 10000,
+        maxRetries: 5,
+        retryInterval: 2000,
+        heartbeatInterval: 30000,
+        ...config.connection
+      }
+    };
+
+    adapter.initialize(defaultConfig);
+  }
+
+  return adapter;
+}
@@ -0,0 +1,196 @@
+/**
+ * Kraken适配器单元测试
+ */
+
+import { krakenChecksum, OrderBookEntry } from '@pixiu/adapter-base';
+import { globalCache } from '@pixiu/shared-core';
+import { KrakenAdapter, DataType } from '../src';
+
+describe('KrakenAdapter', () => {
+  let adapter: KrakenAdapter;
+  let send: jest.Mock;
+
+  const config = {
+    exchange: 'kraken',
+    endpoints: {
+      ws: 'wss://ws.kraken.com/v2',
+      rest: 'https://api.kraken.com'
+    },
+    connection: {
+      timeout: 10000,
+      maxRetries: 3,
+      retryInterval: 1000,
+      heartbeatInterval: 30000
+    }
+  };
+
+  const entry = (price: string, quantity: string): OrderBookEntry => ({
+    price: parseFloat(price),
+    quantity: parseFloat(quantity),
+    rawPrice: price,
+    rawQuantity: quantity
+  });
+
+  const bookMessage = (type: 'snapshot' | 'update', bids: any[], asks: any[], checksum: number) => ({
+    channel: 'book',
+    type,
+    data: [{ symbol: 'BTC/USD', bids, asks, checksum, timestamp: '2024-01-01T00:00:00.000000Z' }]
+  });
+
+  beforeEach(async () => {
+    adapter = new KrakenAdapter();
+    await adapter.initialize({ ...config } as any);
+    send = jest.fn().mockResolvedValue(undefined);
+    (adapter as any).connectionManager = { send };
+  });
+
+  afterEach(async () => {
+    (adapter as any).connectionManager = undefined;
+    await adapter.destroy();
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  describe('交易对映射', () => {
+    it('应该统一资产别名与REST旧格式', () => {
+      expect(KrakenAdapter.normalizeSymbol('BTC/USD')).toBe('BTC/USD');
+      expect(KrakenAdapter.normalizeSymbol('xbt/usd')).toBe('BTC/USD');
+      expect(KrakenAdapter.normalizeSymbol('XXBTZUSD')).toBe('BTC/USD');
+      expect(KrakenAdapter.normalizeSymbol('XETHZEUR')).toBe('ETH/EUR');
+      expect(KrakenAdapter.normalizeSymbol('XDG/USD')).toBe('DOGE/USD');
+    });
+  });
+
+  describe('订阅', () => {
+    it('应该按数据类型发送v2订阅请求', async () => {
+      await (adapter as any).createSubscription('XBT/USD', DataType.TRADE);
+      await (adapter as any).createSubscription('BTC/USD', DataType.KLINE_1H);
+
+      expect(send.mock.calls.map(call => call[0])).toEqual([
+        { method: 'subscribe', params: { channel: 'trade', symbol: ['BTC/USD'] } },
+        { method: 'subscribe', params: { channel: 'ohlc', interval: 60, symbol: ['BTC/USD'] } }
+      ]);
+    });
+
+    it('订阅深度时应该先订阅instrument频道获取精度', async () => {
+      await (adapter as any).createSubscription('BTC/USD', DataType.DEPTH);
+      await (adapter as any).createSubscription('ETH/USD', DataType.DEPTH);
+
+      expect(send.mock.calls.map(call => call[0])).toEqual([
+        { method: 'subscribe', params: { channel: 'instrument' } },
+        { method: 'subscribe', params: { channel: 'book', depth: 10, symbol: ['BTC/USD'] } },
+        { method: 'subscribe', params: { channel: 'book', depth: 10, symbol: ['ETH/USD'] } }
+      ]);
+    });
+  });
+
+  describe('消息解析', () => {
+    it('应该解析成交', () => {
+      const [trade] = (adapter as any).parseMessage({
+        channel: 'trade',
+        type: 'update',
+        data: [{ symbol: 'BTC/USD', side: 'sell', price: 26500.1, qty: 0.25, ord_type: 'market', trade_id: 39332, timestamp: '2023-09-25T07:49:37.708706Z' }]
+      });
+
+      expect(trade).toMatchObject({
+        exchange: 'kraken',
+        symbol: 'BTC/USD',
+        type: DataType.TRADE,
+        data: { id: '39332', price: 26500.1, quantity: 0.25, side: 'sell', timestamp: Date.parse('2023-09-25T07:49:37.708706Z') }
+      });
+    });
+
+    it('应该按周期解析K线', () => {
+      const [kline] = (adapter as any).parseMessage({
+        channel: 'ohlc',
+        type: 'update',
+        data: [{
+          symbol: 'BTC/USD', open: 100, high: 110, low: 95, close: 105, volume: 12.5,
+          interval_begin: '2024-01-01T00:00:00.000000000Z', interval: 5
+        }]
+      });
+
+      expect(kline.type).toBe(DataType.KLINE_5M);
+      expect(kline.data).toMatchObject({
+        openTime: Date.parse('2024-01-01T00:00:00Z'),
+        closeTime: Date.parse('2024-01-01T00:05:00Z') - 1,
+        interval: '5m'
+      });
+    });
+
+    it('订阅失败时应该发出错误', () => {
+      const errors: Error[] = [];
+      adapter.on('error', error => errors.push(error));
+
+      (adapter as any).parseMessage({ method: 'subscribe', success: false, error: 'Currency pair not supported' });
+
+      expect(errors[0].message).toContain('Currency pair not supported');
+    });
+  });
+
+  describe('订单簿', () => {
+    beforeEach(async () => {
+      await (adapter as any).createSubscription('BTC/USD', DataType.DEPTH);
+      (adapter as any).parseMessage({
+        channel: 'instrument',
+        type: 'snapshot',
+        data: { assets: [], pairs: [{ symbol: 'BTC/USD', price_precision: 1, qty_precision: 8 }] }
+      });
+      (adapter as any).parseMessage(bookMessage('snapshot',
+        [{ price: 100, qty: 1 }],
+        [{ price: 101.5, qty: 0.5 }],
+        0
+      ));
+      send.mockClear();
+    });
+
+    it('应该按精度还原字符串并通过校验', () => {
+      const checksum = krakenChecksum(
+        [entry('100.5', '2.00000000'), entry('100.0', '1.00000000')],
+        [entry('101.5', '0.50000000')]
+      );
+
+      (adapter as any).parseMessage(bookMessage('update', [{ price: 100.5, qty: 2 }], [], checksum));
+
+      const book = adapter.getOrderBook('XBT/USD')!;
+      expect(book.isSynced()).toBe(true);
+      expect(book.bestBid()).toMatchObject({ price: 100.5, rawPrice: '100.5', rawQuantity: '2.00000000' });
+      expect(send).not.toHaveBeenCalled();
+    });
+
+    it('校验和不一致时应该重新订阅book', async () => {
+      (adapter as any).parseMessage(bookMessage('update', [{ price: 100.5, qty: 2 }], [], 42));
+      await new Promise(resolve => setTimeout(resolve, 0));
+
+      expect(adapter.getOrderBook('BTC/USD')!.isSynced()).toBe(false);
+      expect(send.mock.calls.map(call => call[0])).toEqual([
+        { method: 'unsubscribe', params: { channel: 'book', depth: 10, symbol: ['BTC/USD'] } },
+        { method: 'subscribe', params: { channel: 'book', depth: 10, symbol: ['BTC/USD'] } }
+      ]);
+    });
+  });
+
+  describe('REST认证', () => {
+    it('应该按官方示例计算API-Sign', () => {
+      const signature = KrakenAdapter.sign(
+        '/0/private/AddOrder',
+        '1616492376594',
+        'nonce=1616492376594&ordertype=limit&pair=XBTUSD&price=37500&type=buy&volume=1.25',
+        'kQH5HW/8p1uGOVjbgWA7FunAmGO8lsSUXNsu3eow76sz84Q18fWxnyRzBHCd3pd5nE9qa99HAZtuZuj6F1huXg=='
+      );
+
+      expect(signature).toBe('4/dpxb3iT4tp/ZCVEwSnEsLxx0bqyhLpdfOpc6fn7OR8+UClSV5n9E6aSS8MPtnRfp32bAb0nmbRn6H8ndwLUQ==');
+    });
+
+    it('nonce应该严格递增', () => {
+      const first = adapter.nextNonce(1700000000000);
+      const second = adapter.nextNonce(1700000000000);
+      const earlier = adapter.nextNonce(1699999999999);
+
+      expect(second).toBeGreaterThan(first);
+      expect(earlier).toBeGreaterThan(second);
+    });
+  });
+});
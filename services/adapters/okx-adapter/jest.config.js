@@ -0,0 +1,34 @@
+/**
+ * OKX Adapter Jest配置
+ */
+
+module.exports = {
+  preset: 'ts-jest',
+  testEnvironment: 'node',
+  rootDir: '.',
+
+  testMatch: [
+    '<rootDir>/tests/**/*.test.ts'
+  ],
+
+  testPathIgnorePatterns: [
+    '/node_modules/',
+    '/dist/',
+    '/coverage/'
+  ],
+
+  transform: {
+    '^.+\\.ts$': ['ts-jest', {
+      tsconfig: 'tsconfig.json'
+    }]
+  },
+
+  collectCoverageFrom: [
+    '<rootDir>/src/**/*.ts',
+    '!<rootDir>/src/**/index.ts'
+  ],
+  coverageDirectory: '<rootDir>/coverage',
+
+  clearMocks: true,
+  restoreMocks: true
+};
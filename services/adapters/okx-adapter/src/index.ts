@@ -0,0 +1,23 @@
+/**
+ * OKX Adapter SDK
+ * OKX交易所适配器SDK
+ */
+
+export * from './okx-adapter';
+export * from './instruments/okx-instrument-provider';
+
+// 重新导出基础类型，方便使用
+export {
+  DataType,
+  AdapterStatus,
+  AdapterConfig,
+  SubscriptionConfig,
+  MarketData,
+  TradeData,
+  TickerData,
+  KlineData,
+  DepthData
+} from '@pixiu/adapter-base';
+
+// 版本信息
+export const VERSION = '1.0.0';
@@ -0,0 +1,85 @@
+/**
+ * OKX品种元数据源
+ * 通过public/instruments接口获取现货与永续合约的步长、最小下单量和合约面值
+ */
+
+import { InstrumentInfo, InstrumentProvider } from '@pixiu/adapter-base';
+import { BaseMonitor, HttpPolicy } from '@pixiu/shared-core';
+import { OkxAdapter } from '../okx-adapter';
+
+export interface OkxInstrumentProviderOptions {
+  /** REST接口地址 */
+  restUrl?: string;
+  /** 需要获取的品种类型，默认现货与永续 */
+  instTypes?: Array<'SPOT' | 'SWAP'>;
+  /** 共享的请求策略 */
+  httpPolicy?: HttpPolicy;
+  /** 注册请求策略的熔断与重试指标 */
+  monitor?: BaseMonitor;
+}
+
+export class OkxInstrumentProvider implements InstrumentProvider {
+  public readonly exchange = 'okx';
+
+  private readonly restUrl: string;
+  private readonly instTypes: Array<'SPOT' | 'SWAP'>;
+  private readonly httpPolicy: HttpPolicy;
+
+  constructor(options: OkxInstrumentProviderOptions = {}) {
+    this.restUrl = options.restUrl ?? 'https://www.okx.com';
+    this.instTypes = options.instTypes ?? ['SPOT', 'SWAP'];
+    this.httpPolicy = options.httpPolicy ?? new HttpPolicy({ name: this.exchange, monitor: options.monitor });
+  }
+
+  /**
+   * 获取全部品种
+   */
+  async fetchInstruments(): Promise<InstrumentInfo[]> {
+    const results = await Promise.all(this.instTypes.map(instType => this.fetchType(instType)));
+    return results.flat();
+  }
+
+  /**
+   * 获取单个品种类型
+   */
+  private async fetchType(instType: 'SPOT' | 'SWAP'): Promise<InstrumentInfo[]> {
+    const path = `/api/v5/public/instruments?instType=${instType}`;
+    const response = await this.httpPolicy.fetch(`${this.restUrl}${path}`, { endpoint: 'GET /api/v5/public/instruments' });
+
+    if (!response.ok) {
+      throw new Error(`OKX request ${path} failed: HTTP ${response.status} ${await response.text()}`);
+    }
+
+    const body: any = await response.json();
+    if (body.code !== '0') {
+      throw new Error(`OKX request ${path} failed: ${body.code} ${body.msg}`);
+    }
+
+    return (body.data || []).map((instrument: any) => this.parseInstrument(instrument));
+  }
+
+  /**
+   * 解析品种，永续合约的数量单位为张，面值为ctVal个ctValCcy
+   */
+  private parseInstrument(instrument: any): InstrumentInfo {
+    const symbol = OkxAdapter.fromInstId(instrument.instId);
+    const [base, quote] = instrument.instId.split('-');
+    const isSwap = instrument.instType === 'SWAP';
+
+    return {
+      exchange: this.exchange,
+      symbol,
+      exchangeSymbol: instrument.instId,
+      base,
+      quote,
+      ...(isSwap ? { settle: instrument.settleCcy } : {}),
+      type: isSwap ? 'swap' : 'spot',
+      tickSize: parseFloat(instrument.tickSz),
+      lotSize: parseFloat(instrument.lotSz),
+      minQuantity: parseFloat(instrument.minSz),
+      // 币本位合约面值以计价币种计，乘数无法直接换算为基础币种
+      contractMultiplier: isSwap && instrument.ctValCcy === base ? parseFloat(instrument.ctVal) : 1,
+      active: instrument.state === 'live'
+    };
+  }
+}
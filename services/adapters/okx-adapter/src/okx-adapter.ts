@@ -0,0 +1,405 @@
+/**
+ * OKX交易所适配器实现
+ * 基于adapter-base框架，接入OKX v5公共WebSocket行情频道
+ */
+
+import {
+  BaseAdapter,
+  BaseConnectionManager,
+  AdapterConfig,
+  DataType,
+  SubscriptionInfo,
+  SubscriptionConfig,
+  MarketData,
+  ConnectionManager,
+  AdapterCapabilitiesDeclaration,
+  TradeData,
+  TickerData,
+  DepthData,
+  OrderBook,
+  HeartbeatMessage,
+  okxChecksum,
+  normalizeSymbol
+} from '@pixiu/adapter-base';
+
+export interface OkxConfig extends AdapterConfig {
+  /** 订阅配置 */
+  subscription?: SubscriptionConfig;
+  /** OKX特定配置 */
+  okx?: {
+    /** 是否使用模拟盘端点 */
+    simulated?: boolean;
+    /** 订阅深度数据时是否维护本地订单簿 */
+    orderBook?: boolean;
+  };
+}
+
+/** OKX公共频道 */
+type OkxChannel = 'trades' | 'tickers' | 'books';
+
+/** OKX订阅参数 */
+interface OkxChannelArg {
+  channel: OkxChannel;
+  instId: string;
+}
+
+export class OkxAdapter extends BaseAdapter {
+  public readonly exchange = 'okx';
+
+  private subscriptionId = 0;
+  private channelMap = new Map<string, OkxChannelArg>(); // subscription -> channel arg
+  private orderBooks = new Map<string, OrderBook>(); // symbol -> order book
+
+  /**
+   * 创建连接管理器
+   */
+  protected async createConnectionManager(): Promise<ConnectionManager> {
+    return new BaseConnectionManager();
+  }
+
+  /**
+   * 初始化方法
+   */
+  async initialize(config: OkxConfig): Promise<void> {
+    if (!config?.endpoints?.ws) {
+      throw new Error('WebSocket endpoint (endpoints.ws) is required');
+    }
+
+    await super.initialize(config);
+  }
+
+  /**
+   * 创建订阅
+   */
+  protected async createSubscription(symbol: string, dataType: DataType): Promise<SubscriptionInfo> {
+    const subscriptionId = `${symbol}:${dataType}:${++this.subscriptionId}`;
+    const arg: OkxChannelArg = { channel: this.mapChannel(dataType), instId: OkxAdapter.toInstId(symbol) };
+
+    this.channelMap.set(subscriptionId, arg);
+
+    if (arg.channel === 'books') {
+      this.ensureOrderBook(arg.instId);
+    }
+
+    await this.sendOperation('subscribe', arg);
+
+    return {
+      id: subscriptionId,
+      symbol: OkxAdapter.fromInstId(arg.instId),
+      dataType,
+      subscribedAt: Date.now(),
+      active: true
+    };
+  }
+
+  /**
+   * 移除订阅，同一频道与产品仍有其他订阅时不发送退订
+   */
+  protected async removeSubscription(subscription: SubscriptionInfo): Promise<void> {
+    const arg = this.channelMap.get(subscription.id);
+    if (!arg) {
+      return;
+    }
+    this.channelMap.delete(subscription.id);
+
+    const stillUsed = Array.from(this.channelMap.values())
+      .some(other => other.channel === arg.channel && other.instId === arg.instId);
+    if (stillUsed) {
+      return;
+    }
+
+    if (arg.channel === 'books') {
+      this.removeOrderBook(subscription.symbol);
+    }
+    await this.sendOperation('unsubscribe', arg);
+  }
+
+  /**
+   * 恢复订阅，沿用原订阅记录重新发送订阅请求
+   */
+  protected async restoreSubscription(subscription: SubscriptionInfo): Promise<void> {
+    const arg = this.channelMap.get(subscription.id);
+    if (arg) {
+      await this.sendOperation('subscribe', arg);
+    }
+  }
+
+  /**
+   * 声明OKX适配器能力
+   * K线频道位于business端点，私有频道需要登录，当前均未接入
+   */
+  protected describeCapabilities(): AdapterCapabilitiesDeclaration {
+    return {
+      dataTypes: [DataType.TRADE, DataType.TICKER, DataType.DEPTH],
+      websocket: true,
+      userDataStream: false,
+      combinedStreams: true,
+      orderBookChecksum: true
+    };
+  }
+
+  /**
+   * OKX只响应文本心跳，不回复WebSocket ping帧
+   */
+  protected describeHeartbeat(): HeartbeatMessage {
+    return { ping: 'ping', isPong: message => message === 'pong' };
+  }
+
+  /**
+   * 解析OKX消息
+   */
+  protected parseMessage(message: any): MarketData[] | null {
+    if (!message || typeof message !== 'object') {
+      // 心跳响应pong已由连接管理器消费，其余非JSON消息忽略
+      return null;
+    }
+
+    if (message.event === 'error') {
+      this.emit('error', new Error(`OKX error ${message.code}: ${message.msg}`));
+      return null;
+    }
+
+    if (!message.arg || !Array.isArray(message.data)) {
+      // subscribe/unsubscribe确认等事件
+      return null;
+    }
+
+    const { channel, instId } = message.arg;
+    switch (channel) {
+      case 'trades':
+        return message.data.map((trade: any) => {
+          const data = this.parseTradeData(trade);
+          return this.createMarketData(trade.instId, DataType.TRADE, data.timestamp, data);
+        });
+
+      case 'tickers':
+        return message.data.map((ticker: any) =>
+          this.createMarketData(ticker.instId, DataType.TICKER, parseInt(ticker.ts, 10), this.parseTickerData(ticker))
+        );
+
+      case 'books':
+        return message.data.map((book: any) => {
+          const timestamp = parseInt(book.ts, 10);
+          this.updateOrderBook(instId, message.action, book, timestamp);
+          return this.createMarketData(instId, DataType.DEPTH, timestamp, this.parseDepthData(book, timestamp));
+        });
+
+      default:
+        return null;
+    }
+  }
+
+  /**
+   * 获取本地维护的订单簿
+   */
+  getOrderBook(symbol: string): OrderBook | undefined {
+    return this.orderBooks.get(OkxAdapter.fromInstId(OkxAdapter.toInstId(symbol)));
+  }
+
+  /**
+   * 构造行情数据
+   */
+  private createMarketData(instId: string, type: DataType, timestamp: number, data: any): MarketData {
+    return {
+      exchange: this.exchange,
+      symbol: OkxAdapter.fromInstId(instId),
+      type,
+      timestamp,
+      data,
+      receivedAt: Date.now()
+    };
+  }
+
+  /**
+   * 解析成交数据
+   */
+  private parseTradeData(trade: any): TradeData {
+    return {
+      id: String(trade.tradeId),
+      price: parseFloat(trade.px),
+      quantity: parseFloat(trade.sz),
+      side: trade.side === 'sell' ? 'sell' : 'buy',
+      timestamp: parseInt(trade.ts, 10)
+    };
+  }
+
+  /**
+   * 解析ticker数据，OKX只提供24小时开盘价，涨跌幅需自行计算
+   */
+  private parseTickerData(ticker: any): TickerData {
+    const lastPrice = parseFloat(ticker.last);
+    const open24h = parseFloat(ticker.open24h);
+    return {
+      lastPrice,
+      bidPrice: parseFloat(ticker.bidPx),
+      askPrice: parseFloat(ticker.askPx),
+      change24h: open24h > 0 ? ((lastPrice - open24h) / open24h) * 100 : 0,
+      volume24h: parseFloat(ticker.vol24h),
+      high24h: parseFloat(ticker.high24h),
+      low24h: parseFloat(ticker.low24h)
+    };
+  }
+
+  /**
+   * 解析深度数据，档位格式为 [价格, 数量, 废弃字段, 订单数]
+   */
+  private parseDepthData(book: any, timestamp: number): DepthData {
+    return {
+      bids: (book.bids || []).map((level: string[]) => [parseFloat(level[0]), parseFloat(level[1])]),
+      asks: (book.asks || []).map((level: string[]) => [parseFloat(level[0]), parseFloat(level[1])]),
+      updateTime: timestamp
+    };
+  }
+
+  /**
+   * 将books频道数据应用到订单簿
+   * seqId/prevSeqId用于检测缺口，checksum由订单簿按前25档验证
+   */
+  private updateOrderBook(instId: string, action: string, book: any, timestamp: number): void {
+    const orderBook = this.orderBooks.get(OkxAdapter.fromInstId(instId));
+    if (!orderBook) {
+      return;
+    }
+
+    const bids = (book.bids || []).map((level: string[]) => [level[0], level[1]] as [string, string]);
+    const asks = (book.asks || []).map((level: string[]) => [level[0], level[1]] as [string, string]);
+
+    if (action === 'snapshot') {
+      orderBook.applySnapshot({ lastUpdateId: book.seqId, bids, asks, timestamp });
+      return;
+    }
+
+    if (!orderBook.isSynced()) {
+      return;
+    }
+
+    orderBook.applyDelta({
+      finalUpdateId: book.seqId,
+      prevFinalUpdateId: book.prevSeqId,
+      bids,
+      asks,
+      checksum: book.checksum,
+      timestamp
+    });
+  }
+
+  /**
+   * 为产品创建订单簿
+   * OKX的books频道重新订阅即会推送新快照，无需REST快照
+   */
+  private ensureOrderBook(instId: string): void {
+    if ((this.config as OkxConfig).okx?.orderBook === false) {
+      return;
+    }
+
+    const symbol = OkxAdapter.fromInstId(instId);
+    if (this.orderBooks.has(symbol)) {
+      return;
+    }
+
+    const book = new OrderBook({ symbol, checksum: okxChecksum, checksumDepth: 25 });
+    book.on('resyncRequired', () => {
+      const arg: OkxChannelArg = { channel: 'books', instId };
+      this.sendOperation('unsubscribe', arg)
+        .then(() => this.sendOperation('subscribe', arg))
+        .catch(error => this.emit('error', error));
+    });
+    this.orderBooks.set(symbol, book);
+  }
+
+  /**
+   * 移除产品的订单簿
+   */
+  private removeOrderBook(symbol: string): void {
+    const book = this.orderBooks.get(symbol);
+    if (book) {
+      book.reset();
+      book.removeAllListeners();
+      this.orderBooks.delete(symbol);
+    }
+  }
+
+  /**
+   * 发送订阅/退订操作
+   */
+  private async sendOperation(op: 'subscribe' | 'unsubscribe', arg: OkxChannelArg): Promise<void> {
+    if (!this.connectionManager) {
+      throw new Error('Connection manager not initialized');
+    }
+
+    await this.connectionManager.send({ op, args: [arg] });
+  }
+
+  /**
+   * 映射数据类型到频道
+   */
+  private mapChannel(dataType: DataType): OkxChannel {
+    switch (dataType) {
+      case DataType.TRADE:
+        return 'trades';
+      case DataType.TICKER:
+        return 'tickers';
+      case DataType.DEPTH:
+        return 'books';
+      default:
+        throw new Error(`Unsupported data type: ${dataType}`);
+    }
+  }
+
+  /**
+   * 标准交易对转换为OKX产品ID
+   * 现货 BTC/USDT -> BTC-USDT，永续合约 BTC/USDT:USDT -> BTC-USDT-SWAP
+   * 已是OKX格式的产品ID原样返回
+   */
+  public static toInstId(symbol: string): string {
+    const normalized = symbol.toUpperCase();
+    if (!normalized.includes('/')) {
+      return normalized;
+    }
+
+    const [pair, settle] = normalized.split(':');
+    const instId = pair.replace('/', '-');
+    return settle ? `${instId}-SWAP` : instId;
+  }
+
+  /**
+   * OKX产品ID转换为标准交易对
+   * 永续合约追加结算币种：U本位 BTC-USDT-SWAP -> BTC/USDT:USDT，币本位 BTC-USD-SWAP -> BTC/USD:BTC
+   */
+  public static fromInstId(instId: string): string {
+    return normalizeSymbol(instId);
+  }
+}
+
+/**
+ * 创建OKX适配器工厂函数
+ */
+export function createOkxAdapter(config?: Partial<OkxConfig>): OkxAdapter {
+  const adapter = new OkxAdapter();
+
+  if (config) {
+    const defaultConfig: OkxConfig = {
+      ...config,
+      exchange: 'okx',
+      endpoints: {
+        ws: config.okx?.simulated
+          ? 'wss://wspap.okx.com:8443/ws/v5/public'
+          : 'wss://ws.okx.com:8443/ws/v5/public',
+        rest: 'https://www.okx.com',
+        ...config.endpoints
+      },
+      connection: {
+        timeout: 10000,
+        maxRetries: 5,
+        retryInterval: 2000,
+        // OKX在30秒内无数据会断开连接，按20秒间隔发送文本ping
+        heartbeatInterval: 20000,
+        ...config.connection
+      }
+    };
+
+    adapter.initialize(defaultConfig);
+  }
+
+  return adapter;
+}
@@ -0,0 +1,48 @@
+/**
+ * OKX品种元数据源单元测试
+ */
+
+import { OkxInstrumentProvider } from '../../src';
+
+describe('OkxInstrumentProvider', () => {
+  const originalFetch = global.fetch;
+
+  const respond = (data: any[]) => ({
+    ok: true,
+    status: 200,
+    json: async () => ({ code: '0', msg: '', data })
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  it('应该映射现货与永续合约元数据', async () => {
+    global.fetch = jest.fn()
+      .mockResolvedValueOnce(respond([
+        { instType: 'SPOT', instId: 'BTC-USDT', baseCcy: 'BTC', quoteCcy: 'USDT', tickSz: '0.1', lotSz: '0.00000001', minSz: '0.00001', state: 'live' }
+      ]))
+      .mockResolvedValueOnce(respond([
+        { instType: 'SWAP', instId: 'BTC-USDT-SWAP', settleCcy: 'USDT', ctVal: '0.01', ctValCcy: 'BTC', tickSz: '0.1', lotSz: '0.01', minSz: '0.01', state: 'live' },
+        { instType: 'SWAP', instId: 'BTC-USD-SWAP', settleCcy: 'BTC', ctVal: '100', ctValCcy: 'USD', tickSz: '0.1', lotSz: '1', minSz: '1', state: 'suspend' }
+      ])) as any;
+
+    const instruments = await new OkxInstrumentProvider().fetchInstruments();
+
+    expect(global.fetch).toHaveBeenCalledWith('https://www.okx.com/api/v5/public/instruments?instType=SPOT');
+    expect(instruments.map(instrument => instrument.symbol)).toEqual(['BTC/USDT', 'BTC/USDT:USDT', 'BTC/USD:BTC']);
+    expect(instruments[0]).toMatchObject({ type: 'spot', tickSize: 0.1, lotSize: 0.00000001, contractMultiplier: 1 });
+    expect(instruments[1]).toMatchObject({ type: 'swap', settle: 'USDT', lotSize: 0.01, contractMultiplier: 0.01, active: true });
+    expect(instruments[2]).toMatchObject({ settle: 'BTC', contractMultiplier: 1, active: false });
+  });
+
+  it('业务错误码应该抛出错误', async () => {
+    global.fetch = jest.fn().mockResolvedValue({
+      ok: true,
+      status: 200,
+      json: async () => ({ code: '51000', msg: 'Parameter instType error', data: [] })
+    }) as any;
+
+    await expect(new OkxInstrumentProvider({ instTypes: ['SPOT'] }).fetchInstruments()).rejects.toThrow('51000');
+  });
+});
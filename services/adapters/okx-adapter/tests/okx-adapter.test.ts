@@ -0,0 +1,200 @@
+/**
+ * OKX适配器单元测试
+ */
+
+import { okxChecksum, OrderBookEntry } from '@pixiu/adapter-base';
+import { globalCache } from '@pixiu/shared-core';
+import { OkxAdapter, DataType } from '../src';
+
+describe('OkxAdapter', () => {
+  let adapter: OkxAdapter;
+  let send: jest.Mock;
+
+  const config = {
+    exchange: 'okx',
+    endpoints: {
+      ws: 'wss://ws.okx.com:8443/ws/v5/public',
+      rest: 'https://www.okx.com'
+    },
+    connection: {
+      timeout: 10000,
+      maxRetries: 3,
+      retryInterval: 1000,
+      heartbeatInterval: 20000
+    }
+  };
+
+  const entry = (price: string, quantity: string): OrderBookEntry => ({
+    price: parseFloat(price),
+    quantity: parseFloat(quantity),
+    rawPrice: price,
+    rawQuantity: quantity
+  });
+
+  const booksMessage = (action: 'snapshot' | 'update', data: Record<string, any>) => ({
+    arg: { channel: 'books', instId: 'BTC-USDT' },
+    action,
+    data: [{ ts: '1700000000000', ...data }]
+  });
+
+  beforeEach(async () => {
+    adapter = new OkxAdapter();
+    await adapter.initialize({ ...config } as any);
+    send = jest.fn().mockResolvedValue(undefined);
+    (adapter as any).connectionManager = { send };
+  });
+
+  afterEach(async () => {
+    (adapter as any).connectionManager = undefined;
+    await adapter.destroy();
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  describe('交易对映射', () => {
+    it('应该转换现货与永续合约产品ID', () => {
+      expect(OkxAdapter.fromInstId('BTC-USDT')).toBe('BTC/USDT');
+      expect(OkxAdapter.fromInstId('BTC-USDT-SWAP')).toBe('BTC/USDT:USDT');
+      expect(OkxAdapter.fromInstId('BTC-USD-SWAP')).toBe('BTC/USD:BTC');
+
+      expect(OkxAdapter.toInstId('btc/usdt')).toBe('BTC-USDT');
+      expect(OkxAdapter.toInstId('BTC/USDT:USDT')).toBe('BTC-USDT-SWAP');
+      expect(OkxAdapter.toInstId('ETH-USDT-SWAP')).toBe('ETH-USDT-SWAP');
+    });
+  });
+
+  describe('订阅', () => {
+    it('应该按数据类型发送频道订阅', async () => {
+      const subscription = await (adapter as any).createSubscription('BTC/USDT:USDT', DataType.TRADE);
+
+      expect(subscription.symbol).toBe('BTC/USDT:USDT');
+      expect(send).toHaveBeenCalledWith({ op: 'subscribe', args: [{ channel: 'trades', instId: 'BTC-USDT-SWAP' }] });
+    });
+
+    it('同一频道仍有订阅时不应发送退订', async () => {
+      const first = await (adapter as any).createSubscription('BTC/USDT', DataType.TICKER);
+      const second = await (adapter as any).createSubscription('BTC-USDT', DataType.TICKER);
+      send.mockClear();
+
+      await (adapter as any).removeSubscription(first);
+      expect(send).not.toHaveBeenCalled();
+
+      await (adapter as any).removeSubscription(second);
+      expect(send).toHaveBeenCalledWith({ op: 'unsubscribe', args: [{ channel: 'tickers', instId: 'BTC-USDT' }] });
+    });
+
+    it('应该拒绝不支持的数据类型', async () => {
+      await expect((adapter as any).createSubscription('BTC/USDT', DataType.KLINE_1M)).rejects.toThrow('Unsupported data type');
+    });
+  });
+
+  describe('消息解析', () => {
+    it('应该解析成交', () => {
+      const [trade] = (adapter as any).parseMessage({
+        arg: { channel: 'trades', instId: 'BTC-USDT-SWAP' },
+        data: [{ instId: 'BTC-USDT-SWAP', tradeId: '130639474', px: '42219.9', sz: '0.12', side: 'sell', ts: '1630048897897' }]
+      });
+
+      expect(trade).toMatchObject({
+        exchange: 'okx',
+        symbol: 'BTC/USDT:USDT',
+        type: DataType.TRADE,
+        timestamp: 1630048897897,
+        data: { id: '130639474', price: 42219.9, quantity: 0.12, side: 'sell' }
+      });
+    });
+
+    it('应该按24小时开盘价计算涨跌幅', () => {
+      const [ticker] = (adapter as any).parseMessage({
+        arg: { channel: 'tickers', instId: 'BTC-USDT' },
+        data: [{
+          instId: 'BTC-USDT', last: '110', bidPx: '109.9', askPx: '110.1', open24h: '100',
+          high24h: '111', low24h: '99', vol24h: '5000', ts: '1700000000000'
+        }]
+      });
+
+      expect(ticker.data).toEqual({
+        lastPrice: 110, bidPrice: 109.9, askPrice: 110.1,
+        change24h: 10, volume24h: 5000, high24h: 111, low24h: 99
+      });
+    });
+
+    it('应该将错误事件转为适配器错误', () => {
+      const errors: Error[] = [];
+      adapter.on('error', error => errors.push(error));
+
+      expect((adapter as any).parseMessage({ event: 'error', code: '60012', msg: 'Invalid request' })).toBeNull();
+      expect((adapter as any).parseMessage('pong')).toBeNull();
+      expect(errors[0].message).toContain('60012');
+    });
+
+    it('应该以文本ping保活并识别pong响应', () => {
+      const heartbeat = (adapter as any).describeHeartbeat();
+
+      expect(heartbeat.ping).toBe('ping');
+      expect(heartbeat.isPong('pong')).toBe(true);
+      expect(heartbeat.isPong({ event: 'subscribe' })).toBe(false);
+    });
+  });
+
+  describe('订单簿', () => {
+    beforeEach(async () => {
+      await (adapter as any).createSubscription('BTC/USDT', DataType.DEPTH);
+      (adapter as any).parseMessage(booksMessage('snapshot', {
+        bids: [['100', '1', '0', '1']],
+        asks: [['101', '2', '0', '1']],
+        seqId: 10,
+        prevSeqId: -1,
+        checksum: okxChecksum([entry('100', '1')], [entry('101', '2')])
+      }));
+      send.mockClear();
+    });
+
+    it('应该应用通过校验的增量', () => {
+      (adapter as any).parseMessage(booksMessage('update', {
+        bids: [['100.5', '3', '0', '2']],
+        asks: [],
+        seqId: 11,
+        prevSeqId: 10,
+        checksum: okxChecksum([entry('100.5', '3'), entry('100', '1')], [entry('101', '2')])
+      }));
+
+      const book = adapter.getOrderBook('BTC-USDT')!;
+      expect(book.isSynced()).toBe(true);
+      expect(book.bestBid()?.price).toBe(100.5);
+      expect(send).not.toHaveBeenCalled();
+    });
+
+    it('校验和不一致时应该重新订阅books', async () => {
+      (adapter as any).parseMessage(booksMessage('update', {
+        bids: [['100.5', '3', '0', '2']],
+        asks: [],
+        seqId: 11,
+        prevSeqId: 10,
+        checksum: 123456
+      }));
+      await new Promise(resolve => setTimeout(resolve, 0));
+
+      expect(adapter.getOrderBook('BTC/USDT')!.isSynced()).toBe(false);
+      expect(send.mock.calls.map(call => call[0])).toEqual([
+        { op: 'unsubscribe', args: [{ channel: 'books', instId: 'BTC-USDT' }] },
+        { op: 'subscribe', args: [{ channel: 'books', instId: 'BTC-USDT' }] }
+      ]);
+    });
+
+    it('seqId不连续时应该重新订阅books', async () => {
+      (adapter as any).parseMessage(booksMessage('update', {
+        bids: [],
+        asks: [['102', '1', '0', '1']],
+        seqId: 15,
+        prevSeqId: 14
+      }));
+      await new Promise(resolve => setTimeout(resolve, 0));
+
+      expect(adapter.getOrderBook('BTC/USDT')!.isSynced()).toBe(false);
+      expect(send).toHaveBeenCalledWith({ op: 'subscribe', args: [{ channel: 'books', instId: 'BTC-USDT' }] });
+    });
+  });
+});
@@ -5,8 +5,8 @@
  */
 
 import { EventEmitter } from 'events';
-import { BaseErrorHandler, BaseMonitor, PubSubClientImpl } from '@pixiu/shared-core';
-import { ExchangeAdapter, MarketData, AdapterStatus } from '@pixiu/adapter-base';
+import { BaseErrorHandler, BaseMonitor, PubSubClientImpl, ComponentLogger } from '@pixiu/shared-core';
+import { ExchangeAdapter, MarketData, AdapterStatus, AdapterMetrics, AdapterCapabilities, DataType, OrderBook } from '@pixiu/adapter-base';
 import { UnifiedDataProcessor } from '../../utils/data-processor';
 
 export interface IntegrationConfig {
@@ -54,6 +54,8 @@ export abstract class AdapterIntegration extends EventEmitter {
   protected config!: IntegrationConfig;
   protected pubsubClient!: PubSubClientImpl;
   protected monitor!: BaseMonitor;
+  /** 以交易所名称为组件的日志记录器 */
+  protected logger!: ComponentLogger;
   protected errorHandler!: BaseErrorHandler;
   
   protected metrics!: IntegrationMetrics;
@@ -82,6 +84,7 @@ export abstract class AdapterIntegration extends EventEmitter {
     this.config = config;
     this.pubsubClient = pubsubClient;
     this.monitor = monitor;
+    this.logger = monitor.child(this.getExchangeName());
     this.errorHandler = errorHandler;
     this.dataProcessor = new UnifiedDataProcessor(monitor);
 
@@ -101,7 +104,7 @@ export abstract class AdapterIntegration extends EventEmitter {
       this.isInitialized = true;
       this.emit('initialized');
       
-      this.monitor.log('info', 'Adapter integration initialized', {
+      this.logger.log('info', 'Adapter integration initialized', {
         exchange: this.getExchangeName(),
         config: this.config
       });
@@ -136,7 +139,7 @@ export abstract class AdapterIntegration extends EventEmitter {
       this.isRunning = true;
       this.emit('started');
       
-      this.monitor.log('info', 'Adapter integration started', {
+      this.logger.log('info', 'Adapter integration started', {
         exchange: this.getExchangeName()
       });
     } catch (error) {
@@ -166,7 +169,7 @@ export abstract class AdapterIntegration extends EventEmitter {
       this.isRunning = false;
       this.emit('stopped');
       
-      this.monitor.log('info', 'Adapter integration stopped', {
+      this.logger.log('info', 'Adapter integration stopped', {
         exchange: this.getExchangeName()
       });
     } catch (error) {
@@ -206,6 +209,35 @@ export abstract class AdapterIntegration extends EventEmitter {
     return this.adapter?.getStatus() || AdapterStatus.DISCONNECTED;
   }
 
+  /**
+   * 获取适配器自身的连接指标
+   */
+  getAdapterMetrics(): AdapterMetrics | undefined {
+    return this.adapter?.getMetrics();
+  }
+
+  /**
+   * 获取适配器本地维护的订单簿
+   */
+  getOrderBook(symbol: string): OrderBook | undefined {
+    return this.adapter?.getOrderBook?.(symbol);
+  }
+
+  /**
+   * 获取适配器能力
+   */
+  getCapabilities(): AdapterCapabilities | undefined {
+    return this.adapter?.getCapabilities();
+  }
+
+  /**
+   * 检查适配器是否支持指定数据类型
+   */
+  supportsDataType(dataType: string): boolean {
+    const capabilities = this.getCapabilities();
+    return !!capabilities && capabilities.dataTypes.includes(dataType as DataType);
+  }
+
   /**
    * 检查是否健康
    */
@@ -228,7 +260,7 @@ export abstract class AdapterIntegration extends EventEmitter {
       this.metrics.adapterStatus = newStatus;
       this.emit('adapterStatusChange', newStatus, oldStatus);
       
-      this.monitor.log('info', 'Adapter status changed', {
+      this.logger.log('info', 'Adapter status changed', {
         exchange: this.getExchangeName(),
         oldStatus,
         newStatus
@@ -265,14 +297,14 @@ export abstract class AdapterIntegration extends EventEmitter {
       
       // 添加调试日志（每100条消息记录一次）
       if (this.metrics.messagesProcessed % 100 === 0) {
-        this.monitor.log('debug', `Processed ${this.metrics.messagesProcessed} messages, published ${this.metrics.messagesPublished}`);
+        this.logger.log('debug', `Processed ${this.metrics.messagesProcessed} messages, published ${this.metrics.messagesPublished}`);
       }
       
       // 数据质量检查（使用统一处理器）
       const validation = this.dataProcessor.validateMarketData(marketData);
       if (!validation.isValid) {
         this.metrics.processingErrors++;
-        this.monitor.log('warn', `Invalid market data: ${validation.errors.join(', ')}`);
+        this.logger.log('warn', `Invalid market data: ${validation.errors.join(', ')}`);
         return;
       }
 
@@ -293,7 +325,7 @@ export abstract class AdapterIntegration extends EventEmitter {
       this.emit('dataProcessed', normalizedData);
     } catch (error) {
       this.metrics.processingErrors++;
-      this.monitor.log('error', `Error processing market data: ${error}`);
+      this.logger.log('error', `Error processing market data: ${error}`);
       await this.handleError(error as Error, 'processMarketData');
     }
   }
@@ -361,7 +393,7 @@ export abstract class AdapterIntegration extends EventEmitter {
       let totalSuccessCount = 0;
       let totalFailureCount = 0;
       
-      this.monitor.log('debug', `Publishing batch: ${messages.length} messages to ${messagesByType.size} topics`);
+      this.logger.log('debug', `Publishing batch: ${messages.length} messages to ${messagesByType.size} topics`);
       
       for (const [topicName, typeMessages] of messagesByType) {
         try {
@@ -373,9 +405,9 @@ export abstract class AdapterIntegration extends EventEmitter {
           totalSuccessCount += batchResult.successCount;
           totalFailureCount += batchResult.failureCount;
           
-          this.monitor.log('debug', `Published to ${topicName}: ${batchResult.successCount} success, ${batchResult.failureCount} failures`);
+          this.logger.log('debug', `Published to ${topicName}: ${batchResult.successCount} success, ${batchResult.failureCount} failures`);
         } catch (topicError) {
-          this.monitor.log('error', `Failed to publish to topic ${topicName}: ${topicError}`);
+          this.logger.log('error', `Failed to publish to topic ${topicName}: ${topicError}`);
           totalFailureCount += typeMessages.length;
         }
       }
@@ -383,7 +415,7 @@ export abstract class AdapterIntegration extends EventEmitter {
       this.metrics.messagesPublished += totalSuccessCount;
       this.metrics.publishErrors += totalFailureCount;
       
-      this.monitor.log('debug', `Batch published: ${totalSuccessCount} success, ${totalFailureCount} failures. Total published: ${this.metrics.messagesPublished}`);
+      this.logger.log('debug', `Batch published: ${totalSuccessCount} success, ${totalFailureCount} failures. Total published: ${this.metrics.messagesPublished}`);
       
       this.emit('batchPublished', {
         successCount: totalSuccessCount,
@@ -391,7 +423,7 @@ export abstract class AdapterIntegration extends EventEmitter {
       });
     } catch (error) {
       this.metrics.publishErrors += messages.length;
-      this.monitor.log('error', `Error in flushMessageBuffer: ${error}`);
+      this.logger.log('error', `Error in flushMessageBuffer: ${error}`);
       await this.handleError(error as Error, 'flushMessageBuffer');
     }
   }
@@ -0,0 +1,79 @@
+/**
+ * 基于adapter-base的通用交易所DataFlow集成
+ * 适配器直接使用统一的AdapterConfig，订阅按交易对与数据类型展开
+ */
+
+import { BaseAdapter, AdapterConfig, DataType } from '@pixiu/adapter-base';
+import { PipelineAdapterIntegration } from './pipeline-adapter-integration';
+
+export abstract class ExchangeDataFlowIntegration extends PipelineAdapterIntegration {
+
+  /**
+   * 创建未初始化的适配器实例，传入服务监控以注册适配器内部组件的指标
+   */
+  protected abstract instantiateAdapter(): BaseAdapter;
+
+  /**
+   * 创建适配器实例，扩展配置按交易所名称挂载（如 okx.simulated）
+   */
+  protected async createAdapter(config: any): Promise<BaseAdapter> {
+    const adapter = this.instantiateAdapter();
+    const exchange = this.getExchangeName();
+    const adapterConfig: AdapterConfig = {
+      exchange,
+      endpoints: config.endpoints,
+      connection: config.connection,
+      auth: config.auth,
+      proxy: config.proxy,
+      proxyPool: config.proxyPool,
+      ...(config.extensions ? { [exchange]: config.extensions } : {})
+    };
+
+    await adapter.initialize(adapterConfig);
+    return adapter;
+  }
+
+  /**
+   * 开始数据订阅
+   */
+  protected async startSubscriptions(): Promise<void> {
+    const exchange = this.getExchangeName();
+    const subscription = this.config.adapterConfig.subscription;
+    const symbols: string[] = subscription?.symbols ?? [];
+    const dataTypes: string[] = subscription?.dataTypes ?? subscription?.streams ?? [];
+
+    if (symbols.length === 0 || dataTypes.length === 0) {
+      this.logger.log('warn', 'No symbols or data types configured for subscription', { exchange });
+      return;
+    }
+
+    const supported = dataTypes.filter(dataType => this.supportsDataType(dataType));
+    const unsupported = dataTypes.filter(dataType => !supported.includes(dataType));
+    if (unsupported.length > 0) {
+      this.logger.log('warn', 'Data types not supported by adapter, skipping', { exchange, dataTypes: unsupported });
+    }
+    if (supported.length === 0) {
+      return;
+    }
+
+    try {
+      await this.adapter.subscribe({ symbols, dataTypes: supported as DataType[] });
+
+      this.logger.log('info', 'Exchange subscriptions started', {
+        exchange,
+        symbols: symbols.length,
+        dataTypes: supported.length,
+        totalSubscriptions: symbols.length * supported.length
+      });
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error);
+      this.logger.log('error', 'Failed to start exchange subscriptions', {
+        exchange,
+        error: errorMessage,
+        symbols,
+        dataTypes: supported
+      });
+      throw error;
+    }
+  }
+}
@@ -4,8 +4,8 @@
  */
 
 import { EventEmitter } from 'events';
-import { BaseErrorHandler, BaseMonitor } from '@pixiu/shared-core';
-import { ExchangeAdapter, MarketData, AdapterStatus } from '@pixiu/adapter-base';
+import { BaseErrorHandler, BaseMonitor, ComponentLogger, getGlobalTracer } from '@pixiu/shared-core';
+import { ExchangeAdapter, MarketData, AdapterStatus, AdapterMetrics, AdapterCapabilities, DataType, OrderBook } from '@pixiu/adapter-base';
 import { DataFlowManager, IDataFlowManager } from '../../dataflow';
 
 /**
@@ -47,6 +47,8 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
   protected config!: PipelineIntegrationConfig;
   protected dataFlowManager!: IDataFlowManager;
   protected monitor!: BaseMonitor;
+  /** 以交易所名称为组件的日志记录器 */
+  protected logger!: ComponentLogger;
   protected errorHandler!: BaseErrorHandler;
   
   protected metrics!: PipelineIntegrationMetrics;
@@ -71,6 +73,7 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
     this.config = config;
     this.dataFlowManager = dataFlowManager;
     this.monitor = monitor;
+    this.logger = monitor.child(this.getExchangeName());
     this.errorHandler = errorHandler;
 
     try {
@@ -89,7 +92,7 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
       this.isInitialized = true;
       this.emit('initialized');
       
-      this.monitor.log('info', 'Pipeline adapter integration initialized', {
+      this.logger.log('info', 'Pipeline adapter integration initialized', {
         exchange: this.getExchangeName(),
         config: this.config
       });
@@ -124,7 +127,7 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
       this.isRunning = true;
       this.emit('started');
       
-      this.monitor.log('info', 'Pipeline adapter integration started', {
+      this.logger.log('info', 'Pipeline adapter integration started', {
         exchange: this.getExchangeName()
       });
     } catch (error) {
@@ -151,7 +154,7 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
       this.isRunning = false;
       this.emit('stopped');
       
-      this.monitor.log('info', 'Pipeline adapter integration stopped', {
+      this.logger.log('info', 'Pipeline adapter integration stopped', {
         exchange: this.getExchangeName()
       });
     } catch (error) {
@@ -191,6 +194,35 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
     return this.adapter?.getStatus() || AdapterStatus.DISCONNECTED;
   }
 
+  /**
+   * 获取适配器自身的连接指标
+   */
+  getAdapterMetrics(): AdapterMetrics | undefined {
+    return this.adapter?.getMetrics();
+  }
+
+  /**
+   * 获取适配器本地维护的订单簿
+   */
+  getOrderBook(symbol: string): OrderBook | undefined {
+    return this.adapter?.getOrderBook?.(symbol);
+  }
+
+  /**
+   * 获取适配器能力
+   */
+  getCapabilities(): AdapterCapabilities | undefined {
+    return this.adapter?.getCapabilities();
+  }
+
+  /**
+   * 检查适配器是否支持指定数据类型
+   */
+  supportsDataType(dataType: string): boolean {
+    const capabilities = this.getCapabilities();
+    return !!capabilities && capabilities.dataTypes.includes(dataType as DataType);
+  }
+
   /**
    * 检查是否健康
    */
@@ -214,7 +246,7 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
       this.metrics.adapterStatus = newStatus;
       this.emit('adapterStatusChange', newStatus, oldStatus);
       
-      this.monitor.log('info', 'Adapter status changed', {
+      this.logger.log('info', 'Adapter status changed', {
         exchange: this.getExchangeName(),
         oldStatus,
         newStatus
@@ -243,6 +275,17 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
    * 处理市场数据 - 核心重构点
    */
   private async processMarketData(marketData: MarketData): Promise<void> {
+    // 从适配器收到数据起计时，由数据流管道在路由完成后结束
+    const span = getGlobalTracer().startSpan('market_data.publish', {
+      kind: 'producer',
+      startTime: marketData.receivedAt,
+      attributes: {
+        'exchange': this.getExchangeName(),
+        'market.symbol': marketData.symbol,
+        'market.data_type': marketData.type
+      }
+    });
+
     try {
       const startTime = Date.now();
       
@@ -252,18 +295,19 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
       
       // 添加调试日志（每100条消息记录一次）
       if (this.metrics.messagesProcessed % 100 === 0) {
-        this.monitor.log('debug', `Processed ${this.metrics.messagesProcessed} messages from ${this.getExchangeName()}`);
+        this.logger.log('debug', `Processed ${this.metrics.messagesProcessed} messages from ${this.getExchangeName()}`);
       }
       
       // 基本数据验证
       if (!this.validateMarketData(marketData)) {
         this.metrics.processingErrors++;
-        this.monitor.log('warn', `Invalid market data from ${this.getExchangeName()}: ${JSON.stringify(marketData)}`);
+        this.logger.log('warn', `Invalid market data from ${this.getExchangeName()}: ${JSON.stringify(marketData)}`);
+        span.setStatus('error', 'invalid market data').end();
         return;
       }
 
       // 发送数据到数据流管道（替代直接的Pub/Sub发布）
-      await this.dataFlowManager.processData(marketData, this.getExchangeName());
+      await this.dataFlowManager.processData(marketData, this.getExchangeName(), span);
       
       this.metrics.messagesSentToPipeline++;
       
@@ -276,7 +320,8 @@ export abstract class PipelineAdapterIntegration extends EventEmitter {
       
     } catch (error) {
       this.metrics.processingErrors++;
-      this.monitor.log('error', `Error processing market data from ${this.getExchangeName()}: ${error}`);
+      this.logger.log('error', `Error processing market data from ${this.getExchangeName()}: ${error}`);
+      span.recordException(error as Error).end();
       await this.handleError(error as Error, 'processMarketData');
     }
   }
@@ -0,0 +1,14 @@
+/**
+ * Binance适配器声明
+ */
+
+import { defineAdapter } from '../registry/adapter-catalog';
+
+defineAdapter({
+  name: 'binance',
+  version: '1.0.0',
+  description: 'Binance exchange adapter integration',
+  supportedFeatures: ['websocket', 'trades', 'tickers', 'klines', 'depth'],
+  load: async () => (await import('./dataflow-integration')).createBinanceDataFlowIntegration,
+  describe: async () => new (await import('@pixiu/binance-adapter')).BinanceAdapter().getCapabilities()
+});
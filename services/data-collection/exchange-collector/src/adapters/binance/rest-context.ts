@@ -0,0 +1,32 @@
+/**
+ * Binance REST共享上下文
+ * 适配器、密钥权限复查与品种刷新共用一个上下文，请求权重统一记账，服务器时钟在服务运行期间定时校准
+ * 上下文随服务启动与停止，适配器不会启动或停止注入的上下文
+ */
+
+import { BaseMonitor, formatProxyUrl } from '@pixiu/shared-core';
+import type { BinanceRestContext } from '@pixiu/binance-adapter';
+import type { ExchangeCollectorConfig } from '../../config/unified-config';
+
+/**
+ * 按采集器配置创建上下文，Binance未启用或未配置REST地址时返回undefined
+ */
+export async function createBinanceRestContext(
+  config: ExchangeCollectorConfig,
+  monitor?: BaseMonitor
+): Promise<BinanceRestContext | undefined> {
+  const adapter = config.adapters.binance;
+  const adapterConfig = adapter?.config as any;
+  if (!adapterConfig?.enabled || !adapterConfig.endpoints?.rest) {
+    return undefined;
+  }
+
+  const { BinanceRestContext } = await import('@pixiu/binance-adapter');
+  return new BinanceRestContext({
+    restUrl: adapterConfig.endpoints.rest,
+    weightLimit: adapter.extensions?.restWeightLimit,
+    monitor,
+    proxyPool: adapterConfig.proxyPool
+      ?? (adapterConfig.proxy ? { proxies: [formatProxyUrl(adapterConfig.proxy)] } : undefined)
+  });
+}
@@ -0,0 +1,34 @@
+/**
+ * Bybit适配器DataFlow集成
+ */
+
+import { BybitAdapter } from '@pixiu/bybit-adapter';
+import { BaseAdapter } from '@pixiu/adapter-base';
+import { ExchangeDataFlowIntegration } from '../base/exchange-dataflow-integration';
+
+/**
+ * Bybit DataFlow适配器集成
+ */
+export class BybitDataFlowIntegration extends ExchangeDataFlowIntegration {
+
+  /**
+   * 创建适配器实例
+   */
+  protected instantiateAdapter(): BaseAdapter {
+    return new BybitAdapter({ monitor: this.monitor });
+  }
+
+  /**
+   * 获取交易所名称
+   */
+  protected getExchangeName(): string {
+    return 'bybit';
+  }
+}
+
+/**
+ * 创建Bybit DataFlow集成实例的工厂函数
+ */
+export function createBybitDataFlowIntegration(): BybitDataFlowIntegration {
+  return new BybitDataFlowIntegration();
+}
@@ -0,0 +1,14 @@
+/**
+ * Bybit适配器声明
+ */
+
+import { defineAdapter } from '../registry/adapter-catalog';
+
+defineAdapter({
+  name: 'bybit',
+  version: '1.0.0',
+  description: 'Bybit v5 exchange adapter integration',
+  supportedFeatures: ['websocket', 'trades', 'tickers', 'klines', 'depth'],
+  load: async () => (await import('./dataflow-integration')).createBybitDataFlowIntegration,
+  describe: async () => new (await import('@pixiu/bybit-adapter')).BybitAdapter().getCapabilities()
+});
@@ -0,0 +1,34 @@
+/**
+ * Coinbase适配器DataFlow集成
+ */
+
+import { CoinbaseAdapter } from '@pixiu/coinbase-adapter';
+import { BaseAdapter } from '@pixiu/adapter-base';
+import { ExchangeDataFlowIntegration } from '../base/exchange-dataflow-integration';
+
+/**
+ * Coinbase DataFlow适配器集成
+ */
+export class CoinbaseDataFlowIntegration extends ExchangeDataFlowIntegration {
+
+  /**
+   * 创建适配器实例
+   */
+  protected instantiateAdapter(): BaseAdapter {
+    return new CoinbaseAdapter({ monitor: this.monitor });
+  }
+
+  /**
+   * 获取交易所名称
+   */
+  protected getExchangeName(): string {
+    return 'coinbase';
+  }
+}
+
+/**
+ * 创建Coinbase DataFlow集成实例的工厂函数
+ */
+export function createCoinbaseDataFlowIntegration(): CoinbaseDataFlowIntegration {
+  return new CoinbaseDataFlowIntegration();
+}
@@ -0,0 +1,14 @@
+/**
+ * Coinbase适配器声明
+ */
+
+import { defineAdapter } from '../registry/adapter-catalog';
+
+defineAdapter({
+  name: 'coinbase',
+  version: '1.0.0',
+  description: 'Coinbase Advanced Trade adapter integration',
+  supportedFeatures: ['websocket', 'trades', 'tickers', 'klines', 'depth'],
+  load: async () => (await import('./dataflow-integration')).createCoinbaseDataFlowIntegration,
+  describe: async () => new (await import('@pixiu/coinbase-adapter')).CoinbaseAdapter().getCapabilities()
+});
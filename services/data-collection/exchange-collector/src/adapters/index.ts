@@ -4,12 +4,18 @@
 
 // 基础集成类
 export * from './base/adapter-integration';
+export * from './base/exchange-dataflow-integration';
 
 // 具体适配器实现
 export * from './binance/dataflow-integration';
+export * from './coinbase/dataflow-integration';
+export * from './okx/dataflow-integration';
+export * from './bybit/dataflow-integration';
+export * from './kraken/dataflow-integration';
 
 // 注册中心
 export * from './registry/adapter-registry';
+export * from './registry/adapter-catalog';
 
 // 重新导出adapter-base的核心类型
 export {
@@ -0,0 +1,34 @@
+/**
+ * Kraken适配器DataFlow集成
+ */
+
+import { KrakenAdapter } from '@pixiu/kraken-adapter';
+import { BaseAdapter } from '@pixiu/adapter-base';
+import { ExchangeDataFlowIntegration } from '../base/exchange-dataflow-integration';
+
+/**
+ * Kraken DataFlow适配器集成
+ */
+export class KrakenDataFlowIntegration extends ExchangeDataFlowIntegration {
+
+  /**
+   * 创建适配器实例
+   */
+  protected instantiateAdapter(): BaseAdapter {
+    return new KrakenAdapter({ monitor: this.monitor });
+  }
+
+  /**
+   * 获取交易所名称
+   */
+  protected getExchangeName(): string {
+    return 'kraken';
+  }
+}
+
+/**
+ * 创建Kraken DataFlow集成实例的工厂函数
+ */
+export function createKrakenDataFlowIntegration(): KrakenDataFlowIntegration {
+  return new KrakenDataFlowIntegration();
+}
@@ -0,0 +1,14 @@
+/**
+ * Kraken适配器声明
+ */
+
+import { defineAdapter } from '../registry/adapter-catalog';
+
+defineAdapter({
+  name: 'kraken',
+  version: '1.0.0',
+  description: 'Kraken exchange adapter integration',
+  supportedFeatures: ['websocket', 'trades', 'tickers', 'klines', 'depth', 'checksum'],
+  load: async () => (await import('./dataflow-integration')).createKrakenDataFlowIntegration,
+  describe: async () => new (await import('@pixiu/kraken-adapter')).KrakenAdapter().getCapabilities()
+});
@@ -0,0 +1,34 @@
+/**
+ * OKX适配器DataFlow集成
+ */
+
+import { OkxAdapter } from '@pixiu/okx-adapter';
+import { BaseAdapter } from '@pixiu/adapter-base';
+import { ExchangeDataFlowIntegration } from '../base/exchange-dataflow-integration';
+
+/**
+ * OKX DataFlow适配器集成
+ */
+export class OkxDataFlowIntegration extends ExchangeDataFlowIntegration {
+
+  /**
+   * 创建适配器实例
+   */
+  protected instantiateAdapter(): BaseAdapter {
+    return new OkxAdapter({ monitor: this.monitor });
+  }
+
+  /**
+   * 获取交易所名称
+   */
+  protected getExchangeName(): string {
+    return 'okx';
+  }
+}
+
+/**
+ * 创建OKX DataFlow集成实例的工厂函数
+ */
+export function createOkxDataFlowIntegration(): OkxDataFlowIntegration {
+  return new OkxDataFlowIntegration();
+}
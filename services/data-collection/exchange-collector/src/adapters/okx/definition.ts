@@ -0,0 +1,14 @@
+/**
+ * OKX适配器声明
+ */
+
+import { defineAdapter } from '../registry/adapter-catalog';
+
+defineAdapter({
+  name: 'okx',
+  version: '1.0.0',
+  description: 'OKX exchange adapter integration',
+  supportedFeatures: ['websocket', 'trades', 'tickers', 'depth', 'checksum'],
+  load: async () => (await import('./dataflow-integration')).createOkxDataFlowIntegration,
+  describe: async () => new (await import('@pixiu/okx-adapter')).OkxAdapter().getCapabilities()
+});
@@ -0,0 +1,103 @@
+/**
+ * 适配器目录
+ * 各交易所在自己目录下的 definition.ts 中声明适配器，注册中心与命令行从这里读取
+ * 交易所SDK只在创建实例或查询能力时才加载，未在配置中启用的交易所不会被初始化
+ */
+
+import { AdapterCapabilities, DataType } from '@pixiu/adapter-base';
+import type { AdapterIntegrationConstructor } from './adapter-registry';
+
+export interface AdapterDefinition {
+  /** 交易所名称，与配置中 adapters 下的键一致 */
+  name: string;
+  version: string;
+  description: string;
+  supportedFeatures: string[];
+  /** 加载适配器集成的工厂函数 */
+  load: () => Promise<AdapterIntegrationConstructor>;
+  /** 加载适配器并返回其能力声明，不建立连接 */
+  describe: () => Promise<AdapterCapabilities>;
+}
+
+export interface AdapterSummary {
+  name: string;
+  version: string;
+  description: string;
+  capabilities?: AdapterCapabilities;
+  /** 适配器加载失败时的错误信息 */
+  error?: string;
+}
+
+const definitions = new Map<string, AdapterDefinition>();
+
+/**
+ * 声明适配器，由各交易所的 definition.ts 在模块加载时调用
+ */
+export function defineAdapter(definition: AdapterDefinition): void {
+  if (definitions.has(definition.name)) {
+    throw new Error(`Adapter already defined: ${definition.name}`);
+  }
+  definitions.set(definition.name, definition);
+}
+
+export function getAdapterDefinitions(): AdapterDefinition[] {
+  return Array.from(definitions.values());
+}
+
+/**
+ * 加载全部适配器并汇总能力，单个适配器加载失败不影响其他适配器
+ */
+export async function listAdapters(list: AdapterDefinition[] = getAdapterDefinitions()): Promise<AdapterSummary[]> {
+  return Promise.all(list.map(async ({ name, version, description, describe }) => {
+    try {
+      return { name, version, description, capabilities: await describe() };
+    } catch (error) {
+      return { name, version, description, error: (error as Error).message };
+    }
+  }));
+}
+
+/**
+ * 格式化适配器列表，供 pixiu exchanges list 输出
+ */
+export function formatAdapterList(adapters: AdapterSummary[]): string {
+  const rows = adapters.map(adapter => {
+    const capabilities = adapter.capabilities;
+    return {
+      name: adapter.name,
+      version: adapter.version,
+      dataTypes: capabilities ? formatDataTypes(capabilities.dataTypes) : '-',
+      features: capabilities ? formatFeatures(capabilities) : `unavailable: ${adapter.error}`
+    };
+  });
+  const header = { name: 'EXCHANGE', version: 'VERSION', dataTypes: 'DATA TYPES', features: 'FEATURES' };
+  const width = (key: 'name' | 'version' | 'dataTypes') => Math.max(...[header, ...rows].map(row => row[key].length));
+
+  return [header, ...rows]
+    .map(row => [
+      row.name.padEnd(width('name')),
+      row.version.padEnd(width('version')),
+      row.dataTypes.padEnd(width('dataTypes')),
+      row.features
+    ].join('  ').trimEnd())
+    .join('\n');
+}
+
+function formatDataTypes(dataTypes: DataType[]): string {
+  return dataTypes.length > 0 ? dataTypes.join(', ') : '-';
+}
+
+function formatFeatures(capabilities: AdapterCapabilities): string {
+  const trading = Object.entries(capabilities.trading)
+    .filter(([, supported]) => supported)
+    .map(([kind]) => kind);
+  const features = [
+    capabilities.websocket && 'websocket',
+    capabilities.combinedStreams && `combined streams${capabilities.maxSubscriptionsPerConnection ? ` (${capabilities.maxSubscriptionsPerConnection}/connection)` : ''}`,
+    capabilities.orderBookChecksum && 'order book checksum',
+    capabilities.userDataStream && 'user data stream',
+    trading.length > 0 && `trading: ${trading.join(', ')}`
+  ].filter((feature): feature is string => typeof feature === 'string');
+
+  return features.length > 0 ? features.join(', ') : '-';
+}
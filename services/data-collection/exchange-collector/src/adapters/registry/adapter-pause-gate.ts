@@ -0,0 +1,128 @@
+/**
+ * 适配器暂停控制
+ * 故障注入与交易所维护等多个来源可能同时暂停同一适配器，按来源记录暂停原因，
+ * 第一个原因出现时停止适配器，最后一个原因解除后才重新启动
+ */
+
+import { EventEmitter } from 'events';
+import type { AdapterRegistry } from './adapter-registry';
+
+export type PausableRegistry = Pick<AdapterRegistry, 'getInstance' | 'startInstance' | 'stopInstance'>;
+
+const MAX_RESTART_DELAY = 60000;
+
+/**
+ * 事件：
+ * - restartFailed(exchange, error, attempt) 重新启动失败后的重试再次失败
+ * - restarted(exchange, attempt) 重试启动成功
+ */
+export class AdapterPauseGate extends EventEmitter {
+  private readonly reasons = new Map<string, Set<string>>();
+  private readonly restartTimers = new Map<string, NodeJS.Timeout>();
+
+  /**
+   * @param canResume 返回false时（如服务正在关闭）原因仍会解除，但不重新启动适配器
+   * @param restartDelay 重新启动失败后首次重试的间隔（毫秒），之后每次翻倍，最长60秒
+   */
+  constructor(
+    private readonly registry: PausableRegistry,
+    private readonly canResume: () => boolean = () => true,
+    private readonly restartDelay = 5000
+  ) {
+    super();
+  }
+
+  /**
+   * 记录暂停原因，适配器此前未暂停时将其停止
+   * 停止失败时原因仍会记录，错误抛给调用方
+   * @returns 本次是否停止了适配器
+   */
+  async pause(exchange: string, reason: string): Promise<boolean> {
+    let reasons = this.reasons.get(exchange);
+    if (!reasons) {
+      reasons = new Set();
+      this.reasons.set(exchange, reasons);
+    }
+
+    const wasRunning = reasons.size === 0;
+    reasons.add(reason);
+    this.cancelRestart(exchange);
+    if (!wasRunning || !this.registry.getInstance(exchange)) {
+      return false;
+    }
+
+    await this.registry.stopInstance(exchange);
+    return true;
+  }
+
+  /**
+   * 解除暂停原因，仅在没有其他原因时重新启动适配器
+   * 未记录过的原因直接忽略，不会启动未被暂停的适配器
+   * 启动失败时抛出错误，并按退避间隔继续重试，直到成功或再次被暂停
+   * @returns 本次是否重新启动了适配器
+   */
+  async resume(exchange: string, reason: string): Promise<boolean> {
+    const reasons = this.reasons.get(exchange);
+    if (!reasons?.delete(reason) || reasons.size > 0) {
+      return false;
+    }
+
+    this.reasons.delete(exchange);
+    if (!this.registry.getInstance(exchange) || !this.canResume()) {
+      return false;
+    }
+
+    try {
+      await this.registry.startInstance(exchange);
+    } catch (error) {
+      this.scheduleRestart(exchange, 1);
+      throw error;
+    }
+    return true;
+  }
+
+  /**
+   * 仍在生效的暂停原因
+   */
+  getReasons(exchange: string): string[] {
+    return Array.from(this.reasons.get(exchange) ?? []);
+  }
+
+  /**
+   * 取消所有待执行的重试
+   */
+  destroy(): void {
+    for (const timer of this.restartTimers.values()) {
+      clearTimeout(timer);
+    }
+    this.restartTimers.clear();
+  }
+
+  private scheduleRestart(exchange: string, attempt: number): void {
+    const delay = Math.min(this.restartDelay * 2 ** (attempt - 1), MAX_RESTART_DELAY);
+    const timer = setTimeout(async () => {
+      this.restartTimers.delete(exchange);
+      if (this.reasons.has(exchange) || !this.registry.getInstance(exchange) || !this.canResume()) {
+        return;
+      }
+
+      try {
+        await this.registry.startInstance(exchange);
+        this.emit('restarted', exchange, attempt);
+      } catch (error) {
+        this.emit('restartFailed', exchange, error, attempt);
+        this.scheduleRestart(exchange, attempt + 1);
+      }
+    }, delay);
+    timer.unref();
+    this.restartTimers.set(exchange, timer);
+  }
+
+  private cancelRestart(exchange: string): void {
+    const timer = this.restartTimers.get(exchange);
+    if (timer) {
+      clearTimeout(timer);
+      this.restartTimers.delete(exchange);
+    }
+  }
+}
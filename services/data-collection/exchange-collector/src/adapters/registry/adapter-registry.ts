@@ -1,18 +1,22 @@
 /**
  * 适配器注册中心
- * 管理所有可用的适配器集成
+ * 管理所有可用的适配器集成，内置适配器来自适配器目录，创建实例时才加载交易所SDK
  */
 
 import { EventEmitter } from 'events';
-import { BaseErrorHandler, BaseMonitor, PubSubClientImpl } from '@pixiu/shared-core';
+import { BaseErrorHandler, BaseMonitor, PubSubClientImpl, ComponentLogger } from '@pixiu/shared-core';
+import { AdapterCapabilities } from '@pixiu/adapter-base';
 import { AdapterIntegration, IntegrationConfig } from '../base/adapter-integration';
-import { createBinanceDataFlowIntegration } from '../binance/dataflow-integration';
+import { getAdapterDefinitions } from './adapter-catalog';
+import './builtin-adapters';
 
 export type AdapterIntegrationConstructor = () => AdapterIntegration;
 
 export interface RegistryEntry {
-  /** 构造函数 */
-  constructor: AdapterIntegrationConstructor;
+  /** 加载构造函数 */
+  load: () => Promise<AdapterIntegrationConstructor>;
+  /** 查询适配器能力，不建立连接 */
+  describe?: () => Promise<AdapterCapabilities>;
   /** 版本 */
   version: string;
   /** 描述 */
@@ -48,6 +52,7 @@ export class AdapterRegistry extends EventEmitter {
   private config!: AdapterRegistryConfig;
   private pubsubClient!: PubSubClientImpl;
   private monitor!: BaseMonitor;
+  private logger!: ComponentLogger;
   private errorHandler!: BaseErrorHandler;
   
   private isInitialized = false;
@@ -65,6 +70,7 @@ export class AdapterRegistry extends EventEmitter {
     this.config = config;
     this.pubsubClient = pubsubClient;
     this.monitor = monitor;
+    this.logger = monitor.child('adapter-registry');
     this.errorHandler = errorHandler;
 
     // 注册内置适配器
@@ -76,7 +82,7 @@ export class AdapterRegistry extends EventEmitter {
     this.isInitialized = true;
     this.emit('initialized');
     
-    this.monitor.log('info', 'Adapter registry initialized', {
+    this.logger.log('info', 'Adapter registry initialized', {
       registeredAdapters: Array.from(this.entries.keys()),
       autoStartAdapters: this.config.autoStart
     });
@@ -88,10 +94,22 @@ export class AdapterRegistry extends EventEmitter {
   register(
     name: string,
     constructor: AdapterIntegrationConstructor,
-    metadata: Partial<Omit<RegistryEntry, 'constructor'>> = {}
+    metadata: Partial<Omit<RegistryEntry, 'load'>> = {}
+  ): void {
+    this.registerLazy(name, async () => constructor, metadata);
+  }
+
+  /**
+   * 注册按需加载的适配器，首次创建实例时才调用load
+   */
+  registerLazy(
+    name: string,
+    load: () => Promise<AdapterIntegrationConstructor>,
+    metadata: Partial<Omit<RegistryEntry, 'load'>> = {}
   ): void {
     const entry: RegistryEntry = {
-      constructor,
+      load,
+      describe: metadata.describe,
       version: metadata.version || '1.0.0',
       description: metadata.description || `${name} adapter integration`,
       supportedFeatures: metadata.supportedFeatures || [],
@@ -102,7 +120,7 @@ export class AdapterRegistry extends EventEmitter {
     this.entries.set(name, entry);
     this.emit('adapterRegistered', name, entry);
     
-    this.monitor.log('info', 'Adapter registered', { name, entry });
+    this.logger.log('info', 'Adapter registered', { name, entry });
   }
 
   /**
@@ -114,7 +132,7 @@ export class AdapterRegistry extends EventEmitter {
       this.entries.delete(name);
       this.emit('adapterUnregistered', name, entry);
       
-      this.monitor.log('info', 'Adapter unregistered', { name });
+      this.logger.log('info', 'Adapter unregistered', { name });
     }
   }
 
@@ -132,7 +150,8 @@ export class AdapterRegistry extends EventEmitter {
     }
 
     try {
-      const instance = entry.constructor();
+      const create = await entry.load();
+      const instance = create();
       
       // 合并默认配置
       const finalConfig = this.mergeConfigs(this.config.defaultConfig, config);
@@ -149,11 +168,11 @@ export class AdapterRegistry extends EventEmitter {
       
       this.emit('instanceCreated', name, instance);
       
-      this.monitor.log('info', 'Adapter instance created', { name });
+      this.logger.log('info', 'Adapter instance created', { name });
       
       return instance;
     } catch (error) {
-      this.monitor.log('error', 'Failed to create adapter instance', { name, error });
+      this.logger.log('error', 'Failed to create adapter instance', { name, error });
       throw error;
     }
   }
@@ -171,9 +190,9 @@ export class AdapterRegistry extends EventEmitter {
       await instance.start();
       this.emit('instanceStarted', name, instance);
       
-      this.monitor.log('info', 'Adapter instance started', { name });
+      this.logger.log('info', 'Adapter instance started', { name });
     } catch (error) {
-      this.monitor.log('error', 'Failed to start adapter instance', { name, error });
+      this.logger.log('error', 'Failed to start adapter instance', { name, error });
       throw error;
     }
   }
@@ -191,9 +210,9 @@ export class AdapterRegistry extends EventEmitter {
       await instance.stop();
       this.emit('instanceStopped', name, instance);
       
-      this.monitor.log('info', 'Adapter instance stopped', { name });
+      this.logger.log('info', 'Adapter instance stopped', { name });
     } catch (error) {
-      this.monitor.log('error', 'Failed to stop adapter instance', { name, error });
+      this.logger.log('error', 'Failed to stop adapter instance', { name, error });
       throw error;
     }
   }
@@ -212,9 +231,9 @@ export class AdapterRegistry extends EventEmitter {
       this.instances.delete(name);
       this.emit('instanceDestroyed', name);
       
-      this.monitor.log('info', 'Adapter instance destroyed', { name });
+      this.logger.log('info', 'Adapter instance destroyed', { name });
     } catch (error) {
-      this.monitor.log('error', 'Failed to destroy adapter instance', { name, error });
+      this.logger.log('error', 'Failed to destroy adapter instance', { name, error });
       throw error;
     }
   }
@@ -240,6 +259,13 @@ export class AdapterRegistry extends EventEmitter {
     return this.entries.get(name);
   }
 
+  /**
+   * 获取适配器能力，运行中的实例直接返回，否则加载适配器查询
+   */
+  async describeAdapter(name: string): Promise<AdapterCapabilities | undefined> {
+    return this.instances.get(name)?.getCapabilities() ?? this.entries.get(name)?.describe?.();
+  }
+
   /**
    * 获取所有注册的适配器
    */
@@ -272,7 +298,7 @@ export class AdapterRegistry extends EventEmitter {
       entry.enabled = enabled;
       this.emit('adapterEnabledChanged', name, enabled);
       
-      this.monitor.log('info', 'Adapter enabled status changed', { name, enabled });
+      this.logger.log('info', 'Adapter enabled status changed', { name, enabled });
     }
   }
 
@@ -284,14 +310,14 @@ export class AdapterRegistry extends EventEmitter {
       try {
         const config = configs.get(name);
         if (!config) {
-          this.monitor.log('warn', 'No config found for auto-start adapter', { name });
+          this.logger.log('warn', 'No config found for auto-start adapter', { name });
           return;
         }
 
         await this.createInstance(name, config);
         await this.startInstance(name);
       } catch (error) {
-        this.monitor.log('error', 'Failed to auto-start adapter', { name, error });
+        this.logger.log('error', 'Failed to auto-start adapter', { name, error });
       }
     });
 
@@ -304,7 +330,7 @@ export class AdapterRegistry extends EventEmitter {
   async stopAllInstances(): Promise<void> {
     const stopPromises = Array.from(this.instances.keys()).map(name => 
       this.stopInstance(name).catch(error => 
-        this.monitor.log('error', 'Failed to stop instance during shutdown', { name, error })
+        this.logger.log('error', 'Failed to stop instance during shutdown', { name, error })
       )
     );
 
@@ -354,18 +380,9 @@ export class AdapterRegistry extends EventEmitter {
    * 注册内置适配器
    */
   private registerBuiltinAdapters(): void {
-    // 注册Binance适配器
-    const binanceMetadata = {
-      version: '1.0.0',
-      description: 'Binance exchange adapter integration',
-      supportedFeatures: ['websocket', 'trades', 'tickers', 'klines', 'depth'],
-      enabled: true
-    };
-    this.register('binance', createBinanceDataFlowIntegration, binanceMetadata);
-
-    // 这里可以注册其他内置适配器
-    // this.register('okx', createOkxIntegration, { ... });
-    // this.register('huobi', createHuobiIntegration, { ... });
+    for (const { name, load, ...metadata } of getAdapterDefinitions()) {
+      this.registerLazy(name, load, metadata);
+    }
   }
 
   /**
@@ -425,7 +442,7 @@ export class AdapterRegistry extends EventEmitter {
       if (!isHealthy) {
         this.emit('instanceUnhealthy', name, instance);
         
-        this.monitor.log('warn', 'Adapter instance is unhealthy', {
+        this.logger.log('warn', 'Adapter instance is unhealthy', {
           name,
           status: instance.getAdapterStatus(),
           metrics: instance.getMetrics()
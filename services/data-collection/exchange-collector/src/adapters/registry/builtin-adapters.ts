@@ -0,0 +1,10 @@
+/**
+ * 内置适配器
+ * 导入各交易所的声明完成注册，新增交易所时在此追加一行
+ */
+
+import '../binance/definition';
+import '../coinbase/definition';
+import '../okx/definition';
+import '../bybit/definition';
+import '../kraken/definition';
@@ -75,6 +75,7 @@ export function createAdapterRouter(
         status: instanceStatus?.status || 'stopped',
         healthy: instanceStatus?.healthy || false,
         metrics: instanceStatus?.metrics,
+        capabilities: await adapterRegistry.describeAdapter(name).catch(() => undefined),
         metadata: entry?.metadata
       });
     } catch (error) {
@@ -0,0 +1,86 @@
+import { Router, Request, Response, NextFunction } from 'express';
+import { BaseMonitor } from '@pixiu/shared-core';
+import { FaultInjector, FaultSpec } from '../chaos';
+
+/**
+ * 创建故障注入控制路由
+ * 未以 --enable-fault-injection 启动时所有请求返回404
+ */
+export function createFaultInjectionRouter(
+  getInjector: () => FaultInjector | undefined,
+  monitor: BaseMonitor
+): Router {
+  const router = Router();
+
+  router.use((_req: Request, res: Response, next: NextFunction) => {
+    if (!getInjector()) {
+      res.status(404).json({
+        error: 'Fault injection is disabled',
+        message: 'Start the collector with --enable-fault-injection to use this API',
+        timestamp: new Date().toISOString()
+      });
+      return;
+    }
+    next();
+  });
+
+  /**
+   * 列出生效中的故障
+   * GET /api/faults
+   */
+  router.get('/', (_req: Request, res: Response) => {
+    res.json({
+      faults: getInjector()!.list(),
+      timestamp: new Date().toISOString()
+    });
+  });
+
+  /**
+   * 注入故障
+   * POST /api/faults
+   */
+  router.post('/', (req: Request, res: Response) => {
+    const { kind, exchange, symbols, duration, delay } = req.body ?? {};
+    const spec: FaultSpec = { kind, exchange, symbols, duration: Number(duration), delay: delay === undefined ? undefined : Number(delay) };
+
+    try {
+      const fault = getInjector()!.inject(spec);
+      res.status(201).json({ fault, timestamp: new Date().toISOString() });
+    } catch (error) {
+      const message = error instanceof Error ? error.message : 'Unknown error';
+      monitor.log('warn', 'Rejected fault injection request', { error: message });
+      res.status(400).json({
+        error: 'Invalid fault',
+        message,
+        timestamp: new Date().toISOString()
+      });
+    }
+  });
+
+  /**
+   * 解除全部故障
+   * DELETE /api/faults
+   */
+  router.delete('/', (_req: Request, res: Response) => {
+    getInjector()!.clearAll();
+    res.json({ success: true, timestamp: new Date().toISOString() });
+  });
+
+  /**
+   * 解除单个故障
+   * DELETE /api/faults/:id
+   */
+  router.delete('/:id', (req: Request, res: Response) => {
+    if (!getInjector()!.clear(req.params.id)) {
+      res.status(404).json({
+        error: 'Fault not found',
+        message: `No active fault with id ${req.params.id}`,
+        timestamp: new Date().toISOString()
+      });
+      return;
+    }
+    res.json({ success: true, timestamp: new Date().toISOString() });
+  });
+
+  return router;
+}
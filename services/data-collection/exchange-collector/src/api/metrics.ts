@@ -21,53 +21,65 @@ export function createMetricsRouter(
       const metrics: string[] = [];
 
       // 服务级别指标
-      metrics.push('# HELP exchange_collector_up Exchange collector service status');
-      metrics.push('# TYPE exchange_collector_up gauge');
-      metrics.push(`exchange_collector_up 1`);
-
-      metrics.push('# HELP exchange_collector_uptime_seconds Service uptime in seconds');
-      metrics.push('# TYPE exchange_collector_uptime_seconds gauge');
-      metrics.push(`exchange_collector_uptime_seconds ${process.uptime()}`);
+      pushMetric(metrics, 'exchange_collector_up', 'Exchange collector service status', 'gauge', [{ value: 1 }]);
+      pushMetric(metrics, 'exchange_collector_uptime_seconds', 'Service uptime in seconds', 'gauge', [{ value: process.uptime() }]);
 
       // 适配器指标
-      metrics.push('# HELP exchange_collector_adapters_registered Number of registered adapters');
-      metrics.push('# TYPE exchange_collector_adapters_registered gauge');
-      metrics.push(`exchange_collector_adapters_registered ${status.registeredAdapters.length}`);
-
-      metrics.push('# HELP exchange_collector_adapters_running Number of running adapters');
-      metrics.push('# TYPE exchange_collector_adapters_running gauge');
-      metrics.push(`exchange_collector_adapters_running ${status.runningInstances.length}`);
-
-      // 各适配器的详细指标
-      for (const instance of status.instanceStatuses) {
-        const labels = `exchange="${instance.name}"`;
-        
-        metrics.push(`# HELP exchange_collector_adapter_healthy Adapter health status`);
-        metrics.push(`# TYPE exchange_collector_adapter_healthy gauge`);
-        metrics.push(`exchange_collector_adapter_healthy{${labels}} ${instance.healthy ? 1 : 0}`);
-
-        if (instance.metrics) {
-          metrics.push(`# HELP exchange_collector_messages_processed_total Total messages processed`);
-          metrics.push(`# TYPE exchange_collector_messages_processed_total counter`);
-          metrics.push(`exchange_collector_messages_processed_total{${labels}} ${instance.metrics.messagesProcessed}`);
-
-          metrics.push(`# HELP exchange_collector_messages_published_total Total messages published`);
-          metrics.push(`# TYPE exchange_collector_messages_published_total counter`);
-          metrics.push(`exchange_collector_messages_published_total{${labels}} ${instance.metrics.messagesPublished}`);
-
-          metrics.push(`# HELP exchange_collector_processing_errors_total Total processing errors`);
-          metrics.push(`# TYPE exchange_collector_processing_errors_total counter`);
-          metrics.push(`exchange_collector_processing_errors_total{${labels}} ${instance.metrics.processingErrors}`);
-
-          metrics.push(`# HELP exchange_collector_publish_errors_total Total publish errors`);
-          metrics.push(`# TYPE exchange_collector_publish_errors_total counter`);
-          metrics.push(`exchange_collector_publish_errors_total{${labels}} ${instance.metrics.publishErrors}`);
-
-          metrics.push(`# HELP exchange_collector_average_latency_ms Average processing latency`);
-          metrics.push(`# TYPE exchange_collector_average_latency_ms gauge`);
-          metrics.push(`exchange_collector_average_latency_ms{${labels}} ${instance.metrics.averageProcessingLatency}`);
-        }
-      }
+      pushMetric(metrics, 'exchange_collector_adapters_registered', 'Number of registered adapters', 'gauge', [
+        { value: status.registeredAdapters.length }
+      ]);
+      pushMetric(metrics, 'exchange_collector_adapters_running', 'Number of running adapters', 'gauge', [
+        { value: status.runningInstances.length }
+      ]);
+
+      // 各适配器的详细指标，同一指标族只输出一次 HELP/TYPE
+      const instances = status.instanceStatuses.map(instance => ({
+        labels: `exchange="${instance.name}"`,
+        healthy: instance.healthy,
+        metrics: instance.metrics,
+        adapterMetrics: adapterRegistry.getInstance(instance.name)?.getAdapterMetrics()
+      }));
+      const withMetrics = instances.filter(instance => instance.metrics);
+      const withAdapterMetrics = instances.filter(instance => instance.adapterMetrics);
+      const withRateLimit = withAdapterMetrics.filter(instance => instance.adapterMetrics!.restRateLimit);
+      const now = Date.now();
+
+      pushMetric(metrics, 'exchange_collector_adapter_healthy', 'Adapter health status', 'gauge',
+        instances.map(instance => ({ labels: instance.labels, value: instance.healthy ? 1 : 0 })));
+
+      pushMetric(metrics, 'exchange_collector_messages_processed_total', 'Total messages processed', 'counter',
+        withMetrics.map(instance => ({ labels: instance.labels, value: instance.metrics.messagesProcessed })));
+      pushMetric(metrics, 'exchange_collector_messages_published_total', 'Total messages published', 'counter',
+        withMetrics.map(instance => ({ labels: instance.labels, value: instance.metrics.messagesPublished })));
+      pushMetric(metrics, 'exchange_collector_processing_errors_total', 'Total processing errors', 'counter',
+        withMetrics.map(instance => ({ labels: instance.labels, value: instance.metrics.processingErrors })));
+      pushMetric(metrics, 'exchange_collector_publish_errors_total', 'Total publish errors', 'counter',
+        withMetrics.map(instance => ({ labels: instance.labels, value: instance.metrics.publishErrors })));
+      pushMetric(metrics, 'exchange_collector_average_latency_ms', 'Average processing latency', 'gauge',
+        withMetrics.map(instance => ({ labels: instance.labels, value: instance.metrics.averageProcessingLatency })));
+
+      // 交易所连接指标
+      pushMetric(metrics, 'exchange_collector_adapter_reconnects_total', 'Total websocket reconnects', 'counter',
+        withAdapterMetrics.map(instance => ({ labels: instance.labels, value: instance.adapterMetrics!.reconnectCount })));
+      pushMetric(metrics, 'exchange_collector_adapter_messages_received_total', 'Total websocket messages received', 'counter',
+        withAdapterMetrics.map(instance => ({ labels: instance.labels, value: instance.adapterMetrics!.messagesReceived })));
+      pushMetric(metrics, 'exchange_collector_adapter_errors_total', 'Total adapter errors', 'counter',
+        withAdapterMetrics.map(instance => ({ labels: instance.labels, value: instance.adapterMetrics!.errorCount })));
+      pushMetric(metrics, 'exchange_collector_adapter_latency_ms', 'Average websocket heartbeat latency', 'gauge',
+        withAdapterMetrics.map(instance => ({ labels: instance.labels, value: instance.adapterMetrics!.averageLatency })));
+      pushMetric(metrics, 'exchange_collector_adapter_heartbeat_age_seconds', 'Seconds since last websocket heartbeat', 'gauge',
+        withAdapterMetrics
+          .filter(instance => instance.adapterMetrics!.lastHeartbeat)
+          .map(instance => ({ labels: instance.labels, value: (now - instance.adapterMetrics!.lastHeartbeat!) / 1000 })));
+
+      // REST限流余量
+      pushMetric(metrics, 'exchange_collector_rest_rate_limit_used', 'REST rate limit weight used in current window', 'gauge',
+        withRateLimit.map(instance => ({ labels: instance.labels, value: instance.adapterMetrics!.restRateLimit!.used })));
+      pushMetric(metrics, 'exchange_collector_rest_rate_limit_headroom', 'REST rate limit weight remaining in current window', 'gauge',
+        withRateLimit.map(instance => {
+          const rateLimit = instance.adapterMetrics!.restRateLimit!;
+          return { labels: instance.labels, value: Math.max(rateLimit.limit - rateLimit.used, 0) };
+        }));
 
       res.set('Content-Type', 'text/plain; version=0.0.4');
       res.send(metrics.join('\n'));
@@ -100,7 +112,8 @@ export function createMetricsRouter(
             name: s.name,
             status: s.status,
             healthy: s.healthy,
-            metrics: s.metrics
+            metrics: s.metrics,
+            adapterMetrics: adapterRegistry.getInstance(s.name)?.getAdapterMetrics()
           }))
         },
         timestamp: new Date().toISOString()
@@ -114,4 +127,30 @@ export function createMetricsRouter(
   });
 
   return router;
+}
+
+interface MetricSample {
+  labels?: string;
+  value: number;
+}
+
+/**
+ * 按 Prometheus 文本格式输出一个指标族
+ */
+function pushMetric(
+  lines: string[],
+  name: string,
+  help: string,
+  type: 'counter' | 'gauge',
+  samples: MetricSample[]
+): void {
+  if (samples.length === 0) {
+    return;
+  }
+
+  lines.push(`# HELP ${name} ${help}`);
+  lines.push(`# TYPE ${name} ${type}`);
+  for (const sample of samples) {
+    lines.push(sample.labels ? `${name}{${sample.labels}} ${sample.value}` : `${name} ${sample.value}`);
+  }
 }
\ No newline at end of file
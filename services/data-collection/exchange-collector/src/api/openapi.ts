@@ -0,0 +1,482 @@
+/**
+ * OpenAPI 文档路由
+ * 描述采集服务对外暴露的 HTTP 接口，供外部仪表盘生成客户端
+ */
+
+import { Router, Request, Response } from 'express';
+
+type OpenApiObject = Record<string, any>;
+
+const jsonContent = (schema: OpenApiObject): OpenApiObject => ({
+  'application/json': { schema }
+});
+
+const ref = (name: string): OpenApiObject => ({ $ref: `#/components/schemas/${name}` });
+
+const jsonResponse = (description: string, schema: OpenApiObject): OpenApiObject => ({
+  description,
+  content: jsonContent(schema)
+});
+
+const errorResponse = (description: string): OpenApiObject => jsonResponse(description, ref('Error'));
+
+const pathParameter = (name: string, description: string): OpenApiObject => ({
+  name,
+  in: 'path',
+  required: true,
+  description,
+  schema: { type: 'string' }
+});
+
+const adapterAction = (action: string): OpenApiObject => ({
+  post: {
+    tags: ['adapters'],
+    summary: `${action} an adapter instance`,
+    parameters: [pathParameter('name', 'Adapter name, e.g. binance')],
+    responses: {
+      200: jsonResponse(`Adapter ${action.toLowerCase()} result`, { type: 'object' }),
+      404: errorResponse('Adapter not found'),
+      500: errorResponse(`Failed to ${action.toLowerCase()} adapter`)
+    }
+  }
+});
+
+const schemas: OpenApiObject = {
+  Error: {
+    type: 'object',
+    required: ['error'],
+    properties: {
+      error: { type: 'string' },
+      message: { type: 'string' }
+    }
+  },
+  HealthStatus: {
+    type: 'object',
+    properties: {
+      status: { type: 'string', enum: ['healthy', 'unhealthy'] },
+      timestamp: { type: 'string', format: 'date-time' },
+      service: { type: 'string' },
+      version: { type: 'string' },
+      uptime: { type: 'number' },
+      checks: { type: 'object' }
+    }
+  },
+  AdapterStatus: {
+    type: 'object',
+    properties: {
+      name: { type: 'string' },
+      version: { type: 'string' },
+      description: { type: 'string' },
+      enabled: { type: 'boolean' },
+      running: { type: 'boolean' },
+      status: { type: 'string' },
+      healthy: { type: 'boolean' },
+      metrics: { type: 'object' }
+    }
+  },
+  AdapterList: {
+    type: 'object',
+    properties: {
+      total: { type: 'integer' },
+      running: { type: 'integer' },
+      adapters: { type: 'array', items: ref('AdapterStatus') }
+    }
+  },
+  Subscription: {
+    type: 'object',
+    properties: {
+      exchange: { type: 'string' },
+      symbol: { type: 'string' },
+      dataTypes: { type: 'array', items: { type: 'string' } },
+      status: { type: 'string', enum: ['active', 'paused', 'error'] },
+      metrics: {
+        type: 'object',
+        properties: {
+          messagesReceived: { type: 'integer' },
+          lastUpdate: { type: 'string', format: 'date-time', nullable: true },
+          bytesReceived: { type: 'integer' },
+          errorCount: { type: 'integer' }
+        }
+      }
+    }
+  },
+  SubscriptionRequest: {
+    type: 'object',
+    required: ['exchange', 'symbol', 'dataTypes'],
+    properties: {
+      exchange: { type: 'string' },
+      symbol: { type: 'string' },
+      dataTypes: { type: 'array', items: { type: 'string' } }
+    }
+  },
+  BatchOperation: {
+    type: 'object',
+    required: ['action', 'subscriptions'],
+    properties: {
+      action: { type: 'string', enum: ['start', 'stop', 'delete'] },
+      subscriptions: {
+        type: 'array',
+        items: {
+          type: 'object',
+          required: ['exchange', 'symbol'],
+          properties: {
+            exchange: { type: 'string' },
+            symbol: { type: 'string' }
+          }
+        }
+      }
+    }
+  },
+  BatchOperationResult: {
+    type: 'object',
+    properties: {
+      success: { type: 'boolean' },
+      results: {
+        type: 'array',
+        items: {
+          type: 'object',
+          properties: {
+            exchange: { type: 'string' },
+            symbol: { type: 'string' },
+            success: { type: 'boolean' },
+            error: { type: 'string' }
+          }
+        }
+      },
+      summary: {
+        type: 'object',
+        properties: {
+          total: { type: 'integer' },
+          successful: { type: 'integer' },
+          failed: { type: 'integer' }
+        }
+      }
+    }
+  },
+  RealTimeStats: {
+    type: 'object',
+    properties: {
+      adapters: { type: 'object', additionalProperties: { type: 'object' } },
+      system: { type: 'object' },
+      cache: { type: 'object' },
+      timestamp: { type: 'string', format: 'date-time' }
+    }
+  },
+  HistoricalStats: {
+    type: 'object',
+    properties: {
+      timeRange: {
+        type: 'object',
+        properties: {
+          start: { type: 'string', format: 'date-time' },
+          end: { type: 'string', format: 'date-time' },
+          interval: { type: 'string' }
+        }
+      },
+      data: { type: 'array', items: { type: 'object' } }
+    }
+  },
+  PubSubToggleRequest: {
+    type: 'object',
+    required: ['enabled'],
+    properties: {
+      enabled: { type: 'boolean' },
+      reason: { type: 'string' }
+    }
+  },
+  FaultSpec: {
+    type: 'object',
+    required: ['kind', 'exchange', 'duration'],
+    properties: {
+      kind: { type: 'string', enum: ['outage', 'stale', 'latency'] },
+      exchange: { type: 'string' },
+      symbols: { type: 'array', items: { type: 'string' }, description: 'Limit stale and latency faults to these symbols' },
+      duration: { type: 'integer', description: 'Milliseconds until the fault clears itself' },
+      delay: { type: 'integer', description: 'Delivery delay in milliseconds, required for latency faults' }
+    }
+  },
+  ActiveFault: {
+    allOf: [
+      ref('FaultSpec'),
+      {
+        type: 'object',
+        properties: {
+          id: { type: 'string' },
+          startedAt: { type: 'integer' },
+          expiresAt: { type: 'integer' }
+        }
+      }
+    ]
+  }
+};
+
+const paths: OpenApiObject = {
+  '/health': {
+    get: {
+      tags: ['health'],
+      summary: 'Service health check',
+      responses: {
+        200: jsonResponse('Service is healthy', ref('HealthStatus')),
+        503: jsonResponse('Service is unhealthy', ref('HealthStatus'))
+      }
+    }
+  },
+  '/health/ready': {
+    get: {
+      tags: ['health'],
+      summary: 'Readiness check',
+      responses: {
+        200: jsonResponse('Service is ready', { type: 'object' }),
+        503: jsonResponse('Service is not ready', { type: 'object' })
+      }
+    }
+  },
+  '/health/live': {
+    get: {
+      tags: ['health'],
+      summary: 'Liveness check',
+      responses: {
+        200: jsonResponse('Service is alive', { type: 'object' })
+      }
+    }
+  },
+  '/metrics': {
+    get: {
+      tags: ['metrics'],
+      summary: 'Prometheus metrics',
+      responses: {
+        200: { description: 'Metrics in Prometheus text format', content: { 'text/plain': { schema: { type: 'string' } } } }
+      }
+    }
+  },
+  '/metrics/json': {
+    get: {
+      tags: ['metrics'],
+      summary: 'Metrics as JSON',
+      responses: {
+        200: jsonResponse('Metrics snapshot', { type: 'object' })
+      }
+    }
+  },
+  '/api/adapters': {
+    get: {
+      tags: ['adapters'],
+      summary: 'List registered adapters',
+      responses: {
+        200: jsonResponse('Adapter list', ref('AdapterList')),
+        500: errorResponse('Failed to get adapters')
+      }
+    }
+  },
+  '/api/adapters/{name}': {
+    get: {
+      tags: ['adapters'],
+      summary: 'Get adapter status',
+      parameters: [pathParameter('name', 'Adapter name, e.g. binance')],
+      responses: {
+        200: jsonResponse('Adapter status', ref('AdapterStatus')),
+        404: errorResponse('Adapter not found')
+      }
+    }
+  },
+  '/api/adapters/{name}/start': adapterAction('Start'),
+  '/api/adapters/{name}/stop': adapterAction('Stop'),
+  '/api/adapters/{name}/restart': adapterAction('Restart'),
+  '/api/subscriptions': {
+    get: {
+      tags: ['subscriptions'],
+      summary: 'List active subscriptions',
+      responses: {
+        200: jsonResponse('Subscription list', {
+          type: 'object',
+          properties: {
+            subscriptions: { type: 'array', items: ref('Subscription') }
+          }
+        }),
+        500: errorResponse('Failed to retrieve subscriptions')
+      }
+    },
+    post: {
+      tags: ['subscriptions'],
+      summary: 'Add a subscription',
+      requestBody: { required: true, content: jsonContent(ref('SubscriptionRequest')) },
+      responses: {
+        201: jsonResponse('Subscription created', { type: 'object' }),
+        400: errorResponse('Invalid request body'),
+        404: errorResponse('Exchange not found')
+      }
+    }
+  },
+  '/api/subscriptions/{exchange}/{symbol}': {
+    delete: {
+      tags: ['subscriptions'],
+      summary: 'Remove a subscription',
+      parameters: [
+        pathParameter('exchange', 'Exchange name'),
+        pathParameter('symbol', 'Trading pair')
+      ],
+      responses: {
+        200: jsonResponse('Subscription removed', { type: 'object' }),
+        404: errorResponse('Exchange or subscription not found')
+      }
+    }
+  },
+  '/api/subscriptions/batch': {
+    post: {
+      tags: ['subscriptions'],
+      summary: 'Start, stop or delete subscriptions in bulk',
+      requestBody: { required: true, content: jsonContent(ref('BatchOperation')) },
+      responses: {
+        200: jsonResponse('Batch operation result', ref('BatchOperationResult')),
+        400: errorResponse('Invalid request body')
+      }
+    }
+  },
+  '/api/subscriptions/stats': {
+    get: {
+      tags: ['subscriptions'],
+      summary: 'Subscription statistics',
+      responses: {
+        200: jsonResponse('Subscription statistics', { type: 'object' })
+      }
+    }
+  },
+  '/api/stats': {
+    get: {
+      tags: ['stats'],
+      summary: 'Real-time collector statistics',
+      responses: {
+        200: jsonResponse('Current statistics', ref('RealTimeStats')),
+        500: errorResponse('Failed to retrieve real-time stats')
+      }
+    }
+  },
+  '/api/stats/history': {
+    get: {
+      tags: ['stats'],
+      summary: 'Historical collector statistics',
+      parameters: [
+        { name: 'start', in: 'query', description: 'Range start, defaults to one hour ago', schema: { type: 'string', format: 'date-time' } },
+        { name: 'end', in: 'query', description: 'Range end, defaults to now', schema: { type: 'string', format: 'date-time' } },
+        { name: 'interval', in: 'query', description: 'Bucket interval', schema: { type: 'string', default: '1m' } }
+      ],
+      responses: {
+        200: jsonResponse('Historical statistics', ref('HistoricalStats')),
+        500: errorResponse('Failed to retrieve historical stats')
+      }
+    }
+  },
+  '/api/stats/stream': {
+    get: {
+      tags: ['stats'],
+      summary: 'Statistics stream',
+      description: 'Server-sent events. Emits `connected` once, then `stats` every 5 seconds with a RealTimeStats payload.',
+      responses: {
+        200: { description: 'Event stream', content: { 'text/event-stream': { schema: { type: 'string' } } } }
+      }
+    }
+  },
+  '/api/pubsub/status': {
+    get: {
+      tags: ['pubsub'],
+      summary: 'Pub/Sub publishing status',
+      responses: {
+        200: jsonResponse('Publishing status', { type: 'object' })
+      }
+    }
+  },
+  '/api/pubsub/toggle': {
+    post: {
+      tags: ['pubsub'],
+      summary: 'Enable or disable Pub/Sub publishing',
+      requestBody: { required: true, content: jsonContent(ref('PubSubToggleRequest')) },
+      responses: {
+        200: jsonResponse('Publishing status after the change', { type: 'object' }),
+        400: errorResponse('Invalid request body')
+      }
+    }
+  },
+  '/api/pubsub/topics': {
+    get: {
+      tags: ['pubsub'],
+      summary: 'List Pub/Sub topics',
+      responses: {
+        200: jsonResponse('Topic list', { type: 'object' })
+      }
+    }
+  },
+  '/api/faults': {
+    get: {
+      tags: ['faults'],
+      summary: 'List active faults',
+      description: 'Only available when the collector runs with `--enable-fault-injection`.',
+      responses: {
+        200: jsonResponse('Active faults', { type: 'object', properties: { faults: { type: 'array', items: ref('ActiveFault') } } }),
+        404: errorResponse('Fault injection is disabled')
+      }
+    },
+    post: {
+      tags: ['faults'],
+      summary: 'Inject a fault',
+      requestBody: { required: true, content: jsonContent(ref('FaultSpec')) },
+      responses: {
+        201: jsonResponse('Injected fault', { type: 'object', properties: { fault: ref('ActiveFault') } }),
+        400: errorResponse('Invalid fault'),
+        404: errorResponse('Fault injection is disabled')
+      }
+    },
+    delete: {
+      tags: ['faults'],
+      summary: 'Clear all faults',
+      responses: {
+        200: jsonResponse('Faults cleared', { type: 'object' }),
+        404: errorResponse('Fault injection is disabled')
+      }
+    }
+  },
+  '/api/faults/{id}': {
+    delete: {
+      tags: ['faults'],
+      summary: 'Clear a fault',
+      parameters: [pathParameter('id', 'Fault id returned when it was injected')],
+      responses: {
+        200: jsonResponse('Fault cleared', { type: 'object' }),
+        404: errorResponse('Fault not found or fault injection disabled')
+      }
+    }
+  }
+};
+
+/**
+ * 生成 OpenAPI 3.0 文档
+ */
+export function createOpenApiDocument(version: string = process.env.npm_package_version || '1.0.0'): OpenApiObject {
+  return {
+    openapi: '3.0.3',
+    info: {
+      title: 'Pixiu Exchange Collector API',
+      version,
+      description: 'Adapter, subscription, statistics and health endpoints of the exchange collector.'
+    },
+    paths,
+    components: { schemas }
+  };
+}
+
+/**
+ * 创建 OpenAPI 文档路由
+ */
+export function createOpenApiRouter(): Router {
+  const router = Router();
+  const document = createOpenApiDocument();
+
+  /**
+   * 获取 OpenAPI 文档
+   * GET /api/openapi.json
+   */
+  router.get('/', (_req: Request, res: Response) => {
+    res.json(document);
+  });
+
+  return router;
+}
@@ -0,0 +1,159 @@
+/**
+ * 故障注入
+ * 供演练使用，在运行中的采集器上模拟交易所中断、行情延迟与行情停滞，故障到期后自动解除
+ */
+
+import { EventEmitter } from 'events';
+import { MarketData } from '@pixiu/adapter-base';
+
+/**
+ * - outage：停止交易所适配器，解除后重新启动
+ * - stale：丢弃匹配的行情，下游缓存停留在注入前的最后一笔
+ * - latency：匹配的行情延迟投递
+ */
+export type FaultKind = 'outage' | 'stale' | 'latency';
+
+export interface FaultSpec {
+  kind: FaultKind;
+  exchange: string;
+  /** 仅影响这些交易对，不设置则影响整个交易所，outage不支持 */
+  symbols?: string[];
+  /** 持续时间（毫秒） */
+  duration: number;
+  /** latency的延迟时间（毫秒） */
+  delay?: number;
+}
+
+export interface ActiveFault extends FaultSpec {
+  id: string;
+  startedAt: number;
+  expiresAt: number;
+}
+
+export interface FaultInjectorOptions {
+  /** 单个故障的最长持续时间（毫秒），默认10分钟 */
+  maxDuration?: number;
+}
+
+const FAULT_KINDS: FaultKind[] = ['outage', 'stale', 'latency'];
+
+/**
+ * 故障注入器
+ *
+ * 事件：
+ * - injected(fault)
+ * - cleared(fault, reason) reason为expired或cleared
+ */
+export class FaultInjector extends EventEmitter {
+  private readonly maxDuration: number;
+  private readonly faults = new Map<string, { fault: ActiveFault; timer: NodeJS.Timeout }>();
+  private nextId = 0;
+
+  constructor(options: FaultInjectorOptions = {}) {
+    super();
+    this.maxDuration = options.maxDuration ?? 600000;
+  }
+
+  /**
+   * 注入故障
+   */
+  inject(spec: FaultSpec): ActiveFault {
+    this.validate(spec);
+
+    const startedAt = Date.now();
+    const fault: ActiveFault = {
+      kind: spec.kind,
+      exchange: spec.exchange,
+      symbols: spec.symbols?.map(symbol => symbol.toUpperCase()),
+      duration: spec.duration,
+      delay: spec.delay,
+      id: `fault-${++this.nextId}`,
+      startedAt,
+      expiresAt: startedAt + spec.duration
+    };
+
+    const timer = setTimeout(() => this.remove(fault.id, 'expired'), spec.duration);
+    timer.unref?.();
+    this.faults.set(fault.id, { fault, timer });
+    this.emit('injected', fault);
+    return fault;
+  }
+
+  /**
+   * 提前解除故障
+   */
+  clear(id: string): boolean {
+    return this.remove(id, 'cleared');
+  }
+
+  clearAll(): void {
+    for (const id of Array.from(this.faults.keys())) {
+      this.remove(id, 'cleared');
+    }
+  }
+
+  list(): ActiveFault[] {
+    return Array.from(this.faults.values(), entry => ({ ...entry.fault }));
+  }
+
+  /**
+   * 按当前故障投递行情
+   * 命中stale时丢弃，命中latency时按最大延迟推迟投递
+   */
+  apply(adapter: string, data: MarketData, deliver: () => void): void {
+    let delay = 0;
+
+    for (const { fault } of this.faults.values()) {
+      if (fault.kind === 'outage' || !this.matches(fault, adapter, data)) {
+        continue;
+      }
+      if (fault.kind === 'stale') {
+        return;
+      }
+      delay = Math.max(delay, fault.delay ?? 0);
+    }
+
+    if (delay > 0) {
+      setTimeout(deliver, delay);
+    } else {
+      deliver();
+    }
+  }
+
+  private matches(fault: ActiveFault, adapter: string, data: MarketData): boolean {
+    if (fault.exchange !== adapter && fault.exchange !== data.exchange) {
+      return false;
+    }
+    return !fault.symbols || fault.symbols.includes(data.symbol.toUpperCase());
+  }
+
+  private validate(spec: FaultSpec): void {
+    if (!FAULT_KINDS.includes(spec.kind)) {
+      throw new Error(`Unknown fault kind: ${spec.kind}`);
+    }
+    if (!spec.exchange) {
+      throw new Error('Fault exchange is required');
+    }
+    if (!Number.isFinite(spec.duration) || spec.duration <= 0 || spec.duration > this.maxDuration) {
+      throw new Error(`Fault duration must be between 1 and ${this.maxDuration} ms`);
+    }
+    if (spec.kind === 'latency' && !(Number.isFinite(spec.delay) && spec.delay! > 0)) {
+      throw new Error('Latency faults require a positive delay');
+    }
+    if (spec.kind === 'outage' && spec.symbols?.length) {
+      throw new Error('Outage faults apply to the whole exchange');
+    }
+  }
+
+  private remove(id: string, reason: 'expired' | 'cleared'): boolean {
+    const entry = this.faults.get(id);
+    if (!entry) {
+      return false;
+    }
+
+    clearTimeout(entry.timer);
+    this.faults.delete(id);
+    this.emit('cleared', entry.fault, reason);
+    return true;
+  }
+}
@@ -0,0 +1,5 @@
+/**
+ * 故障注入演练
+ */
+
+export * from './fault-injector';
@@ -0,0 +1,402 @@
+#!/usr/bin/env node
+/**
+ * Pixiu命令行工具
+ *
+ * 用法：
+ *   pixiu serve --pid-file /run/pixiu/collector.pid
+ *   pixiu replay --input recordings/ --speed 10
+ *   pixiu recording convert --input recordings/ --output recordings-pxb/ --format binary
+ *   pixiu doctor
+ *   pixiu config render --env production
+ *   pixiu exchanges list
+ *   pixiu data fetch --exchange binance --type kline --symbol BTCUSDT --interval 1m \
+ *     --start 2024-01-01 --end 2024-02-01 --output data/BTCUSDT-1m.csv
+ */
+
+import { parseArgs } from 'util';
+import { HistoricalDataSource } from '@pixiu/adapter-base';
+import { BinanceHistoricalDataSource } from '@pixiu/binance-adapter';
+import { HistoricalDownloader } from '../history/historical-downloader';
+import { HistoricalDataKind, HistoricalOutputFormat, inferDatasetFormat } from '../history/dataset';
+import { PidFile, SystemdNotifier } from '../daemon';
+import { RECORDING_FILE_PATTERN, RecordingCompression, RecordingFormat, convertRecording, inferRecordingFormat } from '../recording';
+import { DoctorCheck, formatDoctorReport, runDoctor } from '../doctor';
+
+const HISTORICAL_SOURCES: Record<string, (restUrl?: string) => HistoricalDataSource> = {
+  binance: (restUrl) => new BinanceHistoricalDataSource({ restUrl })
+};
+
+const USAGE = `Usage:
+  pixiu serve [--pid-file <path>] [--enable-fault-injection]
+  pixiu replay --input <path> [options]
+  pixiu recording convert --input <path> --output <path> [options]
+  pixiu doctor [--timeout <ms>] [--json]
+  pixiu config render [--env <name>] [--json]
+  pixiu exchanges list [--json]
+  pixiu data fetch [options]
+
+Serve options:
+  --pid-file <path>     Write the process id to <path> and refuse to start if another instance holds it
+  --enable-fault-injection
+                        Expose /api/faults to simulate outages and stale or delayed market data in drills
+
+Replay options:
+  --input <path>        Recording file or directory of recordings
+  --speed <factor>      Playback speed relative to the recording, 0 for as fast as possible (default: 1)
+  --start <time>        Skip events before this time, ISO date or epoch milliseconds
+  --end <time>          Skip events after this time, ISO date or epoch milliseconds
+
+Recording convert options:
+  --input <path>        Recording file or directory of recordings
+  --output <path>       Output file, or output directory when the input is a directory
+  --format <jsonl|binary>
+                        Output format (default: inferred from the output extension, otherwise binary)
+  --compression <auto|zstd|gzip|none>
+                        Output compression (default: auto)
+
+Doctor options:
+  --timeout <ms>        Timeout for each connectivity probe (default: 10000)
+  --json                Print results as JSON
+
+Config render options:
+  --env <name>          Environment overlay to apply (default: NODE_ENV or development)
+  --json                Print the config and the source of each value as JSON
+
+Exchanges list options:
+  --json                Print adapters and their capabilities as JSON
+
+Data fetch options:
+  --exchange <name>     Exchange to download from (${Object.keys(HISTORICAL_SOURCES).join(', ')})
+  --type <kline|trade>  Data type (default: kline)
+  --symbol <symbol>     Trading pair, e.g. BTCUSDT
+  --interval <interval> Kline interval, e.g. 1m (required for klines)
+  --start <time>        Start time, ISO date or epoch milliseconds
+  --end <time>          End time, ISO date or epoch milliseconds (default: now)
+  --output <path>       Output file
+  --format <csv|jsonl>  Output format (default: inferred from output extension)
+  --gzip                Compress output with gzip (default: on for .gz outputs, zstd for .zst outputs)
+  --rest-url <url>      Override the exchange REST endpoint
+`;
+
+/**
+ * 解析时间参数
+ */
+function parseTime(value: string, name: string): number {
+  const timestamp = /^\d+$/.test(value) ? parseInt(value, 10) : Date.parse(value);
+  if (Number.isNaN(timestamp)) {
+    throw new Error(`Invalid --${name} time: ${value}`);
+  }
+  return timestamp;
+}
+
+/**
+ * 解析输出格式
+ */
+function resolveFormat(format: string | undefined, output: string): HistoricalOutputFormat {
+  const value = format ?? inferDatasetFormat(output).format;
+  if (value !== 'csv' && value !== 'jsonl') {
+    throw new Error(`Unsupported output format: ${value} (supported: csv, jsonl)`);
+  }
+  return value;
+}
+
+/**
+ * data fetch 子命令
+ */
+async function dataFetch(args: string[]): Promise<void> {
+  const { values } = parseArgs({
+    args,
+    options: {
+      exchange: { type: 'string' },
+      type: { type: 'string', default: 'kline' },
+      symbol: { type: 'string' },
+      interval: { type: 'string' },
+      start: { type: 'string' },
+      end: { type: 'string' },
+      output: { type: 'string' },
+      format: { type: 'string' },
+      gzip: { type: 'boolean' },
+      'rest-url': { type: 'string' }
+    }
+  });
+
+  if (!values.exchange || !values.symbol || !values.start || !values.output) {
+    throw new Error('Missing required option: --exchange, --symbol, --start and --output are required');
+  }
+
+  const createSource = HISTORICAL_SOURCES[values.exchange];
+  if (!createSource) {
+    throw new Error(`Historical data is not supported for exchange: ${values.exchange}`);
+  }
+
+  const kind = values.type as HistoricalDataKind;
+  if (kind !== 'kline' && kind !== 'trade') {
+    throw new Error(`Unsupported data type: ${values.type} (supported: kline, trade)`);
+  }
+
+  const downloader = new HistoricalDownloader(createSource(values['rest-url']));
+  downloader.on('page', ({ totalRows, cursor }) => {
+    console.log(`Fetched ${totalRows} rows, up to ${new Date(cursor).toISOString()}`);
+  });
+
+  const result = await downloader.download({
+    kind,
+    symbol: values.symbol,
+    interval: values.interval,
+    startTime: parseTime(values.start, 'start'),
+    endTime: values.end ? parseTime(values.end, 'end') : Date.now(),
+    output: values.output,
+    format: resolveFormat(values.format, values.output),
+    compression: values.gzip ? 'gzip' : inferDatasetFormat(values.output).compression
+  });
+
+  console.log(`${result.resumed ? 'Resumed and completed' : 'Completed'}: ${result.totalRows} rows written to ${values.output}`);
+}
+
+/**
+ * serve 子命令：以守护进程方式运行采集服务
+ * 运行在 systemd Type=notify 单元中时上报就绪状态和看门狗心跳
+ */
+async function serve(args: string[]): Promise<void> {
+  const { values } = parseArgs({
+    args,
+    options: {
+      'pid-file': { type: 'string' },
+      'enable-fault-injection': { type: 'boolean', default: false }
+    }
+  });
+
+  const pidFile = values['pid-file'] ? new PidFile(values['pid-file']) : undefined;
+  pidFile?.acquire();
+
+  const notifier = new SystemdNotifier();
+  const onShutdownSignal = () => {
+    notifier.stopping();
+  };
+  process.once('SIGTERM', onShutdownSignal);
+  process.once('SIGINT', onShutdownSignal);
+
+  // 按需加载，避免 data fetch 等命令初始化服务依赖
+  const { ExchangeCollectorService } = await import('../index');
+  const service = new ExchangeCollectorService();
+  await service.initialize();
+  await service.start({ faultInjection: values['enable-fault-injection'] });
+
+  await notifier.ready('Exchange Collector running');
+}
+
+/**
+ * replay 子命令：以回放模式启动采集服务，将录制文件写入事件总线
+ * WebSocket 客户端与缓存看到的数据与实时采集一致
+ */
+async function replay(args: string[]): Promise<void> {
+  const { values } = parseArgs({
+    args,
+    options: {
+      input: { type: 'string' },
+      speed: { type: 'string', default: '1' },
+      start: { type: 'string' },
+      end: { type: 'string' }
+    }
+  });
+
+  if (!values.input) {
+    throw new Error('Missing required option: --input');
+  }
+
+  const speed = Number(values.speed);
+  if (!(speed >= 0)) {
+    throw new Error(`Invalid --speed: ${values.speed}`);
+  }
+
+  const { ExchangeCollectorService } = await import('../index');
+  const service = new ExchangeCollectorService();
+  await service.initialize();
+  await service.start({ replay: true });
+
+  const result = await service.replay(values.input, {
+    speed,
+    from: values.start ? parseTime(values.start, 'start') : undefined,
+    to: values.end ? parseTime(values.end, 'end') : undefined
+  });
+
+  console.log(`${result.stopped ? 'Stopped' : 'Completed'}: replayed ${result.events} events from ${result.files} files`);
+  await service.stop();
+}
+
+/**
+ * recording convert 子命令：在JSON Lines与二进制录制格式之间转换
+ */
+async function recordingConvert(args: string[]): Promise<void> {
+  const { values } = parseArgs({
+    args,
+    options: {
+      input: { type: 'string' },
+      output: { type: 'string' },
+      format: { type: 'string' },
+      compression: { type: 'string', default: 'auto' }
+    }
+  });
+
+  if (!values.input || !values.output) {
+    throw new Error('Missing required option: --input and --output are required');
+  }
+
+  const format = (values.format ?? (RECORDING_FILE_PATTERN.test(values.output) ? inferRecordingFormat(values.output) : 'binary')) as RecordingFormat;
+  if (format !== 'jsonl' && format !== 'binary') {
+    throw new Error(`Unsupported recording format: ${values.format} (supported: jsonl, binary)`);
+  }
+  const compression = values.compression as RecordingCompression | 'auto';
+  if (!['auto', 'zstd', 'gzip', 'none'].includes(compression)) {
+    throw new Error(`Unsupported compression: ${values.compression} (supported: auto, zstd, gzip, none)`);
+  }
+
+  const result = await convertRecording(values.input, values.output, { format, compression });
+  for (const file of result.files) {
+    console.log(`${file.input} -> ${file.output}: ${file.events} events, ${file.inputBytes} -> ${file.outputBytes} bytes`);
+  }
+  console.log(`Converted ${result.events} events in ${result.files.length} files`);
+}
+
+/**
+ * doctor 子命令：校验配置并探测已启用的交易所
+ * 存在失败项时以非零状态退出，可用于部署前检查
+ */
+async function doctor(args: string[]): Promise<void> {
+  const { values } = parseArgs({
+    args,
+    options: {
+      timeout: { type: 'string', default: '10000' },
+      json: { type: 'boolean' }
+    }
+  });
+
+  const { getExchangeCollectorConfigManager } = await import('../config/unified-config');
+  const configManager = getExchangeCollectorConfigManager();
+  let checks: DoctorCheck[];
+
+  try {
+    const config = await configManager.initialize();
+    checks = [
+      { name: 'config', status: 'ok', message: `Loaded ${config.service?.environment ?? process.env.NODE_ENV ?? 'development'} configuration` },
+      ...await runDoctor(config, configManager.getEnabledAdapters(), { timeout: Number(values.timeout) })
+    ];
+  } catch (error) {
+    checks = [{ name: 'config', status: 'fail', message: (error as Error).message }];
+  }
+
+  console.log(values.json ? JSON.stringify(checks, null, 2) : formatDoctorReport(checks));
+  if (checks.some(check => check.status === 'fail')) {
+    process.exitCode = 1;
+  }
+}
+
+/**
+ * config render 子命令：输出合并后的有效配置及每个值的来源
+ * 明文密钥会被隐藏，密钥引用不做解析
+ */
+async function configRender(args: string[]): Promise<void> {
+  const { values } = parseArgs({
+    args,
+    options: {
+      env: { type: 'string' },
+      json: { type: 'boolean' }
+    }
+  });
+
+  const { getExchangeCollectorConfigManager, redactConfig, renderConfig } = await import('../config');
+  const configManager = getExchangeCollectorConfigManager();
+
+  try {
+    const { environment, files, config, provenance } = configManager.inspect(values.env);
+    const redacted = redactConfig(config);
+
+    if (values.json) {
+      console.log(JSON.stringify({ environment, files, config: redacted, provenance }, null, 2));
+    } else {
+      process.stdout.write(renderConfig(redacted, provenance, [
+        `Environment: ${environment}`,
+        `Layers: default, ${[...files, 'environment variables'].join(', ')}`
+      ]));
+    }
+  } finally {
+    // 停止监听配置文件，否则进程不会退出
+    configManager.destroy();
+  }
+}
+
+/**
+ * exchanges list 子命令：列出内置适配器及其能力
+ * 逐个加载适配器查询能力，不读取配置也不建立连接
+ */
+async function exchangesList(args: string[]): Promise<void> {
+  const { values } = parseArgs({
+    args,
+    options: {
+      json: { type: 'boolean' }
+    }
+  });
+
+  await import('../adapters/registry/builtin-adapters');
+  const { formatAdapterList, listAdapters } = await import('../adapters/registry/adapter-catalog');
+  const adapters = await listAdapters();
+
+  console.log(values.json ? JSON.stringify(adapters, null, 2) : formatAdapterList(adapters));
+  if (adapters.some(adapter => adapter.error)) {
+    process.exitCode = 1;
+  }
+}
+
+/**
+ * 命令行入口
+ */
+export async function main(argv: string[] = process.argv.slice(2)): Promise<void> {
+  const [command, subcommand, ...rest] = argv;
+
+  if (command === 'serve') {
+    await serve(argv.slice(1));
+    return;
+  }
+
+  if (command === 'doctor') {
+    await doctor(argv.slice(1));
+    return;
+  }
+
+  if (command === 'replay') {
+    await replay(argv.slice(1));
+    return;
+  }
+
+  if (command === 'recording' && subcommand === 'convert') {
+    await recordingConvert(rest);
+    return;
+  }
+
+  if (command === 'config' && subcommand === 'render') {
+    await configRender(rest);
+    return;
+  }
+
+  if (command === 'exchanges' && subcommand === 'list') {
+    await exchangesList(rest);
+    return;
+  }
+
+  if (command === 'data' && subcommand === 'fetch') {
+    await dataFetch(rest);
+    return;
+  }
+
+  console.log(USAGE);
+  if (command !== undefined && command !== '--help') {
+    process.exitCode = 1;
+  }
+}
+
+if (require.main === module) {
+  main().catch(error => {
+    console.error((error as Error).message);
+    process.exit(1);
+  });
+}
@@ -215,6 +215,26 @@ export class AdapterConfigFactory {
     };
   }
 
+  /**
+   * 为Coinbase创建默认配置
+   */
+  static createCoinbaseConfig(): AdapterConfiguration {
+    const config = this.createBaseConfig();
+    config.endpoints = {
+      ws: 'wss://advanced-trade-ws.coinbase.com',
+      rest: 'https://api.coinbase.com'
+    };
+
+    const subscription = this.createBaseSubscription();
+    subscription.symbols = ['BTC-USD'];
+    subscription.dataTypes = [DataType.TRADE, DataType.TICKER];
+
+    return {
+      config,
+      subscription
+    };
+  }
+
   /**
    * 根据适配器类型创建默认配置
    */
@@ -224,6 +244,8 @@ export class AdapterConfigFactory {
         return this.createBinanceConfig();
       case AdapterType.OKEX:
         return this.createOkxConfig();
+      case AdapterType.COINBASE:
+        return this.createCoinbaseConfig();
       default:
         return {
           config: this.createBaseConfig(),
@@ -0,0 +1,70 @@
+/**
+ * 有效配置渲染
+ * 将合并后的配置输出为YAML，并在每个值后注明其来源
+ */
+
+import type { ConfigProvenance } from '@pixiu/shared-core';
+
+/** 视为敏感信息的键名 */
+const SENSITIVE_KEY = /(secret|password|passphrase|token|api_?key|private_?key)/i;
+
+/** 密钥引用（如 vault://kv/binance-main）本身不含敏感信息，可以原样输出 */
+const SECRET_REFERENCE = /^[a-z][a-z0-9+-]*:\/\/[^#]+(#.+)?$/;
+
+export const REDACTED = '<redacted>';
+
+/**
+ * 隐藏明文密钥，密钥引用保持原样
+ */
+export function redactConfig<T>(config: T, key = ''): T {
+  if (Array.isArray(config)) {
+    return config.map(item => redactConfig(item, key)) as unknown as T;
+  }
+
+  if (config && typeof config === 'object') {
+    return Object.fromEntries(
+      Object.entries(config).map(([childKey, value]) => [childKey, redactConfig(value, childKey)])
+    ) as T;
+  }
+
+  if (SENSITIVE_KEY.test(key) && typeof config === 'string' && config !== '' && !SECRET_REFERENCE.test(config)) {
+    return REDACTED as unknown as T;
+  }
+
+  return config;
+}
+
+/**
+ * 查找配置值的来源，路径未被记录时沿父级查找
+ */
+export function lookupProvenance(provenance: ConfigProvenance, path: string): string {
+  for (let current = path; current; current = current.slice(0, Math.max(current.lastIndexOf('.'), 0))) {
+    if (provenance[current]) {
+      return provenance[current];
+    }
+  }
+  return 'default';
+}
+
+/**
+ * 渲染为带来源注释的YAML
+ * 标量与数组使用JSON写法，仍是合法的YAML
+ */
+export function renderConfig(config: object, provenance: ConfigProvenance, header: string[] = []): string {
+  const lines = header.map(line => `# ${line}`);
+
+  const render = (value: object, path: string, indent: string) => {
+    for (const [key, child] of Object.entries(value)) {
+      const childPath = path ? `${path}.${key}` : key;
+      if (child && typeof child === 'object' && !Array.isArray(child) && Object.keys(child).length > 0) {
+        lines.push(`${indent}${key}:`);
+        render(child, childPath, `${indent}  `);
+      } else if (child !== undefined) {
+        lines.push(`${indent}${key}: ${JSON.stringify(child)}  # ${lookupProvenance(provenance, childPath)}`);
+      }
+    }
+  };
+
+  render(config, '', '');
+  return `${lines.join('\n')}\n`;
+}
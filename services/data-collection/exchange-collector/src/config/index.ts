@@ -4,4 +4,4 @@
 
 export * from './unified-config';
 export * from './adapter-config';
-export * from './config-merger';
\ No newline at end of file
+export * from './config-merger';export * from './config-render';
@@ -14,10 +14,21 @@ import {
   type MonitoringConfig,
   type PubSubConfig,
   type LoggingConfig,
+  type ConfigReloadResult,
+  type ConfigProvenance,
   DEFAULT_CONFIG_VALUES,
-  createEnvMiddleware
+  createEnvMiddleware,
+  createDefaultSecretResolver,
+  SecretResolver
 } from '@pixiu/shared-core';
 import { resolve } from 'path';
+import { hostname } from 'os';
+import type { MarketDataRecorderOptions } from '../recording';
+import type { ClickHouseStoreConfig } from '../store/clickhouse';
+import type { ExchangeStatusMonitorOptions } from '../monitoring/exchange-status-monitor';
+import type { ApiKeyScopeMonitorOptions } from '../monitoring/api-key-scope-monitor';
+import type { MicrostructureStreamOptions } from '../microstructure';
+import type { InstrumentRefreshOptions } from '../instruments';
 
 /**
  * Exchange Collector特定的配置接口
@@ -45,6 +56,40 @@ export interface ExchangeCollectorConfig extends UnifiedConfig {
       minDataFreshness: number;
     };
   };
+
+  // 行情录制配置
+  recording?: RecordingConfig;
+
+  // 行情存储配置
+  storage?: {
+    clickhouse?: ClickHouseStoreConfig & { enabled: boolean };
+  };
+
+  // 交易所维护状态监控配置
+  exchangeStatus?: ExchangeStatusConfig;
+
+  // 微观结构衍生数据流配置
+  microstructure?: MicrostructureStreamOptions & { enabled: boolean };
+
+  // 品种定时刷新与上下架通知配置
+  instruments?: InstrumentRefreshOptions & { enabled: boolean };
+
+  // API密钥权限校验配置
+  apiKeyScopes?: ApiKeyScopeConfig;
+}
+
+export interface RecordingConfig extends MarketDataRecorderOptions {
+  enabled: boolean;
+}
+
+export interface ExchangeStatusConfig extends ExchangeStatusMonitorOptions {
+  enabled: boolean;
+}
+
+export interface ApiKeyScopeConfig extends Omit<ApiKeyScopeMonitorOptions, 'fetchers'> {
+  enabled: boolean;
+  /** 权限不符时仅告警并继续启动 */
+  allowMismatch?: boolean;
 }
 
 export interface BinanceAdapterConfig extends AdapterConfig {
@@ -57,6 +102,10 @@ export interface BinanceAdapterConfig extends AdapterConfig {
       requests: number;
       interval: number;
     };
+    /** REST每分钟请求权重上限 */
+    restWeightLimit?: number;
+    /** 签名请求有效期（毫秒） */
+    recvWindow?: number;
   };
 }
 
@@ -110,15 +159,29 @@ export interface ExchangeDataFlowConfig extends DataFlowConfig {
   };
 }
 
+/**
+ * 配置分层检查结果
+ */
+export interface ConfigInspection {
+  environment: string;
+  /** 实际加载的配置文件，按合并顺序排列 */
+  files: string[];
+  /** 合并后的配置，密钥引用保持原样 */
+  config: UnifiedConfig;
+  provenance: ConfigProvenance;
+}
+
 /**
  * Exchange Collector配置管理器
  */
 export class ExchangeCollectorConfigManager {
   private configManager: UnifiedConfigManager;
   private currentConfig: ExchangeCollectorConfig | null = null;
+  private secretResolver: SecretResolver;
 
-  constructor() {
+  constructor(secretResolver: SecretResolver = createDefaultSecretResolver()) {
     this.configManager = getGlobalConfigManager();
+    this.secretResolver = secretResolver;
   }
 
   /**
@@ -140,6 +203,12 @@ export class ExchangeCollectorConfigManager {
       
       // 扩展为Exchange Collector特定配置
       this.currentConfig = this.extendToExchangeCollectorConfig(baseConfig);
+
+      // 适配器配置中的密钥引用（如 vault://kv/binance-main）在启动时解析
+      this.currentConfig.adapters = await this.secretResolver.resolve(this.currentConfig.adapters);
+      if (this.currentConfig.storage) {
+        this.currentConfig.storage = await this.secretResolver.resolve(this.currentConfig.storage);
+      }
       
       // 设置配置变更监听
       this.setupConfigChangeHandlers();
@@ -150,6 +219,23 @@ export class ExchangeCollectorConfigManager {
     }
   }
 
+  /**
+   * 加载配置但不解析密钥引用，用于查看各层合并后的结果及每个值的来源
+   */
+  inspect(environment?: string): ConfigInspection {
+    const env = environment || process.env.NODE_ENV || 'development';
+
+    this.configManager.loadJsonSchema(resolve(__dirname, 'config-schema.json'));
+    const config = this.configManager.loadConfiguration(env, this.getConfigPaths(env));
+
+    return {
+      environment: env,
+      files: this.configManager.getLoadedConfigFiles(),
+      config,
+      provenance: this.configManager.getConfigProvenance()
+    };
+  }
+
   /**
    * 获取当前配置
    */
@@ -164,6 +250,20 @@ export class ExchangeCollectorConfigManager {
     this.configManager.updateConfiguration(path, value);
   }
 
+  /**
+   * 重新加载配置文件
+   */
+  reloadConfig(): ConfigReloadResult {
+    return this.configManager.reloadConfiguration();
+  }
+
+  /**
+   * 启用信号触发的配置热更新
+   */
+  enableHotReload(signal: NodeJS.Signals = 'SIGHUP'): void {
+    this.configManager.enableSignalReload(signal);
+  }
+
   /**
    * 订阅配置变更
    */
@@ -244,11 +344,16 @@ export class ExchangeCollectorConfigManager {
 
   // ====== 私有方法 ======
 
+  /**
+   * 配置文件按顺序合并：基础配置、环境配置、主机配置、本地覆盖
+   * 主机名可通过 PIXIU_HOST 指定，便于容器内使用固定的主机配置
+   */
   private getConfigPaths(environment: string): string[] {
     const basePath = resolve(__dirname, '../..');
     return [
       resolve(basePath, 'config', 'default.yaml'),
       resolve(basePath, 'config', `${environment}.yaml`),
+      resolve(basePath, 'config', 'hosts', `${process.env.PIXIU_HOST || hostname()}.yaml`),
       resolve(basePath, 'config', 'local.yaml')
     ];
   }
@@ -266,6 +371,7 @@ export class ExchangeCollectorConfigManager {
     };
 
     const defaultDataFlowConfig = this.getDefaultDataFlowConfig();
+    const storage = (baseConfig as Partial<ExchangeCollectorConfig>).storage;
 
     return {
       ...baseConfig,
@@ -274,7 +380,9 @@ export class ExchangeCollectorConfigManager {
         ...baseConfig.dataflow
       } as ExchangeDataFlowConfig,
       business: defaultBusinessConfig,
-      adapters: baseConfig.adapters || {}
+      // 凭证变更不允许热更新，重新加载时沿用启动时解析的密钥
+      adapters: this.secretResolver.resolveCached(baseConfig.adapters || {}),
+      ...(storage ? { storage: this.secretResolver.resolveCached(storage) } : {})
     } as ExchangeCollectorConfig;
   }
 
@@ -319,22 +427,13 @@ export class ExchangeCollectorConfigManager {
     });
 
     this.configManager.on('configChanged', (config, changes) => {
+      this.currentConfig = this.extendToExchangeCollectorConfig(config);
       console.log('Configuration changed:', changes.map(c => c.path));
-      
-      // 检查是否有关键配置变更需要重启服务
-      const criticalPaths = [
-        'service.server.port',
-        'pubsub.projectId',
-        'adapters'
-      ];
-      
-      const hasCriticalChanges = changes.some(change => 
-        criticalPaths.some(path => change.path.startsWith(path))
-      );
-      
-      if (hasCriticalChanges) {
-        console.warn('Critical configuration changes detected. Service restart may be required.');
-      }
+    });
+
+    // 涉及端口、连接端点或凭证的变更会被拒绝，需重启服务才能生效
+    this.configManager.on('configReloadRejected', (result: ConfigReloadResult) => {
+      console.error('Configuration reload rejected:', result.errors);
     });
   }
 }
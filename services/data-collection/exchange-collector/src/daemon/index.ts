@@ -0,0 +1,6 @@
+/**
+ * 守护进程支持
+ */
+
+export * from './pid-file';
+export * from './systemd-notify';
@@ -0,0 +1,86 @@
+/**
+ * PID文件
+ * 防止同一配置启动多个实例，进程退出时自动删除
+ */
+
+import { closeSync, openSync, readFileSync, unlinkSync, writeSync } from 'fs';
+
+/**
+ * 检查进程是否存活
+ */
+export function isProcessRunning(pid: number): boolean {
+  try {
+    process.kill(pid, 0);
+    return true;
+  } catch (error) {
+    // EPERM表示进程存在但属于其他用户
+    return (error as NodeJS.ErrnoException).code === 'EPERM';
+  }
+}
+
+export class PidFile {
+  private acquired = false;
+  private readonly onExit = () => this.release();
+
+  constructor(readonly path: string) {}
+
+  /**
+   * 读取PID文件中的进程号
+   */
+  read(): number | undefined {
+    try {
+      const pid = parseInt(readFileSync(this.path, 'utf-8').trim(), 10);
+      return Number.isNaN(pid) ? undefined : pid;
+    } catch {
+      return undefined;
+    }
+  }
+
+  /**
+   * 写入当前进程号
+   * 已有存活进程时抛出错误；残留、为空或无法解析的PID文件会被覆盖
+   */
+  acquire(): void {
+    const existing = this.read();
+    if (existing !== undefined && existing !== process.pid && isProcessRunning(existing)) {
+      throw new Error(`Another instance is already running (pid ${existing}, pid file ${this.path})`);
+    }
+    try {
+      unlinkSync(this.path);
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code !== 'ENOENT') {
+        throw error;
+      }
+    }
+
+    // wx：并发启动时只有一个进程能创建成功
+    const fd = openSync(this.path, 'wx');
+    try {
+      writeSync(fd, `${process.pid}\n`);
+    } finally {
+      closeSync(fd);
+    }
+
+    this.acquired = true;
+    process.once('exit', this.onExit);
+  }
+
+  /**
+   * 删除PID文件，仅当文件仍属于当前进程时删除
+   */
+  release(): void {
+    if (!this.acquired) {
+      return;
+    }
+    this.acquired = false;
+    process.removeListener('exit', this.onExit);
+
+    if (this.read() === process.pid) {
+      try {
+        unlinkSync(this.path);
+      } catch {
+        // 文件已被删除
+      }
+    }
+  }
+}
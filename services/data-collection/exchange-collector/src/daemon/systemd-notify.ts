@@ -0,0 +1,101 @@
+/**
+ * systemd通知
+ * 在 Type=notify 服务中上报就绪、停止状态并定期发送看门狗心跳
+ *
+ * Node.js不支持AF_UNIX数据报套接字，通过systemd-notify命令发送，
+ * 单元文件需设置 NotifyAccess=all
+ */
+
+import { execFile } from 'child_process';
+
+export type NotifyExecutor = (args: string[]) => Promise<void>;
+
+export interface SystemdNotifierOptions {
+  /** 通知套接字，默认读取NOTIFY_SOCKET */
+  socket?: string;
+  /** 看门狗超时（微秒），默认读取WATCHDOG_USEC */
+  watchdogUsec?: number;
+  /** 命令执行函数，测试时替换 */
+  executor?: NotifyExecutor;
+}
+
+const defaultExecutor: NotifyExecutor = (args) => new Promise((resolve, reject) => {
+  execFile('systemd-notify', args, (error) => error ? reject(error) : resolve());
+});
+
+export class SystemdNotifier {
+  private readonly socket?: string;
+  private readonly watchdogUsec?: number;
+  private readonly executor: NotifyExecutor;
+  private watchdogTimer?: NodeJS.Timeout;
+
+  constructor(options: SystemdNotifierOptions = {}) {
+    this.socket = options.socket ?? process.env.NOTIFY_SOCKET;
+    this.executor = options.executor ?? defaultExecutor;
+
+    const watchdogPid = process.env.WATCHDOG_PID;
+    const envWatchdog = process.env.WATCHDOG_USEC && (!watchdogPid || parseInt(watchdogPid, 10) === process.pid)
+      ? parseInt(process.env.WATCHDOG_USEC, 10)
+      : undefined;
+    this.watchdogUsec = options.watchdogUsec ?? envWatchdog;
+  }
+
+  /**
+   * 是否运行在systemd通知模式下
+   */
+  isEnabled(): boolean {
+    return !!this.socket;
+  }
+
+  /**
+   * 发送状态，未启用时忽略
+   */
+  async notify(state: Record<string, string | number>): Promise<void> {
+    if (!this.isEnabled()) {
+      return;
+    }
+
+    const assignments = Object.entries(state).map(([key, value]) => `${key}=${value}`);
+    try {
+      await this.executor([`--pid=${process.pid}`, ...assignments]);
+    } catch (error) {
+      console.warn(`systemd-notify failed: ${(error as Error).message}`);
+    }
+  }
+
+  /**
+   * 上报就绪并启动看门狗
+   */
+  async ready(status?: string): Promise<void> {
+    await this.notify({ READY: 1, MAINPID: process.pid, ...(status ? { STATUS: status } : {}) });
+    this.startWatchdog();
+  }
+
+  /**
+   * 上报正在停止
+   */
+  async stopping(): Promise<void> {
+    this.stopWatchdog();
+    await this.notify({ STOPPING: 1 });
+  }
+
+  /**
+   * 按超时的一半发送看门狗心跳
+   */
+  startWatchdog(): void {
+    if (!this.isEnabled() || !this.watchdogUsec || this.watchdogTimer) {
+      return;
+    }
+
+    const interval = Math.max(Math.floor(this.watchdogUsec / 2000), 1);
+    this.watchdogTimer = setInterval(() => this.notify({ WATCHDOG: 1 }), interval);
+    this.watchdogTimer.unref();
+  }
+
+  stopWatchdog(): void {
+    if (this.watchdogTimer) {
+      clearInterval(this.watchdogTimer);
+      this.watchdogTimer = undefined;
+    }
+  }
+}
@@ -4,7 +4,7 @@
  */
 
 import { MarketData } from '@pixiu/adapter-base';
-import { PubSubClientImpl, BaseMonitor } from '@pixiu/shared-core';
+import { PubSubClientImpl, BaseMonitor, getGlobalTracer } from '@pixiu/shared-core';
 import { OutputChannel, ChannelStatus } from '../interfaces';
 import { WebSocketProxy } from '../../websocket/websocket-proxy';
 import { DataStreamCache } from '../../cache';
@@ -61,6 +61,15 @@ export class PubSubOutputChannel implements OutputChannel {
       return;
     }
 
+    // 上游已采样的消息记录一次发布，消息属性中的 traceparent 指向发布Span
+    const span = metadata?.traceparent
+      ? getGlobalTracer().startSpan('pubsub.publish', {
+          kind: 'producer',
+          parent: metadata.traceparent,
+          attributes: { 'messaging.system': 'gcp_pubsub', 'messaging.channel_id': this.id }
+        })
+      : undefined;
+
     try {
       const topicName = this.dataProcessor.buildTopicName(this.topicPrefix, data, 'by_type');
       const messageAttributes = this.dataProcessor.buildMessageAttributes(
@@ -71,13 +80,15 @@ export class PubSubOutputChannel implements OutputChannel {
           channelType: this.type,
           ...(metadata && Object.fromEntries(
             Object.entries(metadata).map(([k, v]) => [k, String(v)])
-          ))
+          )),
+          ...(span?.isRecording() && { traceparent: span.traceparent() })
         }
       );
 
       const messageId = await this.pubsubClient.publish(topicName, data, {
         attributes: messageAttributes
       });
+      span?.setAttribute('messaging.destination', topicName).setAttribute('messaging.message_id', messageId).setStatus('ok').end();
 
       this.status.messagesSent++;
       this.status.lastActivity = Date.now();
@@ -92,6 +103,7 @@ export class PubSubOutputChannel implements OutputChannel {
         type: data.type
       });
     } catch (error) {
+      span?.recordException(error as Error).end();
       this.status.errors++;
       this.status.health = 'unhealthy';
 
@@ -5,7 +5,7 @@
 
 import { EventEmitter } from 'events';
 import { MarketData } from '@pixiu/adapter-base';
-import { BaseMonitor } from '@pixiu/shared-core';
+import { BaseMonitor, Span } from '@pixiu/shared-core';
 import {
   IDataFlowManager,
   DataFlowConfig,
@@ -23,6 +23,7 @@ interface QueueItem {
   data: MarketData;
   source?: string;
   timestamp: number;
+  span?: Span;
 }
 
 /**
@@ -176,8 +177,9 @@ export class DataFlowManager extends EventEmitter implements IDataFlowManager {
   /**
    * 处理市场数据
    */
-  async processData(data: MarketData, source?: string): Promise<void> {
+  async processData(data: MarketData, source?: string, span?: Span): Promise<void> {
     if (!this.config.enabled || !this.isRunning) {
+      span?.setStatus('error', 'data flow is not running').end();
       return;
     }
 
@@ -197,6 +199,7 @@ export class DataFlowManager extends EventEmitter implements IDataFlowManager {
       // 在背压状态下，丢弃最旧的数据
       if (this.processingQueue.length >= this.config.performance.maxQueueSize) {
         const dropped = this.processingQueue.shift();
+        dropped?.span?.setStatus('error', 'dropped by backpressure').end();
         this.monitor.log('debug', 'Dropped old data due to queue overflow', {
           droppedData: dropped?.data.symbol,
           queueSize: this.processingQueue.length
@@ -208,7 +211,8 @@ export class DataFlowManager extends EventEmitter implements IDataFlowManager {
     this.processingQueue.push({
       data,
       source,
-      timestamp: Date.now()
+      timestamp: Date.now(),
+      span
     });
 
     this.stats.currentQueueSize = this.processingQueue.length;
@@ -318,14 +322,16 @@ export class DataFlowManager extends EventEmitter implements IDataFlowManager {
         }
       }
 
-      // 路由数据到输出通道
-      await this.router.route(transformedData);
+      // 路由数据到输出通道，采样的消息向通道传递 traceparent
+      await this.router.route(transformedData, item.span?.isRecording() ? { traceparent: item.span.traceparent() } : undefined);
+      item.span?.setStatus('ok').end();
 
       // 更新统计信息
       this.updateProcessingStats(startTime, false);
       this.emit('dataProcessed', transformedData, this.getStats());
 
     } catch (error) {
+      item.span?.recordException(error as Error).end();
       this.updateProcessingStats(startTime, true);
       this.monitor.log('error', 'Data processing error', {
         error: error.message,
@@ -3,7 +3,7 @@
  */
 
 import { MarketData } from '@pixiu/adapter-base';
-import { BaseMonitor } from '@pixiu/shared-core';
+import { BaseMonitor, Span } from '@pixiu/shared-core';
 
 /**
  * 输出通道接口
@@ -138,8 +138,8 @@ export interface IDataFlowManager {
   /** 注册数据转换器 */
   registerTransformer(transformer: DataTransformer): void;
   
-  /** 处理市场数据 */
-  processData(data: MarketData, source?: string): Promise<void>;
+  /** 处理市场数据，传入的追踪Span在路由完成或数据被丢弃时结束 */
+  processData(data: MarketData, source?: string, span?: Span): Promise<void>;
   
   /** 获取数据流统计信息 */
   getStats(): DataFlowStats;
@@ -105,8 +105,9 @@ export class MessageRouter extends EventEmitter {
 
   /**
    * 路由数据到匹配的通道
+   * @param metadata 附加到每个通道输出的元数据，如 traceparent
    */
-  async route(data: MarketData): Promise<void> {
+  async route(data: MarketData, metadata?: Record<string, any>): Promise<void> {
     const startTime = Date.now();
     
     try {
@@ -159,6 +160,7 @@ export class MessageRouter extends EventEmitter {
         try {
           const dataToSend = transformedData.get(channelId) || data;
           await channel.output(dataToSend, { 
+            ...metadata,
             routedBy: 'message-router',
             routedAt: Date.now() 
           });
@@ -267,6 +267,42 @@ describe('DataFlowManager', () => {
       expect(mockChannel.output).toHaveBeenCalled();
     });
 
+    it('should pass the traceparent of a sampled span to channels and end the span after routing', async () => {
+      const output = jest.fn().mockResolvedValue(undefined);
+      dataFlowManager.registerChannel({
+        id: 'traced-channel',
+        name: 'Traced Channel',
+        type: 'custom',
+        enabled: true,
+        output,
+        close: jest.fn().mockResolvedValue(undefined),
+        getStatus: jest.fn()
+      } as any);
+      dataFlowManager.addRoutingRule({ name: 'traced-rule', condition: () => true, targetChannels: ['traced-channel'], enabled: true, priority: 10 });
+
+      const traceparent = '00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01';
+      const span = {
+        isRecording: () => true,
+        traceparent: () => traceparent,
+        setStatus: jest.fn().mockReturnThis(),
+        recordException: jest.fn().mockReturnThis(),
+        end: jest.fn()
+      };
+
+      await dataFlowManager.processData({
+        exchange: 'binance',
+        symbol: 'BTCUSDT',
+        type: 'trade',
+        timestamp: Date.now(),
+        data: { price: 50000, quantity: 0.1 }
+      } as any, 'binance', span as any);
+      await new Promise(resolve => setTimeout(resolve, 100));
+
+      expect(output).toHaveBeenCalledWith(expect.anything(), expect.objectContaining({ traceparent }));
+      expect(span.setStatus).toHaveBeenCalledWith('ok');
+      expect(span.end).toHaveBeenCalledTimes(1);
+    });
+
     it('should handle backpressure', async () => {
       const config = {
         enabled: true,
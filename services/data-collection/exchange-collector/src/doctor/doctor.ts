@@ -0,0 +1,203 @@
+/**
+ * 启动自检
+ * 校验配置并逐个探测已启用的交易所，在服务启动前发现密钥、网络、时钟与交易对问题
+ */
+
+import { InstrumentInfo } from '@pixiu/adapter-base';
+import { ExchangeCollectorConfig } from '../config/unified-config';
+import { INSTRUMENT_PROVIDERS, InstrumentProviderFactory } from '../instruments';
+import { fetchBinanceApiPermissions, probeRest, probeWebSocket } from './probes';
+
+export type CheckStatus = 'ok' | 'warn' | 'fail' | 'skip';
+
+export interface DoctorCheck {
+  /** 检查项，如 rest、websocket、clock */
+  name: string;
+  /** 交易所，配置检查为空 */
+  exchange?: string;
+  status: CheckStatus;
+  message: string;
+}
+
+export interface DoctorOptions {
+  /** 单项探测超时（毫秒），默认10秒 */
+  timeout?: number;
+  /** 时钟偏差告警阈值（毫秒），默认500 */
+  clockSkewWarn?: number;
+  /** 时钟偏差失败阈值（毫秒），默认5000，与常用的recvWindow一致 */
+  clockSkewFail?: number;
+  /** WebSocket探测，测试时替换 */
+  probeWebSocket?: (url: string, timeout: number) => Promise<number>;
+  /** 品种数据源 */
+  instrumentProviders?: Record<string, InstrumentProviderFactory>;
+}
+
+/**
+ * 探测已启用的交易所
+ */
+export async function runDoctor(
+  config: ExchangeCollectorConfig,
+  enabledAdapters: string[],
+  options: DoctorOptions = {}
+): Promise<DoctorCheck[]> {
+  const checks: DoctorCheck[] = [];
+
+  if (enabledAdapters.length === 0) {
+    checks.push({ name: 'config', status: 'warn', message: 'No adapters are enabled' });
+  }
+
+  for (const exchange of enabledAdapters) {
+    checks.push(...await checkExchange(exchange, config.adapters[exchange], options));
+  }
+
+  return checks;
+}
+
+/**
+ * 探测单个交易所
+ */
+async function checkExchange(exchange: string, adapter: any, options: DoctorOptions): Promise<DoctorCheck[]> {
+  const timeout = options.timeout ?? 10000;
+  const endpoints = adapter?.config?.endpoints ?? {};
+  const checks: DoctorCheck[] = [];
+  const add = (name: string, status: CheckStatus, message: string) => checks.push({ name, exchange, status, message });
+
+  if (!endpoints.rest) {
+    add('rest', 'skip', 'No REST endpoint configured');
+  } else {
+    try {
+      const result = await probeRest(exchange, endpoints.rest, timeout);
+      add('rest', 'ok', `${Math.round(result.latency)} ms to ${endpoints.rest}`);
+
+      const skew = Math.abs(result.clockSkew);
+      const warnAt = options.clockSkewWarn ?? 500;
+      const failAt = options.clockSkewFail ?? 5000;
+      const direction = result.clockSkew > 0 ? 'behind' : 'ahead of';
+      add(
+        'clock',
+        skew >= failAt ? 'fail' : skew >= warnAt ? 'warn' : 'ok',
+        `Local clock is ${Math.round(skew)} ms ${direction} server time`
+      );
+    } catch (error) {
+      add('rest', 'fail', `${endpoints.rest} unreachable: ${(error as Error).message}`);
+    }
+  }
+
+  if (!endpoints.ws) {
+    add('websocket', 'skip', 'No WebSocket endpoint configured');
+  } else {
+    try {
+      const latency = await (options.probeWebSocket ?? probeWebSocket)(endpoints.ws, timeout);
+      add('websocket', 'ok', `${Math.round(latency)} ms to open ${endpoints.ws}`);
+    } catch (error) {
+      add('websocket', 'fail', `${endpoints.ws} unreachable: ${(error as Error).message}`);
+    }
+  }
+
+  checks.push(...await checkApiKey(exchange, adapter?.config?.auth, endpoints.rest, timeout, adapter?.extensions?.recvWindow));
+  checks.push(...await checkSymbols(exchange, adapter?.subscription?.symbols ?? [], endpoints.rest, options));
+
+  return checks;
+}
+
+/**
+ * 检查API密钥权限，采集器只需要读取权限，提现权限应关闭
+ */
+async function checkApiKey(
+  exchange: string,
+  auth: { apiKey?: string; apiSecret?: string } | undefined,
+  restUrl: string | undefined,
+  timeout: number,
+  recvWindow?: number
+): Promise<DoctorCheck[]> {
+  const check = (status: CheckStatus, message: string): DoctorCheck[] => [{ name: 'api-key', exchange, status, message }];
+
+  if (!auth?.apiKey || !auth.apiSecret) {
+    return check('skip', 'No API credentials configured');
+  }
+  if (exchange !== 'binance' || !restUrl) {
+    return check('skip', `Permission check is not supported for ${exchange}`);
+  }
+
+  try {
+    const permissions = await fetchBinanceApiPermissions(restUrl, { apiKey: auth.apiKey, apiSecret: auth.apiSecret }, timeout, recvWindow);
+    const flags = `read=${permissions.read} trade=${permissions.trade} withdraw=${permissions.withdraw} ipRestricted=${permissions.ipRestricted}`;
+
+    if (!permissions.read) {
+      return check('fail', `API key cannot read account data (${flags})`);
+    }
+    if (permissions.withdraw) {
+      return check('warn', `API key has withdrawal permission, which is not needed (${flags})`);
+    }
+    return check('ok', flags);
+  } catch (error) {
+    return check('fail', `API key rejected: ${(error as Error).message}`);
+  }
+}
+
+/**
+ * 检查订阅的交易对是否存在且可交易，并列出最小下单金额
+ */
+async function checkSymbols(
+  exchange: string,
+  symbols: string[],
+  restUrl: string | undefined,
+  options: DoctorOptions
+): Promise<DoctorCheck[]> {
+  const check = (status: CheckStatus, message: string): DoctorCheck => ({ name: 'symbols', exchange, status, message });
+
+  if (symbols.length === 0) {
+    return [check('skip', 'No symbols configured')];
+  }
+
+  const createProvider = (options.instrumentProviders ?? INSTRUMENT_PROVIDERS)[exchange];
+  if (!createProvider || !restUrl) {
+    return [check('skip', `Instrument metadata is not available for ${exchange}`)];
+  }
+
+  let instruments: InstrumentInfo[];
+  try {
+    instruments = await (await createProvider(restUrl)).fetchInstruments();
+  } catch (error) {
+    return [check('fail', `Failed to load instruments: ${(error as Error).message}`)];
+  }
+
+  const bySymbol = new Map<string, InstrumentInfo>();
+  for (const instrument of instruments) {
+    bySymbol.set(instrument.exchangeSymbol.toUpperCase(), instrument);
+    bySymbol.set(instrument.symbol, instrument);
+  }
+
+  return symbols.map(symbol => {
+    const instrument = bySymbol.get(symbol.toUpperCase());
+    if (!instrument) {
+      return check('fail', `${symbol} is not listed`);
+    }
+    if (instrument.active === false) {
+      return check('fail', `${symbol} is not trading`);
+    }
+    const minimum = instrument.minNotional !== undefined
+      ? `min notional ${instrument.minNotional} ${instrument.quote}`
+      : `min quantity ${instrument.minQuantity ?? instrument.lotSize} ${instrument.base}`;
+    return check('ok', `${symbol}: tick ${instrument.tickSize}, lot ${instrument.lotSize}, ${minimum}`);
+  });
+}
+
+/**
+ * 格式化检查结果
+ */
+export function formatDoctorReport(checks: DoctorCheck[]): string {
+  const labels: Record<CheckStatus, string> = { ok: 'OK  ', warn: 'WARN', fail: 'FAIL', skip: 'SKIP' };
+  const lines = checks.map(check => {
+    const scope = check.exchange ? `${check.exchange} ${check.name}` : check.name;
+    return `[${labels[check.status]}] ${scope.padEnd(20)} ${check.message}`;
+  });
+
+  const failed = checks.filter(check => check.status === 'fail').length;
+  const warned = checks.filter(check => check.status === 'warn').length;
+  lines.push('', failed > 0
+    ? `${failed} check(s) failed, ${warned} warning(s)`
+    : `All checks passed${warned > 0 ? ` with ${warned} warning(s)` : ''}`);
+
+  return lines.join('\n');
+}
@@ -0,0 +1,6 @@
+/**
+ * 启动自检
+ */
+
+export * from './probes';
+export * from './doctor';
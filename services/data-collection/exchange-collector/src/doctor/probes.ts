@@ -0,0 +1,110 @@
+/**
+ * 交易所连通性探测
+ * 测量REST与WebSocket延迟、本地时钟偏差，并检查API密钥权限
+ */
+
+import { performance } from 'perf_hooks';
+import WebSocket from 'ws';
+import type { BinanceRestContext } from '@pixiu/binance-adapter';
+
+interface ServerTimeEndpoint {
+  path: string;
+  parse: (body: any) => number;
+}
+
+/**
+ * 各交易所服务器时间接口，路径相对于适配器配置的REST地址
+ */
+export const SERVER_TIME_ENDPOINTS: Record<string, ServerTimeEndpoint> = {
+  binance: { path: '/v3/time', parse: body => body.serverTime },
+  okx: { path: '/api/v5/public/time', parse: body => Number(body.data[0].ts) },
+  bybit: { path: '/v5/market/time', parse: body => Math.floor(Number(body.result.timeNano) / 1e6) },
+  coinbase: { path: '/api/v3/brokerage/time', parse: body => Number(body.epochMillis) },
+  kraken: { path: '/0/public/Time', parse: body => body.result.unixtime * 1000 }
+};
+
+export interface RestProbeResult {
+  /** 请求往返时间（毫秒） */
+  latency: number;
+  /** 服务器时间减去本地时间（毫秒），以往返中点估算 */
+  clockSkew: number;
+}
+
+export interface ApiPermissions {
+  read: boolean;
+  trade: boolean;
+  withdraw: boolean;
+  /** 是否限制了IP白名单 */
+  ipRestricted?: boolean;
+}
+
+/**
+ * 请求服务器时间，测量REST延迟与时钟偏差
+ */
+export async function probeRest(exchange: string, restUrl: string, timeout: number): Promise<RestProbeResult> {
+  const endpoint = SERVER_TIME_ENDPOINTS[exchange];
+  if (!endpoint) {
+    throw new Error(`No server time endpoint known for ${exchange}`);
+  }
+
+  const sentAt = Date.now();
+  const started = performance.now();
+  const response = await fetch(`${restUrl.replace(/\/+$/, '')}${endpoint.path}`, { signal: AbortSignal.timeout(timeout) });
+  const latency = performance.now() - started;
+
+  if (!response.ok) {
+    throw new Error(`HTTP ${response.status}`);
+  }
+
+  const serverTime = endpoint.parse(await response.json());
+  if (!Number.isFinite(serverTime)) {
+    throw new Error('Unexpected server time response');
+  }
+
+  return { latency, clockSkew: serverTime - (sentAt + latency / 2) };
+}
+
+/**
+ * 建立WebSocket连接并测量握手耗时
+ */
+export function probeWebSocket(url: string, timeout: number): Promise<number> {
+  return new Promise((resolve, reject) => {
+    const started = performance.now();
+    const socket = new WebSocket(url, { handshakeTimeout: timeout });
+
+    socket.once('open', () => {
+      resolve(performance.now() - started);
+      socket.close();
+    });
+    socket.once('error', (error) => {
+      reject(error);
+      socket.terminate();
+    });
+  });
+}
+
+/**
+ * 查询Binance API密钥权限
+ * 指定context时复用其签名器与服务器时钟，定时复查不会每次重新校准
+ */
+export async function fetchBinanceApiPermissions(
+  restUrl: string,
+  auth: { apiKey: string; apiSecret: string },
+  timeout: number,
+  recvWindow?: number,
+  context?: BinanceRestContext
+): Promise<ApiPermissions> {
+  let signer = context?.getSigner({ ...auth, recvWindow });
+  if (!signer) {
+    const { BinanceSigner } = await import('@pixiu/binance-adapter');
+    signer = new BinanceSigner({ ...auth, restUrl, recvWindow, timeout });
+  }
+  const body = await signer.request('GET', '/sapi/v1/account/apiRestrictions');
+
+  return {
+    read: body.enableReading === true,
+    trade: body.enableSpotAndMarginTrading === true,
+    withdraw: body.enableWithdrawals === true,
+    ipRestricted: body.ipRestrict === true
+  };
+}
@@ -0,0 +1,237 @@
+/**
+ * 历史数据集格式
+ * 定义CSV/JSONL行格式与校验规则，并提供支持gzip与zstd的流式读取
+ */
+
+import { createReadStream } from 'fs';
+import { createInterface } from 'readline';
+import * as zlib from 'zlib';
+import { Readable, pipeline } from 'stream';
+import { KlineData, TradeData } from '@pixiu/adapter-base';
+import { isZstdSupported } from '../recording/format';
+
+export type HistoricalDataKind = 'kline' | 'trade';
+export type HistoricalOutputFormat = 'csv' | 'jsonl';
+export type HistoricalCompression = 'none' | 'gzip' | 'zstd';
+
+export type HistoricalRecord<K extends HistoricalDataKind> = K extends 'kline' ? KlineData : TradeData;
+
+export interface HistoricalDatasetReadOptions {
+  /** 文件格式，默认按扩展名推断 */
+  format?: HistoricalOutputFormat;
+  /** 压缩方式，默认按扩展名推断 */
+  compression?: HistoricalCompression;
+  /** K线周期，CSV文件不包含该列时写入读取结果 */
+  interval?: string;
+}
+
+export const HISTORICAL_CSV_HEADERS: Record<HistoricalDataKind, string> = {
+  kline: 'open_time,close_time,open,high,low,close,volume',
+  trade: 'id,timestamp,price,quantity,side'
+};
+
+/**
+ * 数据集校验错误
+ */
+export class DatasetValidationError extends Error {
+  constructor(message: string, public readonly line: number) {
+    super(`Line ${line}: ${message}`);
+    this.name = 'DatasetValidationError';
+  }
+}
+
+/**
+ * 根据文件名推断格式与压缩方式，如 trades.jsonl.gz、klines.csv.zst
+ */
+export function inferDatasetFormat(path: string): { format: HistoricalOutputFormat; compression: HistoricalCompression } {
+  const compression: HistoricalCompression = path.endsWith('.gz') ? 'gzip' : path.endsWith('.zst') ? 'zstd' : 'none';
+  const basePath = path.replace(/\.(gz|zst)$/, '');
+  return { format: basePath.endsWith('.jsonl') ? 'jsonl' : 'csv', compression };
+}
+
+/**
+ * 校验运行时支持该压缩方式，zstd需要Node.js 22.15+
+ */
+export function assertDatasetCompression(compression: HistoricalCompression): void {
+  if (compression === 'zstd' && !isZstdSupported()) {
+    throw new Error(`zstd compression requires Node.js 22.15 or later (running ${process.version})`);
+  }
+}
+
+/**
+ * 打开数据集文件，读取或解压失败时由读取方的迭代抛出，不会产生未处理的error事件
+ */
+function openDataset(path: string, compression: HistoricalCompression): Readable {
+  assertDatasetCompression(compression);
+  const source = createReadStream(path);
+  if (compression === 'none') {
+    return source;
+  }
+  const decompressor = compression === 'gzip' ? zlib.createGunzip() : (zlib as any).createZstdDecompress();
+  // 错误会销毁管道末端的解压流，并由readline的迭代器抛出
+  return pipeline(source, decompressor, () => undefined);
+}
+
+/**
+ * 将K线格式化为一行
+ */
+export function formatKlineRow(kline: KlineData, format: HistoricalOutputFormat): string {
+  if (format === 'jsonl') {
+    return JSON.stringify(kline);
+  }
+  return [kline.openTime, kline.closeTime, kline.open, kline.high, kline.low, kline.close, kline.volume].join(',');
+}
+
+/**
+ * 将成交格式化为一行
+ */
+export function formatTradeRow(trade: TradeData, format: HistoricalOutputFormat): string {
+  if (format === 'jsonl') {
+    return JSON.stringify(trade);
+  }
+  return [trade.id, trade.timestamp, trade.price, trade.quantity, trade.side].join(',');
+}
+
+/**
+ * 流式读取历史数据集，逐行校验，不会将整个文件载入内存
+ */
+export async function* readHistoricalDataset<K extends HistoricalDataKind>(
+  path: string,
+  kind: K,
+  options: HistoricalDatasetReadOptions = {}
+): AsyncGenerator<HistoricalRecord<K>> {
+  const inferred = inferDatasetFormat(path);
+  const format = options.format ?? inferred.format;
+  const compression = options.compression ?? inferred.compression;
+
+  const stream = openDataset(path, compression);
+  const lines = createInterface({ input: stream, crlfDelay: Infinity });
+  let lineNumber = 0;
+  let lastTimestamp = -Infinity;
+
+  try {
+    for await (const line of lines) {
+      lineNumber++;
+
+      if (format === 'csv' && lineNumber === 1) {
+        if (line !== HISTORICAL_CSV_HEADERS[kind]) {
+          throw new DatasetValidationError(`Unexpected CSV header for ${kind} dataset: ${line}`, lineNumber);
+        }
+        continue;
+      }
+      if (line.trim() === '') {
+        continue;
+      }
+
+      const record = kind === 'kline'
+        ? parseKlineRow(line, format, lineNumber, options.interval)
+        : parseTradeRow(line, format, lineNumber);
+
+      const timestamp = 'openTime' in record ? record.openTime : record.timestamp;
+      if (timestamp < lastTimestamp) {
+        throw new DatasetValidationError(`Timestamp ${timestamp} is earlier than previous row`, lineNumber);
+      }
+      lastTimestamp = timestamp;
+
+      yield record as HistoricalRecord<K>;
+    }
+  } finally {
+    lines.close();
+    stream.destroy();
+  }
+}
+
+/**
+ * 解析并校验K线行
+ */
+function parseKlineRow(line: string, format: HistoricalOutputFormat, lineNumber: number, interval?: string): KlineData {
+  let kline: KlineData;
+
+  if (format === 'jsonl') {
+    const value = parseJsonRow(line, lineNumber);
+    kline = {
+      open: requireNumber(value.open, 'open', lineNumber),
+      high: requireNumber(value.high, 'high', lineNumber),
+      low: requireNumber(value.low, 'low', lineNumber),
+      close: requireNumber(value.close, 'close', lineNumber),
+      volume: requireNumber(value.volume, 'volume', lineNumber),
+      openTime: requireNumber(value.openTime, 'openTime', lineNumber),
+      closeTime: requireNumber(value.closeTime, 'closeTime', lineNumber),
+      interval: typeof value.interval === 'string' ? value.interval : interval ?? ''
+    };
+  } else {
+    const fields = splitCsvRow(line, 7, lineNumber);
+    kline = {
+      openTime: requireNumber(fields[0], 'open_time', lineNumber),
+      closeTime: requireNumber(fields[1], 'close_time', lineNumber),
+      open: requireNumber(fields[2], 'open', lineNumber),
+      high: requireNumber(fields[3], 'high', lineNumber),
+      low: requireNumber(fields[4], 'low', lineNumber),
+      close: requireNumber(fields[5], 'close', lineNumber),
+      volume: requireNumber(fields[6], 'volume', lineNumber),
+      interval: interval ?? ''
+    };
+  }
+
+  if (kline.high < kline.low || kline.closeTime < kline.openTime || kline.volume < 0) {
+    throw new DatasetValidationError('Inconsistent kline values', lineNumber);
+  }
+  return kline;
+}
+
+/**
+ * 解析并校验成交行
+ */
+function parseTradeRow(line: string, format: HistoricalOutputFormat, lineNumber: number): TradeData {
+  let value: any;
+  if (format === 'jsonl') {
+    value = parseJsonRow(line, lineNumber);
+  } else {
+    const [id, timestamp, price, quantity, side] = splitCsvRow(line, 5, lineNumber);
+    value = { id, timestamp, price, quantity, side };
+  }
+
+  if (value.side !== 'buy' && value.side !== 'sell') {
+    throw new DatasetValidationError(`Invalid trade side: ${value.side}`, lineNumber);
+  }
+  if (value.id === undefined || value.id === '') {
+    throw new DatasetValidationError('Missing trade id', lineNumber);
+  }
+
+  const trade: TradeData = {
+    id: String(value.id),
+    timestamp: requireNumber(value.timestamp, 'timestamp', lineNumber),
+    price: requireNumber(value.price, 'price', lineNumber),
+    quantity: requireNumber(value.quantity, 'quantity', lineNumber),
+    side: value.side
+  };
+
+  if (trade.price <= 0 || trade.quantity <= 0) {
+    throw new DatasetValidationError('Trade price and quantity must be positive', lineNumber);
+  }
+  return trade;
+}
+
+function parseJsonRow(line: string, lineNumber: number): any {
+  try {
+    return JSON.parse(line);
+  } catch {
+    throw new DatasetValidationError('Invalid JSON', lineNumber);
+  }
+}
+
+function splitCsvRow(line: string, expected: number, lineNumber: number): string[] {
+  const fields = line.split(',');
+  if (fields.length !== expected) {
+    throw new DatasetValidationError(`Expected ${expected} columns, got ${fields.length}`, lineNumber);
+  }
+  return fields;
+}
+
+function requireNumber(value: unknown, field: string, lineNumber: number): number {
+  const number = typeof value === 'number' ? value : typeof value === 'string' && value !== '' ? Number(value) : NaN;
+  if (!Number.isFinite(number)) {
+    throw new DatasetValidationError(`Invalid number for ${field}: ${value}`, lineNumber);
+  }
+  return number;
+}
@@ -0,0 +1,273 @@
+/**
+ * 历史数据下载器
+ * 从历史数据源分页下载K线或成交，写入CSV/JSONL文件，支持gzip/zstd压缩与断点续传
+ */
+
+import { EventEmitter } from 'events';
+import { promises as fs } from 'fs';
+import { dirname } from 'path';
+import * as zlib from 'zlib';
+import { HistoricalDataSource } from '@pixiu/adapter-base';
+import {
+  HistoricalDataKind,
+  HistoricalOutputFormat,
+  HistoricalCompression,
+  HISTORICAL_CSV_HEADERS,
+  assertDatasetCompression,
+  formatKlineRow,
+  formatTradeRow
+} from './dataset';
+
+export interface HistoricalDownloadOptions {
+  /** 数据类型 */
+  kind: HistoricalDataKind;
+  /** 交易对 */
+  symbol: string;
+  /** K线周期，kind为kline时必填 */
+  interval?: string;
+  /** 起始时间（包含） */
+  startTime: number;
+  /** 结束时间（包含） */
+  endTime: number;
+  /** 输出文件路径 */
+  output: string;
+  /** 输出格式 */
+  format?: HistoricalOutputFormat;
+  /** 压缩方式，每页写入一个独立的gzip成员或zstd帧 */
+  compression?: HistoricalCompression;
+  /** 断点文件路径，默认为 <output>.checkpoint.json */
+  checkpointPath?: string;
+}
+
+export interface HistoricalDownloadResult {
+  /** 本次写入的行数 */
+  rows: number;
+  /** 累计写入的行数（含续传前） */
+  totalRows: number;
+  /** 本次请求的页数 */
+  pages: number;
+  /** 是否从断点续传 */
+  resumed: boolean;
+}
+
+interface DownloadCheckpoint {
+  exchange: string;
+  kind: HistoricalDataKind;
+  symbol: string;
+  interval?: string;
+  startTime: number;
+  endTime: number;
+  format: HistoricalOutputFormat;
+  compression: HistoricalCompression;
+  /** 下一页K线起始时间 */
+  nextTime: number;
+  /** 下一页成交起始ID */
+  nextTradeId?: string;
+  /** 已写入的行数 */
+  rows: number;
+  /** 已确认写入的文件字节数 */
+  bytes: number;
+}
+
+/**
+ * 历史数据下载器
+ *
+ * 事件：
+ * - page: 一页数据写入完成
+ */
+export class HistoricalDownloader extends EventEmitter {
+  constructor(private readonly source: HistoricalDataSource) {
+    super();
+  }
+
+  /**
+   * 下载指定时间范围的数据，完成后删除断点文件
+   */
+  async download(options: HistoricalDownloadOptions): Promise<HistoricalDownloadResult> {
+    if (options.kind === 'kline' && !options.interval) {
+      throw new Error('Kline download requires an interval');
+    }
+    if (options.endTime < options.startTime) {
+      throw new Error('End time must not be earlier than start time');
+    }
+
+    const checkpointPath = options.checkpointPath ?? `${options.output}.checkpoint.json`;
+    const format = options.format ?? 'csv';
+    const compression = options.compression ?? 'none';
+    assertDatasetCompression(compression);
+    const previous = await this.loadCheckpoint(checkpointPath, options, format, compression);
+    const checkpoint = previous ?? await this.createOutput(options, format, compression);
+    const result: HistoricalDownloadResult = { rows: 0, totalRows: checkpoint.rows, pages: 0, resumed: previous !== null };
+
+    if (previous) {
+      // 丢弃上次中断时未确认的部分写入
+      await fs.truncate(options.output, previous.bytes);
+    }
+
+    for (;;) {
+      const page = options.kind === 'kline'
+        ? await this.fetchKlinePage(options, checkpoint)
+        : await this.fetchTradePage(options, checkpoint);
+      result.pages++;
+
+      if (page.lines.length > 0) {
+        const content = this.encode(page.lines.join('\n') + '\n', compression);
+        await fs.appendFile(options.output, content);
+        checkpoint.bytes += content.length;
+        checkpoint.rows += page.lines.length;
+        result.rows += page.lines.length;
+        result.totalRows = checkpoint.rows;
+      }
+
+      if (page.done) {
+        break;
+      }
+
+      await this.saveCheckpoint(checkpointPath, checkpoint);
+      this.emit('page', { rows: page.lines.length, totalRows: checkpoint.rows, cursor: checkpoint.nextTime });
+    }
+
+    await fs.rm(checkpointPath, { force: true });
+    return result;
+  }
+
+  /**
+   * 获取一页K线并推进游标
+   */
+  private async fetchKlinePage(
+    options: HistoricalDownloadOptions,
+    checkpoint: DownloadCheckpoint
+  ): Promise<{ lines: string[]; done: boolean }> {
+    const klines = await this.source.fetchKlines({
+      symbol: options.symbol,
+      interval: options.interval!,
+      startTime: checkpoint.nextTime,
+      endTime: options.endTime,
+      limit: this.source.maxPageSize
+    });
+
+    const rows = klines.filter(kline => kline.openTime >= checkpoint.nextTime && kline.openTime <= options.endTime);
+    // 按原始数据的最后一条推进游标，整页都被过滤掉时也能前进
+    if (klines.length > 0) {
+      const nextTime = klines[klines.length - 1].openTime + 1;
+      if (nextTime <= checkpoint.nextTime && klines.length >= this.source.maxPageSize) {
+        throw new Error(`Kline page starting at ${checkpoint.nextTime} did not advance the cursor`);
+      }
+      checkpoint.nextTime = Math.max(checkpoint.nextTime, nextTime);
+    }
+
+    return {
+      lines: rows.map(kline => formatKlineRow(kline, checkpoint.format)),
+      done: klines.length < this.source.maxPageSize || checkpoint.nextTime > options.endTime
+    };
+  }
+
+  /**
+   * 获取一页成交并推进游标，首页按时间定位，之后按成交ID连续翻页
+   */
+  private async fetchTradePage(
+    options: HistoricalDownloadOptions,
+    checkpoint: DownloadCheckpoint
+  ): Promise<{ lines: string[]; done: boolean }> {
+    const byId = checkpoint.nextTradeId !== undefined;
+    const trades = await this.source.fetchTrades({
+      symbol: options.symbol,
+      startTime: byId ? undefined : checkpoint.nextTime,
+      endTime: options.endTime,
+      fromId: checkpoint.nextTradeId,
+      limit: this.source.maxPageSize
+    });
+
+    if (trades.length > 0) {
+      const last = trades[trades.length - 1];
+      checkpoint.nextTradeId = (BigInt(last.id) + 1n).toString();
+      checkpoint.nextTime = last.timestamp + 1;
+    }
+
+    return {
+      lines: trades.map(trade => formatTradeRow(trade, checkpoint.format)),
+      done: trades.length === 0 || (byId && trades.length < this.source.maxPageSize)
+    };
+  }
+
+  /**
+   * 按压缩方式编码写入内容
+   */
+  private encode(content: string, compression: HistoricalCompression): Buffer {
+    switch (compression) {
+      case 'gzip':
+        return zlib.gzipSync(content);
+      case 'zstd':
+        return (zlib as any).zstdCompressSync(content);
+      default:
+        return Buffer.from(content, 'utf-8');
+    }
+  }
+
+  /**
+   * 读取与本次下载参数一致的断点
+   */
+  private async loadCheckpoint(
+    path: string,
+    options: HistoricalDownloadOptions,
+    format: HistoricalOutputFormat,
+    compression: HistoricalCompression
+  ): Promise<DownloadCheckpoint | null> {
+    let checkpoint: DownloadCheckpoint;
+    try {
+      checkpoint = JSON.parse(await fs.readFile(path, 'utf-8'));
+    } catch {
+      return null;
+    }
+
+    const matches = checkpoint.exchange === this.source.exchange &&
+      checkpoint.kind === options.kind &&
+      checkpoint.symbol === options.symbol &&
+      checkpoint.interval === options.interval &&
+      checkpoint.startTime === options.startTime &&
+      checkpoint.endTime === options.endTime &&
+      checkpoint.format === format &&
+      checkpoint.compression === compression;
+
+    if (!matches) {
+      throw new Error(`Checkpoint ${path} was created for a different download; remove it to start over`);
+    }
+    return checkpoint;
+  }
+
+  /**
+   * 创建输出文件并写入表头
+   */
+  private async createOutput(
+    options: HistoricalDownloadOptions,
+    format: HistoricalOutputFormat,
+    compression: HistoricalCompression
+  ): Promise<DownloadCheckpoint> {
+    const header = format === 'csv' ? this.encode(HISTORICAL_CSV_HEADERS[options.kind] + '\n', compression) : Buffer.alloc(0);
+    await fs.mkdir(dirname(options.output), { recursive: true });
+    await fs.writeFile(options.output, header);
+
+    return {
+      exchange: this.source.exchange,
+      kind: options.kind,
+      symbol: options.symbol,
+      interval: options.interval,
+      startTime: options.startTime,
+      endTime: options.endTime,
+      format,
+      compression,
+      nextTime: options.startTime,
+      rows: 0,
+      bytes: header.length
+    };
+  }
+
+  /**
+   * 原子写入断点文件
+   */
+  private async saveCheckpoint(path: string, checkpoint: DownloadCheckpoint): Promise<void> {
+    const tempPath = `${path}.tmp`;
+    await fs.writeFile(tempPath, JSON.stringify(checkpoint), 'utf-8');
+    await fs.rename(tempPath, path);
+  }
+}
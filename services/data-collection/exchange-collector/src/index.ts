@@ -3,9 +3,11 @@
  * 负责初始化服务并启动适配器
  */
 
-import { BaseErrorHandler, BaseMonitor, PubSubClientImpl, globalCache } from '@pixiu/shared-core';
+import { BaseErrorHandler, BaseMonitor, ComponentLogger, EventBus, MetricDefinition, PubSubClientImpl, Tracer, globalCache, getGlobalTracer, setGlobalTracer } from '@pixiu/shared-core';
+import { MarketData } from '@pixiu/adapter-base';
 import { getExchangeCollectorConfigManager } from './config/unified-config';
 import { AdapterRegistry } from './adapters/registry/adapter-registry';
+import { AdapterPauseGate } from './adapters/registry/adapter-pause-gate';
 import { IntegrationConfig } from './adapters/base/adapter-integration';
 import express = require('express');
 import cors = require('cors');
@@ -16,9 +18,32 @@ import { createAdapterRouter } from './api/adapters';
 import { createSubscriptionRouter } from './api/subscriptions';
 import { createStatsRouter } from './api/stats';
 import { createPubSubControlRouter } from './api/pubsub-control';
+import { createOpenApiRouter } from './api/openapi';
+import { createFaultInjectionRouter } from './api/faults';
 import { StatsReporter } from './monitoring/stats-reporter';
+import { ExchangeStatusMonitor } from './monitoring/exchange-status-monitor';
+import { API_PERMISSION_FETCHERS, ApiKeyCredentials, ApiKeyScopeMonitor, ApiKeyScopeReport } from './monitoring/api-key-scope-monitor';
+import { fetchBinanceApiPermissions } from './doctor/probes';
+import { createBinanceRestContext } from './adapters/binance/rest-context';
 import { createWebSocketServer, CollectorWebSocketServer } from './websocket';
 import { createDataStreamCache, DataStreamCache } from './cache';
+import { ClickHouseMarketDataStore } from './store/clickhouse';
+import { MarketDataRecorder, MarketDataReplayer, ReplayOptions, ReplayResult, listRecordings } from './recording';
+import { MicrostructureStream } from './microstructure';
+import { createInstrumentRegistry, INSTRUMENT_PROVIDERS } from './instruments';
+import { ActiveFault, FaultInjector } from './chaos';
+import type { InstrumentListingChange, InstrumentRegistry } from '@pixiu/adapter-base';
+import type { BinanceRestContext } from '@pixiu/binance-adapter';
+
+/** 已注册start()期间指标的监控及指标名称，重复启动时不再重复注册 */
+const registeredMetrics = new WeakMap<BaseMonitor, Set<string>>();
+
+/**
+ * 服务内部事件主题
+ */
+interface CollectorTopics {
+  marketData: { adapter: string; data: MarketData };
+}
 
 /**
  * Exchange Collector 服务类
@@ -27,12 +52,24 @@ export class ExchangeCollectorService {
   private app!: express.Application;
   private server: any;
   private adapterRegistry!: AdapterRegistry;
+  private adapterPauseGate!: AdapterPauseGate;
   private pubsubClient!: PubSubClientImpl;
   private monitor!: BaseMonitor;
+  private logger!: ComponentLogger;
   private errorHandler!: BaseErrorHandler;
   private statsReporter!: StatsReporter;
   private webSocketServer!: CollectorWebSocketServer;
   private dataStreamCache!: DataStreamCache;
+  private eventBus!: EventBus<CollectorTopics>;
+  private recorder?: MarketDataRecorder;
+  private replayer?: MarketDataReplayer;
+  private replayMode = false;
+  private marketDataStore?: ClickHouseMarketDataStore;
+  private exchangeStatusMonitor?: ExchangeStatusMonitor;
+  private apiKeyScopeMonitor?: ApiKeyScopeMonitor;
+  private instrumentRegistry?: InstrumentRegistry;
+  private faultInjector?: FaultInjector;
+  private binanceRestContext?: BinanceRestContext;
   private configManager = getExchangeCollectorConfigManager();
   private isShuttingDown = false;
 
@@ -51,6 +88,9 @@ export class ExchangeCollectorService {
         throw new Error('Failed to load configuration');
       }
 
+      // 支持 SIGHUP 触发配置热更新
+      this.configManager.enableHotReload();
+
       // 初始化监控
       this.monitor = new BaseMonitor({
         metrics: {
@@ -69,11 +109,23 @@ export class ExchangeCollectorService {
         },
         logging: {
           level: config.logging.level,
+          componentLevels: config.logging.componentLevels,
           format: config.logging.format,
           output: config.logging.output,
           file: config.logging.file
         }
       });
+      this.logger = this.monitor.child('collector');
+
+      // 初始化分布式追踪，未配置时为不采样的空实现
+      if (config.monitoring.tracing?.enabled) {
+        setGlobalTracer(new Tracer(config.monitoring.tracing));
+      }
+
+      // 日志级别随配置热更新生效
+      this.configManager.onConfigChange(updated => {
+        this.monitor.configureLogLevels(updated.logging.level, updated.logging.componentLevels);
+      });
 
       // 初始化错误处理器
       this.errorHandler = new BaseErrorHandler({
@@ -94,6 +146,13 @@ export class ExchangeCollectorService {
 
       // 初始化适配器注册中心
       this.adapterRegistry = new AdapterRegistry();
+      this.adapterPauseGate = new AdapterPauseGate(this.adapterRegistry, () => !this.isShuttingDown);
+      this.adapterPauseGate.on('restartFailed', (exchange: string, error: Error, attempt: number) => {
+        this.logger.log('error', 'Failed to restart adapter, retrying', { exchange, attempt, error: error.message });
+      });
+      this.adapterPauseGate.on('restarted', (exchange: string, attempt: number) => {
+        this.logger.log('info', 'Adapter restarted after retry', { exchange, attempt });
+      });
       const registryConfig = {
         defaultConfig: {
           publishConfig: {
@@ -133,35 +192,56 @@ export class ExchangeCollectorService {
       // 初始化统计报告器
       this.initializeStatsReporter(config);
 
-      this.monitor.log('info', 'Exchange Collector service initialized', {
+      this.logger.log('info', 'Exchange Collector service initialized', {
         config: {
           adapters: Object.keys(config.adapters),
-          enabledAdapters: configManager.getEnabledAdapters()
+          enabledAdapters: this.configManager.getEnabledAdapters()
         }
       });
     } catch (error) {
-      this.monitor?.log('error', 'Failed to initialize service', { error });
+      this.logger?.log('error', 'Failed to initialize service', { error });
       throw error;
     }
   }
 
   /**
    * 启动服务
+   * replay模式下不启动适配器也不录制，数据由 replay() 写入事件总线
+   * faultInjection开启后可通过 /api/faults 注入演练故障
    */
-  async start(): Promise<void> {
+  async start(options: { replay?: boolean; faultInjection?: boolean } = {}): Promise<void> {
     try {
       const config = this.configManager.getCurrentConfig();
       if (!config) {
         throw new Error('Configuration not loaded');
       }
 
+      if (this.isShuttingDown) {
+        throw new Error('Service is shutting down');
+      }
+
+      this.replayMode = options.replay ?? false;
+      if (options.faultInjection) {
+        this.enableFaultInjection();
+      }
+
       // 启动适配器
-      await this.startAdapters();
+      if (!this.replayMode) {
+        this.binanceRestContext = await createBinanceRestContext(config, this.monitor);
+        // 校准失败不影响启动，签名请求会在时钟未校准时重新校准
+        this.binanceRestContext?.start().catch(error => {
+          this.logger.log('warn', 'Failed to calibrate Binance server clock', { error: error.message });
+        });
+        await this.verifyApiKeyScopes();
+        await this.startAdapters();
+        this.startExchangeStatusMonitor();
+        await this.startInstrumentRefresh();
+      }
 
       // 启动 HTTP 服务器
       await new Promise<void>((resolve, reject) => {
         this.server = this.app.listen(config.server.port, config.server.host, () => {
-          this.monitor.log('info', 'HTTP server started', {
+          this.logger.log('info', 'HTTP server started', {
             host: config.server.host,
             port: config.server.port
           });
@@ -172,12 +252,15 @@ export class ExchangeCollectorService {
       // 初始化 WebSocket 服务器
       this.initializeWebSocket();
 
+      // 初始化行情存储
+      await this.initializeStorage();
+
       // 启动统计报告器
       this.statsReporter.start();
 
-      this.monitor.log('info', 'Exchange Collector service started successfully');
+      this.logger.log('info', 'Exchange Collector service started successfully');
     } catch (error) {
-      this.monitor.log('error', 'Failed to start service', { error });
+      this.logger.log('error', 'Failed to start service', { error });
       throw error;
     }
   }
@@ -191,7 +274,7 @@ export class ExchangeCollectorService {
     }
 
     this.isShuttingDown = true;
-    this.monitor.log('info', 'Stopping Exchange Collector service...');
+    this.logger.log('info', 'Stopping Exchange Collector service...');
 
     try {
       // 停止统计报告器
@@ -199,7 +282,43 @@ export class ExchangeCollectorService {
         this.statsReporter.stop();
       }
 
-      // 停止 WebSocket 服务器
+      this.replayer?.stop();
+      this.exchangeStatusMonitor?.stop();
+      this.apiKeyScopeMonitor?.stop();
+      this.faultInjector?.clearAll();
+      this.adapterPauseGate?.destroy();
+      this.instrumentRegistry?.stopAutoRefresh();
+
+      // 停止接收新的 HTTP 连接，进行中的请求在最后等待完成
+      const serverClosed = this.server
+        ? new Promise<void>((resolve) => this.server.close(() => resolve()))
+        : Promise.resolve();
+
+      // 先停止所有适配器，断开交易所连接，不再产生新数据
+      if (this.adapterRegistry) {
+        await this.adapterRegistry.stopAllInstances();
+      }
+      this.binanceRestContext?.destroy();
+
+      // 投递事件总线中剩余的数据
+      if (this.eventBus) {
+        await this.eventBus.drain();
+        this.eventBus.close();
+      }
+
+      // 关闭录制文件
+      if (this.recorder) {
+        await this.recorder.close();
+      }
+
+      // 写入剩余的存储批次
+      if (this.marketDataStore) {
+        await this.marketDataStore.close().catch((error) => {
+          this.logger.log('error', 'Failed to flush market data store', { error });
+        });
+      }
+
+      // 再关闭 WebSocket 服务器，此前已转发的数据不会被截断
       if (this.webSocketServer) {
         await this.webSocketServer.close();
       }
@@ -209,24 +328,14 @@ export class ExchangeCollectorService {
         this.dataStreamCache.close();
       }
 
-      // 停止接收新的 HTTP 请求
-      if (this.server) {
-        await new Promise<void>((resolve) => {
-          this.server.close(() => resolve());
-        });
-      }
-
-      // 停止所有适配器
-      if (this.adapterRegistry) {
-        await this.adapterRegistry.stopAllInstances();
-      }
+      await serverClosed;
 
-      // 销毁组件
+      // 销毁组件，Pub/Sub 客户端关闭时发送剩余批次
       await this.cleanup();
 
-      this.monitor.log('info', 'Exchange Collector service stopped successfully');
+      this.logger.log('info', 'Exchange Collector service stopped successfully');
     } catch (error) {
-      this.monitor.log('error', 'Error during service shutdown', { error });
+      this.logger.log('error', 'Error during service shutdown', { error });
       throw error;
     }
   }
@@ -250,7 +359,7 @@ export class ExchangeCollectorService {
       const start = Date.now();
       res.on('finish', () => {
         const duration = Date.now() - start;
-        this.monitor.log('debug', 'HTTP request', {
+        this.logger.log('debug', 'HTTP request', {
           method: req.method,
           path: req.path,
           statusCode: res.statusCode,
@@ -267,6 +376,8 @@ export class ExchangeCollectorService {
     this.app.use('/api/subscriptions', createSubscriptionRouter(this.adapterRegistry, this.monitor, this.dataStreamCache));
     this.app.use('/api/stats', createStatsRouter(this.adapterRegistry, this.monitor, this.dataStreamCache));
     this.app.use('/api/pubsub', createPubSubControlRouter(this.adapterRegistry, this.monitor));
+    this.app.use('/api/faults', createFaultInjectionRouter(() => this.faultInjector, this.monitor));
+    this.app.use('/api/openapi.json', createOpenApiRouter());
 
     // 静态文件服务 - 服务前端构建文件
     const frontendDistPath = path.join(__dirname, '../frontend/dist');
@@ -285,7 +396,7 @@ export class ExchangeCollectorService {
       // 对于其他路由，返回index.html以支持前端路由
       res.sendFile(path.join(frontendDistPath, 'index.html'), (err) => {
         if (err) {
-          this.monitor.log('error', 'Failed to serve index.html', { error: err, path: req.path });
+          this.logger.log('error', 'Failed to serve index.html', { error: err, path: req.path });
           res.status(404).json({ error: 'Frontend not available' });
         }
       });
@@ -293,7 +404,7 @@ export class ExchangeCollectorService {
 
     // 错误处理
     this.app.use((err: any, _req: express.Request, res: express.Response, _next: express.NextFunction) => {
-      this.monitor.log('error', 'Unhandled error in Express', { error: err });
+      this.logger.log('error', 'Unhandled error in Express', { error: err });
       res.status(500).json({ error: 'Internal server error' });
     });
   }
@@ -316,7 +427,10 @@ export class ExchangeCollectorService {
           adapterConfig: {
             exchange: exchangeName,
             ...adapterConfig.config,
-            subscription: adapterConfig.subscription
+            subscription: adapterConfig.subscription,
+            extensions: exchangeName === 'binance' && this.binanceRestContext
+              ? { ...adapterConfig.extensions, restContext: this.binanceRestContext }
+              : adapterConfig.extensions
           },
           publishConfig: {
             topicPrefix: config.pubsub.topicPrefix,
@@ -339,6 +453,269 @@ export class ExchangeCollectorService {
     await this.adapterRegistry.startAutoAdapters(adapterConfigs);
   }
 
+  /**
+   * 启动前校验各交易所API密钥权限，之后定时复查
+   * 权限过大或不足时拒绝启动，除非配置了allowMismatch
+   */
+  private async verifyApiKeyScopes(): Promise<void> {
+    const config = this.configManager.getCurrentConfig();
+    const apiKeyScopes = config?.apiKeyScopes;
+    if (!config || !apiKeyScopes?.enabled) {
+      return;
+    }
+
+    const keys: Record<string, ApiKeyCredentials> = {};
+    for (const exchange of this.configManager.getEnabledAdapters()) {
+      const adapter = config.adapters[exchange];
+      const auth = (adapter.config as any).auth;
+      if (auth?.apiKey && auth.apiSecret && adapter.config.endpoints?.rest) {
+        keys[exchange] = {
+          apiKey: auth.apiKey,
+          apiSecret: auth.apiSecret,
+          restUrl: adapter.config.endpoints.rest,
+          recvWindow: adapter.extensions?.recvWindow
+        };
+      }
+    }
+
+    // Binance复用适配器的签名器与服务器时钟，权重与行情请求统一记账
+    const monitor = new ApiKeyScopeMonitor(keys, {
+      ...apiKeyScopes,
+      fetchers: {
+        ...API_PERMISSION_FETCHERS,
+        binance: (restUrl, auth, timeout, recvWindow) =>
+          fetchBinanceApiPermissions(restUrl, auth, timeout, recvWindow, this.binanceRestContext)
+      }
+    });
+    this.registerMetricOnce({
+      name: 'api_key_scope_violation',
+      description: 'Whether an API key has permissions other than those the config requires (1) or not (0)',
+      type: 'gauge',
+      labels: ['exchange']
+    });
+    this.registerMetricOnce({
+      name: 'api_key_scope_check_errors_total',
+      description: 'Periodic API key permission checks that could not query the exchange',
+      type: 'counter',
+      labels: ['exchange']
+    });
+
+    const reports = await monitor.check();
+    for (const report of reports) {
+      if (report.status === 'unsupported') {
+        this.logger.log('warn', 'API key permissions cannot be verified for this exchange', { exchange: report.exchange });
+        continue;
+      }
+      this.monitor.updateMetric('api_key_scope_violation', report.status === 'ok' ? 0 : 1, { exchange: report.exchange });
+    }
+
+    const failed = reports.filter(report => report.status === 'violation' || report.status === 'error');
+    if (failed.length > 0) {
+      const summary = failed.map(report => `${report.exchange}: ${report.problems.join(', ')}`).join('; ');
+      if (!apiKeyScopes.allowMismatch) {
+        throw new Error(`API key scope check failed (${summary})`);
+      }
+      this.logger.log('warn', 'API key scope check failed, continuing because allowMismatch is set', { summary });
+    }
+
+    monitor.on('verified', (exchange: string) => {
+      this.monitor.updateMetric('api_key_scope_violation', 0, { exchange });
+    });
+    monitor.on('violation', (report: ApiKeyScopeReport) => {
+      this.monitor.updateMetric('api_key_scope_violation', 1, { exchange: report.exchange });
+      this.logger.log('error', 'API key permissions no longer match the config', {
+        exchange: report.exchange,
+        problems: report.problems
+      });
+    });
+    monitor.on('error', (exchange: string, error: Error) => {
+      this.monitor.incrementCounter('api_key_scope_check_errors_total', 1, { exchange });
+      this.logger.log('warn', 'Failed to query API key permissions', { exchange, error: error.message });
+    });
+
+    monitor.start();
+    this.apiKeyScopeMonitor = monitor;
+
+    this.logger.log('info', 'API key scope checks enabled', {
+      exchanges: monitor.getExchanges(),
+      checkInterval: apiKeyScopes.checkInterval ?? 3600000
+    });
+  }
+
+  /**
+   * 启用故障注入
+   * outage故障停止对应适配器，解除后在没有其他暂停原因（如交易所维护）时重新启动；行情类故障在事件总线入口生效
+   */
+  private enableFaultInjection(): void {
+    const injector = new FaultInjector();
+
+    injector.on('injected', async (fault: ActiveFault) => {
+      this.logger.log('warn', 'Fault injected', { ...fault });
+      if (fault.kind === 'outage') {
+        await this.pauseAdapter(fault.exchange, `fault:${fault.id}`);
+      }
+    });
+    injector.on('cleared', async (fault: ActiveFault, reason: string) => {
+      this.logger.log('warn', 'Fault cleared', { id: fault.id, kind: fault.kind, exchange: fault.exchange, reason });
+      if (fault.kind === 'outage') {
+        await this.resumeAdapter(fault.exchange, `fault:${fault.id}`);
+      }
+    });
+
+    this.faultInjector = injector;
+    this.logger.log('warn', 'Fault injection is enabled, do not run this instance against production consumers');
+  }
+
+  /**
+   * 注册指标，同一监控上已注册的指标直接跳过
+   */
+  private registerMetricOnce(definition: MetricDefinition): void {
+    let names = registeredMetrics.get(this.monitor);
+    if (!names) {
+      names = new Set();
+      registeredMetrics.set(this.monitor, names);
+    }
+    if (!names.has(definition.name)) {
+      names.add(definition.name);
+      this.monitor.registerMetric(definition);
+    }
+  }
+
+  /**
+   * 按原因暂停适配器，停止失败只记录日志
+   */
+  private async pauseAdapter(exchange: string, reason: string): Promise<void> {
+    try {
+      await this.adapterPauseGate.pause(exchange, reason);
+    } catch (error) {
+      this.logger.log('error', 'Failed to stop paused adapter', { exchange, reason, error: (error as Error).message });
+    }
+  }
+
+  /**
+   * 解除暂停原因，启动失败时由暂停控制按退避间隔重试
+   */
+  private async resumeAdapter(exchange: string, reason: string): Promise<void> {
+    try {
+      if (!await this.adapterPauseGate.resume(exchange, reason)) {
+        const reasons = this.adapterPauseGate.getReasons(exchange);
+        if (reasons.length > 0) {
+          this.logger.log('info', 'Adapter remains paused', { exchange, reasons });
+        }
+      }
+    } catch (error) {
+      this.logger.log('error', 'Failed to restart adapter, retrying', { exchange, reason, error: (error as Error).message });
+    }
+  }
+
+  /**
+   * 按配置监控交易所维护状态
+   * 维护期间停止对应适配器，避免反复重连；恢复并确认后，没有其他暂停原因（如故障注入）时重新启动
+   */
+  private startExchangeStatusMonitor(): void {
+    const exchangeStatus = this.configManager.getCurrentConfig()?.exchangeStatus;
+    if (!exchangeStatus?.enabled) {
+      return;
+    }
+
+    const monitor = new ExchangeStatusMonitor(this.configManager.getEnabledAdapters(), exchangeStatus);
+    this.registerMetricOnce({
+      name: 'exchange_maintenance',
+      description: 'Whether an exchange is paused for maintenance (1) or operational (0)',
+      type: 'gauge',
+      labels: ['exchange']
+    });
+
+    monitor.on('paused', async (exchange: string, message?: string) => {
+      this.monitor.updateMetric('exchange_maintenance', 1, { exchange });
+      this.logger.log('warn', 'Exchange under maintenance, pausing adapter', { exchange, message });
+      await this.pauseAdapter(exchange, 'maintenance');
+    });
+    monitor.on('resumed', async (exchange: string) => {
+      this.monitor.updateMetric('exchange_maintenance', 0, { exchange });
+      this.logger.log('info', 'Exchange maintenance finished, resuming adapter', { exchange });
+      await this.resumeAdapter(exchange, 'maintenance');
+    });
+    monitor.on('error', (exchange: string, error: Error) => {
+      this.logger.log('debug', 'Failed to query exchange status', { exchange, error: error.message });
+    });
+
+    monitor.start();
+    this.exchangeStatusMonitor = monitor;
+
+    this.logger.log('info', 'Exchange status monitoring enabled', {
+      exchanges: monitor.getExchanges(),
+      pollInterval: exchangeStatus.pollInterval ?? 60000
+    });
+  }
+
+  /**
+   * 定时刷新品种元数据，识别新上架和下架的交易对
+   * 关注列表中的变化推送给WebSocket客户端，已订阅的交易对下架时记录告警
+   */
+  private async startInstrumentRefresh(): Promise<void> {
+    const config = this.configManager.getCurrentConfig();
+    const instruments = config?.instruments;
+    if (!config || !instruments?.enabled) {
+      return;
+    }
+
+    const registry = await createInstrumentRegistry(config, this.configManager.getEnabledAdapters(), instruments, INSTRUMENT_PROVIDERS, {
+      monitor: this.monitor,
+      binanceRestContext: this.binanceRestContext
+    });
+    this.registerMetricOnce({
+      name: 'instrument_listing_changes_total',
+      description: 'Instruments newly listed or delisted since the collector started',
+      type: 'counter',
+      labels: ['exchange', 'change']
+    });
+
+    registry.on('listingChange', ({ exchange, listed, delisted }: InstrumentListingChange) => {
+      this.logger.log('info', 'Instrument listings changed', {
+        exchange,
+        listed: listed.map(instrument => instrument.symbol),
+        delisted: delisted.map(instrument => instrument.symbol)
+      });
+      this.monitor.incrementCounter('instrument_listing_changes_total', listed.length, { exchange, change: 'listed' });
+      this.monitor.incrementCounter('instrument_listing_changes_total', delisted.length, { exchange, change: 'delisted' });
+
+      const subscribed = new Set(this.configManager.getAdapterConfig(exchange)?.subscription.symbols.map(symbol => symbol.toUpperCase()));
+      for (const instrument of delisted) {
+        if (subscribed.has(instrument.exchangeSymbol.toUpperCase()) || subscribed.has(instrument.symbol)) {
+          this.logger.log('warn', 'Subscribed instrument is no longer trading', { exchange, symbol: instrument.exchangeSymbol });
+        }
+      }
+    });
+    registry.on('loadFailed', (exchange: string, error: Error) => {
+      this.logger.log('warn', 'Failed to refresh instruments', { exchange, error: error.message });
+    });
+
+    registry.watch(instruments.watchlist?.length ? instruments.watchlist : ['*'], (change) => {
+      this.webSocketServer.broadcast({
+        type: 'instrument',
+        payload: {
+          type: 'instrument',
+          exchange: change.exchange,
+          data: change,
+          timestamp: Date.now()
+        }
+      });
+    });
+
+    // 首次加载建立基准，之后的刷新才会识别上下架
+    for (const exchange of this.configManager.getEnabledAdapters()) {
+      registry.load(exchange).catch(() => undefined);
+    }
+    registry.startAutoRefresh();
+    this.instrumentRegistry = registry;
+
+    this.logger.log('info', 'Instrument refresh enabled', {
+      refreshInterval: instruments.refreshInterval ?? 3600000,
+      watchlist: instruments.watchlist ?? []
+    });
+  }
+
   /**
    * 初始化统计报告器
    */
@@ -354,7 +731,7 @@ export class ExchangeCollectorService {
       }
     );
 
-    this.monitor.log('info', 'Stats reporter initialized', {
+    this.logger.log('info', 'Stats reporter initialized', {
       reportInterval: this.statsReporter.getConfig().reportInterval
     });
   }
@@ -383,7 +760,7 @@ export class ExchangeCollectorService {
       }
     );
 
-    this.monitor.log('info', 'WebSocket server initialized', {
+    this.logger.log('info', 'WebSocket server initialized', {
       path: '/ws',
       maxConnections: 1000
     });
@@ -394,46 +771,189 @@ export class ExchangeCollectorService {
 
   /**
    * 设置数据流转发到WebSocket
+   * 通过事件总线分发，WebSocket 广播与缓存各自缓冲，互不阻塞
    */
   private setupDataStreamForwarding(): void {
+    this.eventBus = new EventBus<CollectorTopics>({ monitor: this.monitor });
+    this.eventBus.on('error', (error, stats) => {
+      this.logger.log('error', 'Error forwarding market data', {
+        error,
+        subscriber: stats.name
+      });
+    });
+
+    // 客户端需要完整的成交流，队列满时丢弃最旧数据
+    this.eventBus.subscribe('marketData', ({ adapter, data: marketData }) => {
+      // 构造WebSocket消息格式
+      const websocketMessage = {
+        type: marketData.type || 'market_data',
+        exchange: marketData.exchange || adapter,
+        symbol: marketData.symbol,
+        data: marketData.data,
+        timestamp: marketData.timestamp || new Date().toISOString()
+      };
+
+      // 转发到WebSocket客户端
+      this.webSocketServer.broadcast({
+        type: websocketMessage.type,
+        payload: websocketMessage
+      });
+
+      this.logger.log('debug', 'Market data forwarded to WebSocket', {
+        adapter,
+        symbol: marketData.symbol,
+        type: marketData.type
+      });
+    }, { name: 'websocket', policy: 'drop-oldest', capacity: 10000 });
+
+    // 缓存只保留每个数据流的最新值
+    if (this.dataStreamCache) {
+      this.eventBus.subscribe('marketData', ({ adapter, data: marketData }) => {
+        this.dataStreamCache.set(this.getStreamKey(adapter, marketData), marketData, adapter);
+      }, {
+        name: 'cache',
+        policy: 'coalesce',
+        coalesceKey: ({ adapter, data }) => this.getStreamKey(adapter, data)
+      });
+    }
+
+    this.setupRecording();
+    this.setupMicrostructure();
+
     // 监听适配器处理的数据
-    this.adapterRegistry.on('instanceDataProcessed', (adapterName: string, marketData: any) => {
-      try {
-        // 构造WebSocket消息格式
-        const websocketMessage = {
-          type: marketData.type || 'market_data',
-          exchange: marketData.exchange || adapterName,
-          symbol: marketData.symbol,
-          data: marketData.data,
-          timestamp: marketData.timestamp || new Date().toISOString()
-        };
+    this.adapterRegistry.on('instanceDataProcessed', (adapterName: string, marketData: MarketData) => {
+      const publish = () => this.eventBus.publish('marketData', { adapter: adapterName, data: marketData });
+      if (this.faultInjector) {
+        this.faultInjector.apply(adapterName, marketData, publish);
+      } else {
+        publish();
+      }
+    });
 
-        // 转发到WebSocket客户端
-        this.webSocketServer.broadcast({
-          type: websocketMessage.type,
-          payload: websocketMessage
-        });
+    this.logger.log('info', 'Data stream forwarding to WebSocket configured');
+  }
 
-        // 缓存数据
-        if (this.dataStreamCache) {
-          this.dataStreamCache.set(`${adapterName}:${marketData.symbol}:${marketData.type}`, marketData, adapterName);
+  /**
+   * 按配置录制行情
+   * 写盘慢于行情时丢弃最旧数据，不阻塞实时转发
+   */
+  private setupRecording(): void {
+    const recording = this.configManager.getCurrentConfig()?.recording;
+    if (!recording?.enabled || this.replayMode) {
+      return;
+    }
+
+    const recorder = new MarketDataRecorder(recording);
+    recorder.on('error', (error) => {
+      this.logger.log('error', 'Market data recording failed', { error });
+    });
+    recorder.on('rotated', (file) => {
+      this.logger.log('info', 'Market data recording file closed', { file });
+    });
+
+    this.eventBus.subscribe('marketData', ({ adapter, data }) => recorder.record(adapter, data), {
+      name: 'recorder',
+      policy: 'drop-oldest',
+      capacity: 50000
+    });
+    this.recorder = recorder;
+
+    this.logger.log('info', 'Market data recording enabled', {
+      directory: recording.directory,
+      streams: recording.streams?.length ?? 'all'
+    });
+  }
+
+  /**
+   * 按配置计算微观结构特征，作为 microstructure 类型的衍生数据推送给WebSocket客户端
+   * 盘口特征依赖适配器维护的订单簿，回放模式下只有成交流特征
+   */
+  private setupMicrostructure(): void {
+    const microstructure = this.configManager.getCurrentConfig()?.microstructure;
+    if (!microstructure?.enabled) {
+      return;
+    }
+
+    const stream = new MicrostructureStream(microstructure, (adapter, symbol) =>
+      this.adapterRegistry.getInstance(adapter)?.getOrderBook(symbol)
+    );
+    stream.on('features', (adapter: string, features) => {
+      this.webSocketServer.broadcast({
+        type: 'microstructure',
+        payload: {
+          type: 'microstructure',
+          exchange: adapter,
+          symbol: features.symbol,
+          data: features,
+          timestamp: features.timestamp
         }
+      });
+    });
 
-        this.monitor.log('debug', 'Market data forwarded to WebSocket', {
-          adapter: adapterName,
-          symbol: marketData.symbol,
-          type: marketData.type
-        });
-      } catch (error) {
-        this.monitor.log('error', 'Error forwarding market data to WebSocket', {
-          error: error,
-          adapter: adapterName,
-          data: marketData
-        });
-      }
+    this.eventBus.subscribe('marketData', ({ adapter, data }) => stream.handle(adapter, data), {
+      name: 'microstructure',
+      policy: 'drop-oldest',
+      capacity: 10000
+    });
+
+    this.logger.log('info', 'Microstructure feature stream enabled', {
+      window: microstructure.window ?? 10000,
+      publishInterval: microstructure.publishInterval ?? 1000
+    });
+  }
+
+  /**
+   * 按配置将行情写入ClickHouse
+   * 回放模式下同样写入，可用录制文件回填历史数据，重复行由表引擎去重
+   */
+  private async initializeStorage(): Promise<void> {
+    const clickhouse = this.configManager.getCurrentConfig()?.storage?.clickhouse;
+    if (!clickhouse?.enabled) {
+      return;
+    }
+
+    const store = new ClickHouseMarketDataStore(clickhouse);
+    store.on('error', (error, kind) => {
+      this.logger.log('error', 'Failed to write market data to ClickHouse', { error, kind });
+    });
+
+    const migrations = await store.start();
+    this.eventBus.subscribe('marketData', ({ data }) => store.write(data), {
+      name: 'clickhouse',
+      policy: 'drop-oldest',
+      capacity: 50000
+    });
+    this.marketDataStore = store;
+
+    this.logger.log('info', 'ClickHouse market data store enabled', {
+      url: clickhouse.url,
+      database: clickhouse.database ?? 'pixiu',
+      migrations
+    });
+  }
+
+  /**
+   * 将录制文件回放到事件总线，需先以replay模式启动
+   */
+  async replay(path: string, options: ReplayOptions = {}): Promise<ReplayResult> {
+    if (!this.replayMode || !this.eventBus) {
+      throw new Error('Service must be started in replay mode before replaying recordings');
+    }
+
+    const files = await listRecordings(path);
+    this.replayer = new MarketDataReplayer();
+    this.replayer.on('file', (file) => {
+      this.logger.log('info', 'Replaying recording', { file });
     });
 
-    this.monitor.log('info', 'Data stream forwarding to WebSocket configured');
+    return this.replayer.replay(files, ({ adapter, data }) => this.eventBus.publish('marketData', { adapter, data }), options);
+  }
+
+  /**
+   * 数据流缓存键
+   */
+  private getStreamKey(adapter: string, marketData: MarketData): string {
+    return `${adapter}:${marketData.symbol}:${marketData.type}`;
   }
 
   /**
@@ -450,7 +970,7 @@ export class ExchangeCollectorService {
       }
     );
 
-    this.monitor.log('info', 'Data stream cache initialized', {
+    this.logger.log('info', 'Data stream cache initialized', {
       maxSize: this.dataStreamCache.getMetrics().totalEntries,
       ttl: config.cache?.ttl || 300000
     });
@@ -470,6 +990,9 @@ export class ExchangeCollectorService {
       cleanupTasks.push(this.pubsubClient.close());
     }
 
+    // 导出剩余的追踪数据
+    cleanupTasks.push(getGlobalTracer().shutdown());
+
     // Monitor doesn't need explicit cleanup
 
     // 清理全局缓存
@@ -483,7 +1006,20 @@ export class ExchangeCollectorService {
    */
   private setupGracefulShutdown(): void {
     const shutdown = async (signal: string) => {
+      if (this.isShuttingDown) {
+        return;
+      }
+
       console.log(`\nReceived ${signal}, starting graceful shutdown...`);
+
+      // 超时后强制退出，避免某个组件卡住导致进程无法结束
+      const timeout = this.configManager.getCurrentConfig()?.service?.server?.shutdownTimeout ?? 30000;
+      const forceExit = setTimeout(() => {
+        console.error(`Graceful shutdown did not finish within ${timeout}ms, forcing exit`);
+        process.exit(1);
+      }, timeout);
+      forceExit.unref();
+
       try {
         await this.stop();
         process.exit(0);
@@ -493,8 +1029,17 @@ export class ExchangeCollectorService {
       }
     };
 
-    process.on('SIGTERM', () => shutdown('SIGTERM'));
-    process.on('SIGINT', () => shutdown('SIGINT'));
+    // 关闭过程中再次收到信号时立即退出
+    const onSignal = (signal: string) => {
+      if (this.isShuttingDown) {
+        console.error(`Received ${signal} again, exiting immediately`);
+        process.exit(1);
+      }
+      shutdown(signal);
+    };
+
+    process.on('SIGTERM', () => onSignal('SIGTERM'));
+    process.on('SIGINT', () => onSignal('SIGINT'));
     process.on('uncaughtException', (error) => {
       console.error('Uncaught exception:', error);
       shutdown('uncaughtException');
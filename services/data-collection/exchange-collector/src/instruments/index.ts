@@ -0,0 +1,63 @@
+/**
+ * 交易品种元数据
+ * 按交易所创建品种数据源，供启动自检和品种定时刷新使用
+ * 适配器包在创建数据源时才加载，未启用的交易所不会被引入
+ */
+
+import { InstrumentProvider, InstrumentRegistry } from '@pixiu/adapter-base';
+import { BaseMonitor } from '@pixiu/shared-core';
+import type { BinanceRestContext } from '@pixiu/binance-adapter';
+import type { ExchangeCollectorConfig } from '../config/unified-config';
+
+export interface InstrumentRefreshOptions {
+  /** 刷新间隔（毫秒），默认1小时 */
+  refreshInterval?: number;
+  /** 关注的交易对模式，如 *USDT，上下架时推送给WebSocket客户端；为空时推送全部 */
+  watchlist?: string[];
+}
+
+export interface InstrumentProviderDependencies {
+  /** 服务监控，数据源的请求策略向其注册指标 */
+  monitor?: BaseMonitor;
+  /** Binance共享上下文，与适配器共用限流器 */
+  binanceRestContext?: BinanceRestContext;
+}
+
+export type InstrumentProviderFactory = (
+  restUrl: string,
+  dependencies?: InstrumentProviderDependencies
+) => InstrumentProvider | Promise<InstrumentProvider>;
+
+/** 支持拉取品种元数据的交易所 */
+export const INSTRUMENT_PROVIDERS: Record<string, InstrumentProviderFactory> = {
+  binance: async (restUrl, { monitor, binanceRestContext } = {}) => {
+    const { BinanceInstrumentProvider } = await import('@pixiu/binance-adapter');
+    return new BinanceInstrumentProvider({ restUrl, monitor, httpPolicy: binanceRestContext?.httpPolicy });
+  },
+  okx: async (restUrl, { monitor } = {}) => {
+    const { OkxInstrumentProvider } = await import('@pixiu/okx-adapter');
+    return new OkxInstrumentProvider({ restUrl, monitor });
+  }
+};
+
+/**
+ * 为已启用且配置了REST端点的交易所创建品种注册中心
+ */
+export async function createInstrumentRegistry(
+  config: ExchangeCollectorConfig,
+  exchanges: string[],
+  options: InstrumentRefreshOptions = {},
+  providers: Record<string, InstrumentProviderFactory> = INSTRUMENT_PROVIDERS,
+  dependencies: InstrumentProviderDependencies = {}
+): Promise<InstrumentRegistry> {
+  const registry = new InstrumentRegistry({ ttl: options.refreshInterval ?? 60 * 60 * 1000 });
+
+  for (const exchange of exchanges) {
+    const restUrl = config.adapters[exchange]?.config?.endpoints?.rest;
+    if (providers[exchange] && restUrl) {
+      registry.addProvider(await providers[exchange](restUrl, dependencies));
+    }
+  }
+
+  return registry;
+}
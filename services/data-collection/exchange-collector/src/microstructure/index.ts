@@ -0,0 +1,5 @@
+/**
+ * 微观结构衍生数据流
+ */
+
+export * from './microstructure-stream';
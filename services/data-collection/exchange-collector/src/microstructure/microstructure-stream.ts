@@ -0,0 +1,88 @@
+/**
+ * 微观结构衍生数据流
+ * 从实时成交与适配器维护的订单簿计算特征，按交易对限频输出
+ */
+
+import { EventEmitter } from 'events';
+import {
+  DataType,
+  MarketData,
+  MicrostructureFeatures,
+  MicrostructureTracker,
+  MicrostructureTrackerConfig,
+  OrderBook,
+  TradeData
+} from '@pixiu/adapter-base';
+
+export interface MicrostructureStreamOptions extends MicrostructureTrackerConfig {
+  /** 同一交易对两次输出的最小间隔（毫秒），默认1000 */
+  publishInterval?: number;
+}
+
+/** 按适配器与交易对查找订单簿 */
+export type OrderBookLookup = (adapter: string, symbol: string) => OrderBook | undefined;
+
+/**
+ * 微观结构衍生数据流
+ * 订单簿尚未同步时不计算盘口特征，避免快照前的残缺数据
+ *
+ * 事件：
+ * - features(adapter, features)
+ */
+export class MicrostructureStream extends EventEmitter {
+  private readonly publishInterval: number;
+  private readonly trackers = new Map<string, MicrostructureTracker>();
+  private readonly lastPublished = new Map<string, number>();
+
+  constructor(private readonly options: MicrostructureStreamOptions, private readonly lookupOrderBook: OrderBookLookup) {
+    super();
+    this.publishInterval = options.publishInterval ?? 1000;
+  }
+
+  /**
+   * 处理一条行情，到达输出间隔时发出特征
+   */
+  handle(adapter: string, marketData: MarketData): void {
+    const tracker = this.getTracker(adapter);
+
+    if (marketData.type === DataType.TRADE) {
+      tracker.addTrade(marketData.symbol, marketData.data as TradeData);
+    } else if (marketData.type === DataType.DEPTH || marketData.type === DataType.ORDER_BOOK) {
+      const book = this.lookupOrderBook(adapter, marketData.symbol);
+      if (!book?.isSynced()) {
+        return;
+      }
+      tracker.updateBook(marketData.symbol, book.toDepthData(this.options.depthLevels ?? 5), marketData.timestamp);
+    } else {
+      return;
+    }
+
+    const key = `${adapter}:${marketData.symbol}`;
+    const last = this.lastPublished.get(key);
+    if (last !== undefined && marketData.timestamp - last < this.publishInterval) {
+      return;
+    }
+
+    const features = tracker.getFeatures(marketData.symbol, marketData.timestamp);
+    if (features) {
+      this.lastPublished.set(key, marketData.timestamp);
+      this.emit('features', adapter, features);
+    }
+  }
+
+  /**
+   * 查询最新特征
+   */
+  getFeatures(adapter: string, symbol: string, now = Date.now()): MicrostructureFeatures | undefined {
+    return this.trackers.get(adapter)?.getFeatures(symbol, now);
+  }
+
+  private getTracker(adapter: string): MicrostructureTracker {
+    let tracker = this.trackers.get(adapter);
+    if (!tracker) {
+      tracker = new MicrostructureTracker(this.options);
+      this.trackers.set(adapter, tracker);
+    }
+    return tracker;
+  }
+}
@@ -0,0 +1,177 @@
+/**
+ * API密钥权限校验
+ * 启动时与定时核对各交易所密钥的权限是否符合配置要求，权限过大或不足都视为违规
+ */
+
+import { EventEmitter } from 'events';
+import { ApiPermissions, fetchBinanceApiPermissions } from '../doctor/probes';
+
+export interface ApiKeyScopeRequirements {
+  /** 交易权限，true要求开启，false要求关闭，不设置则不检查 */
+  trade?: boolean;
+  /** 是否允许提现权限，仅资金划转场景开启，默认要求关闭 */
+  allowWithdraw?: boolean;
+  /** 是否要求密钥绑定IP白名单，交易所未返回该信息时不检查 */
+  requireIpRestriction?: boolean;
+}
+
+export interface ApiKeyCredentials {
+  apiKey: string;
+  apiSecret: string;
+  restUrl: string;
+  recvWindow?: number;
+}
+
+export type ApiPermissionFetcher = (
+  restUrl: string,
+  auth: { apiKey: string; apiSecret: string },
+  timeout: number,
+  recvWindow?: number
+) => Promise<ApiPermissions>;
+
+/**
+ * 可查询密钥权限的交易所
+ */
+export const API_PERMISSION_FETCHERS: Record<string, ApiPermissionFetcher> = {
+  binance: fetchBinanceApiPermissions
+};
+
+export interface ApiKeyScopeMonitorOptions extends ApiKeyScopeRequirements {
+  /** 按交易所覆盖权限要求 */
+  exchanges?: Record<string, ApiKeyScopeRequirements>;
+  /** 复查间隔（毫秒），默认1小时，0表示仅在启动时检查 */
+  checkInterval?: number;
+  /** 单次查询超时（毫秒），默认10秒 */
+  timeout?: number;
+  /** 权限查询实现，测试时替换 */
+  fetchers?: Record<string, ApiPermissionFetcher>;
+}
+
+export interface ApiKeyScopeReport {
+  exchange: string;
+  /** error表示无法查询权限，unsupported表示交易所不提供权限查询 */
+  status: 'ok' | 'violation' | 'error' | 'unsupported';
+  permissions?: ApiPermissions;
+  /** 违规项或查询失败原因 */
+  problems: string[];
+}
+
+/**
+ * 对比密钥权限与要求，返回违规项
+ */
+export function evaluateApiKeyScopes(permissions: ApiPermissions, requirements: ApiKeyScopeRequirements): string[] {
+  const problems: string[] = [];
+
+  if (!permissions.read) {
+    problems.push('read permission is required');
+  }
+  if (requirements.trade === true && !permissions.trade) {
+    problems.push('trade permission is required');
+  }
+  if (requirements.trade === false && permissions.trade) {
+    problems.push('trade permission must be disabled');
+  }
+  if (!requirements.allowWithdraw && permissions.withdraw) {
+    problems.push('withdraw permission must be disabled');
+  }
+  if (requirements.requireIpRestriction && permissions.ipRestricted === false) {
+    problems.push('API key is not restricted to an IP allow-list');
+  }
+
+  return problems;
+}
+
+/**
+ * 密钥权限监控
+ *
+ * 事件：
+ * - verified(exchange) 复查通过
+ * - violation(report) 复查发现违规
+ * - error(exchange, error) 复查时查询失败
+ */
+export class ApiKeyScopeMonitor extends EventEmitter {
+  private readonly checkInterval: number;
+  private readonly timeout: number;
+  private readonly fetchers: Record<string, ApiPermissionFetcher>;
+  private timer?: NodeJS.Timeout;
+
+  constructor(
+    private readonly keys: Record<string, ApiKeyCredentials>,
+    private readonly options: ApiKeyScopeMonitorOptions = {}
+  ) {
+    super();
+    this.checkInterval = options.checkInterval ?? 3600000;
+    this.timeout = options.timeout ?? 10000;
+    this.fetchers = options.fetchers ?? API_PERMISSION_FETCHERS;
+  }
+
+  /**
+   * 配置了密钥的交易所
+   */
+  getExchanges(): string[] {
+    return Object.keys(this.keys);
+  }
+
+  /**
+   * 交易所的权限要求，按交易所配置覆盖全局要求
+   */
+  getRequirements(exchange: string): ApiKeyScopeRequirements {
+    const { trade, allowWithdraw, requireIpRestriction } = this.options;
+    return { trade, allowWithdraw, requireIpRestriction, ...this.options.exchanges?.[exchange] };
+  }
+
+  /**
+   * 检查所有密钥一次
+   */
+  async check(): Promise<ApiKeyScopeReport[]> {
+    return Promise.all(this.getExchanges().map(exchange => this.checkExchange(exchange)));
+  }
+
+  /**
+   * 定时复查，启动时的检查由调用方通过 check() 完成
+   */
+  start(): void {
+    if (this.timer || this.getExchanges().length === 0 || this.checkInterval <= 0) {
+      return;
+    }
+
+    this.timer = setInterval(async () => {
+      for (const report of await this.check()) {
+        if (report.status === 'ok') {
+          this.emit('verified', report.exchange);
+        } else if (report.status === 'violation') {
+          this.emit('violation', report);
+        } else if (report.status === 'error') {
+          this.emit('error', report.exchange, new Error(report.problems[0]));
+        }
+      }
+    }, this.checkInterval);
+    // 复查不应阻止进程退出
+    this.timer.unref();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = undefined;
+    }
+  }
+
+  private async checkExchange(exchange: string): Promise<ApiKeyScopeReport> {
+    const fetcher = this.fetchers[exchange];
+    if (!fetcher) {
+      return { exchange, status: 'unsupported', problems: [] };
+    }
+
+    const { apiKey, apiSecret, restUrl, recvWindow } = this.keys[exchange];
+    let permissions: ApiPermissions;
+    try {
+      permissions = await fetcher(restUrl, { apiKey, apiSecret }, this.timeout, recvWindow);
+    } catch (error) {
+      return { exchange, status: 'error', problems: [`permission query failed: ${(error as Error).message}`] };
+    }
+
+    const problems = evaluateApiKeyScopes(permissions, this.getRequirements(exchange));
+    return { exchange, status: problems.length > 0 ? 'violation' : 'ok', permissions, problems };
+  }
+}
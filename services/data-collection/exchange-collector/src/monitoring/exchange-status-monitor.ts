@@ -0,0 +1,227 @@
+/**
+ * 交易所维护状态监控
+ * 定时查询交易所系统状态接口，维护期间暂停对应适配器，连续确认恢复后再重新启动
+ */
+
+import { EventEmitter } from 'events';
+
+export type VenueStatus = 'operational' | 'maintenance' | 'unknown';
+
+export interface VenueStatusReport {
+  status: VenueStatus;
+  /** 交易所返回的说明，如维护公告标题 */
+  message?: string;
+}
+
+interface StatusEndpoint {
+  url: string;
+  parse: (body: any) => VenueStatusReport;
+}
+
+/** Kraken系统维护或离线 */
+const KRAKEN_PAUSED_STATES = new Set(['maintenance', 'offline']);
+
+/** Coinbase组件中断或维护，degraded_performance与partial_outage仍有行情 */
+const COINBASE_PAUSED_STATES = new Set(['major_outage', 'under_maintenance']);
+
+/**
+ * 查找Coinbase状态页中Advanced Trade的行情组件，组件名可能只在所属分组中体现产品线
+ */
+function findCoinbaseMarketDataComponent(components: any[]): any | undefined {
+  const names = new Map<string, string>(components.map(component => [component.id, component.name]));
+  return components.find(component => {
+    if (component.group) {
+      return false;
+    }
+    const fullName = `${names.get(component.group_id) ?? ''} ${component.name}`.toLowerCase();
+    return fullName.includes('advanced trade') && fullName.includes('market data');
+  });
+}
+
+/**
+ * 各交易所公开的系统状态接口
+ * Bybit没有无需认证的状态接口，不在此列
+ */
+export const STATUS_ENDPOINTS: Record<string, StatusEndpoint> = {
+  binance: {
+    url: 'https://api.binance.com/sapi/v1/system/status',
+    // 0为正常，1为系统维护
+    parse: body => ({ status: body.status === 0 ? 'operational' : 'maintenance', message: body.msg })
+  },
+  okx: {
+    url: 'https://www.okx.com/api/v5/system/status?state=ongoing',
+    // 仅关注WebSocket（0）与交易服务（5）的进行中维护，其他业务维护不影响行情
+    parse: body => {
+      const ongoing = (body.data || []).filter((item: any) => item.serviceType === '0' || item.serviceType === '5');
+      return ongoing.length > 0
+        ? { status: 'maintenance', message: ongoing.map((item: any) => item.title).join('; ') }
+        : { status: 'operational' };
+    }
+  },
+  kraken: {
+    url: 'https://api.kraken.com/0/public/SystemStatus',
+    // cancel_only、post_only、limit_only只限制下单，行情照常推送，不暂停
+    parse: body => ({
+      status: KRAKEN_PAUSED_STATES.has(body.result?.status) ? 'maintenance' : 'operational',
+      message: body.result?.status
+    })
+  },
+  coinbase: {
+    url: 'https://status.coinbase.com/api/v2/components.json',
+    // 只看Advanced Trade行情组件，其他产品线的故障不影响行情
+    parse: body => {
+      const component = findCoinbaseMarketDataComponent(body.components || []);
+      if (!component) {
+        throw new Error('Coinbase status page has no Advanced Trade market data component');
+      }
+      return {
+        status: COINBASE_PAUSED_STATES.has(component.status) ? 'maintenance' : 'operational',
+        message: component.status
+      };
+    }
+  }
+};
+
+export interface ExchangeStatusMonitorOptions {
+  /** 轮询间隔（毫秒），默认60秒 */
+  pollInterval?: number;
+  /** 维护结束后需要连续几次查询正常才恢复，默认2 */
+  confirmations?: number;
+  /** 单次查询超时（毫秒），默认10秒 */
+  timeout?: number;
+  /** 覆盖状态接口地址，如测试网或代理 */
+  endpoints?: Record<string, string>;
+}
+
+interface VenueState {
+  status: VenueStatus;
+  message?: string;
+  checkedAt?: number;
+  paused: boolean;
+  healthyPolls: number;
+}
+
+/**
+ * 交易所维护状态监控
+ * 查询失败不会暂停交易所，网络问题由连接管理器的重连处理
+ *
+ * 事件：
+ * - statusChange(exchange, status, previous)
+ * - paused(exchange, message) 交易所进入维护
+ * - resumed(exchange) 维护结束且已连续确认
+ * - error(exchange, error) 查询失败
+ */
+export class ExchangeStatusMonitor extends EventEmitter {
+  private readonly pollInterval: number;
+  private readonly confirmations: number;
+  private readonly timeout: number;
+  private readonly venues = new Map<string, VenueState>();
+  private timer?: NodeJS.Timeout;
+
+  constructor(exchanges: string[], private readonly options: ExchangeStatusMonitorOptions = {}) {
+    super();
+    this.pollInterval = options.pollInterval ?? 60000;
+    this.confirmations = options.confirmations ?? 2;
+    this.timeout = options.timeout ?? 10000;
+
+    for (const exchange of exchanges) {
+      if (STATUS_ENDPOINTS[exchange]) {
+        this.venues.set(exchange, { status: 'unknown', paused: false, healthyPolls: 0 });
+      }
+    }
+  }
+
+  /**
+   * 已监控的交易所
+   */
+  getExchanges(): string[] {
+    return Array.from(this.venues.keys());
+  }
+
+  start(): void {
+    if (this.timer || this.venues.size === 0) {
+      return;
+    }
+    this.timer = setInterval(() => void this.poll(), this.pollInterval);
+    void this.poll();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = undefined;
+    }
+  }
+
+  /**
+   * 交易所是否因维护暂停
+   */
+  isPaused(exchange: string): boolean {
+    return this.venues.get(exchange)?.paused ?? false;
+  }
+
+  /**
+   * 各交易所最近一次查询结果
+   */
+  getStatuses(): Record<string, { status: VenueStatus; message?: string; checkedAt?: number; paused: boolean }> {
+    return Object.fromEntries(Array.from(this.venues.entries()).map(([exchange, state]) => [exchange, {
+      status: state.status,
+      message: state.message,
+      checkedAt: state.checkedAt,
+      paused: state.paused
+    }]));
+  }
+
+  /**
+   * 查询所有交易所一次
+   */
+  async poll(): Promise<void> {
+    await Promise.all(this.getExchanges().map(exchange => this.check(exchange)));
+  }
+
+  private async check(exchange: string): Promise<void> {
+    const state = this.venues.get(exchange)!;
+
+    let report: VenueStatusReport;
+    try {
+      report = await this.fetchStatus(exchange);
+    } catch (error) {
+      this.emit('error', exchange, error);
+      return;
+    }
+
+    const previous = state.status;
+    state.status = report.status;
+    state.message = report.message;
+    state.checkedAt = Date.now();
+    if (report.status !== previous) {
+      this.emit('statusChange', exchange, report.status, previous);
+    }
+
+    if (report.status === 'maintenance') {
+      state.healthyPolls = 0;
+      if (!state.paused) {
+        state.paused = true;
+        this.emit('paused', exchange, report.message);
+      }
+      return;
+    }
+
+    if (state.paused && ++state.healthyPolls >= this.confirmations) {
+      state.paused = false;
+      state.healthyPolls = 0;
+      this.emit('resumed', exchange);
+    }
+  }
+
+  private async fetchStatus(exchange: string): Promise<VenueStatusReport> {
+    const endpoint = STATUS_ENDPOINTS[exchange];
+    const url = this.options.endpoints?.[exchange] ?? endpoint.url;
+
+    const response = await fetch(url, { signal: AbortSignal.timeout(this.timeout) });
+    if (!response.ok) {
+      throw new Error(`HTTP ${response.status}`);
+    }
+    return endpoint.parse(await response.json());
+  }
+}
@@ -0,0 +1,674 @@
+/**
+ * 二进制录制格式
+ *
+ * 文件以 "PXRB" 和版本号开头，之后是一系列帧：[类型 u8][长度 varint][内容]
+ * - 通道帧：首次出现的 适配器/交易所/交易对/数据类型 组合，分配递增的通道编号
+ * - 事件帧：通道编号、与该通道上一条记录的纳秒时间差，以及行情内容
+ *
+ * 行情内容按值类型紧凑编码：十进制小数存为整数尾数和小数位数，对象键和短字符串在文件内复用，
+ * 深度档位 [[价格, 数量], ...] 按列存储，价格存为相邻档位的差值。
+ * 编码与解码都有状态，同一文件必须从头顺序读取。
+ */
+
+import { RecordedEvent } from './format';
+
+const MAGIC = Buffer.from('PXRB');
+const VERSION = 1;
+const HEADER_SIZE = MAGIC.length + 1;
+
+const FRAME_CHANNEL = 1;
+const FRAME_EVENT = 2;
+
+enum Tag {
+  Undefined = 0,
+  Null = 1,
+  False = 2,
+  True = 3,
+  Integer = 4,
+  Decimal = 5,
+  Float = 6,
+  String = 7,
+  StringDef = 8,
+  StringRef = 9,
+  NumericString = 10,
+  Array = 11,
+  Object = 12,
+  Levels = 13
+}
+
+/** 事件帧中存在的字段 */
+const HAS_TIMESTAMP = 1;
+const HAS_RECEIVED_AT = 2;
+const HAS_DATA = 4;
+const HAS_EXTRA = 8;
+
+/** 复用的字符串上限，交易ID之类的唯一值不会进入字符串表 */
+const MAX_INTERNED_STRINGS = 4096;
+const MAX_INTERNED_LENGTH = 32;
+
+/** 尾数上限，保证差值和zigzag变换后仍在安全整数范围内 */
+const MAX_MANTISSA = 2 ** 50;
+const NANOS_PER_MILLI = 1_000_000n;
+
+/** 单调时钟与墙钟的偏差超过此值时重新对齐 */
+const MAX_CLOCK_DRIFT = NANOS_PER_MILLI;
+
+/** 单调时钟到纳秒墙钟的偏移 */
+let clockOffset = BigInt(Date.now()) * NANOS_PER_MILLI - process.hrtime.bigint();
+
+/**
+ * 纳秒精度的当前时间（Unix纪元起）
+ * 以 Date.now() 为基准加上单调时钟的增量，系统时钟被NTP校正或手动调整后，
+ * 与 Date.now() 相差超过1毫秒时重新对齐
+ */
+export function wallClockNanos(): bigint {
+  const monotonic = process.hrtime.bigint();
+  const wall = BigInt(Date.now()) * NANOS_PER_MILLI;
+  const nanos = clockOffset + monotonic;
+  const drift = nanos - wall;
+  if (drift > MAX_CLOCK_DRIFT || drift < -MAX_CLOCK_DRIFT) {
+    clockOffset = wall - monotonic;
+    return wall;
+  }
+  return nanos;
+}
+
+/**
+ * 记录的纳秒时间戳
+ */
+export function eventNanos(event: RecordedEvent): bigint {
+  return BigInt(Math.round(event.t)) * NANOS_PER_MILLI + BigInt(event.tn ?? 0);
+}
+
+/**
+ * 拆分为十进制尾数与小数位数，无法精确还原时返回undefined
+ */
+function decimalParts(value: number): [number, number] | undefined {
+  if (!Number.isFinite(value) || Object.is(value, -0)) {
+    return undefined;
+  }
+
+  const text = String(value);
+  if (text.includes('e')) {
+    return undefined;
+  }
+
+  const dot = text.indexOf('.');
+  const scale = dot < 0 ? 0 : text.length - dot - 1;
+  const mantissa = dot < 0 ? value : Number(text.slice(0, dot) + text.slice(dot + 1));
+  if (scale > 15 || Math.abs(mantissa) >= MAX_MANTISSA || mantissa / 10 ** scale !== value) {
+    return undefined;
+  }
+  return [mantissa, scale];
+}
+
+/**
+ * 是否为深度档位列表：非空且每项都是两个可按十进制编码的数字
+ */
+function isLevels(value: unknown[]): value is Array<[number, number]> {
+  return value.length > 0 && value.every(level =>
+    Array.isArray(level) && level.length === 2 &&
+    decimalParts(level[0]) !== undefined && decimalParts(level[1]) !== undefined
+  );
+}
+
+/**
+ * 将一列数字换算到统一的小数位数，溢出或无法精确还原时返回undefined
+ */
+function scaleColumn(values: number[]): { scale: number; mantissas: number[] } | undefined {
+  const scale = Math.max(...values.map(value => decimalParts(value)![1]));
+  const factor = 10 ** scale;
+  const mantissas = values.map(value => Math.round(value * factor));
+
+  for (let i = 0; i < values.length; i++) {
+    if (Math.abs(mantissas[i]) >= MAX_MANTISSA || mantissas[i] / factor !== values[i]) {
+      return undefined;
+    }
+  }
+  return { scale, mantissas };
+}
+
+class ByteWriter {
+  private buffer = Buffer.allocUnsafe(256);
+  private position = 0;
+
+  u8(value: number): void {
+    this.ensure(1);
+    this.buffer[this.position++] = value;
+  }
+
+  varint(value: number): void {
+    this.ensure(8);
+    while (value >= 128) {
+      this.buffer[this.position++] = (value % 128) | 128;
+      value = Math.floor(value / 128);
+    }
+    this.buffer[this.position++] = value;
+  }
+
+  zigzag(value: number): void {
+    this.varint(value >= 0 ? value * 2 : -value * 2 - 1);
+  }
+
+  zigzagBig(value: bigint): void {
+    let encoded = value >= 0n ? value << 1n : ((-value) << 1n) - 1n;
+    this.ensure(10);
+    while (encoded >= 128n) {
+      this.buffer[this.position++] = Number(encoded & 127n) | 128;
+      encoded >>= 7n;
+    }
+    this.buffer[this.position++] = Number(encoded);
+  }
+
+  float(value: number): void {
+    this.ensure(8);
+    this.buffer.writeDoubleLE(value, this.position);
+    this.position += 8;
+  }
+
+  string(value: string): void {
+    const length = Buffer.byteLength(value);
+    this.varint(length);
+    this.ensure(length);
+    this.buffer.write(value, this.position, 'utf-8');
+    this.position += length;
+  }
+
+  bytes(value: Buffer): void {
+    this.ensure(value.length);
+    value.copy(this.buffer, this.position);
+    this.position += value.length;
+  }
+
+  get length(): number {
+    return this.position;
+  }
+
+  finish(): Buffer {
+    return Buffer.from(this.buffer.subarray(0, this.position));
+  }
+
+  private ensure(size: number): void {
+    if (this.position + size <= this.buffer.length) {
+      return;
+    }
+    const next = Buffer.allocUnsafe(Math.max(this.buffer.length * 2, this.position + size));
+    this.buffer.copy(next, 0, 0, this.position);
+    this.buffer = next;
+  }
+}
+
+class ByteReader {
+  position = 0;
+
+  constructor(private readonly buffer: Buffer, private readonly end = buffer.length) {}
+
+  u8(): number {
+    if (this.position >= this.end) {
+      throw new Error('Corrupt binary recording: frame ends unexpectedly');
+    }
+    return this.buffer[this.position++];
+  }
+
+  varint(): number {
+    let value = 0;
+    let multiplier = 1;
+    for (;;) {
+      const byte = this.u8();
+      value += (byte & 127) * multiplier;
+      if (byte < 128) {
+        return value;
+      }
+      multiplier *= 128;
+    }
+  }
+
+  zigzag(): number {
+    const value = this.varint();
+    return value % 2 === 0 ? value / 2 : -(value + 1) / 2;
+  }
+
+  zigzagBig(): bigint {
+    let value = 0n;
+    let shift = 0n;
+    for (;;) {
+      const byte = this.u8();
+      value |= BigInt(byte & 127) << shift;
+      if (byte < 128) {
+        break;
+      }
+      shift += 7n;
+    }
+    return (value & 1n) === 0n ? value >> 1n : -((value + 1n) >> 1n);
+  }
+
+  float(): number {
+    this.require(8);
+    const value = this.buffer.readDoubleLE(this.position);
+    this.position += 8;
+    return value;
+  }
+
+  string(): string {
+    const length = this.varint();
+    this.require(length);
+    const value = this.buffer.toString('utf-8', this.position, this.position + length);
+    this.position += length;
+    return value;
+  }
+
+  private require(size: number): void {
+    if (this.position + size > this.end) {
+      throw new Error('Corrupt binary recording: frame ends unexpectedly');
+    }
+  }
+}
+
+interface ChannelState {
+  id: number;
+  lastNanos: bigint;
+}
+
+interface DecodedChannel {
+  adapter: string;
+  exchange: any;
+  symbol: any;
+  type: any;
+  lastNanos: bigint;
+}
+
+/**
+ * 二进制录制编码器，每个文件使用一个实例
+ */
+export class BinaryRecordingEncoder {
+  private readonly channels = new Map<string, ChannelState>();
+  private readonly keys = new Map<string, number>();
+  private readonly strings = new Map<string, number>();
+
+  /**
+   * 文件头
+   */
+  header(): Buffer {
+    return Buffer.concat([MAGIC, Buffer.from([VERSION])]);
+  }
+
+  /**
+   * 编码一条记录，新通道会先写入通道帧
+   */
+  encode(event: RecordedEvent): Buffer {
+    const { exchange, symbol, type, timestamp, receivedAt, data, ...extra } = event.data as any;
+    const channelKey = `${event.adapter}\u0000${exchange}\u0000${symbol}\u0000${type}`;
+    const nanos = eventNanos(event);
+    const output = new ByteWriter();
+
+    let channel = this.channels.get(channelKey);
+    if (!channel) {
+      channel = { id: this.channels.size, lastNanos: 0n };
+      this.channels.set(channelKey, channel);
+
+      const definition = new ByteWriter();
+      definition.string(event.adapter);
+      this.writeValue(definition, exchange);
+      this.writeValue(definition, symbol);
+      this.writeValue(definition, type);
+      this.writeFrame(output, FRAME_CHANNEL, definition);
+    }
+
+    const body = new ByteWriter();
+    body.varint(channel.id);
+    body.zigzagBig(nanos - channel.lastNanos);
+    channel.lastNanos = nanos;
+
+    // 交易所时间与接收时间通常与记录时间相差不多，存为差值
+    const timestampDelta = this.timeDelta(timestamp, event.t);
+    const receivedAtDelta = this.timeDelta(receivedAt, event.t);
+    if (timestampDelta === undefined && timestamp !== undefined) {
+      extra.timestamp = timestamp;
+    }
+    if (receivedAtDelta === undefined && receivedAt !== undefined) {
+      extra.receivedAt = receivedAt;
+    }
+    const hasExtra = Object.keys(extra).length > 0;
+
+    body.u8(
+      (timestampDelta !== undefined ? HAS_TIMESTAMP : 0) |
+      (receivedAtDelta !== undefined ? HAS_RECEIVED_AT : 0) |
+      (data !== undefined ? HAS_DATA : 0) |
+      (hasExtra ? HAS_EXTRA : 0)
+    );
+    if (timestampDelta !== undefined) {
+      body.zigzag(timestampDelta);
+    }
+    if (receivedAtDelta !== undefined) {
+      body.zigzag(receivedAtDelta);
+    }
+    if (data !== undefined) {
+      this.writeValue(body, data);
+    }
+    if (hasExtra) {
+      this.writeValue(body, extra);
+    }
+
+    this.writeFrame(output, FRAME_EVENT, body);
+    return output.finish();
+  }
+
+  private timeDelta(value: unknown, t: number): number | undefined {
+    if (typeof value !== 'number' || !Number.isSafeInteger(value) || Math.abs(value - t) >= MAX_MANTISSA) {
+      return undefined;
+    }
+    return value - t;
+  }
+
+  private writeFrame(output: ByteWriter, kind: number, payload: ByteWriter): void {
+    output.u8(kind);
+    output.varint(payload.length);
+    output.bytes(payload.finish());
+  }
+
+  private writeValue(writer: ByteWriter, value: any): void {
+    if (value === undefined) {
+      writer.u8(Tag.Undefined);
+    } else if (value === null) {
+      writer.u8(Tag.Null);
+    } else if (typeof value === 'boolean') {
+      writer.u8(value ? Tag.True : Tag.False);
+    } else if (typeof value === 'number') {
+      this.writeNumber(writer, value);
+    } else if (typeof value === 'string') {
+      this.writeString(writer, value);
+    } else if (Array.isArray(value)) {
+      this.writeArray(writer, value);
+    } else if (typeof value === 'object') {
+      const entries = Object.entries(value).filter(([, child]) => child !== undefined);
+      writer.u8(Tag.Object);
+      writer.varint(entries.length);
+      for (const [key, child] of entries) {
+        // 0表示新键，之后跟键名；否则为已有键的序号加1
+        const index = this.keys.get(key);
+        if (index === undefined) {
+          writer.varint(0);
+          writer.string(key);
+          this.keys.set(key, this.keys.size);
+        } else {
+          writer.varint(index + 1);
+        }
+        this.writeValue(writer, child);
+      }
+    } else {
+      // 函数、bigint等无法出现在JSON中的值按JSON.stringify的规则处理
+      writer.u8(Tag.Null);
+    }
+  }
+
+  private writeNumber(writer: ByteWriter, value: number): void {
+    const parts = decimalParts(value);
+    if (!parts) {
+      writer.u8(Tag.Float);
+      writer.float(value);
+    } else if (parts[1] === 0) {
+      writer.u8(Tag.Integer);
+      writer.zigzag(parts[0]);
+    } else {
+      writer.u8(Tag.Decimal);
+      writer.zigzag(parts[0]);
+      writer.u8(parts[1]);
+    }
+  }
+
+  private writeString(writer: ByteWriter, value: string): void {
+    // 交易ID等纯数字字符串存为整数
+    if (/^(0|[1-9]\d{0,14})$/.test(value)) {
+      writer.u8(Tag.NumericString);
+      writer.varint(Number(value));
+      return;
+    }
+
+    const index = this.strings.get(value);
+    if (index !== undefined) {
+      writer.u8(Tag.StringRef);
+      writer.varint(index);
+    } else if (value.length <= MAX_INTERNED_LENGTH && this.strings.size < MAX_INTERNED_STRINGS) {
+      writer.u8(Tag.StringDef);
+      writer.string(value);
+      this.strings.set(value, this.strings.size);
+    } else {
+      writer.u8(Tag.String);
+      writer.string(value);
+    }
+  }
+
+  private writeArray(writer: ByteWriter, value: any[]): void {
+    if (isLevels(value)) {
+      const prices = scaleColumn(value.map(level => level[0]));
+      const quantities = scaleColumn(value.map(level => level[1]));
+      if (prices && quantities) {
+        writer.u8(Tag.Levels);
+        writer.varint(value.length);
+        writer.u8(prices.scale);
+        writer.u8(quantities.scale);
+        let previous = 0;
+        for (let i = 0; i < value.length; i++) {
+          writer.zigzag(prices.mantissas[i] - previous);
+          writer.zigzag(quantities.mantissas[i]);
+          previous = prices.mantissas[i];
+        }
+        return;
+      }
+    }
+
+    writer.u8(Tag.Array);
+    writer.varint(value.length);
+    for (const item of value) {
+      this.writeValue(writer, item === undefined ? null : item);
+    }
+  }
+}
+
+/**
+ * 二进制录制解码器
+ * 按任意大小的数据块输入，返回其中完整的记录；不完整的帧留到下一块
+ */
+export class BinaryRecordingDecoder {
+  private pending: Buffer = Buffer.alloc(0);
+  private headerRead = false;
+  private readonly channels: DecodedChannel[] = [];
+  private readonly keys: string[] = [];
+  private readonly strings: string[] = [];
+
+  /**
+   * 输入数据块
+   */
+  push(chunk: Buffer): RecordedEvent[] {
+    this.pending = this.pending.length > 0 ? Buffer.concat([this.pending, chunk]) : chunk;
+    const events: RecordedEvent[] = [];
+    let offset = 0;
+
+    if (!this.headerRead) {
+      if (this.pending.length < HEADER_SIZE) {
+        return events;
+      }
+      if (!this.pending.subarray(0, MAGIC.length).equals(MAGIC)) {
+        throw new Error('Not a binary recording: missing PXRB header');
+      }
+      if (this.pending[MAGIC.length] !== VERSION) {
+        throw new Error(`Unsupported binary recording version: ${this.pending[MAGIC.length]}`);
+      }
+      this.headerRead = true;
+      offset = HEADER_SIZE;
+    }
+
+    for (;;) {
+      const frame = this.nextFrame(offset);
+      if (!frame) {
+        break;
+      }
+
+      const reader = new ByteReader(this.pending, frame.end);
+      reader.position = frame.start;
+      if (frame.kind === FRAME_CHANNEL) {
+        this.readChannel(reader);
+      } else if (frame.kind === FRAME_EVENT) {
+        events.push(this.readEvent(reader));
+      }
+      // 未知类型的帧直接跳过，便于后续版本追加帧类型
+      offset = frame.end;
+    }
+
+    this.pending = this.pending.subarray(offset);
+    return events;
+  }
+
+  /**
+   * 是否有未读完的数据，读到文件末尾时为true说明最后一帧被截断
+   */
+  hasPending(): boolean {
+    return this.pending.length > 0;
+  }
+
+  /**
+   * 读取从offset开始的帧头，帧不完整时返回undefined
+   */
+  private nextFrame(offset: number): { kind: number; start: number; end: number } | undefined {
+    let position = offset + 1;
+    let length = 0;
+    let multiplier = 1;
+
+    for (;;) {
+      if (position >= this.pending.length) {
+        return undefined;
+      }
+      const byte = this.pending[position++];
+      length += (byte & 127) * multiplier;
+      if (byte < 128) {
+        break;
+      }
+      multiplier *= 128;
+    }
+
+    if (position + length > this.pending.length) {
+      return undefined;
+    }
+    return { kind: this.pending[offset], start: position, end: position + length };
+  }
+
+  private readChannel(reader: ByteReader): void {
+    this.channels.push({
+      adapter: reader.string(),
+      exchange: this.readValue(reader),
+      symbol: this.readValue(reader),
+      type: this.readValue(reader),
+      lastNanos: 0n
+    });
+  }
+
+  private readEvent(reader: ByteReader): RecordedEvent {
+    const channel = this.channels[reader.varint()];
+    if (!channel) {
+      throw new Error('Corrupt binary recording: event references an unknown channel');
+    }
+
+    const nanos = channel.lastNanos + reader.zigzagBig();
+    channel.lastNanos = nanos;
+    const t = Number(nanos / NANOS_PER_MILLI);
+    const tn = Number(nanos % NANOS_PER_MILLI);
+
+    const flags = reader.u8();
+    const data: any = {};
+    if (channel.exchange !== undefined) {
+      data.exchange = channel.exchange;
+    }
+    if (channel.symbol !== undefined) {
+      data.symbol = channel.symbol;
+    }
+    if (channel.type !== undefined) {
+      data.type = channel.type;
+    }
+    if (flags & HAS_TIMESTAMP) {
+      data.timestamp = t + reader.zigzag();
+    }
+    const receivedAt = flags & HAS_RECEIVED_AT ? t + reader.zigzag() : undefined;
+    if (flags & HAS_DATA) {
+      data.data = this.readValue(reader);
+    }
+    if (receivedAt !== undefined) {
+      data.receivedAt = receivedAt;
+    }
+    if (flags & HAS_EXTRA) {
+      Object.assign(data, this.readValue(reader));
+    }
+
+    return tn > 0 ? { t, tn, adapter: channel.adapter, data } : { t, adapter: channel.adapter, data };
+  }
+
+  private readValue(reader: ByteReader): any {
+    const tag = reader.u8();
+    switch (tag) {
+      case Tag.Undefined:
+        return undefined;
+      case Tag.Null:
+        return null;
+      case Tag.False:
+        return false;
+      case Tag.True:
+        return true;
+      case Tag.Integer:
+        return reader.zigzag();
+      case Tag.Decimal: {
+        const mantissa = reader.zigzag();
+        return mantissa / 10 ** reader.u8();
+      }
+      case Tag.Float:
+        return reader.float();
+      case Tag.String:
+        return reader.string();
+      case Tag.StringDef: {
+        const value = reader.string();
+        this.strings.push(value);
+        return value;
+      }
+      case Tag.StringRef:
+        return this.strings[reader.varint()];
+      case Tag.NumericString:
+        return String(reader.varint());
+      case Tag.Array: {
+        const length = reader.varint();
+        const items = new Array(length);
+        for (let i = 0; i < length; i++) {
+          items[i] = this.readValue(reader);
+        }
+        return items;
+      }
+      case Tag.Object: {
+        const count = reader.varint();
+        const value: Record<string, any> = {};
+        for (let i = 0; i < count; i++) {
+          const index = reader.varint();
+          let key: string;
+          if (index === 0) {
+            key = reader.string();
+            this.keys.push(key);
+          } else {
+            key = this.keys[index - 1];
+          }
+          value[key] = this.readValue(reader);
+        }
+        return value;
+      }
+      case Tag.Levels: {
+        const length = reader.varint();
+        const priceFactor = 10 ** reader.u8();
+        const quantityFactor = 10 ** reader.u8();
+        const levels = new Array(length);
+        let price = 0;
+        for (let i = 0; i < length; i++) {
+          price += reader.zigzag();
+          levels[i] = [price / priceFactor, reader.zigzag() / quantityFactor];
+        }
+        return levels;
+      }
+      default:
+        throw new Error(`Corrupt binary recording: unknown value tag ${tag}`);
+    }
+  }
+}
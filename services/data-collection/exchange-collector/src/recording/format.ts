@@ -0,0 +1,116 @@
+/**
+ * 行情录制文件格式
+ * - jsonl：每行一条JSON记录
+ * - binary：紧凑二进制编码（.pxb），见 binary-format.ts
+ * 按文件扩展名压缩：.zst（zstd）、.gz（gzip）或不压缩
+ */
+
+import * as zlib from 'zlib';
+import { Transform } from 'stream';
+import { MarketData } from '@pixiu/adapter-base';
+
+export type RecordingCompression = 'zstd' | 'gzip' | 'none';
+
+export type RecordingFormat = 'jsonl' | 'binary';
+
+/**
+ * 录制记录
+ */
+export interface RecordedEvent {
+  /** 采集器收到数据的时间，回放按该时间间隔还原节奏 */
+  t: number;
+  /** 纳秒时间戳中不足1毫秒的部分（0-999999），仅二进制格式录制时存在 */
+  tn?: number;
+  /** 适配器名称 */
+  adapter: string;
+  /** 原始行情数据 */
+  data: MarketData;
+}
+
+const FORMAT_EXTENSIONS: Record<RecordingFormat, string> = {
+  jsonl: '.jsonl',
+  binary: '.pxb'
+};
+
+const COMPRESSION_EXTENSIONS: Record<RecordingCompression, string> = {
+  zstd: '.zst',
+  gzip: '.gz',
+  none: ''
+};
+
+/** 录制文件名匹配规则 */
+export const RECORDING_FILE_PATTERN = /\.(jsonl|pxb)(\.zst|\.gz)?$/;
+
+/**
+ * 当前运行时是否支持zstd（Node.js 22.15+）
+ */
+export function isZstdSupported(): boolean {
+  return typeof (zlib as any).createZstdCompress === 'function';
+}
+
+/**
+ * 解析压缩方式，auto时优先使用zstd，不支持则退回gzip
+ */
+export function resolveCompression(compression: RecordingCompression | 'auto' = 'auto'): RecordingCompression {
+  if (compression === 'auto') {
+    return isZstdSupported() ? 'zstd' : 'gzip';
+  }
+  if (compression === 'zstd' && !isZstdSupported()) {
+    throw new Error(`zstd compression requires Node.js 22.15 or later (running ${process.version})`);
+  }
+  return compression;
+}
+
+/**
+ * 格式与压缩方式对应的文件扩展名
+ */
+export function recordingExtension(compression: RecordingCompression, format: RecordingFormat = 'jsonl'): string {
+  return FORMAT_EXTENSIONS[format] + COMPRESSION_EXTENSIONS[compression];
+}
+
+/**
+ * 根据文件名推断录制格式
+ */
+export function inferRecordingFormat(path: string): RecordingFormat {
+  return /\.pxb(\.zst|\.gz)?$/.test(path) ? 'binary' : 'jsonl';
+}
+
+/**
+ * 根据文件名推断压缩方式
+ */
+export function inferRecordingCompression(path: string): RecordingCompression {
+  if (path.endsWith('.zst')) {
+    return 'zstd';
+  }
+  return path.endsWith('.gz') ? 'gzip' : 'none';
+}
+
+/**
+ * 创建压缩流
+ */
+export function createCompressor(compression: RecordingCompression): Transform | undefined {
+  switch (compression) {
+    case 'zstd':
+      resolveCompression('zstd');
+      return (zlib as any).createZstdCompress();
+    case 'gzip':
+      return zlib.createGzip();
+    default:
+      return undefined;
+  }
+}
+
+/**
+ * 创建解压流
+ */
+export function createDecompressor(compression: RecordingCompression): Transform | undefined {
+  switch (compression) {
+    case 'zstd':
+      resolveCompression('zstd');
+      return (zlib as any).createZstdDecompress();
+    case 'gzip':
+      return zlib.createGunzip();
+    default:
+      return undefined;
+  }
+}
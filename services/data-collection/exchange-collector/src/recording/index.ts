@@ -0,0 +1,9 @@
+/**
+ * 行情录制与回放
+ */
+
+export * from './format';
+export * from './binary-format';
+export * from './market-data-recorder';
+export * from './market-data-replayer';
+export * from './recording-converter';
@@ -0,0 +1,226 @@
+/**
+ * 行情录制器
+ * 将订阅的行情写入按大小和时间滚动的压缩文件，供 pixiu replay 回放
+ */
+
+import { EventEmitter } from 'events';
+import { createWriteStream, mkdirSync, WriteStream } from 'fs';
+import { finished } from 'stream/promises';
+import { join } from 'path';
+import { Writable } from 'stream';
+import { MarketData } from '@pixiu/adapter-base';
+import {
+  RecordedEvent,
+  RecordingCompression,
+  RecordingFormat,
+  createCompressor,
+  recordingExtension,
+  resolveCompression
+} from './format';
+import { BinaryRecordingEncoder, wallClockNanos } from './binary-format';
+
+/**
+ * 录制的数据流，未设置的字段匹配全部
+ */
+export interface RecordingStreamFilter {
+  exchange?: string;
+  symbol?: string;
+  /** 数据类型，如 trade、depth、kline_1m */
+  types?: string[];
+}
+
+export interface MarketDataRecorderOptions {
+  /** 输出目录 */
+  directory: string;
+  /** 录制的数据流，为空时录制全部 */
+  streams?: RecordingStreamFilter[];
+  /** 文件格式，默认jsonl；binary体积更小且记录纳秒时间 */
+  format?: RecordingFormat;
+  /** 压缩方式，默认auto：支持时使用zstd，否则使用gzip */
+  compression?: RecordingCompression | 'auto';
+  /** 单个文件的未压缩字节数上限，默认256MB */
+  rotateBytes?: number;
+  /** 单个文件的时间跨度（毫秒），默认1小时 */
+  rotateInterval?: number;
+  /** 文件名前缀，默认market-data */
+  filePrefix?: string;
+}
+
+export interface RecorderStats {
+  /** 当前文件 */
+  file?: string;
+  /** 累计写入的记录数 */
+  records: number;
+  /** 当前文件已写入的未压缩字节数 */
+  bytes: number;
+  /** 已关闭的文件数 */
+  rotations: number;
+}
+
+interface OpenFile {
+  path: string;
+  output: WriteStream;
+  writer: Writable;
+  encoder?: BinaryRecordingEncoder;
+  openedAt: number;
+  bytes: number;
+}
+
+/**
+ * 行情录制器
+ *
+ * 事件：
+ * - rotated(path) 文件写入完成并关闭
+ * - error(error)
+ */
+export class MarketDataRecorder extends EventEmitter {
+  private readonly compression: RecordingCompression;
+  private readonly format: RecordingFormat;
+  private readonly rotateBytes: number;
+  private readonly rotateInterval: number;
+  private readonly filePrefix: string;
+  private current?: OpenFile;
+  private lastPath?: string;
+  private records = 0;
+  private rotations = 0;
+  private closed = false;
+
+  constructor(private readonly options: MarketDataRecorderOptions) {
+    super();
+    this.compression = resolveCompression(options.compression);
+    this.format = options.format ?? 'jsonl';
+    this.rotateBytes = options.rotateBytes ?? 256 * 1024 * 1024;
+    this.rotateInterval = options.rotateInterval ?? 60 * 60 * 1000;
+    this.filePrefix = options.filePrefix ?? 'market-data';
+    mkdirSync(options.directory, { recursive: true });
+  }
+
+  /**
+   * 是否录制该数据流
+   */
+  matches(adapter: string, data: MarketData): boolean {
+    const streams = this.options.streams;
+    if (!streams || streams.length === 0) {
+      return true;
+    }
+
+    const exchange = data.exchange || adapter;
+    return streams.some(stream =>
+      (!stream.exchange || stream.exchange === exchange) &&
+      (!stream.symbol || stream.symbol.toUpperCase() === data.symbol?.toUpperCase()) &&
+      (!stream.types || stream.types.includes(data.type))
+    );
+  }
+
+  /**
+   * 写入一条行情，不匹配的数据流直接忽略
+   * 未指定接收时间时取当前时间，二进制格式精确到纳秒
+   * 写入缓冲区满时等待落盘
+   */
+  async record(adapter: string, data: MarketData, receivedAt?: number): Promise<void> {
+    if (this.closed) {
+      throw new Error('Recorder is closed');
+    }
+    if (!this.matches(adapter, data)) {
+      return;
+    }
+
+    const event = this.createEvent(adapter, data, receivedAt);
+
+    if (this.current && (
+      this.current.bytes >= this.rotateBytes ||
+      event.t - this.current.openedAt >= this.rotateInterval
+    )) {
+      await this.closeCurrent();
+    }
+
+    const file = this.current ?? this.open(event.t);
+    const chunk = file.encoder ? file.encoder.encode(event) : JSON.stringify(event) + '\n';
+    file.bytes += Buffer.byteLength(chunk);
+    this.records++;
+
+    if (!file.writer.write(chunk)) {
+      await new Promise<void>(resolve => file.writer.once('drain', resolve));
+    }
+  }
+
+  /**
+   * 关闭当前文件并立即开始新文件
+   */
+  async rotate(): Promise<void> {
+    await this.closeCurrent();
+  }
+
+  /**
+   * 刷新并关闭录制器
+   */
+  async close(): Promise<void> {
+    this.closed = true;
+    await this.closeCurrent();
+  }
+
+  getStats(): RecorderStats {
+    return {
+      file: this.current?.path,
+      records: this.records,
+      bytes: this.current?.bytes ?? 0,
+      rotations: this.rotations
+    };
+  }
+
+  private createEvent(adapter: string, data: MarketData, receivedAt?: number): RecordedEvent {
+    if (receivedAt !== undefined || this.format !== 'binary') {
+      return { t: receivedAt ?? Date.now(), adapter, data };
+    }
+
+    const nanos = wallClockNanos();
+    const tn = Number(nanos % 1_000_000n);
+    return { t: Number(nanos / 1_000_000n), ...(tn > 0 ? { tn } : {}), adapter, data };
+  }
+
+  /**
+   * 打开新文件，文件名包含开始时间，按名称排序即按时间排序
+   */
+  private open(timestamp: number): OpenFile {
+    const stamp = new Date(timestamp).toISOString().replace(/[-:]/g, '').replace('.', '');
+    const extension = recordingExtension(this.compression, this.format);
+    let path = join(this.options.directory, `${this.filePrefix}-${stamp}${extension}`);
+    // 同一毫秒内多次滚动时追加序号，"_" 排在 "." 之后以保持排序
+    if (path === this.lastPath) {
+      path = join(this.options.directory, `${this.filePrefix}-${stamp}_${this.rotations}${extension}`);
+    }
+
+    const output = createWriteStream(path, { flags: 'wx' });
+    output.on('error', error => this.emit('error', error));
+
+    const compressor = createCompressor(this.compression);
+    if (compressor) {
+      compressor.on('error', error => this.emit('error', error));
+      compressor.pipe(output);
+    }
+
+    const writer = compressor ?? output;
+    const encoder = this.format === 'binary' ? new BinaryRecordingEncoder() : undefined;
+    this.current = { path, output, writer, encoder, openedAt: timestamp, bytes: 0 };
+    if (encoder) {
+      const header = encoder.header();
+      writer.write(header);
+      this.current.bytes += header.length;
+    }
+    return this.current;
+  }
+
+  private async closeCurrent(): Promise<void> {
+    const file = this.current;
+    if (!file) {
+      return;
+    }
+    this.current = undefined;
+    this.lastPath = file.path;
+
+    file.writer.end();
+    await finished(file.output);
+    this.rotations++;
+    this.emit('rotated', file.path);
+  }
+}
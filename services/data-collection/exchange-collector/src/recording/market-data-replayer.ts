@@ -0,0 +1,209 @@
+/**
+ * 行情回放
+ * 按录制时的时间间隔读取录制文件，可加速或以最快速度回放
+ */
+
+import { EventEmitter } from 'events';
+import { createReadStream, promises as fs } from 'fs';
+import { createInterface } from 'readline';
+import { join } from 'path';
+import { Readable, pipeline } from 'stream';
+import {
+  RecordedEvent,
+  RECORDING_FILE_PATTERN,
+  createDecompressor,
+  inferRecordingCompression,
+  inferRecordingFormat
+} from './format';
+import { BinaryRecordingDecoder } from './binary-format';
+
+export interface ReplayOptions {
+  /** 回放倍速，0表示不等待，默认1 */
+  speed?: number;
+  /** 跳过早于该时间的记录 */
+  from?: number;
+  /** 跳过晚于该时间的记录 */
+  to?: number;
+}
+
+export interface ReplayResult {
+  /** 回放的记录数 */
+  events: number;
+  /** 读取的文件数 */
+  files: number;
+  /** 第一条与最后一条记录的时间 */
+  startTime?: number;
+  endTime?: number;
+  /** 是否被stop()中断 */
+  stopped: boolean;
+}
+
+/**
+ * 列出录制文件，目录按文件名排序
+ */
+export async function listRecordings(path: string): Promise<string[]> {
+  const stat = await fs.stat(path);
+  if (!stat.isDirectory()) {
+    return [path];
+  }
+
+  const entries = await fs.readdir(path);
+  return entries
+    .filter(name => RECORDING_FILE_PATTERN.test(name))
+    .sort()
+    .map(name => join(path, name));
+}
+
+/**
+ * 打开录制文件，按扩展名解压
+ * 读取或解压失败时管道末端的流以该错误销毁，由读取方的迭代抛出
+ */
+function openRecording(path: string): Readable {
+  const stream = createReadStream(path);
+  const decompressor = createDecompressor(inferRecordingCompression(path));
+  return decompressor ? pipeline(stream, decompressor, () => undefined) : stream;
+}
+
+/**
+ * 流式读取录制文件，按扩展名识别格式
+ * 写入中断的文件可能以不完整的一行或一帧结尾，该记录会被忽略
+ */
+export async function* readRecording(path: string): AsyncGenerator<RecordedEvent> {
+  if (inferRecordingFormat(path) === 'binary') {
+    yield* readBinaryRecording(path);
+    return;
+  }
+
+  const stream = openRecording(path);
+  const lines = createInterface({ input: stream, crlfDelay: Infinity });
+  let pending: string | undefined;
+
+  try {
+    for await (const line of lines) {
+      if (pending !== undefined) {
+        yield JSON.parse(pending);
+      }
+      pending = line.trim() ? line : undefined;
+    }
+  } catch (error) {
+    // 压缩流被截断时丢弃最后一行
+    if ((error as NodeJS.ErrnoException).code !== 'Z_BUF_ERROR') {
+      throw error;
+    }
+    return;
+  }
+
+  if (pending !== undefined) {
+    try {
+      yield JSON.parse(pending);
+    } catch {
+      // 最后一行不完整
+    }
+  }
+}
+
+async function* readBinaryRecording(path: string): AsyncGenerator<RecordedEvent> {
+  const decoder = new BinaryRecordingDecoder();
+
+  try {
+    for await (const chunk of openRecording(path)) {
+      yield* decoder.push(chunk);
+    }
+  } catch (error) {
+    // 压缩流被截断时丢弃最后一帧
+    if ((error as NodeJS.ErrnoException).code !== 'Z_BUF_ERROR') {
+      throw error;
+    }
+  }
+}
+
+/**
+ * 行情回放器
+ *
+ * 事件：
+ * - file(path) 开始读取文件
+ */
+export class MarketDataReplayer extends EventEmitter {
+  private stopped = false;
+  private wake?: () => void;
+
+  /**
+   * 回放录制文件，对每条记录调用publish并等待其完成
+   */
+  async replay(
+    files: string[],
+    publish: (event: RecordedEvent) => void | Promise<void>,
+    options: ReplayOptions = {}
+  ): Promise<ReplayResult> {
+    const speed = options.speed ?? 1;
+    if (!(speed >= 0)) {
+      throw new Error(`Invalid replay speed: ${speed}`);
+    }
+
+    this.stopped = false;
+    const result: ReplayResult = { events: 0, files: 0, stopped: false };
+    let wallStart = 0;
+
+    for (const file of files) {
+      if (this.stopped) {
+        break;
+      }
+      result.files++;
+      this.emit('file', file);
+
+      for await (const event of readRecording(file)) {
+        if (this.stopped) {
+          break;
+        }
+        if (options.from !== undefined && event.t < options.from) {
+          continue;
+        }
+        if (options.to !== undefined && event.t > options.to) {
+          continue;
+        }
+
+        if (result.startTime === undefined) {
+          result.startTime = event.t;
+          wallStart = Date.now();
+        } else if (speed > 0) {
+          const delay = wallStart + (event.t - result.startTime) / speed - Date.now();
+          if (delay > 0) {
+            await this.sleep(delay);
+            if (this.stopped) {
+              break;
+            }
+          }
+        }
+
+        await publish(event);
+        result.events++;
+        result.endTime = event.t;
+      }
+    }
+
+    result.stopped = this.stopped;
+    return result;
+  }
+
+  /**
+   * 停止回放
+   */
+  stop(): void {
+    this.stopped = true;
+    this.wake?.();
+  }
+
+  private sleep(ms: number): Promise<void> {
+    return new Promise(resolve => {
+      const timer = setTimeout(() => {
+        this.wake = undefined;
+        resolve();
+      }, ms);
+      this.wake = () => {
+        clearTimeout(timer);
+        this.wake = undefined;
+        resolve();
+      };
+    });
+  }
+}
@@ -0,0 +1,100 @@
+/**
+ * 录制格式转换
+ * 在JSON Lines与二进制格式之间转换已有录制，目录输入会逐个文件转换并保留文件名
+ */
+
+import { createWriteStream, mkdirSync, promises as fs } from 'fs';
+import { basename, dirname, join } from 'path';
+import { finished } from 'stream/promises';
+import {
+  RECORDING_FILE_PATTERN,
+  RecordingCompression,
+  RecordingFormat,
+  createCompressor,
+  recordingExtension,
+  resolveCompression
+} from './format';
+import { BinaryRecordingEncoder } from './binary-format';
+import { listRecordings, readRecording } from './market-data-replayer';
+
+export interface ConvertRecordingOptions {
+  /** 目标格式 */
+  format: RecordingFormat;
+  /** 目标压缩方式，默认auto */
+  compression?: RecordingCompression | 'auto';
+}
+
+export interface ConvertRecordingResult {
+  /** 转换的文件，输入与输出一一对应 */
+  files: Array<{ input: string; output: string; events: number; inputBytes: number; outputBytes: number }>;
+  events: number;
+}
+
+/**
+ * 转换单个录制文件
+ */
+export async function convertRecordingFile(
+  input: string,
+  output: string,
+  options: ConvertRecordingOptions
+): Promise<number> {
+  mkdirSync(dirname(output), { recursive: true });
+
+  const destination = createWriteStream(output, { flags: 'wx' });
+  const compressor = createCompressor(resolveCompression(options.compression));
+  compressor?.pipe(destination);
+  const writer = compressor ?? destination;
+
+  const encoder = options.format === 'binary' ? new BinaryRecordingEncoder() : undefined;
+  if (encoder) {
+    writer.write(encoder.header());
+  }
+
+  let events = 0;
+  try {
+    for await (const event of readRecording(input)) {
+      const chunk = encoder ? encoder.encode(event) : JSON.stringify(event) + '\n';
+      if (!writer.write(chunk)) {
+        await new Promise<void>(resolve => writer.once('drain', resolve));
+      }
+      events++;
+    }
+  } finally {
+    writer.end();
+    await finished(destination);
+  }
+
+  return events;
+}
+
+/**
+ * 转换录制文件或目录
+ * 输入为目录时输出也是目录，文件名仅替换扩展名
+ */
+export async function convertRecording(
+  input: string,
+  output: string,
+  options: ConvertRecordingOptions
+): Promise<ConvertRecordingResult> {
+  const extension = recordingExtension(resolveCompression(options.compression), options.format);
+  const inputIsDirectory = (await fs.stat(input)).isDirectory();
+  const result: ConvertRecordingResult = { files: [], events: 0 };
+
+  for (const file of await listRecordings(input)) {
+    const target = inputIsDirectory
+      ? join(output, basename(file).replace(RECORDING_FILE_PATTERN, extension))
+      : output;
+
+    const events = await convertRecordingFile(file, target, options);
+    result.files.push({
+      input: file,
+      output: target,
+      events,
+      inputBytes: (await fs.stat(file)).size,
+      outputBytes: (await fs.stat(target)).size
+    });
+    result.events += events;
+  }
+
+  return result;
+}
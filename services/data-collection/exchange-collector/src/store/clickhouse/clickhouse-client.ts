@@ -0,0 +1,95 @@
+/**
+ * ClickHouse HTTP客户端
+ * 通过HTTP接口执行查询与JSONEachRow批量写入，不依赖原生驱动
+ */
+
+export interface ClickHouseClientConfig {
+  /** HTTP接口地址，如 http://localhost:8123 */
+  url: string;
+  /** 数据库，默认pixiu */
+  database?: string;
+  /** 用户名，默认default */
+  username?: string;
+  /** 密码，支持密钥引用 */
+  password?: string;
+  /** 请求超时（毫秒），默认30秒 */
+  timeout?: number;
+}
+
+/**
+ * ClickHouse请求错误
+ */
+export class ClickHouseError extends Error {
+  constructor(message: string, readonly status: number, readonly query: string) {
+    super(message);
+    this.name = 'ClickHouseError';
+  }
+}
+
+export class ClickHouseClient {
+  readonly database: string;
+  private readonly url: string;
+  private readonly timeout: number;
+
+  constructor(private readonly config: ClickHouseClientConfig) {
+    this.url = config.url.replace(/\/+$/, '');
+    this.database = config.database ?? 'pixiu';
+    this.timeout = config.timeout ?? 30000;
+  }
+
+  /**
+   * 执行不返回数据的语句
+   */
+  async command(query: string, options: { database?: string | null } = {}): Promise<void> {
+    await this.request(query, undefined, options.database);
+  }
+
+  /**
+   * 查询并按JSONEachRow解析结果
+   */
+  async query<T = Record<string, unknown>>(query: string): Promise<T[]> {
+    const text = await this.request(`${query} FORMAT JSONEachRow`);
+    return text.split('\n').filter(line => line.trim()).map(line => JSON.parse(line));
+  }
+
+  /**
+   * 批量写入
+   */
+  async insert(table: string, rows: Record<string, unknown>[]): Promise<void> {
+    if (rows.length === 0) {
+      return;
+    }
+    const body = rows.map(row => JSON.stringify(row)).join('\n');
+    await this.request(`INSERT INTO ${table} FORMAT JSONEachRow`, body);
+  }
+
+  /**
+   * database为null时不指定数据库，用于创建数据库
+   */
+  private async request(query: string, body?: string, database: string | null = this.database): Promise<string> {
+    const params = new URLSearchParams();
+    if (database) {
+      params.set('database', database);
+    }
+    // 带请求体时查询只能放在URL参数中
+    if (body !== undefined) {
+      params.set('query', query);
+    }
+
+    const response = await fetch(`${this.url}/?${params}`, {
+      method: 'POST',
+      headers: {
+        'X-ClickHouse-User': this.config.username ?? 'default',
+        ...(this.config.password ? { 'X-ClickHouse-Key': this.config.password } : {})
+      },
+      body: body ?? query,
+      signal: AbortSignal.timeout(this.timeout)
+    });
+
+    const text = await response.text();
+    if (!response.ok) {
+      throw new ClickHouseError(`ClickHouse request failed: HTTP ${response.status} ${text.trim()}`, response.status, query);
+    }
+    return text;
+  }
+}
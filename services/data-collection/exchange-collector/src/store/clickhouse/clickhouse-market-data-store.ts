@@ -0,0 +1,229 @@
+/**
+ * ClickHouse行情存储
+ * 缓冲成交、K线和行情快照并按表批量写入，写入失败时保留数据等待下次重试
+ */
+
+import { EventEmitter } from 'events';
+import { MarketData } from '@pixiu/adapter-base';
+import { ClickHouseClient, ClickHouseClientConfig } from './clickhouse-client';
+import { migrate } from './migrations';
+
+export type StoredDataKind = 'trade' | 'kline' | 'ticker';
+
+export interface ClickHouseStoreConfig extends ClickHouseClientConfig {
+  /** 存储的数据类型，默认全部 */
+  types?: StoredDataKind[];
+  /** 单表缓冲达到该行数时立即写入，默认5000 */
+  batchSize?: number;
+  /** 定时写入间隔（毫秒），默认1秒 */
+  flushInterval?: number;
+  /** 写入失败时单表最多保留的行数，超出后丢弃最旧数据，默认100000 */
+  maxBufferSize?: number;
+}
+
+export interface ClickHouseStoreStats {
+  /** 已写入的行数 */
+  inserted: Record<StoredDataKind, number>;
+  /** 等待写入的行数 */
+  buffered: Record<StoredDataKind, number>;
+  /** 因缓冲区满丢弃的行数 */
+  dropped: number;
+  /** 写入失败次数 */
+  failures: number;
+}
+
+const TABLES: Record<StoredDataKind, string> = {
+  trade: 'trades',
+  kline: 'klines',
+  ticker: 'tickers'
+};
+
+/**
+ * ClickHouse行情存储
+ *
+ * 事件：
+ * - flushed(kind, rows) 批量写入完成
+ * - error(error, kind) 写入失败
+ */
+export class ClickHouseMarketDataStore extends EventEmitter {
+  private readonly client: ClickHouseClient;
+  private readonly types: Set<StoredDataKind>;
+  private readonly batchSize: number;
+  private readonly flushInterval: number;
+  private readonly maxBufferSize: number;
+  private readonly buffers: Record<StoredDataKind, Record<string, unknown>[]> = { trade: [], kline: [], ticker: [] };
+  private readonly flushing = new Map<StoredDataKind, Promise<void>>();
+  private readonly stats: ClickHouseStoreStats = {
+    inserted: { trade: 0, kline: 0, ticker: 0 },
+    buffered: { trade: 0, kline: 0, ticker: 0 },
+    dropped: 0,
+    failures: 0
+  };
+  private flushTimer?: NodeJS.Timeout;
+
+  constructor(config: ClickHouseStoreConfig, client: ClickHouseClient = new ClickHouseClient(config)) {
+    super();
+    this.client = client;
+    this.types = new Set(config.types ?? ['trade', 'kline', 'ticker']);
+    this.batchSize = config.batchSize ?? 5000;
+    this.flushInterval = config.flushInterval ?? 1000;
+    this.maxBufferSize = config.maxBufferSize ?? 100000;
+  }
+
+  /**
+   * 执行表结构迁移并开始定时写入
+   */
+  async start(): Promise<number[]> {
+    const executed = await migrate(this.client);
+    this.flushTimer = setInterval(() => {
+      this.flush().catch(() => undefined);
+    }, this.flushInterval);
+    return executed;
+  }
+
+  /**
+   * 缓冲一条行情，不存储的类型直接忽略
+   */
+  write(data: MarketData): void {
+    const kind = this.kindOf(data);
+    if (!kind || !this.types.has(kind)) {
+      return;
+    }
+
+    const buffer = this.buffers[kind];
+    buffer.push(this.toRow(kind, data));
+
+    if (buffer.length > this.maxBufferSize) {
+      const overflow = buffer.length - this.maxBufferSize;
+      buffer.splice(0, overflow);
+      this.stats.dropped += overflow;
+    }
+
+    if (buffer.length >= this.batchSize) {
+      this.flushKind(kind).catch(() => undefined);
+    }
+  }
+
+  /**
+   * 写入全部缓冲数据，任一表失败时抛出第一个错误
+   */
+  async flush(): Promise<void> {
+    const results = await Promise.allSettled(
+      (Object.keys(this.buffers) as StoredDataKind[]).map(kind => this.flushKind(kind))
+    );
+    const failed = results.find((result): result is PromiseRejectedResult => result.status === 'rejected');
+    if (failed) {
+      throw failed.reason;
+    }
+  }
+
+  /**
+   * 停止定时写入并写入剩余数据
+   */
+  async close(): Promise<void> {
+    if (this.flushTimer) {
+      clearInterval(this.flushTimer);
+      this.flushTimer = undefined;
+    }
+    await this.flush();
+  }
+
+  getStats(): ClickHouseStoreStats {
+    for (const kind of Object.keys(this.buffers) as StoredDataKind[]) {
+      this.stats.buffered[kind] = this.buffers[kind].length;
+    }
+    return {
+      ...this.stats,
+      inserted: { ...this.stats.inserted },
+      buffered: { ...this.stats.buffered }
+    };
+  }
+
+  /**
+   * 写入单表缓冲，同一张表同时只有一个写入请求
+   */
+  private async flushKind(kind: StoredDataKind): Promise<void> {
+    while (this.flushing.has(kind)) {
+      await this.flushing.get(kind)!.catch(() => undefined);
+    }
+
+    const rows = this.buffers[kind].splice(0, this.batchSize);
+    if (rows.length === 0) {
+      return;
+    }
+
+    const request = this.client.insert(TABLES[kind], rows)
+      .then(() => {
+        this.stats.inserted[kind] += rows.length;
+        this.emit('flushed', kind, rows.length);
+      })
+      .catch(error => {
+        // 放回缓冲区头部，保持写入顺序
+        this.buffers[kind].unshift(...rows);
+        this.stats.failures++;
+        if (this.listenerCount('error') > 0) {
+          this.emit('error', error, kind);
+        }
+        throw error;
+      })
+      .finally(() => {
+        this.flushing.delete(kind);
+      });
+
+    this.flushing.set(kind, request);
+    await request;
+
+    if (this.buffers[kind].length >= this.batchSize) {
+      await this.flushKind(kind);
+    }
+  }
+
+  private kindOf(data: MarketData): StoredDataKind | undefined {
+    const type = String(data.type);
+    if (type === 'trade') {
+      return 'trade';
+    }
+    if (type === 'ticker') {
+      return 'ticker';
+    }
+    return type.startsWith('kline') ? 'kline' : undefined;
+  }
+
+  private toRow(kind: StoredDataKind, market: MarketData): Record<string, unknown> {
+    const data = market.data;
+    const base = { exchange: market.exchange, symbol: market.symbol, received_at: market.receivedAt ?? Date.now() };
+
+    switch (kind) {
+      case 'trade':
+        return {
+          ...base,
+          id: String(data.id),
+          price: data.price,
+          quantity: data.quantity,
+          side: data.side,
+          timestamp: data.timestamp ?? market.timestamp
+        };
+      case 'kline':
+        return {
+          ...base,
+          interval: data.interval,
+          open_time: data.openTime,
+          close_time: data.closeTime,
+          open: data.open,
+          high: data.high,
+          low: data.low,
+          close: data.close,
+          volume: data.volume
+        };
+      case 'ticker':
+        return {
+          ...base,
+          last_price: data.lastPrice,
+          bid_price: data.bidPrice,
+          ask_price: data.askPrice,
+          volume_24h: data.volume24h,
+          timestamp: market.timestamp
+        };
+    }
+  }
+}
@@ -0,0 +1,7 @@
+/**
+ * ClickHouse存储
+ */
+
+export * from './clickhouse-client';
+export * from './migrations';
+export * from './clickhouse-market-data-store';
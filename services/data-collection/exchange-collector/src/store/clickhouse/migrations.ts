@@ -0,0 +1,95 @@
+/**
+ * ClickHouse表结构迁移
+ * 迁移按版本号顺序执行，已执行的版本记录在 schema_migrations 表中
+ */
+
+import { ClickHouseClient } from './clickhouse-client';
+
+export interface Migration {
+  version: number;
+  name: string;
+  statements: string[];
+}
+
+/**
+ * 行情表迁移
+ * 使用ReplacingMergeTree按主键去重：K线未收盘时会多次更新，回放录制文件时也可能重复写入
+ */
+export const MARKET_DATA_MIGRATIONS: Migration[] = [
+  {
+    version: 1,
+    name: 'create_market_data_tables',
+    statements: [
+      `CREATE TABLE IF NOT EXISTS trades (
+        exchange LowCardinality(String),
+        symbol LowCardinality(String),
+        id String,
+        price Float64,
+        quantity Float64,
+        side Enum8('buy' = 1, 'sell' = 2),
+        timestamp DateTime64(3, 'UTC'),
+        received_at DateTime64(3, 'UTC')
+      ) ENGINE = ReplacingMergeTree(received_at)
+      PARTITION BY toYYYYMM(timestamp)
+      ORDER BY (exchange, symbol, timestamp, id)`,
+      `CREATE TABLE IF NOT EXISTS klines (
+        exchange LowCardinality(String),
+        symbol LowCardinality(String),
+        interval LowCardinality(String),
+        open_time DateTime64(3, 'UTC'),
+        close_time DateTime64(3, 'UTC'),
+        open Float64,
+        high Float64,
+        low Float64,
+        close Float64,
+        volume Float64,
+        received_at DateTime64(3, 'UTC')
+      ) ENGINE = ReplacingMergeTree(received_at)
+      PARTITION BY toYYYYMM(open_time)
+      ORDER BY (exchange, symbol, interval, open_time)`,
+      `CREATE TABLE IF NOT EXISTS tickers (
+        exchange LowCardinality(String),
+        symbol LowCardinality(String),
+        last_price Float64,
+        bid_price Float64,
+        ask_price Float64,
+        volume_24h Float64,
+        timestamp DateTime64(3, 'UTC'),
+        received_at DateTime64(3, 'UTC')
+      ) ENGINE = MergeTree
+      PARTITION BY toYYYYMM(timestamp)
+      ORDER BY (exchange, symbol, timestamp)`
+    ]
+  }
+];
+
+/**
+ * 创建数据库并执行未应用的迁移，返回本次执行的版本
+ * ClickHouse不支持事务，语句需保证可重复执行
+ */
+export async function migrate(client: ClickHouseClient, migrations: Migration[] = MARKET_DATA_MIGRATIONS): Promise<number[]> {
+  await client.command(`CREATE DATABASE IF NOT EXISTS ${client.database}`, { database: null });
+  await client.command(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version UInt32,
+    name String,
+    applied_at DateTime64(3, 'UTC')
+  ) ENGINE = MergeTree ORDER BY version`);
+
+  const applied = new Set(
+    (await client.query<{ version: number }>('SELECT version FROM schema_migrations')).map(row => Number(row.version))
+  );
+
+  const executed: number[] = [];
+  for (const migration of [...migrations].sort((a, b) => a.version - b.version)) {
+    if (applied.has(migration.version)) {
+      continue;
+    }
+    for (const statement of migration.statements) {
+      await client.command(statement);
+    }
+    await client.insert('schema_migrations', [{ version: migration.version, name: migration.name, applied_at: Date.now() }]);
+    executed.push(migration.version);
+  }
+
+  return executed;
+}
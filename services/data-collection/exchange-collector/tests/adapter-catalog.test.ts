@@ -0,0 +1,64 @@
+/**
+ * Adapter catalog tests
+ */
+
+import { AdapterCapabilities, DataType } from '@pixiu/adapter-base';
+import { AdapterDefinition, formatAdapterList, getAdapterDefinitions, listAdapters } from '../src/adapters/registry/adapter-catalog';
+import '../src/adapters/registry/builtin-adapters';
+
+describe('adapter catalog', () => {
+  const capabilities: AdapterCapabilities = {
+    dataTypes: [DataType.TRADE, DataType.DEPTH],
+    websocket: true,
+    userDataStream: false,
+    combinedStreams: true,
+    maxSubscriptionsPerConnection: 1024,
+    orderBookChecksum: false,
+    trading: { spot: true, margin: false, futures: false, oco: true, postOnly: false }
+  };
+
+  const definition = (name: string, describe: () => Promise<AdapterCapabilities>): AdapterDefinition => ({
+    name,
+    version: '1.0.0',
+    description: `${name} adapter`,
+    supportedFeatures: [],
+    load: jest.fn(),
+    describe
+  });
+
+  it('registers every built-in exchange', () => {
+    const definitions = getAdapterDefinitions();
+
+    expect(definitions.map(entry => entry.name)).toEqual(['binance', 'coinbase', 'okx', 'bybit', 'kraken']);
+    for (const entry of definitions) {
+      expect(typeof entry.load).toBe('function');
+    }
+  });
+
+  it('keeps listing other adapters when one fails to load', async () => {
+    const adapters = await listAdapters([
+      definition('alpha', async () => capabilities),
+      definition('beta', async () => {
+        throw new Error('Cannot find module');
+      })
+    ]);
+
+    expect(adapters).toEqual([
+      { name: 'alpha', version: '1.0.0', description: 'alpha adapter', capabilities },
+      { name: 'beta', version: '1.0.0', description: 'beta adapter', error: 'Cannot find module' }
+    ]);
+  });
+
+  it('formats capabilities as a table', () => {
+    const output = formatAdapterList([
+      { name: 'alpha', version: '1.0.0', description: 'alpha adapter', capabilities },
+      { name: 'beta', version: '1.0.0', description: 'beta adapter', error: 'Cannot find module' }
+    ]);
+
+    expect(output.split('\n')).toEqual([
+      'EXCHANGE  VERSION  DATA TYPES    FEATURES',
+      'alpha     1.0.0    trade, depth  websocket, combined streams (1024/connection), trading: spot, oco',
+      'beta      1.0.0    -             unavailable: Cannot find module'
+    ]);
+  });
+});
@@ -0,0 +1,121 @@
+/**
+ * AdapterPauseGate unit tests
+ */
+
+import { AdapterPauseGate, PausableRegistry } from '../src/adapters/registry/adapter-pause-gate';
+
+describe('AdapterPauseGate', () => {
+  let registry: PausableRegistry & { startInstance: jest.Mock; stopInstance: jest.Mock };
+
+  beforeEach(() => {
+    registry = {
+      getInstance: jest.fn((name: string) => (name === 'binance' ? ({} as any) : undefined)),
+      startInstance: jest.fn().mockResolvedValue(undefined),
+      stopInstance: jest.fn().mockResolvedValue(undefined)
+    };
+  });
+
+  it('stops on the first reason and restarts only after the last one clears', async () => {
+    const gate = new AdapterPauseGate(registry);
+
+    expect(await gate.pause('binance', 'fault:1')).toBe(true);
+    expect(await gate.pause('binance', 'maintenance')).toBe(false);
+    expect(registry.stopInstance).toHaveBeenCalledTimes(1);
+
+    expect(await gate.resume('binance', 'fault:1')).toBe(false);
+    expect(registry.startInstance).not.toHaveBeenCalled();
+    expect(gate.getReasons('binance')).toEqual(['maintenance']);
+
+    expect(await gate.resume('binance', 'maintenance')).toBe(true);
+    expect(registry.startInstance).toHaveBeenCalledTimes(1);
+    expect(gate.getReasons('binance')).toEqual([]);
+  });
+
+  it('ignores reasons that never paused the adapter', async () => {
+    const gate = new AdapterPauseGate(registry);
+
+    expect(await gate.resume('binance', 'maintenance')).toBe(false);
+    await gate.pause('binance', 'fault:1');
+    expect(await gate.resume('binance', 'fault:2')).toBe(false);
+
+    expect(registry.startInstance).not.toHaveBeenCalled();
+    expect(gate.getReasons('binance')).toEqual(['fault:1']);
+  });
+
+  it('clears reasons without restarting when resuming is not allowed', async () => {
+    let shuttingDown = false;
+    const gate = new AdapterPauseGate(registry, () => !shuttingDown);
+
+    await gate.pause('binance', 'fault:1');
+    shuttingDown = true;
+
+    expect(await gate.resume('binance', 'fault:1')).toBe(false);
+    expect(registry.startInstance).not.toHaveBeenCalled();
+    expect(gate.getReasons('binance')).toEqual([]);
+  });
+
+  it('records reasons for exchanges without a running instance', async () => {
+    const gate = new AdapterPauseGate(registry);
+
+    expect(await gate.pause('okx', 'maintenance')).toBe(false);
+    expect(await gate.resume('okx', 'maintenance')).toBe(false);
+
+    expect(registry.stopInstance).not.toHaveBeenCalled();
+    expect(registry.startInstance).not.toHaveBeenCalled();
+  });
+});
+
+describe('AdapterPauseGate restart retries', () => {
+  let registry: PausableRegistry & { startInstance: jest.Mock; stopInstance: jest.Mock };
+
+  beforeEach(() => {
+    jest.useFakeTimers();
+    registry = {
+      getInstance: jest.fn(() => ({} as any)),
+      startInstance: jest.fn().mockResolvedValue(undefined),
+      stopInstance: jest.fn().mockResolvedValue(undefined)
+    };
+  });
+
+  afterEach(() => {
+    jest.useRealTimers();
+  });
+
+  it('surfaces a failed restart and retries with backoff until it succeeds', async () => {
+    const gate = new AdapterPauseGate(registry, () => true, 1000);
+    const failures: number[] = [];
+    const restarted = jest.fn();
+    gate.on('restartFailed', (_exchange, _error, attempt) => failures.push(attempt));
+    gate.on('restarted', restarted);
+    registry.startInstance
+      .mockRejectedValueOnce(new Error('connect ECONNREFUSED'))
+      .mockRejectedValueOnce(new Error('connect ECONNREFUSED'));
+
+    await gate.pause('binance', 'maintenance');
+    await expect(gate.resume('binance', 'maintenance')).rejects.toThrow('connect ECONNREFUSED');
+
+    await jest.advanceTimersByTimeAsync(1000);
+    expect(failures).toEqual([1]);
+
+    await jest.advanceTimersByTimeAsync(1999);
+    expect(registry.startInstance).toHaveBeenCalledTimes(2);
+    await jest.advanceTimersByTimeAsync(1);
+
+    expect(registry.startInstance).toHaveBeenCalledTimes(3);
+    expect(restarted).toHaveBeenCalledWith('binance', 2);
+  });
+
+  it('surfaces a failed stop and cancels a pending restart when paused again', async () => {
+    const gate = new AdapterPauseGate(registry, () => true, 1000);
+    registry.startInstance.mockRejectedValueOnce(new Error('connect ECONNREFUSED'));
+    await gate.pause('binance', 'maintenance');
+    await expect(gate.resume('binance', 'maintenance')).rejects.toThrow();
+
+    registry.stopInstance.mockRejectedValueOnce(new Error('stop failed'));
+    await expect(gate.pause('binance', 'fault:1')).rejects.toThrow('stop failed');
+    await jest.advanceTimersByTimeAsync(5000);
+
+    expect(registry.startInstance).toHaveBeenCalledTimes(1);
+    expect(gate.getReasons('binance')).toEqual(['fault:1']);
+  });
+});
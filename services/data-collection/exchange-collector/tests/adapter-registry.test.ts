@@ -5,6 +5,7 @@
 import { AdapterRegistry, AdapterRegistryConfig } from '../src/adapters/registry/adapter-registry';
 import { BaseErrorHandler, BaseMonitor, PubSubClientImpl, globalCache } from '@pixiu/shared-core';
 import { AdapterIntegration, IntegrationConfig } from '../src/adapters/base/adapter-integration';
+import { OkxAdapter } from '@pixiu/okx-adapter';
 
 // Mock classes
 class MockAdapterIntegration extends AdapterIntegration {
@@ -48,6 +49,7 @@ describe('AdapterRegistry', () => {
 
     mockMonitor = {
       log: jest.fn(),
+      child: jest.fn(() => mockMonitor),
       registerHealthCheck: jest.fn(),
       registerMetric: jest.fn(),
       updateMetric: jest.fn(),
@@ -120,6 +122,10 @@ describe('AdapterRegistry', () => {
 
       const registeredAdapters = adapterRegistry.getRegisteredAdapters();
       expect(registeredAdapters).toContain('binance');
+      expect(registeredAdapters).toContain('coinbase');
+      expect(registeredAdapters).toContain('okx');
+      expect(registeredAdapters).toContain('bybit');
+      expect(registeredAdapters).toContain('kraken');
     });
   });
 
@@ -178,6 +184,52 @@ describe('AdapterRegistry', () => {
     });
   });
 
+  describe('按需加载', () => {
+    const config: IntegrationConfig = {
+      adapterConfig: { exchange: 'test' },
+      publishConfig: {
+        topicPrefix: 'test',
+        enableBatching: false,
+        batchSize: 1,
+        batchTimeout: 1000
+      },
+      monitoringConfig: {
+        enableMetrics: true,
+        enableHealthCheck: true,
+        metricsInterval: 30000
+      }
+    };
+
+    beforeEach(async () => {
+      await adapterRegistry.initialize(
+        mockConfig,
+        mockPubsubClient,
+        mockMonitor,
+        mockErrorHandler
+      );
+    });
+
+    it('应该在首次创建实例时才加载适配器', async () => {
+      const load = jest.fn().mockResolvedValue(() => new MockAdapterIntegration());
+      adapterRegistry.registerLazy('lazy-adapter', load, { supportedFeatures: ['test'] });
+
+      expect(adapterRegistry.getRegistryEntry('lazy-adapter')?.supportedFeatures).toEqual(['test']);
+      expect(load).not.toHaveBeenCalled();
+
+      await adapterRegistry.createInstance('lazy-adapter', config);
+      expect(load).toHaveBeenCalledTimes(1);
+    });
+
+    it('未创建实例时应该通过describe查询能力', async () => {
+      const capabilities = { dataTypes: [], websocket: true } as any;
+      const describe = jest.fn().mockResolvedValue(capabilities);
+      adapterRegistry.registerLazy('lazy-adapter', jest.fn(), { describe });
+
+      expect(await adapterRegistry.describeAdapter('lazy-adapter')).toBe(capabilities);
+      expect(await adapterRegistry.describeAdapter('non-existent')).toBeUndefined();
+    });
+  });
+
   describe('实例管理', () => {
     beforeEach(async () => {
       await adapterRegistry.initialize(
@@ -459,4 +511,24 @@ describe('AdapterRegistry', () => {
       expect(status.runningInstances.length).toBe(0);
     });
   });
+
+  describe('订单簿', () => {
+    it('内置适配器实例应该提供适配器维护的订单簿', async () => {
+      await adapterRegistry.initialize(mockConfig, mockPubsubClient, mockMonitor, mockErrorHandler);
+      const book = {} as any;
+      const getOrderBook = jest.spyOn(OkxAdapter.prototype, 'getOrderBook').mockReturnValue(book);
+
+      await adapterRegistry.createInstance('okx', {
+        adapterConfig: {
+          exchange: 'okx',
+          endpoints: { ws: 'wss://ws.okx.com:8443/ws/v5/public' },
+          connection: { timeout: 1000, maxRetries: 0, retryInterval: 100, heartbeatInterval: 20000 }
+        }
+      } as any);
+
+      expect(adapterRegistry.getInstance('okx')!.getOrderBook('BTC/USDT')).toBe(book);
+      expect(getOrderBook).toHaveBeenCalledWith('BTC/USDT');
+      getOrderBook.mockRestore();
+    });
+  });
 });
\ No newline at end of file
@@ -0,0 +1,108 @@
+import request from 'supertest';
+import express from 'express';
+import { BaseMonitor } from '@pixiu/shared-core';
+import { AdapterRegistry } from '../../src/adapters/registry/adapter-registry';
+import { createMetricsRouter } from '../../src/api/metrics';
+
+describe('Metrics API', () => {
+  let app: express.Application;
+  let adapterRegistry: AdapterRegistry;
+  let monitor: BaseMonitor;
+
+  const integrationMetrics = {
+    messagesProcessed: 100,
+    messagesPublished: 95,
+    processingErrors: 1,
+    publishErrors: 4,
+    averageProcessingLatency: 2.5
+  };
+
+  const createInstance = (overrides: Record<string, any> = {}) => ({
+    getAdapterMetrics: jest.fn().mockReturnValue({
+      status: 'connected',
+      messagesReceived: 120,
+      messagesSent: 3,
+      errorCount: 2,
+      reconnectCount: 5,
+      averageLatency: 12,
+      dataQualityScore: 1,
+      lastHeartbeat: Date.now() - 3000,
+      ...overrides
+    })
+  });
+
+  beforeEach(() => {
+    monitor = {
+      log: jest.fn()
+    } as any;
+
+    const instances: Record<string, any> = {
+      binance: createInstance({ restRateLimit: { used: 1200, limit: 6000, updatedAt: Date.now() } }),
+      okx: createInstance()
+    };
+
+    adapterRegistry = {
+      getStatus: jest.fn().mockReturnValue({
+        initialized: true,
+        registeredAdapters: ['binance', 'okx'],
+        enabledAdapters: ['binance', 'okx'],
+        runningInstances: ['binance', 'okx'],
+        instanceStatuses: [
+          { name: 'binance', status: 'connected', healthy: true, metrics: integrationMetrics },
+          { name: 'okx', status: 'connected', healthy: false, metrics: integrationMetrics }
+        ]
+      }),
+      getInstance: jest.fn().mockImplementation((name: string) => instances[name])
+    } as any;
+
+    app = express();
+    app.use('/metrics', createMetricsRouter(adapterRegistry, monitor));
+  });
+
+  describe('GET /metrics', () => {
+    it('should output each metric family header once', async () => {
+      const response = await request(app)
+        .get('/metrics')
+        .expect(200);
+
+      const helpLines = response.text.split('\n').filter(line => line.startsWith('# HELP exchange_collector_adapter_healthy '));
+      expect(helpLines).toHaveLength(1);
+      expect(response.text).toContain('exchange_collector_adapter_healthy{exchange="binance"} 1');
+      expect(response.text).toContain('exchange_collector_adapter_healthy{exchange="okx"} 0');
+    });
+
+    it('should expose websocket reconnect and heartbeat metrics', async () => {
+      const response = await request(app)
+        .get('/metrics')
+        .expect(200);
+
+      expect(response.text).toContain('# TYPE exchange_collector_adapter_reconnects_total counter');
+      expect(response.text).toContain('exchange_collector_adapter_reconnects_total{exchange="binance"} 5');
+      expect(response.text).toContain('exchange_collector_adapter_messages_received_total{exchange="okx"} 120');
+      expect(response.text).toMatch(/exchange_collector_adapter_heartbeat_age_seconds\{exchange="binance"\} \d/);
+    });
+
+    it('should expose REST rate limit headroom only for adapters that report it', async () => {
+      const response = await request(app)
+        .get('/metrics')
+        .expect(200);
+
+      expect(response.text).toContain('exchange_collector_rest_rate_limit_used{exchange="binance"} 1200');
+      expect(response.text).toContain('exchange_collector_rest_rate_limit_headroom{exchange="binance"} 4800');
+      expect(response.text).not.toContain('exchange_collector_rest_rate_limit_headroom{exchange="okx"}');
+    });
+  });
+
+  describe('GET /metrics/json', () => {
+    it('should include adapter connection metrics', async () => {
+      const response = await request(app)
+        .get('/metrics/json')
+        .expect(200);
+
+      expect(response.body.adapters.instances[0].adapterMetrics).toMatchObject({
+        reconnectCount: 5,
+        restRateLimit: { used: 1200, limit: 6000 }
+      });
+    });
+  });
+});
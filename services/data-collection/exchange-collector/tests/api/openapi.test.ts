@@ -0,0 +1,69 @@
+import request from 'supertest';
+import express from 'express';
+import { createOpenApiDocument, createOpenApiRouter } from '../../src/api/openapi';
+
+describe('OpenAPI document', () => {
+  const collectRefs = (value: any, refs: string[] = []): string[] => {
+    if (value && typeof value === 'object') {
+      for (const [key, child] of Object.entries(value)) {
+        if (key === '$ref' && typeof child === 'string') {
+          refs.push(child);
+        } else {
+          collectRefs(child, refs);
+        }
+      }
+    }
+    return refs;
+  };
+
+  it('should describe every collector endpoint', () => {
+    const document = createOpenApiDocument('1.2.3');
+
+    expect(document.openapi).toBe('3.0.3');
+    expect(document.info.version).toBe('1.2.3');
+    expect(Object.keys(document.paths)).toEqual(expect.arrayContaining([
+      '/health',
+      '/metrics',
+      '/api/adapters',
+      '/api/adapters/{name}/restart',
+      '/api/subscriptions',
+      '/api/subscriptions/{exchange}/{symbol}',
+      '/api/stats/stream',
+      '/api/pubsub/toggle'
+    ]));
+  });
+
+  it('should only reference defined schemas', () => {
+    const document = createOpenApiDocument();
+    const schemaNames = Object.keys(document.components.schemas);
+
+    for (const ref of collectRefs(document)) {
+      expect(schemaNames).toContain(ref.replace('#/components/schemas/', ''));
+    }
+  });
+
+  it('should declare path parameters used in templated paths', () => {
+    const document = createOpenApiDocument();
+
+    for (const [path, operations] of Object.entries<any>(document.paths)) {
+      const templated = (path.match(/\{(\w+)\}/g) || []).map(name => name.slice(1, -1));
+      for (const operation of Object.values<any>(operations)) {
+        const declared = (operation.parameters || [])
+          .filter((parameter: any) => parameter.in === 'path')
+          .map((parameter: any) => parameter.name);
+        expect(declared.sort()).toEqual(templated.sort());
+        expect(Object.keys(operation.responses).length).toBeGreaterThan(0);
+      }
+    }
+  });
+
+  it('should serve the document as JSON', async () => {
+    const app = express();
+    app.use('/api/openapi.json', createOpenApiRouter());
+
+    const response = await request(app).get('/api/openapi.json');
+
+    expect(response.status).toBe(200);
+    expect(response.body.info.title).toBe('Pixiu Exchange Collector API');
+  });
+});
@@ -28,6 +28,7 @@ describe('BinanceIntegration', () => {
 
     mockMonitor = {
       log: jest.fn(),
+      child: jest.fn(() => mockMonitor),
       registerHealthCheck: jest.fn(),
       registerMetric: jest.fn(),
       updateMetric: jest.fn(),
@@ -0,0 +1,76 @@
+/**
+ * Fault injector tests
+ */
+
+import { DataType, MarketData } from '@pixiu/adapter-base';
+import { FaultInjector } from '../../src/chaos';
+
+describe('FaultInjector', () => {
+  const trade = (symbol: string): MarketData => ({
+    exchange: 'binance',
+    symbol,
+    type: DataType.TRADE,
+    timestamp: Date.now(),
+    receivedAt: Date.now(),
+    data: { price: 1 }
+  });
+
+  let injector: FaultInjector;
+
+  beforeEach(() => {
+    jest.useFakeTimers();
+    injector = new FaultInjector({ maxDuration: 60000 });
+  });
+
+  afterEach(() => {
+    injector.clearAll();
+    jest.useRealTimers();
+  });
+
+  it('drops market data for stale symbols only', () => {
+    injector.inject({ kind: 'stale', exchange: 'binance', symbols: ['btcusdt'], duration: 1000 });
+    const delivered: string[] = [];
+
+    injector.apply('binance', trade('BTCUSDT'), () => delivered.push('BTCUSDT'));
+    injector.apply('binance', trade('ETHUSDT'), () => delivered.push('ETHUSDT'));
+    injector.apply('okx', { ...trade('BTCUSDT'), exchange: 'okx' }, () => delivered.push('okx'));
+
+    expect(delivered).toEqual(['ETHUSDT', 'okx']);
+  });
+
+  it('delays market data by the largest matching latency', () => {
+    injector.inject({ kind: 'latency', exchange: 'binance', duration: 5000, delay: 100 });
+    injector.inject({ kind: 'latency', exchange: 'binance', duration: 5000, delay: 300 });
+    const deliver = jest.fn();
+
+    injector.apply('binance', trade('BTCUSDT'), deliver);
+    jest.advanceTimersByTime(299);
+    expect(deliver).not.toHaveBeenCalled();
+    jest.advanceTimersByTime(1);
+
+    expect(deliver).toHaveBeenCalledTimes(1);
+  });
+
+  it('clears faults when they expire', () => {
+    const cleared: string[] = [];
+    injector.on('cleared', (fault, reason) => cleared.push(`${fault.kind}:${reason}`));
+
+    injector.inject({ kind: 'outage', exchange: 'binance', duration: 1000 });
+    const stale = injector.inject({ kind: 'stale', exchange: 'binance', duration: 5000 });
+    jest.advanceTimersByTime(1000);
+
+    expect(cleared).toEqual(['outage:expired']);
+    expect(injector.list().map(fault => fault.id)).toEqual([stale.id]);
+    expect(injector.clear(stale.id)).toBe(true);
+    expect(cleared).toEqual(['outage:expired', 'stale:cleared']);
+  });
+
+  it('rejects invalid faults', () => {
+    expect(() => injector.inject({ kind: 'latency', exchange: 'binance', duration: 1000 }))
+      .toThrow('Latency faults require a positive delay');
+    expect(() => injector.inject({ kind: 'stale', exchange: 'binance', duration: 120000 }))
+      .toThrow('Fault duration must be between 1 and 60000 ms');
+    expect(() => injector.inject({ kind: 'outage', exchange: 'binance', symbols: ['BTCUSDT'], duration: 1000 }))
+      .toThrow('Outage faults apply to the whole exchange');
+  });
+});
@@ -90,6 +90,9 @@ describe('AdapterConfigFactory', () => {
       
       const okxConfig = AdapterConfigFactory.createDefaultConfig(AdapterType.OKEX);
       expect(okxConfig.config.endpoints.ws).toContain('okx');
+
+      const coinbaseConfig = AdapterConfigFactory.createDefaultConfig(AdapterType.COINBASE);
+      expect(coinbaseConfig.config.endpoints.ws).toContain('coinbase');
     });
   });
 });
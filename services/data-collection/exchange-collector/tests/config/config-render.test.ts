@@ -0,0 +1,58 @@
+/**
+ * Effective config rendering tests
+ */
+
+import { REDACTED, lookupProvenance, redactConfig, renderConfig } from '../../src/config/config-render';
+
+describe('redactConfig', () => {
+  it('hides plaintext secrets but keeps secret references', () => {
+    const redacted = redactConfig({
+      adapters: {
+        binance: { config: { auth: { apiKey: 'abc', apiSecret: 'def' } } },
+        coinbase: { config: { auth: { apiKey: 'aws-sm://pixiu/coinbase#apiKey', apiSecret: 'env://COINBASE_API_SECRET' } } }
+      },
+      storage: { clickhouse: { password: 'hunter2', url: 'http://clickhouse:8123' } }
+    });
+
+    expect(redacted.adapters.binance.config.auth).toEqual({ apiKey: REDACTED, apiSecret: REDACTED });
+    expect(redacted.adapters.coinbase.config.auth.apiKey).toBe('aws-sm://pixiu/coinbase#apiKey');
+    expect(redacted.storage.clickhouse).toEqual({ password: REDACTED, url: 'http://clickhouse:8123' });
+  });
+});
+
+describe('renderConfig', () => {
+  it('annotates every value with the layer that set it', () => {
+    const output = renderConfig(
+      {
+        service: { server: { port: 18000, host: '0.0.0.0' } },
+        adapters: { binance: { subscription: { symbols: ['BTCUSDT'] } } }
+      },
+      {
+        'service.server.port': '/etc/pixiu/config/hosts/collector-1.yaml',
+        'service.server.host': 'env:HOST',
+        'adapters.binance.subscription.symbols': '/etc/pixiu/config/production.yaml'
+      },
+      ['Environment: production']
+    );
+
+    expect(output).toBe([
+      '# Environment: production',
+      'service:',
+      '  server:',
+      '    port: 18000  # /etc/pixiu/config/hosts/collector-1.yaml',
+      '    host: "0.0.0.0"  # env:HOST',
+      'adapters:',
+      '  binance:',
+      '    subscription:',
+      '      symbols: ["BTCUSDT"]  # /etc/pixiu/config/production.yaml',
+      ''
+    ].join('\n'));
+  });
+
+  it('falls back to the nearest recorded parent, then to default', () => {
+    const provenance = { 'dataflow': '/etc/pixiu/config/default.yaml' };
+
+    expect(lookupProvenance(provenance, 'dataflow.batching.batchSize')).toBe('/etc/pixiu/config/default.yaml');
+    expect(lookupProvenance(provenance, 'business.enableDataPersistence')).toBe('default');
+  });
+});
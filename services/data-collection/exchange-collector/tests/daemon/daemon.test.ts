@@ -0,0 +1,100 @@
+import { mkdtempSync, rmSync, writeFileSync, readFileSync, existsSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { PidFile, SystemdNotifier } from '../../src/daemon';
+
+describe('PidFile', () => {
+  let dir: string;
+  let path: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'pixiu-pid-'));
+    path = join(dir, 'collector.pid');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('writes the current pid and removes it on release', () => {
+    const pidFile = new PidFile(path);
+
+    pidFile.acquire();
+    expect(readFileSync(path, 'utf-8')).toBe(`${process.pid}\n`);
+
+    pidFile.release();
+    expect(existsSync(path)).toBe(false);
+  });
+
+  it('refuses to start while another live process holds the file', () => {
+    // The parent process is always alive
+    writeFileSync(path, `${process.ppid}\n`);
+
+    expect(() => new PidFile(path).acquire()).toThrow(`already running (pid ${process.ppid}`);
+    expect(readFileSync(path, 'utf-8')).toBe(`${process.ppid}\n`);
+  });
+
+  it('replaces a stale pid file', () => {
+    writeFileSync(path, '999999999\n');
+
+    new PidFile(path).acquire();
+
+    expect(readFileSync(path, 'utf-8')).toBe(`${process.pid}\n`);
+  });
+
+  it('replaces an empty or unparseable pid file', () => {
+    for (const content of ['', 'not-a-pid\n']) {
+      writeFileSync(path, content);
+      const pidFile = new PidFile(path);
+
+      pidFile.acquire();
+
+      expect(readFileSync(path, 'utf-8')).toBe(`${process.pid}\n`);
+      pidFile.release();
+    }
+  });
+
+  it('does not remove a pid file taken over by another process', () => {
+    const pidFile = new PidFile(path);
+    pidFile.acquire();
+    writeFileSync(path, '12345\n');
+
+    pidFile.release();
+
+    expect(readFileSync(path, 'utf-8')).toBe('12345\n');
+  });
+});
+
+describe('SystemdNotifier', () => {
+  afterEach(() => {
+    jest.useRealTimers();
+  });
+
+  it('does nothing outside systemd', async () => {
+    const executor = jest.fn().mockResolvedValue(undefined);
+    const notifier = new SystemdNotifier({ socket: '', executor });
+
+    await notifier.ready();
+
+    expect(notifier.isEnabled()).toBe(false);
+    expect(executor).not.toHaveBeenCalled();
+  });
+
+  it('reports readiness and sends watchdog pings at half the timeout', async () => {
+    jest.useFakeTimers();
+    const executor = jest.fn().mockResolvedValue(undefined);
+    const notifier = new SystemdNotifier({ socket: '/run/systemd/notify', watchdogUsec: 10_000_000, executor });
+
+    await notifier.ready('running');
+    jest.advanceTimersByTime(10_000);
+    await notifier.stopping();
+    jest.advanceTimersByTime(10_000);
+
+    expect(executor.mock.calls.map(call => call[0])).toEqual([
+      [`--pid=${process.pid}`, 'READY=1', `MAINPID=${process.pid}`, 'STATUS=running'],
+      [`--pid=${process.pid}`, 'WATCHDOG=1'],
+      [`--pid=${process.pid}`, 'WATCHDOG=1'],
+      [`--pid=${process.pid}`, 'STOPPING=1']
+    ]);
+  });
+});
@@ -0,0 +1,107 @@
+/**
+ * Startup self-test tests
+ */
+
+import { InstrumentProvider } from '@pixiu/adapter-base';
+import { formatDoctorReport, runDoctor } from '../../src/doctor';
+
+describe('runDoctor', () => {
+  const originalFetch = global.fetch;
+  let serverTime: () => number;
+  let apiRestrictions: Record<string, unknown>;
+  let requested: string[];
+
+  const adapter = (overrides: any = {}) => ({
+    enabled: true,
+    config: {
+      endpoints: { ws: 'wss://stream.binance.com:9443/ws', rest: 'https://api.binance.com/api' },
+      ...overrides.config
+    },
+    subscription: { symbols: ['BTCUSDT', 'LUNAUSDT', 'FOOUSDT'], dataTypes: ['trade'], ...overrides.subscription }
+  });
+
+  const provider: InstrumentProvider = {
+    exchange: 'binance',
+    fetchInstruments: async () => [
+      { exchange: 'binance', symbol: 'BTC/USDT', exchangeSymbol: 'BTCUSDT', base: 'BTC', quote: 'USDT', type: 'spot', tickSize: 0.01, lotSize: 0.00001, minNotional: 5, active: true },
+      { exchange: 'binance', symbol: 'LUNA/USDT', exchangeSymbol: 'LUNAUSDT', base: 'LUNA', quote: 'USDT', type: 'spot', tickSize: 0.0001, lotSize: 0.01, active: false }
+    ]
+  };
+
+  const options = {
+    probeWebSocket: async () => 42,
+    instrumentProviders: { binance: () => provider }
+  };
+
+  beforeEach(() => {
+    serverTime = () => Date.now();
+    apiRestrictions = { enableReading: true, enableSpotAndMarginTrading: false, enableWithdrawals: false, ipRestrict: true };
+    requested = [];
+    global.fetch = jest.fn(async (input: any) => {
+      const url = String(input);
+      requested.push(url);
+      const body = url.includes('/v3/time') ? { serverTime: serverTime() } : apiRestrictions;
+      return { ok: true, status: 200, headers: new Headers(), json: async () => body } as any;
+    }) as any;
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  it('reports latency, clock, websocket and symbol checks for each enabled adapter', async () => {
+    const checks = await runDoctor({ adapters: { binance: adapter() } } as any, ['binance'], options);
+
+    expect(checks.map(check => [check.name, check.status])).toEqual([
+      ['rest', 'ok'],
+      ['clock', 'ok'],
+      ['websocket', 'ok'],
+      ['api-key', 'skip'],
+      ['symbols', 'ok'],
+      ['symbols', 'fail'],
+      ['symbols', 'fail']
+    ]);
+    expect(requested[0]).toBe('https://api.binance.com/api/v3/time');
+    expect(checks[4].message).toBe('BTCUSDT: tick 0.01, lot 0.00001, min notional 5 USDT');
+    expect(checks[5].message).toBe('LUNAUSDT is not trading');
+    expect(checks[6].message).toBe('FOOUSDT is not listed');
+  });
+
+  it('flags clock skew beyond the configured thresholds', async () => {
+    serverTime = () => Date.now() + 2000;
+    let [, clock] = await runDoctor({ adapters: { binance: adapter() } } as any, ['binance'], options);
+    expect(clock.status).toBe('warn');
+    expect(clock.message).toMatch(/^Local clock is 2\d{3} ms behind server time$/);
+
+    serverTime = () => Date.now() - 6000;
+    [, clock] = await runDoctor({ adapters: { binance: adapter() } } as any, ['binance'], options);
+    expect(clock.status).toBe('fail');
+    expect(clock.message).toContain('ahead of server time');
+  });
+
+  it('checks Binance API key permissions with a signed request', async () => {
+    const config = { adapters: { binance: adapter({ config: { auth: { apiKey: 'key', apiSecret: 'secret' } } }) } };
+
+    let apiKey = (await runDoctor(config as any, ['binance'], options)).find(check => check.name === 'api-key')!;
+    expect(apiKey.status).toBe('ok');
+    const url = new URL(requested.find(request => request.includes('apiRestrictions'))!);
+    expect(url.origin + url.pathname).toBe('https://api.binance.com/sapi/v1/account/apiRestrictions');
+    expect(url.searchParams.get('signature')).toMatch(/^[0-9a-f]{64}$/);
+
+    apiRestrictions.enableWithdrawals = true;
+    apiKey = (await runDoctor(config as any, ['binance'], options)).find(check => check.name === 'api-key')!;
+    expect(apiKey.status).toBe('warn');
+    expect(apiKey.message).toContain('withdrawal permission');
+  });
+
+  it('reports unreachable endpoints as failures', async () => {
+    global.fetch = jest.fn().mockRejectedValue(new Error('getaddrinfo ENOTFOUND api.binance.com')) as any;
+
+    const checks = await runDoctor({ adapters: { binance: adapter({ subscription: { symbols: [] } }) } } as any, ['binance'], {
+      probeWebSocket: async () => { throw new Error('Opening handshake has timed out'); }
+    });
+
+    expect(checks.filter(check => check.status === 'fail').map(check => check.name)).toEqual(['rest', 'websocket']);
+    expect(formatDoctorReport(checks)).toContain('2 check(s) failed, 0 warning(s)');
+  });
+});
@@ -0,0 +1,40 @@
+/**
+ * Connectivity probe tests
+ */
+
+import { BinanceRestContext } from '@pixiu/binance-adapter';
+import { fetchBinanceApiPermissions } from '../../src/doctor/probes';
+
+describe('fetchBinanceApiPermissions', () => {
+  const originalFetch = global.fetch;
+  const auth = { apiKey: 'key', apiSecret: 'secret' };
+  let requested: string[];
+
+  beforeEach(() => {
+    requested = [];
+    global.fetch = jest.fn(async (input: any) => {
+      const url = String(input);
+      requested.push(new URL(url).pathname);
+      const body = url.includes('/v3/time')
+        ? { serverTime: Date.now() }
+        : { enableReading: true, enableSpotAndMarginTrading: true, enableWithdrawals: false, ipRestrict: false };
+      return { ok: true, status: 200, headers: new Headers(), json: async () => body } as any;
+    }) as any;
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  it('reuses the shared signer and server clock across checks', async () => {
+    const context = new BinanceRestContext({ restUrl: 'https://api.binance.com/api' });
+
+    const first = await fetchBinanceApiPermissions(context.restUrl, auth, 1000, undefined, context);
+    await fetchBinanceApiPermissions(context.restUrl, auth, 1000, undefined, context);
+
+    expect(first).toEqual({ read: true, trade: true, withdraw: false, ipRestricted: false });
+    expect(requested).toEqual(['/api/v3/time', '/sapi/v1/account/apiRestrictions', '/sapi/v1/account/apiRestrictions']);
+    expect(context.clock.isCalibrated()).toBe(true);
+    context.destroy();
+  });
+});
@@ -0,0 +1,135 @@
+import { mkdtempSync, rmSync, writeFileSync, appendFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import * as zlib from 'zlib';
+import { gzipSync } from 'zlib';
+import { HistoricalDataSource, KlineData } from '@pixiu/adapter-base';
+import { HistoricalDownloader } from '../../src/history/historical-downloader';
+import { isZstdSupported } from '../../src/recording/format';
+import {
+  readHistoricalDataset,
+  inferDatasetFormat,
+  DatasetValidationError
+} from '../../src/history/dataset';
+
+const MINUTE = 60000;
+
+async function collect<T>(iterator: AsyncIterable<T>): Promise<T[]> {
+  const rows: T[] = [];
+  for await (const row of iterator) {
+    rows.push(row);
+  }
+  return rows;
+}
+
+describe('Historical dataset', () => {
+  let outputDir: string;
+
+  beforeEach(() => {
+    outputDir = mkdtempSync(join(tmpdir(), 'pixiu-dataset-'));
+  });
+
+  afterEach(() => {
+    rmSync(outputDir, { recursive: true, force: true });
+  });
+
+  describe('inferDatasetFormat', () => {
+    it('should infer format and compression from the file name', () => {
+      expect(inferDatasetFormat('a/klines.csv')).toEqual({ format: 'csv', compression: 'none' });
+      expect(inferDatasetFormat('a/trades.jsonl.gz')).toEqual({ format: 'jsonl', compression: 'gzip' });
+      expect(inferDatasetFormat('a/klines.csv.gz')).toEqual({ format: 'csv', compression: 'gzip' });
+      expect(inferDatasetFormat('a/trades.jsonl.zst')).toEqual({ format: 'jsonl', compression: 'zstd' });
+    });
+  });
+
+  describe('readHistoricalDataset', () => {
+    it('should stream back a gzip CSV written page by page by the downloader', async () => {
+      const klines: KlineData[] = Array.from({ length: 5 }, (_, i) => ({
+        open: 100, high: 101, low: 99, close: 100, volume: i,
+        openTime: i * MINUTE, closeTime: (i + 1) * MINUTE - 1, interval: '1m'
+      }));
+      const source: HistoricalDataSource = {
+        exchange: 'mock',
+        maxPageSize: 2,
+        fetchKlines: async query => klines.filter(kline => kline.openTime >= query.startTime).slice(0, query.limit),
+        fetchTrades: async () => []
+      };
+      const output = join(outputDir, 'klines.csv.gz');
+
+      await new HistoricalDownloader(source).download({
+        kind: 'kline',
+        symbol: 'BTCUSDT',
+        interval: '1m',
+        startTime: 0,
+        endTime: 4 * MINUTE,
+        output,
+        compression: 'gzip'
+      });
+
+      const rows = await collect(readHistoricalDataset(output, 'kline', { interval: '1m' }));
+      expect(rows).toEqual(klines);
+    });
+
+    it('should read JSONL trades', async () => {
+      const output = join(outputDir, 'trades.jsonl');
+      writeFileSync(output, [
+        JSON.stringify({ id: '1', price: 100, quantity: 1, side: 'buy', timestamp: 1000 }),
+        JSON.stringify({ id: '2', price: 101, quantity: 2, side: 'sell', timestamp: 2000 })
+      ].join('\n') + '\n');
+
+      const rows = await collect(readHistoricalDataset(output, 'trade'));
+      expect(rows.map(row => row.id)).toEqual(['1', '2']);
+      expect(rows[1].side).toBe('sell');
+    });
+
+    it('should reject an unexpected CSV header', async () => {
+      const output = join(outputDir, 'trades.csv');
+      writeFileSync(output, 'foo,bar\n1,2\n');
+
+      await expect(collect(readHistoricalDataset(output, 'trade'))).rejects.toBeInstanceOf(DatasetValidationError);
+    });
+
+    it('should report the line of an invalid row', async () => {
+      const output = join(outputDir, 'trades.csv');
+      writeFileSync(output, 'id,timestamp,price,quantity,side\n1,1000,100,1,buy\n2,2000,abc,1,sell\n');
+
+      await expect(collect(readHistoricalDataset(output, 'trade'))).rejects.toMatchObject({ line: 3 });
+    });
+
+    it('should reject rows that go back in time', async () => {
+      const output = join(outputDir, 'trades.csv.gz');
+      writeFileSync(output, gzipSync('id,timestamp,price,quantity,side\n1,2000,100,1,buy\n'));
+      appendFileSync(output, gzipSync('2,1000,100,1,sell\n'));
+
+      await expect(collect(readHistoricalDataset(output, 'trade'))).rejects.toThrow('earlier than previous row');
+    });
+
+    it('should reject a missing file instead of crashing', async () => {
+      await expect(collect(readHistoricalDataset(join(outputDir, 'missing.csv.gz'), 'trade')))
+        .rejects.toMatchObject({ code: 'ENOENT' });
+    });
+
+    it('should reject a truncated gzip file', async () => {
+      const output = join(outputDir, 'trades.csv.gz');
+      writeFileSync(output, gzipSync('id,timestamp,price,quantity,side\n1,1000,100,1,buy\n').subarray(0, 20));
+
+      await expect(collect(readHistoricalDataset(output, 'trade'))).rejects.toMatchObject({ code: 'Z_BUF_ERROR' });
+    });
+
+    it('should read zstd files when the runtime supports zstd', async () => {
+      const output = join(outputDir, 'trades.jsonl.zst');
+
+      if (!isZstdSupported()) {
+        writeFileSync(output, '');
+        await expect(collect(readHistoricalDataset(output, 'trade'))).rejects.toThrow('zstd compression requires Node.js 22.15');
+        return;
+      }
+
+      writeFileSync(output, (zlib as any).zstdCompressSync(
+        JSON.stringify({ id: '1', price: 100, quantity: 1, side: 'buy', timestamp: 1000 }) + '\n'
+      ));
+      const rows = await collect(readHistoricalDataset(output, 'trade'));
+      expect(rows.map(row => row.id)).toEqual(['1']);
+    });
+  });
+});
@@ -0,0 +1,229 @@
+import { mkdtempSync, readFileSync, rmSync, existsSync, writeFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { HistoricalDataSource, HistoricalKlineQuery, HistoricalTradeQuery, KlineData, TradeData } from '@pixiu/adapter-base';
+import { HistoricalDownloader } from '../../src/history/historical-downloader';
+
+const MINUTE = 60000;
+
+describe('HistoricalDownloader', () => {
+  let outputDir: string;
+  let source: HistoricalDataSource;
+  let klines: KlineData[];
+  let trades: TradeData[];
+
+  beforeEach(() => {
+    outputDir = mkdtempSync(join(tmpdir(), 'pixiu-history-'));
+
+    klines = Array.from({ length: 7 }, (_, i) => ({
+      open: 100 + i,
+      high: 101 + i,
+      low: 99 + i,
+      close: 100.5 + i,
+      volume: 10,
+      openTime: i * MINUTE,
+      closeTime: (i + 1) * MINUTE - 1,
+      interval: '1m'
+    }));
+
+    trades = Array.from({ length: 5 }, (_, i): TradeData => ({
+      id: `${100 + i}`,
+      price: 100 + i,
+      quantity: 1,
+      side: i % 2 === 0 ? 'buy' : 'sell',
+      timestamp: i * 1000
+    }));
+
+    source = {
+      exchange: 'mock',
+      maxPageSize: 3,
+      fetchKlines: jest.fn(async (query: HistoricalKlineQuery) => klines
+        .filter(kline => kline.openTime >= query.startTime && kline.openTime <= (query.endTime ?? Infinity))
+        .slice(0, query.limit)),
+      fetchTrades: jest.fn(async (query: HistoricalTradeQuery) => trades
+        .filter(trade => query.fromId !== undefined
+          ? parseInt(trade.id, 10) >= parseInt(query.fromId!, 10)
+          : trade.timestamp >= (query.startTime ?? 0))
+        .filter(trade => trade.timestamp <= (query.endTime ?? Infinity))
+        .slice(0, query.limit))
+    };
+  });
+
+  afterEach(() => {
+    rmSync(outputDir, { recursive: true, force: true });
+  });
+
+  describe('Klines', () => {
+    it('should paginate through the whole range and write CSV', async () => {
+      const output = join(outputDir, 'klines.csv');
+      const downloader = new HistoricalDownloader(source);
+
+      const result = await downloader.download({
+        kind: 'kline',
+        symbol: 'BTCUSDT',
+        interval: '1m',
+        startTime: 0,
+        endTime: 6 * MINUTE,
+        output
+      });
+
+      const lines = readFileSync(output, 'utf-8').trim().split('\n');
+      expect(lines[0]).toBe('open_time,close_time,open,high,low,close,volume');
+      expect(lines).toHaveLength(8);
+      expect(lines[1]).toBe('0,59999,100,101,99,100.5,10');
+      expect(result).toMatchObject({ rows: 7, totalRows: 7, pages: 3, resumed: false });
+      expect(existsSync(`${output}.checkpoint.json`)).toBe(false);
+    });
+
+    it('should stop at the end time', async () => {
+      const output = join(outputDir, 'klines.jsonl');
+      const downloader = new HistoricalDownloader(source);
+
+      await downloader.download({
+        kind: 'kline',
+        symbol: 'BTCUSDT',
+        interval: '1m',
+        startTime: MINUTE,
+        endTime: 2 * MINUTE,
+        output,
+        format: 'jsonl'
+      });
+
+      const rows = readFileSync(output, 'utf-8').trim().split('\n').map(line => JSON.parse(line));
+      expect(rows.map(row => row.openTime)).toEqual([MINUTE, 2 * MINUTE]);
+    });
+
+    it('should advance past a full page that is entirely filtered out', async () => {
+      source.fetchKlines = jest.fn(async (query: HistoricalKlineQuery) => klines
+        .filter(kline => kline.openTime >= query.startTime)
+        .slice(0, query.limit));
+      const downloader = new HistoricalDownloader(source);
+
+      const result = await downloader.download({
+        kind: 'kline',
+        symbol: 'BTCUSDT',
+        interval: '1m',
+        startTime: 0,
+        endTime: 2.5 * MINUTE,
+        output: join(outputDir, 'klines.csv')
+      });
+
+      expect(result).toMatchObject({ rows: 3, pages: 2 });
+    });
+
+    it('should fail instead of looping when a full page does not advance', async () => {
+      source.fetchKlines = jest.fn(async () => klines.slice(0, 3));
+      const downloader = new HistoricalDownloader(source);
+
+      await expect(downloader.download({
+        kind: 'kline',
+        symbol: 'BTCUSDT',
+        interval: '1m',
+        startTime: 3 * MINUTE,
+        endTime: 6 * MINUTE,
+        output: join(outputDir, 'klines.csv')
+      })).rejects.toThrow('did not advance');
+    });
+
+    it('should require an interval', async () => {
+      const downloader = new HistoricalDownloader(source);
+
+      await expect(downloader.download({
+        kind: 'kline',
+        symbol: 'BTCUSDT',
+        startTime: 0,
+        endTime: MINUTE,
+        output: join(outputDir, 'klines.csv')
+      })).rejects.toThrow('interval');
+    });
+  });
+
+  describe('Trades', () => {
+    it('should page by trade id after the first page', async () => {
+      const output = join(outputDir, 'trades.csv');
+      const downloader = new HistoricalDownloader(source);
+
+      const result = await downloader.download({
+        kind: 'trade',
+        symbol: 'BTCUSDT',
+        startTime: 0,
+        endTime: 10000,
+        output
+      });
+
+      const lines = readFileSync(output, 'utf-8').trim().split('\n');
+      expect(lines.slice(1).map(line => line.split(',')[0])).toEqual(['100', '101', '102', '103', '104']);
+      expect(result.rows).toBe(5);
+      expect((source.fetchTrades as jest.Mock).mock.calls[1][0]).toMatchObject({ fromId: '103' });
+    });
+  });
+
+  describe('Resume', () => {
+    it('should resume from the checkpoint after an interruption', async () => {
+      const output = join(outputDir, 'klines.csv');
+      const options = {
+        kind: 'kline' as const,
+        symbol: 'BTCUSDT',
+        interval: '1m',
+        startTime: 0,
+        endTime: 6 * MINUTE,
+        output
+      };
+
+      const fetchKlines = source.fetchKlines as jest.Mock;
+      const original = fetchKlines.getMockImplementation()!;
+      fetchKlines
+        .mockImplementationOnce(original)
+        .mockRejectedValueOnce(new Error('network error'));
+
+      await expect(new HistoricalDownloader(source).download(options)).rejects.toThrow('network error');
+      expect(existsSync(`${output}.checkpoint.json`)).toBe(true);
+
+      const result = await new HistoricalDownloader(source).download(options);
+
+      const lines = readFileSync(output, 'utf-8').trim().split('\n');
+      expect(lines).toHaveLength(8);
+      expect(result).toMatchObject({ rows: 4, totalRows: 7, resumed: true });
+      expect(fetchKlines.mock.calls[2][0].startTime).toBe(2 * MINUTE + 1);
+    });
+
+    it('should discard partially written data beyond the checkpoint', async () => {
+      const output = join(outputDir, 'klines.csv');
+      const options = {
+        kind: 'kline' as const,
+        symbol: 'BTCUSDT',
+        interval: '1m',
+        startTime: 0,
+        endTime: 6 * MINUTE,
+        output
+      };
+
+      const fetchKlines = source.fetchKlines as jest.Mock;
+      const original = fetchKlines.getMockImplementation()!;
+      fetchKlines
+        .mockImplementationOnce(original)
+        .mockRejectedValueOnce(new Error('network error'));
+
+      await expect(new HistoricalDownloader(source).download(options)).rejects.toThrow();
+      writeFileSync(output, readFileSync(output, 'utf-8') + '999,999,1,1,1,1,1\n');
+
+      await new HistoricalDownloader(source).download(options);
+
+      expect(readFileSync(output, 'utf-8')).not.toContain('999,999');
+    });
+
+    it('should reject a checkpoint from a different download', async () => {
+      const output = join(outputDir, 'klines.csv');
+      writeFileSync(`${output}.checkpoint.json`, JSON.stringify({ exchange: 'mock', kind: 'kline', symbol: 'ETHUSDT' }));
+
+      await expect(new HistoricalDownloader(source).download({
+        kind: 'kline',
+        symbol: 'BTCUSDT',
+        interval: '1m',
+        startTime: 0,
+        endTime: MINUTE,
+        output
+      })).rejects.toThrow('different download');
+    });
+  });
+});
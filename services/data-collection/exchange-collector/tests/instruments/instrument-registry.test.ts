@@ -0,0 +1,60 @@
+/**
+ * Instrument refresh wiring tests
+ */
+
+import { InstrumentInfo, InstrumentProvider } from '@pixiu/adapter-base';
+import { createInstrumentRegistry, INSTRUMENT_PROVIDERS } from '../../src/instruments';
+
+describe('createInstrumentRegistry', () => {
+  const listing = (exchange: string, exchangeSymbol: string): InstrumentInfo => ({
+    exchange, symbol: `${exchangeSymbol.slice(0, 3)}/USDT`, exchangeSymbol, base: exchangeSymbol.slice(0, 3), quote: 'USDT',
+    type: 'spot', tickSize: 0.01, lotSize: 0.001
+  });
+
+  const config = {
+    adapters: {
+      binance: { enabled: true, config: { endpoints: { ws: 'wss://stream.binance.com:9443/ws', rest: 'https://api.binance.com/api' } } },
+      okx: { enabled: true, config: { endpoints: { ws: 'wss://ws.okx.com:8443/ws/v5/public', rest: '' } } },
+      kraken: { enabled: true, config: { endpoints: { ws: 'wss://ws.kraken.com', rest: 'https://api.kraken.com' } } }
+    }
+  } as any;
+
+  it('registers providers for enabled exchanges that have a REST endpoint', async () => {
+    const restUrls: Record<string, string> = {};
+    const provider = (exchange: string) => (restUrl: string): InstrumentProvider => {
+      restUrls[exchange] = restUrl;
+      return { exchange, fetchInstruments: async () => [listing(exchange, 'BTCUSDT')] };
+    };
+
+    const registry = await createInstrumentRegistry(config, ['binance', 'okx', 'kraken'], {}, {
+      binance: provider('binance'),
+      okx: provider('okx')
+    });
+
+    expect(restUrls).toEqual({ binance: 'https://api.binance.com/api' });
+    expect(await registry.load('binance')).toHaveLength(1);
+    await expect(registry.load('okx')).rejects.toThrow('No instrument provider registered for okx');
+  });
+
+  it('loads the adapter package when the built-in provider is created', async () => {
+    const provider = await INSTRUMENT_PROVIDERS.binance('https://api.binance.com/api');
+
+    expect(provider.exchange).toBe('binance');
+    expect(typeof provider.fetchInstruments).toBe('function');
+  });
+
+  it('reports listings that match the watchlist after a refresh', async () => {
+    let instruments = [listing('binance', 'BTCUSDT')];
+    const registry = await createInstrumentRegistry(config, ['binance'], { refreshInterval: 60000 }, {
+      binance: () => ({ exchange: 'binance', fetchInstruments: async () => instruments })
+    });
+    const changes: string[][] = [];
+    registry.watch(['*USDT'], change => changes.push(change.listed.map(instrument => instrument.exchangeSymbol)));
+
+    await registry.load('binance');
+    instruments = [...instruments, listing('binance', 'WIFUSDT'), { ...listing('binance', 'WIFBTC'), quote: 'BTC', symbol: 'WIF/BTC' }];
+    await registry.load('binance', true);
+
+    expect(changes).toEqual([['WIFUSDT']]);
+  });
+});
@@ -0,0 +1,61 @@
+/**
+ * Microstructure feature stream tests
+ */
+
+import { DataType, MarketData, MicrostructureFeatures, OrderBook } from '@pixiu/adapter-base';
+import { MicrostructureStream } from '../../src/microstructure';
+
+describe('MicrostructureStream', () => {
+  let synced: boolean;
+  let published: Array<[string, MicrostructureFeatures]>;
+  let stream: MicrostructureStream;
+
+  const book = {
+    isSynced: () => synced,
+    toDepthData: () => ({ bids: [[100, 3]], asks: [[101, 1]], updateTime: 0 })
+  } as unknown as OrderBook;
+
+  const marketData = (type: DataType, timestamp: number, data: any = {}): MarketData => ({
+    exchange: 'binance',
+    symbol: 'BTC/USDT',
+    type,
+    timestamp,
+    receivedAt: timestamp,
+    data
+  });
+
+  beforeEach(() => {
+    synced = true;
+    published = [];
+    stream = new MicrostructureStream({ window: 5000, publishInterval: 1000 }, (adapter) => (adapter === 'binance' ? book : undefined));
+    stream.on('features', (adapter, features) => published.push([adapter, features]));
+  });
+
+  it('publishes features on the first update of a symbol', () => {
+    stream.handle('binance', marketData(DataType.TRADE, 0, { id: '1', price: 100, quantity: 2, side: 'buy', timestamp: 0 }));
+    stream.handle('binance', marketData(DataType.DEPTH, 0));
+
+    expect(published).toHaveLength(1);
+    expect(published[0][0]).toBe('binance');
+    expect(published[0][1]).toMatchObject({ symbol: 'BTC/USDT', tradeCount: 1, tradeFlowImbalance: 1 });
+    expect(stream.getFeatures('binance', 'BTC/USDT', 100)?.tradeCount).toBe(1);
+  });
+
+  it('throttles output per symbol', () => {
+    stream.handle('binance', marketData(DataType.DEPTH, 0));
+    stream.handle('binance', marketData(DataType.DEPTH, 500));
+    stream.handle('binance', marketData(DataType.DEPTH, 1000));
+
+    expect(published.map(([, features]) => features.timestamp)).toEqual([0, 1000]);
+    expect(published[1][1]).toMatchObject({ mid: 100.5, queueImbalance: 0.5 });
+  });
+
+  it('skips depth updates until the order book is synced', () => {
+    synced = false;
+    stream.handle('binance', marketData(DataType.DEPTH, 0));
+    stream.handle('okx', marketData(DataType.DEPTH, 0));
+    stream.handle('binance', marketData(DataType.TICKER, 0));
+
+    expect(published).toHaveLength(0);
+  });
+});
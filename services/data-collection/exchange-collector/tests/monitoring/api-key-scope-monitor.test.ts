@@ -0,0 +1,76 @@
+/**
+ * API key scope validation tests
+ */
+
+import { ApiKeyScopeMonitor, evaluateApiKeyScopes } from '../../src/monitoring/api-key-scope-monitor';
+
+describe('evaluateApiKeyScopes', () => {
+  const readOnly = { read: true, trade: false, withdraw: false, ipRestricted: true };
+
+  it('accepts a read-only key when trading is not required', () => {
+    expect(evaluateApiKeyScopes(readOnly, {})).toEqual([]);
+  });
+
+  it('reports under- and over-privileged keys', () => {
+    expect(evaluateApiKeyScopes(readOnly, { trade: true })).toEqual(['trade permission is required']);
+    expect(evaluateApiKeyScopes({ ...readOnly, trade: true }, { trade: false })).toEqual(['trade permission must be disabled']);
+    expect(evaluateApiKeyScopes({ ...readOnly, withdraw: true }, {})).toEqual(['withdraw permission must be disabled']);
+    expect(evaluateApiKeyScopes({ ...readOnly, withdraw: true }, { allowWithdraw: true })).toEqual([]);
+  });
+
+  it('only checks the IP allow-list when the exchange reports it', () => {
+    expect(evaluateApiKeyScopes({ ...readOnly, ipRestricted: false }, { requireIpRestriction: true }))
+      .toEqual(['API key is not restricted to an IP allow-list']);
+    expect(evaluateApiKeyScopes({ ...readOnly, ipRestricted: undefined }, { requireIpRestriction: true })).toEqual([]);
+  });
+});
+
+describe('ApiKeyScopeMonitor', () => {
+  const key = { apiKey: 'key', apiSecret: 'secret', restUrl: 'https://api.binance.com' };
+
+  it('applies per-exchange requirements over the global ones', async () => {
+    const monitor = new ApiKeyScopeMonitor({ binance: key }, {
+      trade: false,
+      exchanges: { binance: { trade: true } },
+      fetchers: { binance: async () => ({ read: true, trade: true, withdraw: false }) }
+    });
+
+    const [report] = await monitor.check();
+
+    expect(report).toMatchObject({ exchange: 'binance', status: 'ok', problems: [] });
+  });
+
+  it('reports unsupported exchanges and failed queries separately from violations', async () => {
+    const monitor = new ApiKeyScopeMonitor({ binance: key, okx: key }, {
+      fetchers: { binance: async () => { throw new Error('Invalid API-key'); } }
+    });
+
+    const reports = await monitor.check();
+
+    expect(reports).toEqual([
+      { exchange: 'binance', status: 'error', problems: ['permission query failed: Invalid API-key'] },
+      { exchange: 'okx', status: 'unsupported', problems: [] }
+    ]);
+  });
+
+  it('emits violations found by periodic checks', async () => {
+    jest.useFakeTimers();
+    let withdraw = false;
+    const monitor = new ApiKeyScopeMonitor({ binance: key }, {
+      checkInterval: 1000,
+      fetchers: { binance: async () => ({ read: true, trade: false, withdraw }) }
+    });
+    const events: string[] = [];
+    monitor.on('verified', exchange => events.push(`verified:${exchange}`));
+    monitor.on('violation', report => events.push(`violation:${report.problems.join(',')}`));
+
+    monitor.start();
+    await jest.advanceTimersByTimeAsync(1000);
+    withdraw = true;
+    await jest.advanceTimersByTimeAsync(1000);
+    monitor.stop();
+    jest.useRealTimers();
+
+    expect(events).toEqual(['verified:binance', 'violation:withdraw permission must be disabled']);
+  });
+});
@@ -0,0 +1,133 @@
+/**
+ * Exchange maintenance status monitor tests
+ */
+
+import { ExchangeStatusMonitor } from '../../src/monitoring/exchange-status-monitor';
+
+describe('ExchangeStatusMonitor', () => {
+  const originalFetch = global.fetch;
+  let binanceStatus: number;
+  let okxMaintenance: any[];
+
+  beforeEach(() => {
+    binanceStatus = 0;
+    okxMaintenance = [];
+    global.fetch = jest.fn(async (input: any) => {
+      const url = String(input);
+      const body = url.includes('binance')
+        ? { status: binanceStatus, msg: binanceStatus === 0 ? 'normal' : 'system_maintenance' }
+        : { code: '0', data: okxMaintenance };
+      return { ok: true, status: 200, json: async () => body };
+    }) as any;
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  it('only monitors exchanges with a known status endpoint', () => {
+    const monitor = new ExchangeStatusMonitor(['binance', 'bybit', 'okx']);
+
+    expect(monitor.getExchanges()).toEqual(['binance', 'okx']);
+  });
+
+  it('pauses on maintenance and resumes after consecutive healthy polls', async () => {
+    const monitor = new ExchangeStatusMonitor(['binance'], { confirmations: 2 });
+    const events: string[] = [];
+    monitor.on('paused', (exchange, message) => events.push(`paused:${exchange}:${message}`));
+    monitor.on('resumed', exchange => events.push(`resumed:${exchange}`));
+
+    await monitor.poll();
+    binanceStatus = 1;
+    await monitor.poll();
+    await monitor.poll();
+    expect(monitor.isPaused('binance')).toBe(true);
+
+    binanceStatus = 0;
+    await monitor.poll();
+    expect(monitor.isPaused('binance')).toBe(true);
+    await monitor.poll();
+
+    expect(monitor.isPaused('binance')).toBe(false);
+    expect(events).toEqual(['paused:binance:system_maintenance', 'resumed:binance']);
+  });
+
+  it('ignores OKX maintenance of services unrelated to trading', async () => {
+    const monitor = new ExchangeStatusMonitor(['okx']);
+
+    okxMaintenance = [{ title: 'Earn upgrade', state: 'ongoing', serviceType: '7' }];
+    await monitor.poll();
+    expect(monitor.getStatuses().okx.status).toBe('operational');
+
+    okxMaintenance = [{ title: 'Spot system upgrade', state: 'ongoing', serviceType: '5' }];
+    await monitor.poll();
+    expect(monitor.getStatuses().okx).toMatchObject({ status: 'maintenance', message: 'Spot system upgrade', paused: true });
+  });
+
+  it('pauses Kraken only for maintenance or offline', async () => {
+    let krakenStatus = 'cancel_only';
+    global.fetch = jest.fn(async () => ({ ok: true, status: 200, json: async () => ({ result: { status: krakenStatus } }) })) as any;
+    const monitor = new ExchangeStatusMonitor(['kraken']);
+
+    for (const status of ['cancel_only', 'post_only', 'limit_only']) {
+      krakenStatus = status;
+      await monitor.poll();
+      expect(monitor.isPaused('kraken')).toBe(false);
+    }
+
+    krakenStatus = 'offline';
+    await monitor.poll();
+    expect(monitor.getStatuses().kraken).toMatchObject({ status: 'maintenance', message: 'offline', paused: true });
+  });
+
+  it('follows the Coinbase Advanced Trade market data component', async () => {
+    let marketData = 'operational';
+    global.fetch = jest.fn(async (input: any) => {
+      expect(String(input)).toBe('https://status.coinbase.com/api/v2/components.json');
+      return {
+        ok: true,
+        status: 200,
+        json: async () => ({
+          components: [
+            { id: 'g1', name: 'Advanced Trade', group: true, status: 'partial_outage' },
+            { id: 'c1', name: 'Market Data', group_id: 'g1', status: marketData },
+            { id: 'c2', name: 'Order Placement', group_id: 'g1', status: 'major_outage' },
+            { id: 'c3', name: 'Coinbase Wallet', group_id: null, status: 'major_outage' }
+          ]
+        })
+      };
+    }) as any;
+    const monitor = new ExchangeStatusMonitor(['coinbase']);
+
+    await monitor.poll();
+    expect(monitor.getStatuses().coinbase).toMatchObject({ status: 'operational', paused: false });
+
+    marketData = 'major_outage';
+    await monitor.poll();
+    expect(monitor.getStatuses().coinbase).toMatchObject({ status: 'maintenance', message: 'major_outage', paused: true });
+  });
+
+  it('does not pause Coinbase when the market data component is missing', async () => {
+    global.fetch = jest.fn(async () => ({ ok: true, status: 200, json: async () => ({ components: [] }) })) as any;
+    const monitor = new ExchangeStatusMonitor(['coinbase']);
+    const errors: Error[] = [];
+    monitor.on('error', (_exchange, error) => errors.push(error));
+
+    await monitor.poll();
+
+    expect(errors[0].message).toContain('Advanced Trade market data');
+    expect(monitor.isPaused('coinbase')).toBe(false);
+  });
+
+  it('does not pause when the status endpoint is unreachable', async () => {
+    global.fetch = jest.fn().mockRejectedValue(new Error('ECONNREFUSED')) as any;
+    const monitor = new ExchangeStatusMonitor(['binance']);
+    const errors: Error[] = [];
+    monitor.on('error', (_exchange, error) => errors.push(error));
+
+    await monitor.poll();
+
+    expect(errors[0].message).toBe('ECONNREFUSED');
+    expect(monitor.getStatuses().binance).toMatchObject({ status: 'unknown', paused: false });
+  });
+});
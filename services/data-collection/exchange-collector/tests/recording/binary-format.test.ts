@@ -0,0 +1,165 @@
+/**
+ * Binary recording format and converter tests
+ */
+
+import { mkdtempSync, readdirSync, rmSync, statSync, writeFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import {
+  BinaryRecordingDecoder,
+  BinaryRecordingEncoder,
+  MarketDataRecorder,
+  RecordedEvent,
+  convertRecording,
+  listRecordings,
+  readRecording,
+  wallClockNanos
+} from '../../src/recording';
+
+describe('binary recording format', () => {
+  let directory: string;
+
+  const trade = (t: number, price: number, extra: Record<string, unknown> = {}): RecordedEvent => ({
+    t,
+    adapter: 'binance',
+    data: {
+      exchange: 'binance',
+      symbol: 'BTCUSDT',
+      type: 'trade',
+      timestamp: t - 3,
+      data: { id: String(4000000000 + t), price, quantity: 0.00123, side: 'buy', timestamp: t - 3 },
+      receivedAt: t - 1,
+      ...extra
+    } as any
+  });
+
+  const depth = (t: number): RecordedEvent => ({
+    t,
+    adapter: 'binance',
+    data: {
+      exchange: 'binance',
+      symbol: 'ETHUSDT',
+      type: 'depth',
+      timestamp: t,
+      data: {
+        bids: [[3000.12, 1.5], [3000.11, 0.035], [2999.9, 12]],
+        asks: [[3000.13, 0.2], [3000.5, 7.25]],
+        updateTime: t,
+        firstUpdateId: 1001,
+        finalUpdateId: 1009
+      },
+      receivedAt: t
+    } as any
+  });
+
+  const roundTrip = (events: RecordedEvent[], chunkSize?: number): RecordedEvent[] => {
+    const encoder = new BinaryRecordingEncoder();
+    const bytes = Buffer.concat([encoder.header(), ...events.map(event => encoder.encode(event))]);
+    const decoder = new BinaryRecordingDecoder();
+    if (!chunkSize) {
+      return decoder.push(bytes);
+    }
+    const decoded: RecordedEvent[] = [];
+    for (let offset = 0; offset < bytes.length; offset += chunkSize) {
+      decoded.push(...decoder.push(bytes.subarray(offset, offset + chunkSize)));
+    }
+    return decoded;
+  };
+
+  const collect = async (path: string): Promise<RecordedEvent[]> => {
+    const events: RecordedEvent[] = [];
+    for (const file of await listRecordings(path)) {
+      for await (const event of readRecording(file)) {
+        events.push(event);
+      }
+    }
+    return events;
+  };
+
+  beforeEach(() => {
+    directory = mkdtempSync(join(tmpdir(), 'pixiu-binary-recording-'));
+  });
+
+  afterEach(() => {
+    rmSync(directory, { recursive: true, force: true });
+  });
+
+  it('round-trips market data exactly, across channels and chunk boundaries', () => {
+    const events = [
+      trade(1700000000000, 42000.12),
+      { ...depth(1700000000001), tn: 250 },
+      trade(1700000000005, 41999.99, { latency: 4 }),
+      trade(1700000000002, 0.1 + 0.2, { note: { nested: [null, true, -0.5, 'x'.repeat(64)] } }),
+      { ...depth(1700000000010), tn: 999999 }
+    ];
+
+    expect(roundTrip(events)).toEqual(events);
+    expect(roundTrip(events, 3)).toEqual(events);
+  });
+
+  it('is much smaller than JSON lines for typical trades and depth updates', () => {
+    const events: RecordedEvent[] = [];
+    for (let i = 0; i < 1000; i++) {
+      events.push(trade(1700000000000 + i * 7, 42000 + (i % 50) / 100));
+      events.push(depth(1700000000000 + i * 7 + 3));
+    }
+
+    const encoder = new BinaryRecordingEncoder();
+    const binaryBytes = events.reduce((total, event) => total + encoder.encode(event).length, 0);
+    const jsonBytes = events.reduce((total, event) => total + JSON.stringify(event).length + 1, 0);
+
+    expect(binaryBytes * 5).toBeLessThan(jsonBytes);
+  });
+
+  it('records nanosecond timestamps and ignores a truncated final frame', async () => {
+    const recorder = new MarketDataRecorder({ directory, format: 'binary', compression: 'none' });
+    await recorder.record('binance', trade(0, 1).data);
+    await recorder.record('binance', trade(0, 2).data);
+    await recorder.close();
+
+    const [file] = await listRecordings(directory);
+    expect(file).toMatch(/\.pxb$/);
+    const events = await collect(file);
+    expect(events).toHaveLength(2);
+    expect(Math.abs(events[0].t - Date.now())).toBeLessThan(5000);
+    expect(events[1].t * 1e6 + (events[1].tn ?? 0)).toBeGreaterThanOrEqual(events[0].t * 1e6 + (events[0].tn ?? 0));
+
+    const encoder = new BinaryRecordingEncoder();
+    const bytes = Buffer.concat([encoder.header(), encoder.encode(trade(1, 1)), encoder.encode(trade(2, 2))]);
+    writeFileSync(join(directory, 'truncated.pxb'), bytes.subarray(0, bytes.length - 4));
+    expect(await collect(join(directory, 'truncated.pxb'))).toEqual([trade(1, 1)]);
+  });
+
+  it('re-anchors nanosecond timestamps when the system clock is adjusted', () => {
+    const now = Date.now();
+    const clock = jest.spyOn(Date, 'now').mockReturnValue(now + 3600000);
+
+    const adjusted = Number(wallClockNanos() / 1_000_000n);
+    clock.mockRestore();
+    const restored = Number(wallClockNanos() / 1_000_000n);
+
+    expect(adjusted).toBe(now + 3600000);
+    expect(Math.abs(restored - Date.now())).toBeLessThanOrEqual(1);
+  });
+
+  it('converts JSON lines recordings to binary and back', async () => {
+    const source = join(directory, 'jsonl');
+    const recorder = new MarketDataRecorder({ directory: source, compression: 'gzip' });
+    for (let i = 0; i < 20; i++) {
+      const event = i % 2 ? depth(1000 + i) : trade(1000 + i, 100 + i);
+      await recorder.record(event.adapter, event.data, event.t);
+    }
+    await recorder.close();
+
+    const binary = await convertRecording(source, join(directory, 'binary'), { format: 'binary', compression: 'gzip' });
+    expect(binary.events).toBe(20);
+    expect(readdirSync(join(directory, 'binary'))[0]).toMatch(/^market-data-.*\.pxb\.gz$/);
+    expect(binary.files[0].outputBytes).toBeLessThan(binary.files[0].inputBytes);
+
+    const restored = join(directory, 'restored.jsonl');
+    await convertRecording(binary.files[0].output, restored, { format: 'jsonl', compression: 'none' });
+
+    expect(await collect(restored)).toEqual(await collect(source));
+    expect(statSync(restored).size).toBeGreaterThan(0);
+  });
+});
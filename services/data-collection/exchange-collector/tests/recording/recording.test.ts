@@ -0,0 +1,169 @@
+/**
+ * Market data recorder and replayer tests
+ */
+
+import { mkdirSync, mkdtempSync, readdirSync, rmSync, readFileSync, writeFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { gzipSync } from 'zlib';
+import { MarketData } from '@pixiu/adapter-base';
+import {
+  MarketDataRecorder,
+  MarketDataReplayer,
+  RecordedEvent,
+  listRecordings,
+  readRecording
+} from '../../src/recording';
+
+describe('market data recording', () => {
+  let directory: string;
+
+  const trade = (symbol: string, price: number): MarketData => ({
+    exchange: 'binance',
+    symbol,
+    type: 'trade',
+    timestamp: 1700000000000,
+    receivedAt: 1700000000001,
+    data: { id: String(price), price, quantity: 1, side: 'buy', timestamp: 1700000000000 }
+  } as any);
+
+  const collect = async (path: string): Promise<RecordedEvent[]> => {
+    const events: RecordedEvent[] = [];
+    for (const file of await listRecordings(path)) {
+      for await (const event of readRecording(file)) {
+        events.push(event);
+      }
+    }
+    return events;
+  };
+
+  beforeEach(() => {
+    directory = mkdtempSync(join(tmpdir(), 'pixiu-recording-'));
+  });
+
+  afterEach(() => {
+    rmSync(directory, { recursive: true, force: true });
+  });
+
+  it('writes matching streams and reads them back', async () => {
+    const recorder = new MarketDataRecorder({
+      directory,
+      compression: 'gzip',
+      streams: [{ exchange: 'binance', symbol: 'BTCUSDT', types: ['trade'] }]
+    });
+
+    await recorder.record('binance', trade('BTCUSDT', 100), 1000);
+    await recorder.record('binance', trade('ETHUSDT', 10), 1001);
+    await recorder.record('binance', { ...trade('BTCUSDT', 101), type: 'ticker' } as any, 1002);
+    await recorder.close();
+
+    const events = await collect(directory);
+    expect(events).toHaveLength(1);
+    expect(events[0]).toMatchObject({ t: 1000, adapter: 'binance', data: { symbol: 'BTCUSDT', data: { price: 100 } } });
+    expect(readdirSync(directory)[0]).toMatch(/^market-data-19700101T000001000Z\.jsonl\.gz$/);
+  });
+
+  it('rotates files by size and by time', async () => {
+    const lineBytes = JSON.stringify({ t: 0, adapter: 'binance', data: trade('BTCUSDT', 1) }).length + 1;
+    const recorder = new MarketDataRecorder({ directory, compression: 'none', rotateBytes: lineBytes + 1, rotateInterval: 60000 });
+    const rotated: string[] = [];
+    recorder.on('rotated', path => rotated.push(path));
+
+    await recorder.record('binance', trade('BTCUSDT', 1), 0);
+    await recorder.record('binance', trade('BTCUSDT', 2), 1);
+    await recorder.record('binance', trade('BTCUSDT', 3), 2);
+    await recorder.record('binance', trade('BTCUSDT', 4), 120000);
+    await recorder.close();
+
+    expect(rotated).toHaveLength(3);
+    expect(await listRecordings(directory)).toHaveLength(3);
+    expect((await collect(directory)).map(event => event.data.data)).toEqual([
+      expect.objectContaining({ price: 1 }),
+      expect.objectContaining({ price: 2 }),
+      expect.objectContaining({ price: 3 }),
+      expect.objectContaining({ price: 4 })
+    ]);
+  });
+
+  it('ignores a truncated final line', async () => {
+    const lines = [
+      JSON.stringify({ t: 1, adapter: 'binance', data: trade('BTCUSDT', 1) }),
+      JSON.stringify({ t: 2, adapter: 'binance', data: trade('BTCUSDT', 2) }).slice(0, 30)
+    ];
+    writeFileSync(join(directory, 'partial.jsonl'), lines.join('\n'));
+    const compressed = gzipSync(lines.join('\n') + '\n');
+    // Writer killed before the gzip trailer was written
+    writeFileSync(join(directory, 'truncated.jsonl.gz'), compressed.subarray(0, compressed.length - 8));
+
+    expect(await collect(join(directory, 'partial.jsonl'))).toHaveLength(1);
+    expect(await collect(join(directory, 'truncated.jsonl.gz'))).toHaveLength(1);
+  });
+
+  it('replays at the recorded pace scaled by speed', async () => {
+    const recorder = new MarketDataRecorder({ directory, compression: 'gzip' });
+    await recorder.record('binance', trade('BTCUSDT', 1), 10000);
+    await recorder.record('binance', trade('BTCUSDT', 2), 10100);
+    await recorder.record('binance', trade('BTCUSDT', 3), 10200);
+    await recorder.close();
+
+    const replayer = new MarketDataReplayer();
+    const published: Array<{ price: number; at: number }> = [];
+    const started = Date.now();
+    const result = await replayer.replay(await listRecordings(directory), event => {
+      published.push({ price: (event.data.data as any).price, at: Date.now() - started });
+    }, { speed: 2 });
+
+    expect(result).toMatchObject({ events: 3, files: 1, startTime: 10000, endTime: 10200, stopped: false });
+    expect(published.map(event => event.price)).toEqual([1, 2, 3]);
+    expect(published[2].at).toBeGreaterThanOrEqual(90);
+  });
+
+  it('applies the time window and can be stopped', async () => {
+    const recorder = new MarketDataRecorder({ directory, compression: 'none' });
+    for (let i = 0; i < 5; i++) {
+      await recorder.record('binance', trade('BTCUSDT', i), i * 1000);
+    }
+    await recorder.close();
+    const files = await listRecordings(directory);
+
+    const windowed: number[] = [];
+    await new MarketDataReplayer().replay(files, event => {
+      windowed.push(event.t);
+    }, { speed: 0, from: 1000, to: 3000 });
+    expect(windowed).toEqual([1000, 2000, 3000]);
+
+    const replayer = new MarketDataReplayer();
+    const stopped: number[] = [];
+    const result = await replayer.replay(files, event => {
+      stopped.push(event.t);
+      if (stopped.length === 2) {
+        replayer.stop();
+      }
+    }, { speed: 1 });
+    expect(result).toMatchObject({ events: 2, stopped: true });
+    expect(readFileSync(files[0], 'utf-8').split('\n').filter(Boolean)).toHaveLength(5);
+  });
+
+  it('propagates read and decompression errors to the reader', async () => {
+    writeFileSync(join(directory, 'corrupt.jsonl.gz'), 'not gzip at all');
+    mkdirSync(join(directory, 'unreadable.pxb.gz'));
+
+    await expect(collect(join(directory, 'corrupt.jsonl.gz'))).rejects.toMatchObject({ code: 'Z_DATA_ERROR' });
+    const read = async () => {
+      for await (const _event of readRecording(join(directory, 'unreadable.pxb.gz'))) {
+        // drain
+      }
+    };
+    await expect(read()).rejects.toMatchObject({ code: 'EISDIR' });
+  });
+
+  it('rejects zstd when the runtime does not support it', () => {
+    const zlib = require('zlib');
+    if (typeof zlib.createZstdCompress === 'function') {
+      expect(new MarketDataRecorder({ directory, compression: 'zstd' })).toBeDefined();
+    } else {
+      expect(() => new MarketDataRecorder({ directory, compression: 'zstd' })).toThrow('zstd compression requires Node.js 22.15');
+      expect(new MarketDataRecorder({ directory }).getStats()).toMatchObject({ records: 0 });
+    }
+  });
+});
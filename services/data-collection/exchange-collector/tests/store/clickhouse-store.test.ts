@@ -0,0 +1,108 @@
+/**
+ * ClickHouse store tests
+ */
+
+import { MarketData } from '@pixiu/adapter-base';
+import { ClickHouseClient, ClickHouseMarketDataStore, migrate } from '../../src/store/clickhouse';
+
+describe('ClickHouse store', () => {
+  const originalFetch = global.fetch;
+  let requests: Array<{ url: URL; body: string; headers: Record<string, string> }>;
+  let respond: (url: URL, body: string) => { status: number; body: string };
+
+  const trade = (id: number): MarketData => ({
+    exchange: 'binance',
+    symbol: 'BTCUSDT',
+    type: 'trade',
+    timestamp: 1700000000000 + id,
+    receivedAt: 1700000000100 + id,
+    data: { id: String(id), price: 100 + id, quantity: 1, side: 'buy', timestamp: 1700000000000 + id }
+  } as any);
+
+  const kline: MarketData = {
+    exchange: 'okx',
+    symbol: 'BTC-USDT',
+    type: 'kline_1m',
+    timestamp: 1700000000000,
+    receivedAt: 1700000000500,
+    data: { open: 1, high: 2, low: 0.5, close: 1.5, volume: 10, openTime: 1700000000000, closeTime: 1700000059999, interval: '1m' }
+  } as any;
+
+  beforeEach(() => {
+    requests = [];
+    respond = () => ({ status: 200, body: '' });
+    global.fetch = jest.fn(async (input: any, init: any) => {
+      const url = new URL(input);
+      requests.push({ url, body: init.body, headers: init.headers });
+      const { status, body } = respond(url, init.body);
+      return { ok: status === 200, status, text: async () => body } as any;
+    }) as any;
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  it('applies only migrations that have not run yet', async () => {
+    const client = new ClickHouseClient({ url: 'http://clickhouse:8123/', database: 'market', username: 'pixiu', password: 'secret' });
+    respond = (_url, body) => ({ status: 200, body: body.startsWith('SELECT version') ? '{"version":1}\n' : '' });
+
+    const executed = await migrate(client, [
+      { version: 1, name: 'first', statements: ['CREATE TABLE a (x UInt8) ENGINE = Memory'] },
+      { version: 2, name: 'second', statements: ['CREATE TABLE b (x UInt8) ENGINE = Memory'] }
+    ]);
+
+    expect(executed).toEqual([2]);
+    expect(requests[0].url.searchParams.has('database')).toBe(false);
+    expect(requests[0].body).toBe('CREATE DATABASE IF NOT EXISTS market');
+    expect(requests[1].url.searchParams.get('database')).toBe('market');
+    expect(requests[1].headers).toMatchObject({ 'X-ClickHouse-User': 'pixiu', 'X-ClickHouse-Key': 'secret' });
+    const statements = requests.map(request => request.url.searchParams.get('query') ?? request.body);
+    expect(statements).not.toContain('CREATE TABLE a (x UInt8) ENGINE = Memory');
+    expect(statements).toContain('CREATE TABLE b (x UInt8) ENGINE = Memory');
+    expect(statements[statements.length - 1]).toBe('INSERT INTO schema_migrations FORMAT JSONEachRow');
+  });
+
+  it('batches rows per table as JSONEachRow', async () => {
+    const store = new ClickHouseMarketDataStore({ url: 'http://clickhouse:8123', batchSize: 2, types: ['trade', 'kline'] });
+
+    store.write(trade(1));
+    store.write(kline);
+    store.write({ ...trade(9), type: 'depth' } as any);
+    expect(requests).toHaveLength(0);
+
+    store.write(trade(2));
+    await store.flush();
+
+    const inserts = requests.map(request => ({
+      query: request.url.searchParams.get('query'),
+      rows: request.body.split('\n').map(line => JSON.parse(line))
+    }));
+    expect(inserts).toEqual([
+      { query: 'INSERT INTO trades FORMAT JSONEachRow', rows: [expect.objectContaining({ id: '1', price: 101, side: 'buy' }), expect.objectContaining({ id: '2' })] },
+      { query: 'INSERT INTO klines FORMAT JSONEachRow', rows: [expect.objectContaining({ interval: '1m', open_time: 1700000000000, received_at: 1700000000500 })] }
+    ]);
+    expect(store.getStats().inserted).toEqual({ trade: 2, kline: 1, ticker: 0 });
+  });
+
+  it('keeps failed batches for the next flush and bounds the buffer', async () => {
+    const store = new ClickHouseMarketDataStore({ url: 'http://clickhouse:8123', batchSize: 100, maxBufferSize: 3 });
+    const errors: Error[] = [];
+    store.on('error', error => errors.push(error));
+    respond = () => ({ status: 503, body: 'Code: 241. DB::Exception: Memory limit exceeded' });
+
+    for (let i = 1; i <= 4; i++) {
+      store.write(trade(i));
+    }
+    await expect(store.flush()).rejects.toThrow('HTTP 503 Code: 241');
+    expect(errors).toHaveLength(1);
+    expect(store.getStats()).toMatchObject({ buffered: { trade: 3 }, dropped: 1, failures: 1 });
+
+    respond = () => ({ status: 200, body: '' });
+    await store.close();
+
+    const last = requests[requests.length - 1];
+    expect(last.body.split('\n').map(line => JSON.parse(line).id)).toEqual(['2', '3', '4']);
+    expect(store.getStats().buffered.trade).toBe(0);
+  });
+});
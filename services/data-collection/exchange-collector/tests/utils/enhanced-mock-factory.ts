@@ -265,8 +265,9 @@ export class EnhancedMockFactory {
    * 创建BaseMonitor Mock
    */
   static createBaseMonitorMock(): jest.Mocked<BaseMonitor> {
-    return {
+    const mockMonitor: any = {
       log: jest.fn(),
+      child: jest.fn(() => mockMonitor),
       error: jest.fn(),
       warn: jest.fn(),
       info: jest.fn(),
@@ -276,7 +277,8 @@ export class EnhancedMockFactory {
       recordMetric: jest.fn(),
       getMetrics: jest.fn(() => ({})),
       createChildMonitor: jest.fn(() => EnhancedMockFactory.createBaseMonitorMock())
-    } as any;
+    };
+    return mockMonitor;
   }
 
   /**
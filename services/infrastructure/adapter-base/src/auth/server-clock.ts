@@ -0,0 +1,120 @@
+/**
+ * 交易所服务器时钟
+ * 定期探测服务器时间，以指数加权平均估算本地时钟偏差，为签名请求提供对齐后的时间戳
+ */
+
+import { EventEmitter } from 'events';
+
+export interface ServerClockOptions {
+  /** 获取服务器时间（毫秒） */
+  fetchServerTime: () => Promise<number>;
+  /** 校准间隔（毫秒），默认5分钟，0表示只手动校准 */
+  interval?: number;
+  /** 新样本的权重，默认0.3 */
+  alpha?: number;
+  /** 往返时间超过该值的样本误差过大，直接丢弃，默认2000毫秒 */
+  maxRoundTrip?: number;
+}
+
+/**
+ * 服务器时钟
+ *
+ * 事件：
+ * - calibrated(offset, roundTrip) 完成一次校准
+ * - error(error) 定时校准失败，沿用上一次的偏差
+ */
+export class ServerClock extends EventEmitter {
+  private readonly interval: number;
+  private readonly alpha: number;
+  private readonly maxRoundTrip: number;
+  private offset = 0;
+  private samples = 0;
+  private timer?: NodeJS.Timeout;
+  private pending?: Promise<number>;
+
+  constructor(private readonly options: ServerClockOptions) {
+    super();
+    this.interval = options.interval ?? 5 * 60 * 1000;
+    this.alpha = options.alpha ?? 0.3;
+    this.maxRoundTrip = options.maxRoundTrip ?? 2000;
+  }
+
+  /**
+   * 对齐服务器后的当前时间
+   */
+  now(): number {
+    return Math.round(Date.now() + this.offset);
+  }
+
+  /**
+   * 服务器时间减去本地时间（毫秒）
+   */
+  getOffset(): number {
+    return this.offset;
+  }
+
+  isCalibrated(): boolean {
+    return this.samples > 0;
+  }
+
+  /**
+   * 探测一次服务器时间并更新偏差，并发调用共享同一次探测
+   * reset为true时丢弃历史样本，用于时间戳被交易所拒绝后立即修正
+   */
+  calibrate(reset = false): Promise<number> {
+    if (!this.pending) {
+      this.pending = this.sample(reset).finally(() => {
+        this.pending = undefined;
+      });
+    }
+    return this.pending;
+  }
+
+  /**
+   * 开始定时校准并立即校准一次
+   * 首次校准失败时仍保持定时校准，错误交由调用方处理
+   */
+  async start(): Promise<void> {
+    if (this.interval > 0 && !this.timer) {
+      this.timer = setInterval(() => {
+        this.calibrate().catch(error => {
+          if (this.listenerCount('error') > 0) {
+            this.emit('error', error);
+          }
+        });
+      }, this.interval);
+      this.timer.unref?.();
+    }
+    await this.calibrate();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = undefined;
+    }
+  }
+
+  private async sample(reset: boolean): Promise<number> {
+    const sentAt = Date.now();
+    const serverTime = await this.options.fetchServerTime();
+    const roundTrip = Date.now() - sentAt;
+
+    if (roundTrip > this.maxRoundTrip) {
+      throw new Error(`Server time probe took ${roundTrip} ms, above the ${this.maxRoundTrip} ms limit`);
+    }
+
+    // 假设请求与响应耗时相同，服务器时间对应往返的中点
+    const sampleOffset = serverTime - (sentAt + roundTrip / 2);
+    if (reset || this.samples === 0) {
+      this.offset = sampleOffset;
+      this.samples = 1;
+    } else {
+      this.offset = this.alpha * sampleOffset + (1 - this.alpha) * this.offset;
+      this.samples++;
+    }
+
+    this.emit('calibrated', this.offset, roundTrip);
+    return this.offset;
+  }
+}
@@ -3,19 +3,44 @@
  */
 
 import { EventEmitter } from 'events';
-import { BaseErrorHandler, ErrorCategory, ErrorSeverity, RecoveryStrategy } from '@pixiu/shared-core';
+import { BaseErrorHandler, BaseMonitor, ErrorCategory, ErrorSeverity, ProxyPool, RecoveryStrategy, formatProxyUrl } from '@pixiu/shared-core';
 import {
   ExchangeAdapter,
   AdapterConfig,
   AdapterStatus,
   AdapterMetrics,
+  AdapterCapabilities,
+  AdapterCapabilitiesDeclaration,
   SubscriptionConfig,
   SubscriptionInfo,
   MarketData,
   DataType,
   AdapterEventMap
 } from '../interfaces/adapter';
-import { ConnectionManager } from '../interfaces/connection';
+import { ConnectionManager, HeartbeatMessage } from '../interfaces/connection';
+
+/**
+ * 默认适配器能力：支持全部行情数据类型，不支持交易
+ */
+export const DEFAULT_ADAPTER_CAPABILITIES: AdapterCapabilities = {
+  dataTypes: Object.values(DataType),
+  websocket: true,
+  userDataStream: false,
+  combinedStreams: false,
+  orderBookChecksum: false,
+  trading: {
+    spot: false,
+    margin: false,
+    futures: false,
+    oco: false,
+    postOnly: false
+  }
+};
+
+export interface BaseAdapterOptions {
+  /** 服务监控，适配器内部的请求策略等组件向其注册指标 */
+  monitor?: BaseMonitor;
+}
 
 export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapter {
   public abstract readonly exchange: string;
@@ -26,13 +51,15 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
   protected errorHandler!: BaseErrorHandler;
   protected subscriptions = new Map<string, SubscriptionInfo>();
   protected metrics!: AdapterMetrics;
+  protected readonly monitor?: BaseMonitor;
+  private proxyPool?: ProxyPool;
   
   private reconnectAttempts = 0;
-  private heartbeatTimer?: NodeJS.Timeout;
   private lastHeartbeat = 0;
 
-  constructor() {
+  constructor(options: BaseAdapterOptions = {}) {
     super();
+    this.monitor = options.monitor;
     this.initializeErrorHandler();
     this.initializeMetrics();
   }
@@ -61,6 +88,22 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
     };
   }
 
+  /**
+   * 获取适配器能力
+   */
+  getCapabilities(): AdapterCapabilities {
+    const declared = this.describeCapabilities();
+    return {
+      ...DEFAULT_ADAPTER_CAPABILITIES,
+      ...declared,
+      dataTypes: [...(declared.dataTypes ?? DEFAULT_ADAPTER_CAPABILITIES.dataTypes)],
+      trading: {
+        ...DEFAULT_ADAPTER_CAPABILITIES.trading,
+        ...declared.trading
+      }
+    };
+  }
+
   /**
    * 初始化适配器
    */
@@ -96,16 +139,19 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
         maxRetries: this.config.connection.maxRetries,
         retryInterval: this.config.connection.retryInterval,
         heartbeatInterval: this.config.connection.heartbeatInterval,
-        heartbeatTimeout: this.config.connection.timeout
+        heartbeatTimeout: this.config.connection.timeout,
+        reconnectStrategy: this.config.connection.reconnectStrategy,
+        enableCompression: this.config.connection.enableCompression,
+        heartbeatMessage: this.describeHeartbeat(),
+        proxyPool: this.getProxyPool()
       });
+      this.proxyPool?.start();
 
       this.setStatus(AdapterStatus.CONNECTED);
       this.metrics.connectedAt = Date.now();
       this.reconnectAttempts = 0;
       
-      // 启动心跳
-      this.startHeartbeat();
-      
+      // 心跳由连接管理器负责，这里只需监听其heartbeat事件
       this.emit('connected');
     } catch (error) {
       this.setStatus(AdapterStatus.ERROR);
@@ -125,9 +171,6 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
     try {
       this.setStatus(AdapterStatus.DISCONNECTED);
       
-      // 停止心跳
-      this.stopHeartbeat();
-      
       // 清理订阅
       await this.unsubscribeAll();
       
@@ -135,6 +178,7 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
       if (this.connectionManager) {
         await this.connectionManager.disconnect();
       }
+      this.proxyPool?.stop();
       
       this.emit('disconnected');
     } catch (error) {
@@ -151,6 +195,12 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
       throw new Error('Adapter is not connected');
     }
 
+    const supported = this.getCapabilities().dataTypes;
+    const unsupported = config.dataTypes.filter(type => !supported.includes(type));
+    if (unsupported.length > 0) {
+      throw new Error(`Data types not supported by ${this.exchange}: ${unsupported.join(', ')}`);
+    }
+
     const subscriptions: SubscriptionInfo[] = [];
     
     try {
@@ -273,6 +323,7 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
       if (this.connectionManager) {
         await this.connectionManager.destroy();
       }
+      this.proxyPool?.destroy();
       
       this.removeAllListeners();
     } catch (error) {
@@ -284,7 +335,40 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
   protected abstract createConnectionManager(): Promise<ConnectionManager>;
   protected abstract createSubscription(symbol: string, dataType: DataType): Promise<SubscriptionInfo>;
   protected abstract removeSubscription(subscription: SubscriptionInfo): Promise<void>;
-  protected abstract parseMessage(message: any): MarketData | null;
+  /** 解析交易所消息，单条消息含多笔数据（如批量成交）时可返回数组 */
+  protected abstract parseMessage(message: any): MarketData | MarketData[] | null;
+
+  /**
+   * 声明适配器能力，子类覆盖以描述交易所支持的功能
+   */
+  protected describeCapabilities(): AdapterCapabilitiesDeclaration {
+    return {};
+  }
+
+  /**
+   * 声明应用层心跳，默认使用WebSocket ping帧
+   * 交易所要求以消息保活时子类覆盖
+   */
+  protected describeHeartbeat(): HeartbeatMessage | undefined {
+    return undefined;
+  }
+
+  /**
+   * 按配置创建出口代理池，WebSocket与REST请求共用
+   * 只配置了单个proxy时视为仅含一个代理的池
+   */
+  protected getProxyPool(): ProxyPool | undefined {
+    if (!this.proxyPool) {
+      const options = this.config.proxyPool
+        ?? (this.config.proxy ? { proxies: [formatProxyUrl(this.config.proxy)] } : undefined);
+      if (options) {
+        this.proxyPool = new ProxyPool(options);
+        this.proxyPool.on('down', (url: string, failures: number) => this.emit('proxyDown', url, failures));
+        this.proxyPool.on('up', (url: string) => this.emit('proxyUp', url));
+      }
+    }
+    return this.proxyPool;
+  }
 
   /**
    * 初始化错误处理器
@@ -340,6 +424,28 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
       this.emit('disconnected', reason);
     });
 
+    // 连接管理器自动重连时同步适配器状态
+    this.connectionManager.on('reconnecting', (attempt) => {
+      this.setStatus(AdapterStatus.RECONNECTING);
+      this.metrics.reconnectCount++;
+      this.emit('reconnecting', attempt);
+    });
+
+    // 重连成功后自动恢复订阅
+    this.connectionManager.on('reconnected', () => {
+      this.setStatus(AdapterStatus.CONNECTED);
+      this.metrics.connectedAt = Date.now();
+      this.emit('connected');
+      this.restoreSubscriptions().catch(error => this.handleError(error, 'restore_subscriptions'));
+    });
+
+    this.connectionManager.on('heartbeat', (latency) => {
+      this.lastHeartbeat = Date.now();
+      this.metrics.lastHeartbeat = this.lastHeartbeat;
+      this.updateLatency(latency);
+      this.emit('heartbeat', this.lastHeartbeat);
+    });
+
     this.connectionManager.on('message', (message) => {
       this.handleMessage(message);
     });
@@ -356,8 +462,9 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
     try {
       this.metrics.messagesReceived++;
       
-      const marketData = this.parseMessage(message);
-      if (marketData) {
+      const parsed = this.parseMessage(message);
+      const items = Array.isArray(parsed) ? parsed : parsed ? [parsed] : [];
+      for (const marketData of items) {
         marketData.receivedAt = Date.now();
         marketData.latency = this.calculateMessageLatency(marketData);
         
@@ -482,27 +589,6 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
     this.emit('statusChange', newStatus, previousStatus);
   }
 
-  /**
-   * 启动心跳
-   */
-  private startHeartbeat(): void {
-    this.stopHeartbeat();
-    
-    this.heartbeatTimer = setInterval(() => {
-      this.sendHeartbeat();
-    }, this.config.connection.heartbeatInterval);
-  }
-
-  /**
-   * 停止心跳
-   */
-  private stopHeartbeat(): void {
-    if (this.heartbeatTimer) {
-      clearInterval(this.heartbeatTimer);
-      this.heartbeatTimer = undefined;
-    }
-  }
-
   /**
    * 恢复订阅
    */
@@ -510,16 +596,24 @@ export abstract class BaseAdapter extends EventEmitter implements ExchangeAdapte
     const activeSubscriptions = Array.from(this.subscriptions.values())
       .filter(sub => sub.active);
     
-    // 重新订阅
+    // 重新订阅，保留原订阅ID以便调用方继续使用
     for (const subscription of activeSubscriptions) {
       try {
-        await this.createSubscription(subscription.symbol, subscription.dataType);
+        await this.restoreSubscription(subscription);
       } catch (error) {
         await this.handleError(error as Error, 'restore_subscription');
       }
     }
   }
 
+  /**
+   * 在新连接上恢复单个订阅
+   * 默认重新发送订阅请求，子类可按交易所协议覆盖
+   */
+  protected async restoreSubscription(subscription: SubscriptionInfo): Promise<void> {
+    await this.createSubscription(subscription.symbol, subscription.dataType);
+  }
+
   /**
    * 验证配置
    */
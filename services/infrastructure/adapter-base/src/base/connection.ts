@@ -4,13 +4,14 @@
 
 import { EventEmitter } from 'events';
 import WebSocket from 'ws';
-import { retry } from '@pixiu/shared-core';
+import { PooledProxy, createProxyAgent, formatProxyUrl, retry } from '@pixiu/shared-core';
 import {
   ConnectionManager,
   ConnectionConfig,
   ConnectionState,
   ConnectionMetrics,
-  ConnectionEventMap
+  ConnectionEventMap,
+  HeartbeatMessage
 } from '../interfaces/connection';
 
 export class BaseConnectionManager extends EventEmitter implements ConnectionManager {
@@ -22,8 +23,10 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
   private heartbeatTimeoutTimer?: NodeJS.Timeout;
   private reconnectTimer?: NodeJS.Timeout;
   private reconnectAttempts = 0;
+  private missedHeartbeats = 0;
   private latencyHistory: number[] = [];
   private lastPingTime = 0;
+  private pendingPong?: () => void;
 
   constructor() {
     super();
@@ -79,6 +82,7 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
       this.setState(ConnectionState.CONNECTED);
       this.metrics.connectedAt = Date.now();
       this.reconnectAttempts = 0;
+      this.missedHeartbeats = 0;
       
       this.startHeartbeat();
       this.emit('connected');
@@ -92,6 +96,9 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
    * 断开连接
    */
   async disconnect(): Promise<void> {
+    // 即使已处于断开状态，也要取消可能已调度的自动重连
+    this.clearReconnectTimer();
+
     if (this.state === ConnectionState.DISCONNECTED) {
       return;
     }
@@ -99,12 +106,7 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
     this.setState(ConnectionState.DISCONNECTING);
     
     this.stopHeartbeat();
-    this.clearReconnectTimer();
-    
-    if (this.ws) {
-      this.ws.close(1000, 'Normal closure');
-      this.ws = undefined;
-    }
+    this.teardownSocket(1000, 'Normal closure');
     
     this.setState(ConnectionState.DISCONNECTED);
     this.emit('disconnected', 'Normal closure');
@@ -118,35 +120,30 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
       return;
     }
 
+    this.clearReconnectTimer();
+    this.stopHeartbeat();
     this.setState(ConnectionState.RECONNECTING);
     this.reconnectAttempts++;
+    this.metrics.reconnectAttempts++;
     
     this.emit('reconnecting', this.reconnectAttempts);
     
-    // 关闭现有连接
-    if (this.ws) {
-      this.ws.close();
-      this.ws = undefined;
-    }
-    
-    // 等待重连间隔
-    await this.delay(this.config.retryInterval);
+    // 关闭现有连接，先解绑事件避免旧连接的close事件干扰状态
+    this.teardownSocket();
     
     try {
       await this.establishConnection();
       this.setState(ConnectionState.CONNECTED);
       this.metrics.connectedAt = Date.now();
+      this.reconnectAttempts = 0;
+      this.missedHeartbeats = 0;
       
       this.startHeartbeat();
       this.emit('reconnected');
     } catch (error) {
-      if (this.reconnectAttempts < this.config.maxRetries) {
-        // 调度下次重连
-        this.scheduleReconnect();
-      } else {
-        this.setState(ConnectionState.ERROR);
-        this.emit('error', new Error(`Max reconnect attempts (${this.config.maxRetries}) exceeded`));
-      }
+      // 重连失败，按退避策略调度下次重连
+      this.setState(ConnectionState.DISCONNECTED);
+      this.scheduleReconnect();
     }
   }
 
@@ -196,29 +193,62 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
         return;
       }
 
+      if (this.config.heartbeatMessage) {
+        this.pingWithMessage(this.config.heartbeatMessage).then(resolve, reject);
+        return;
+      }
+
+      const ws = this.ws;
       this.lastPingTime = Date.now();
+
+      // 设置pong监听器（延迟统计由setupEventHandlers中的pong处理器负责）
+      const pongHandler = () => {
+        clearTimeout(timeoutTimer);
+        resolve(Date.now() - this.lastPingTime);
+      };
+
+      // 设置超时
+      const timeoutTimer = setTimeout(() => {
+        ws.off('pong', pongHandler);
+        reject(new Error('Ping timeout'));
+      }, this.config.heartbeatTimeout);
+
+      ws.once('pong', pongHandler);
       
-      this.ws.ping((error: Error | undefined) => {
+      ws.ping((error: Error | undefined) => {
         if (error) {
+          clearTimeout(timeoutTimer);
+          ws.off('pong', pongHandler);
           reject(error);
         }
       });
+    });
+  }
 
-      // 设置pong监听器
-      const pongHandler = () => {
-        const latency = Date.now() - this.lastPingTime;
-        this.updateLatency(latency);
-        this.ws?.off('pong', pongHandler);
-        resolve(latency);
-      };
-
-      this.ws.on('pong', pongHandler);
+  /**
+   * 发送应用层心跳消息，收到心跳响应后返回延迟
+   */
+  private pingWithMessage(heartbeat: HeartbeatMessage): Promise<number> {
+    return new Promise((resolve, reject) => {
+      this.lastPingTime = Date.now();
 
-      // 设置超时
-      setTimeout(() => {
-        this.ws?.off('pong', pongHandler);
+      const timeoutTimer = setTimeout(() => {
+        this.pendingPong = undefined;
         reject(new Error('Ping timeout'));
       }, this.config.heartbeatTimeout);
+
+      this.pendingPong = () => {
+        clearTimeout(timeoutTimer);
+        this.pendingPong = undefined;
+        resolve(Date.now() - this.lastPingTime);
+      };
+
+      const data = typeof heartbeat.ping === 'string' ? heartbeat.ping : JSON.stringify(heartbeat.ping);
+      this.sendRaw(data).catch(error => {
+        clearTimeout(timeoutTimer);
+        this.pendingPong = undefined;
+        reject(error);
+      });
     });
   }
 
@@ -265,9 +295,18 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
           handshakeTimeout: this.config.timeout
         };
 
-        // 配置代理
-        if (this.config.proxy) {
-          // 这里可以配置代理逻辑
+        // 未显式配置时沿用ws的默认协商行为
+        if (this.config.enableCompression !== undefined) {
+          wsOptions.perMessageDeflate = this.config.enableCompression;
+        }
+
+        // 配置代理，代理池按健康状态轮换
+        let proxy: PooledProxy | undefined;
+        if (this.config.proxyPool) {
+          proxy = this.config.proxyPool.next();
+          wsOptions.agent = proxy.agent;
+        } else if (this.config.proxy) {
+          wsOptions.agent = createProxyAgent(formatProxyUrl(this.config.proxy));
         }
 
         this.ws = new WebSocket(this.config.url, wsOptions);
@@ -275,6 +314,11 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
         // 连接成功
         this.ws.on('open', () => {
           clearTimeout(connectTimeout);
+          if (proxy) {
+            this.config.proxyPool!.recordSuccess(proxy);
+            // 连接建立后的错误不再计入代理失败
+            proxy = undefined;
+          }
           this.setupEventHandlers();
           resolve();
         });
@@ -282,6 +326,9 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
         // 连接失败
         this.ws.on('error', (error) => {
           clearTimeout(connectTimeout);
+          if (proxy) {
+            this.config.proxyPool!.recordFailure(proxy);
+          }
           this.metrics.errorCount++;
           reject(error);
         });
@@ -310,10 +357,10 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
       try {
         const message = data.toString();
         const parsed = JSON.parse(message);
-        this.emit('message', parsed);
+        this.dispatchMessage(parsed);
       } catch (error) {
         // 如果JSON解析失败，发送原始数据
-        this.emit('message', data.toString());
+        this.dispatchMessage(data.toString());
       }
     });
 
@@ -323,13 +370,12 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
       
       const wasConnected = this.state === ConnectionState.CONNECTED;
       this.setState(ConnectionState.DISCONNECTED);
+      this.emit('disconnected', reason?.toString());
       
       if (wasConnected && code !== 1000) {
-        // 非正常关闭，尝试重连
+        // 非正常关闭，按退避策略尝试重连
         this.scheduleReconnect();
       }
-      
-      this.emit('disconnected', reason.toString());
     });
 
     // 连接错误
@@ -339,11 +385,29 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
     });
 
     // Pong响应
-    this.ws.on('pong', () => {
-      const latency = Date.now() - this.lastPingTime;
-      this.updateLatency(latency);
-      this.emit('heartbeat', latency);
-    });
+    this.ws.on('pong', () => this.recordHeartbeat());
+  }
+
+  /**
+   * 转发消息，应用层心跳响应在此消费
+   */
+  private dispatchMessage(message: any): void {
+    if (this.config.heartbeatMessage?.isPong(message)) {
+      this.pendingPong?.();
+      this.recordHeartbeat();
+      return;
+    }
+    this.emit('message', message);
+  }
+
+  /**
+   * 记录心跳响应
+   */
+  private recordHeartbeat(): void {
+    const latency = Date.now() - this.lastPingTime;
+    this.metrics.lastHeartbeat = Date.now();
+    this.updateLatency(latency);
+    this.emit('heartbeat', latency);
   }
 
   /**
@@ -380,11 +444,16 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
   private async sendHeartbeat(): Promise<void> {
     try {
       await this.ping();
+      this.missedHeartbeats = 0;
     } catch (error) {
+      this.missedHeartbeats++;
       this.emit('heartbeatTimeout');
-      // 心跳失败，可能需要重连
-      if (this.isConnected()) {
-        this.scheduleReconnect();
+
+      // 连续丢失心跳超过阈值，认为连接已失效，立即重连
+      const maxMissed = this.config.maxMissedHeartbeats ?? 1;
+      if (this.missedHeartbeats >= maxMissed && this.state === ConnectionState.CONNECTED) {
+        this.missedHeartbeats = 0;
+        this.reconnect().catch(reconnectError => this.emit('error', reconnectError));
       }
     }
   }
@@ -395,11 +464,55 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
   private scheduleReconnect(): void {
     this.clearReconnectTimer();
     
-    if (this.reconnectAttempts < this.config.maxRetries) {
-      this.reconnectTimer = setTimeout(() => {
-        this.reconnect();
-      }, this.config.retryInterval);
+    if (this.reconnectAttempts >= this.config.maxRetries) {
+      this.setState(ConnectionState.ERROR);
+      this.emit('error', new Error(`Max reconnect attempts (${this.config.maxRetries}) exceeded`));
+      return;
     }
+
+    const delay = this.calculateReconnectDelay(this.reconnectAttempts);
+    this.reconnectTimer = setTimeout(() => {
+      this.reconnectTimer = undefined;
+      this.reconnect().catch(error => this.emit('error', error));
+    }, delay);
+  }
+
+  /**
+   * 计算重连延迟（指数退避 + 可选抖动）
+   */
+  protected calculateReconnectDelay(attempt: number): number {
+    const baseDelay = this.config.retryInterval;
+    const strategy = this.config.reconnectStrategy;
+    if (!strategy) {
+      return baseDelay;
+    }
+
+    const backoffBase = strategy.backoffBase ?? 2;
+    const maxDelay = strategy.maxRetryInterval ?? 30000;
+    let delay = Math.min(baseDelay * Math.pow(backoffBase, attempt), maxDelay);
+
+    // 添加抖动以避免雷群效应
+    if (strategy.jitter) {
+      delay = delay * (0.5 + Math.random() * 0.5);
+    }
+
+    return Math.floor(delay);
+  }
+
+  /**
+   * 关闭并释放当前WebSocket
+   */
+  private teardownSocket(code?: number, reason?: string): void {
+    if (!this.ws) {
+      return;
+    }
+
+    const ws = this.ws;
+    this.ws = undefined;
+    ws.removeAllListeners();
+    // 关闭过程中仍可能触发error事件，忽略以避免未处理异常
+    ws.on('error', () => {});
+    ws.close(code, reason);
   }
 
   /**
@@ -471,10 +584,4 @@ export class BaseConnectionManager extends EventEmitter implements ConnectionMan
     return sum / this.latencyHistory.length;
   }
 
-  /**
-   * 延迟工具函数
-   */
-  private delay(ms: number): Promise<void> {
-    return new Promise(resolve => setTimeout(resolve, ms));
-  }
 }
\ No newline at end of file
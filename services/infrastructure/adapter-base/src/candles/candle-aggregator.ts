@@ -0,0 +1,351 @@
+/**
+ * K线聚合器
+ * 基于逐笔成交构建任意周期的时间K线，以及成交量K线和笔数K线
+ */
+
+import { EventEmitter } from 'events';
+import { KlineData, TradeData } from '../interfaces/adapter';
+
+/** K线规格 */
+export type CandleSpec =
+  | { kind: 'time'; interval: string }
+  | { kind: 'volume'; threshold: number }
+  | { kind: 'tick'; count: number };
+
+export interface Candle extends KlineData {
+  /** 交易对 */
+  symbol: string;
+  /** 成交笔数 */
+  trades: number;
+  /** 是否已收盘 */
+  closed: boolean;
+}
+
+export interface CandleAggregatorConfig {
+  /** 需要聚合的K线规格 */
+  specs: CandleSpec[];
+  /** 迟到成交处理策略：drop丢弃，revise修正已收盘K线 */
+  lateTradePolicy?: 'drop' | 'revise';
+  /** 允许修正已收盘K线的时间窗口（毫秒） */
+  allowedLateness?: number;
+  /** 时间K线在周期结束后延迟收盘的时间（毫秒） */
+  closeDelay?: number;
+  /** 收盘检查间隔（毫秒） */
+  closeCheckInterval?: number;
+  /** 无成交周期是否补齐平盘K线 */
+  fillGaps?: boolean;
+}
+
+interface CandleSeries {
+  symbol: string;
+  spec: CandleSpec;
+  intervalMs?: number;
+  current?: Candle;
+  lastClosed?: Candle;
+  lastClosedAt?: number;
+}
+
+const INTERVAL_UNITS: Record<string, number> = {
+  s: 1000,
+  m: 60 * 1000,
+  h: 60 * 60 * 1000,
+  d: 24 * 60 * 60 * 1000
+};
+
+/**
+ * 解析时间周期（如 1s、15m、4h、1d）为毫秒
+ */
+export function parseCandleInterval(interval: string): number {
+  const match = /^(\d+)([smhd])$/.exec(interval);
+  if (!match || parseInt(match[1], 10) <= 0) {
+    throw new Error(`Invalid candle interval: ${interval}`);
+  }
+  return parseInt(match[1], 10) * INTERVAL_UNITS[match[2]];
+}
+
+/**
+ * 获取K线规格的标识
+ */
+export function getCandleSpecKey(spec: CandleSpec): string {
+  switch (spec.kind) {
+    case 'time':
+      return spec.interval;
+    case 'volume':
+      return `volume_${spec.threshold}`;
+    case 'tick':
+      return `tick_${spec.count}`;
+  }
+}
+
+/**
+ * K线聚合器
+ *
+ * 事件：
+ * - candleClosed: K线收盘
+ * - candleRevised: 已收盘K线因迟到成交被修正
+ * - lateTrade: 迟到成交被丢弃
+ */
+export class CandleAggregator extends EventEmitter {
+  private readonly config: CandleAggregatorConfig;
+  private readonly series = new Map<string, CandleSeries>();
+  private closeTimer?: NodeJS.Timeout;
+  private droppedTrades = 0;
+
+  constructor(config: CandleAggregatorConfig) {
+    super();
+    this.config = config;
+
+    for (const spec of config.specs) {
+      if (spec.kind === 'time') {
+        parseCandleInterval(spec.interval);
+      } else if (spec.kind === 'volume' && !(spec.threshold > 0)) {
+        throw new Error(`Invalid volume candle threshold: ${spec.threshold}`);
+      } else if (spec.kind === 'tick' && !(spec.count > 0)) {
+        throw new Error(`Invalid tick candle count: ${spec.count}`);
+      }
+    }
+  }
+
+  /**
+   * 启动定时收盘检查
+   */
+  start(): void {
+    if (this.closeTimer) {
+      return;
+    }
+    this.closeTimer = setInterval(() => this.flush(), this.config.closeCheckInterval ?? 1000);
+  }
+
+  /**
+   * 停止定时收盘检查
+   */
+  stop(): void {
+    if (this.closeTimer) {
+      clearInterval(this.closeTimer);
+      this.closeTimer = undefined;
+    }
+  }
+
+  /**
+   * 输入一笔成交
+   */
+  addTrade(symbol: string, trade: TradeData): void {
+    for (const spec of this.config.specs) {
+      const series = this.getSeries(symbol, spec);
+      if (spec.kind === 'time') {
+        this.addTimeTrade(series, trade);
+      } else {
+        this.addCountedTrade(series, trade);
+      }
+    }
+  }
+
+  /**
+   * 收盘所有已到期的时间K线
+   */
+  flush(now = Date.now()): void {
+    const closeDelay = this.config.closeDelay ?? 0;
+
+    for (const series of this.series.values()) {
+      while (series.current && series.intervalMs && now >= series.current.closeTime + 1 + closeDelay) {
+        const closed = this.closeCandle(series);
+        if (!this.config.fillGaps) {
+          break;
+        }
+        series.current = this.createFlatCandle(series, closed.closeTime + 1, closed.close);
+      }
+    }
+  }
+
+  /**
+   * 获取当前未收盘K线
+   */
+  getCurrentCandle(symbol: string, spec: CandleSpec): Candle | undefined {
+    const current = this.series.get(this.getSeriesKey(symbol, spec))?.current;
+    return current ? { ...current } : undefined;
+  }
+
+  /**
+   * 获取最近收盘的K线
+   */
+  getLastClosedCandle(symbol: string, spec: CandleSpec): Candle | undefined {
+    const lastClosed = this.series.get(this.getSeriesKey(symbol, spec))?.lastClosed;
+    return lastClosed ? { ...lastClosed } : undefined;
+  }
+
+  /**
+   * 获取被丢弃的迟到成交数
+   */
+  getDroppedTradeCount(): number {
+    return this.droppedTrades;
+  }
+
+  /**
+   * 停止并清空所有K线
+   */
+  destroy(): void {
+    this.stop();
+    this.series.clear();
+    this.removeAllListeners();
+  }
+
+  /**
+   * 时间K线处理成交
+   */
+  private addTimeTrade(series: CandleSeries, trade: TradeData): void {
+    const intervalMs = series.intervalMs!;
+    const openTime = Math.floor(trade.timestamp / intervalMs) * intervalMs;
+
+    if (series.current && openTime < series.current.openTime) {
+      this.handleLateTrade(series, trade, openTime);
+      return;
+    }
+
+    if (!series.current && series.lastClosed && openTime <= series.lastClosed.openTime) {
+      this.handleLateTrade(series, trade, openTime);
+      return;
+    }
+
+    while (series.current && openTime > series.current.openTime) {
+      const closed = this.closeCandle(series);
+      const nextOpenTime = closed.closeTime + 1;
+      if (this.config.fillGaps && nextOpenTime < openTime) {
+        series.current = this.createFlatCandle(series, nextOpenTime, closed.close);
+      }
+    }
+
+    if (!series.current) {
+      series.current = this.createCandle(series, openTime, openTime + intervalMs - 1, trade);
+      return;
+    }
+
+    this.applyTrade(series.current, trade);
+  }
+
+  /**
+   * 成交量K线与笔数K线处理成交
+   */
+  private addCountedTrade(series: CandleSeries, trade: TradeData): void {
+    if (!series.current) {
+      series.current = this.createCandle(series, trade.timestamp, trade.timestamp, trade);
+    } else {
+      this.applyTrade(series.current, trade);
+      series.current.closeTime = Math.max(series.current.closeTime, trade.timestamp);
+    }
+
+    const spec = series.spec;
+    const complete = spec.kind === 'volume'
+      ? series.current.volume >= spec.threshold
+      : spec.kind === 'tick' && series.current.trades >= spec.count;
+
+    if (complete) {
+      this.closeCandle(series);
+    }
+  }
+
+  /**
+   * 处理迟到成交
+   */
+  private handleLateTrade(series: CandleSeries, trade: TradeData, openTime: number): void {
+    const lastClosed = series.lastClosed;
+    const allowedLateness = this.config.allowedLateness ?? 0;
+    const revisable = this.config.lateTradePolicy === 'revise' &&
+      lastClosed !== undefined &&
+      lastClosed.openTime === openTime &&
+      Date.now() - series.lastClosedAt! <= allowedLateness;
+
+    if (revisable) {
+      this.applyTrade(lastClosed!, trade);
+      this.emit('candleRevised', { ...lastClosed! });
+      return;
+    }
+
+    this.droppedTrades++;
+    this.emit('lateTrade', series.symbol, trade, getCandleSpecKey(series.spec));
+  }
+
+  /**
+   * 收盘当前K线
+   */
+  private closeCandle(series: CandleSeries): Candle {
+    const candle = series.current!;
+    candle.closed = true;
+
+    series.lastClosed = candle;
+    series.lastClosedAt = Date.now();
+    series.current = undefined;
+
+    this.emit('candleClosed', { ...candle });
+    return candle;
+  }
+
+  /**
+   * 将成交计入K线
+   */
+  private applyTrade(candle: Candle, trade: TradeData): void {
+    candle.high = Math.max(candle.high, trade.price);
+    candle.low = Math.min(candle.low, trade.price);
+    candle.close = trade.price;
+    candle.volume += trade.quantity;
+    candle.trades++;
+  }
+
+  /**
+   * 以首笔成交创建K线
+   */
+  private createCandle(series: CandleSeries, openTime: number, closeTime: number, trade: TradeData): Candle {
+    return {
+      symbol: series.symbol,
+      interval: getCandleSpecKey(series.spec),
+      open: trade.price,
+      high: trade.price,
+      low: trade.price,
+      close: trade.price,
+      volume: trade.quantity,
+      trades: 1,
+      openTime,
+      closeTime,
+      closed: false
+    };
+  }
+
+  /**
+   * 创建无成交的平盘K线
+   */
+  private createFlatCandle(series: CandleSeries, openTime: number, price: number): Candle {
+    return {
+      symbol: series.symbol,
+      interval: getCandleSpecKey(series.spec),
+      open: price,
+      high: price,
+      low: price,
+      close: price,
+      volume: 0,
+      trades: 0,
+      openTime,
+      closeTime: openTime + series.intervalMs! - 1,
+      closed: false
+    };
+  }
+
+  /**
+   * 获取或创建K线序列
+   */
+  private getSeries(symbol: string, spec: CandleSpec): CandleSeries {
+    const key = this.getSeriesKey(symbol, spec);
+    let series = this.series.get(key);
+    if (!series) {
+      series = {
+        symbol,
+        spec,
+        intervalMs: spec.kind === 'time' ? parseCandleInterval(spec.interval) : undefined
+      };
+      this.series.set(key, series);
+    }
+    return series;
+  }
+
+  private getSeriesKey(symbol: string, spec: CandleSpec): string {
+    return `${symbol}|${getCandleSpecKey(spec)}`;
+  }
+}
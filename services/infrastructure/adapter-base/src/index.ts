@@ -7,11 +7,34 @@
 export * from './interfaces/adapter';
 export * from './interfaces/connection';
 export * from './interfaces/parser';
+export * from './interfaces/history';
+export * from './interfaces/instrument';
 
 // 基础实现
 export * from './base/adapter';
 export * from './base/connection';
 
+// 服务器时间同步
+export * from './auth/server-clock';
+
+// 订单簿
+export * from './orderbook/order-book';
+export * from './orderbook/checksum';
+
+// 交易品种
+export * from './instruments/symbol';
+export * from './instruments/instrument-registry';
+
+// K线聚合
+export * from './candles/candle-aggregator';
+
+// 技术指标
+export * from './indicators/series';
+export * from './indicators/indicators';
+
+// 微观结构特征
+export * from './microstructure/microstructure-tracker';
+
 // 工厂模式
 export * from './factory/adapter-factory';
 
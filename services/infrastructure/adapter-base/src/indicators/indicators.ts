@@ -0,0 +1,343 @@
+/**
+ * 常用技术指标的增量实现
+ */
+
+import { KlineData } from '../interfaces/adapter';
+import { Indicator, Series } from './series';
+
+/** 指标使用的K线字段 */
+export type Bar = Pick<KlineData, 'high' | 'low' | 'close' | 'volume'> & Partial<Pick<KlineData, 'openTime'>>;
+
+function assertPeriod(name: string, period: number): void {
+  if (!Number.isInteger(period) || period < 1) {
+    throw new Error(`${name} period must be a positive integer: ${period}`);
+  }
+}
+
+/**
+ * 简单移动平均
+ */
+export class SMA implements Indicator {
+  private readonly window: Series<number>;
+  private sum = 0;
+  value: number | undefined;
+
+  constructor(readonly period: number) {
+    assertPeriod('SMA', period);
+    this.window = new Series(period);
+  }
+
+  get ready(): boolean {
+    return this.value !== undefined;
+  }
+
+  update(input: number): number | undefined {
+    const evicted = this.window.push(input);
+    this.sum += input - (evicted ?? 0);
+
+    if (this.window.length === this.period) {
+      this.value = this.sum / this.period;
+    }
+    return this.value;
+  }
+
+  reset(): void {
+    this.window.clear();
+    this.sum = 0;
+    this.value = undefined;
+  }
+}
+
+/**
+ * 指数移动平均，以前 period 个值的简单平均作为初始值
+ */
+export class EMA implements Indicator {
+  private readonly alpha: number;
+  private seedSum = 0;
+  private seedCount = 0;
+  value: number | undefined;
+
+  constructor(readonly period: number) {
+    assertPeriod('EMA', period);
+    this.alpha = 2 / (period + 1);
+  }
+
+  get ready(): boolean {
+    return this.value !== undefined;
+  }
+
+  update(input: number): number | undefined {
+    if (this.value !== undefined) {
+      this.value += this.alpha * (input - this.value);
+      return this.value;
+    }
+
+    this.seedSum += input;
+    this.seedCount++;
+    if (this.seedCount === this.period) {
+      this.value = this.seedSum / this.period;
+    }
+    return this.value;
+  }
+
+  reset(): void {
+    this.seedSum = 0;
+    this.seedCount = 0;
+    this.value = undefined;
+  }
+}
+
+/**
+ * Wilder平滑（RMA），RSI与ATR使用
+ */
+class WilderAverage {
+  private seedSum = 0;
+  private seedCount = 0;
+  value: number | undefined;
+
+  constructor(private readonly period: number) {}
+
+  update(input: number): number | undefined {
+    if (this.value !== undefined) {
+      this.value = (this.value * (this.period - 1) + input) / this.period;
+      return this.value;
+    }
+
+    this.seedSum += input;
+    this.seedCount++;
+    if (this.seedCount === this.period) {
+      this.value = this.seedSum / this.period;
+    }
+    return this.value;
+  }
+
+  reset(): void {
+    this.seedSum = 0;
+    this.seedCount = 0;
+    this.value = undefined;
+  }
+}
+
+/**
+ * 相对强弱指数（Wilder平滑），需要 period + 1 个价格完成预热
+ */
+export class RSI implements Indicator {
+  private readonly gains: WilderAverage;
+  private readonly losses: WilderAverage;
+  private previous: number | undefined;
+  value: number | undefined;
+
+  constructor(readonly period = 14) {
+    assertPeriod('RSI', period);
+    this.gains = new WilderAverage(period);
+    this.losses = new WilderAverage(period);
+  }
+
+  get ready(): boolean {
+    return this.value !== undefined;
+  }
+
+  update(input: number): number | undefined {
+    if (this.previous === undefined) {
+      this.previous = input;
+      return undefined;
+    }
+
+    const change = input - this.previous;
+    this.previous = input;
+    const gain = this.gains.update(Math.max(change, 0));
+    const loss = this.losses.update(Math.max(-change, 0));
+
+    if (gain !== undefined && loss !== undefined) {
+      if (loss === 0) {
+        this.value = gain === 0 ? 50 : 100;
+      } else {
+        this.value = 100 - 100 / (1 + gain / loss);
+      }
+    }
+    return this.value;
+  }
+
+  reset(): void {
+    this.gains.reset();
+    this.losses.reset();
+    this.previous = undefined;
+    this.value = undefined;
+  }
+}
+
+export interface MACDValue {
+  macd: number;
+  signal: number;
+  histogram: number;
+}
+
+/**
+ * 指数平滑异同移动平均
+ */
+export class MACD implements Indicator<number, MACDValue> {
+  private readonly fast: EMA;
+  private readonly slow: EMA;
+  private readonly signal: EMA;
+  value: MACDValue | undefined;
+
+  constructor(fastPeriod = 12, slowPeriod = 26, signalPeriod = 9) {
+    if (fastPeriod >= slowPeriod) {
+      throw new Error(`MACD fast period must be shorter than slow period: ${fastPeriod} >= ${slowPeriod}`);
+    }
+    this.fast = new EMA(fastPeriod);
+    this.slow = new EMA(slowPeriod);
+    this.signal = new EMA(signalPeriod);
+  }
+
+  get ready(): boolean {
+    return this.value !== undefined;
+  }
+
+  update(input: number): MACDValue | undefined {
+    const fast = this.fast.update(input);
+    const slow = this.slow.update(input);
+    if (fast === undefined || slow === undefined) {
+      return undefined;
+    }
+
+    const macd = fast - slow;
+    const signal = this.signal.update(macd);
+    if (signal !== undefined) {
+      this.value = { macd, signal, histogram: macd - signal };
+    }
+    return this.value;
+  }
+
+  reset(): void {
+    this.fast.reset();
+    this.slow.reset();
+    this.signal.reset();
+    this.value = undefined;
+  }
+}
+
+export interface BollingerValue {
+  middle: number;
+  upper: number;
+  lower: number;
+  /** 带宽 (upper - lower) / middle */
+  bandwidth: number;
+}
+
+/**
+ * 布林带，标准差按总体方差计算
+ */
+export class BollingerBands implements Indicator<number, BollingerValue> {
+  private readonly window: Series<number>;
+  private sum = 0;
+  private sumSquares = 0;
+  value: BollingerValue | undefined;
+
+  constructor(readonly period = 20, readonly multiplier = 2) {
+    assertPeriod('BollingerBands', period);
+    this.window = new Series(period);
+  }
+
+  get ready(): boolean {
+    return this.value !== undefined;
+  }
+
+  update(input: number): BollingerValue | undefined {
+    const evicted = this.window.push(input) ?? 0;
+    this.sum += input - evicted;
+    this.sumSquares += input * input - evicted * evicted;
+
+    if (this.window.length === this.period) {
+      const middle = this.sum / this.period;
+      // 累加误差可能使方差略小于0
+      const deviation = Math.sqrt(Math.max(this.sumSquares / this.period - middle * middle, 0));
+      const upper = middle + this.multiplier * deviation;
+      const lower = middle - this.multiplier * deviation;
+      this.value = { middle, upper, lower, bandwidth: middle === 0 ? 0 : (upper - lower) / middle };
+    }
+    return this.value;
+  }
+
+  reset(): void {
+    this.window.clear();
+    this.sum = 0;
+    this.sumSquares = 0;
+    this.value = undefined;
+  }
+}
+
+/**
+ * 平均真实波幅（Wilder平滑）
+ */
+export class ATR implements Indicator<Bar> {
+  private readonly average: WilderAverage;
+  private previousClose: number | undefined;
+  value: number | undefined;
+
+  constructor(readonly period = 14) {
+    assertPeriod('ATR', period);
+    this.average = new WilderAverage(period);
+  }
+
+  get ready(): boolean {
+    return this.value !== undefined;
+  }
+
+  update(bar: Bar): number | undefined {
+    const trueRange = this.previousClose === undefined
+      ? bar.high - bar.low
+      : Math.max(bar.high - bar.low, Math.abs(bar.high - this.previousClose), Math.abs(bar.low - this.previousClose));
+    this.previousClose = bar.close;
+
+    this.value = this.average.update(trueRange);
+    return this.value;
+  }
+
+  reset(): void {
+    this.average.reset();
+    this.previousClose = undefined;
+    this.value = undefined;
+  }
+}
+
+/**
+ * 成交量加权平均价，按典型价 (high + low + close) / 3 计算
+ * 设置 sessionMs 后按K线开盘时间所在的交易时段重置（如每日UTC 0点）
+ */
+export class VWAP implements Indicator<Bar> {
+  private priceVolume = 0;
+  private volume = 0;
+  private session: number | undefined;
+  value: number | undefined;
+
+  constructor(readonly sessionMs?: number) {}
+
+  get ready(): boolean {
+    return this.value !== undefined;
+  }
+
+  update(bar: Bar): number | undefined {
+    if (this.sessionMs && bar.openTime !== undefined) {
+      const session = Math.floor(bar.openTime / this.sessionMs);
+      if (this.session !== undefined && session !== this.session) {
+        this.reset();
+      }
+      this.session = session;
+    }
+
+    this.priceVolume += ((bar.high + bar.low + bar.close) / 3) * bar.volume;
+    this.volume += bar.volume;
+    if (this.volume > 0) {
+      this.value = this.priceVolume / this.volume;
+    }
+    return this.value;
+  }
+
+  reset(): void {
+    this.priceVolume = 0;
+    this.volume = 0;
+    this.session = undefined;
+    this.value = undefined;
+  }
+}
@@ -0,0 +1,165 @@
+/**
+ * 指标序列与组合
+ * 所有指标均为增量计算，每次更新的时间复杂度为O(1)
+ */
+
+/**
+ * 增量指标
+ * 预热期内 update 返回 undefined
+ */
+export interface Indicator<I = number, O = number> {
+  /** 是否已完成预热 */
+  readonly ready: boolean;
+  /** 最新值 */
+  readonly value: O | undefined;
+  /** 输入新数据并返回最新值 */
+  update(input: I): O | undefined;
+  /** 清空状态 */
+  reset(): void;
+}
+
+/**
+ * 定长环形序列，get(0) 为最新值
+ */
+export class Series<T> {
+  private readonly buffer: T[];
+  private start = 0;
+  private count = 0;
+
+  constructor(readonly capacity: number) {
+    if (!(capacity > 0)) {
+      throw new Error(`Series capacity must be positive: ${capacity}`);
+    }
+    this.buffer = new Array(capacity);
+  }
+
+  get length(): number {
+    return this.count;
+  }
+
+  /**
+   * 追加数据，超出容量时返回被淘汰的最旧值
+   */
+  push(value: T): T | undefined {
+    if (this.count < this.capacity) {
+      this.buffer[(this.start + this.count) % this.capacity] = value;
+      this.count++;
+      return undefined;
+    }
+
+    const evicted = this.buffer[this.start];
+    this.buffer[this.start] = value;
+    this.start = (this.start + 1) % this.capacity;
+    return evicted;
+  }
+
+  /**
+   * 获取 ago 个周期之前的值
+   */
+  get(ago = 0): T | undefined {
+    if (ago < 0 || ago >= this.count) {
+      return undefined;
+    }
+    return this.buffer[(this.start + this.count - 1 - ago) % this.capacity];
+  }
+
+  /**
+   * 按时间顺序（旧到新）返回全部值
+   */
+  toArray(): T[] {
+    return Array.from({ length: this.count }, (_, index) => this.buffer[(this.start + index) % this.capacity]);
+  }
+
+  clear(): void {
+    this.start = 0;
+    this.count = 0;
+  }
+}
+
+/**
+ * 带历史记录的指标，便于读取前几个周期的值（如判断金叉）
+ */
+export class IndicatorSeries<I, O> implements Indicator<I, O> {
+  readonly history: Series<O>;
+
+  constructor(private readonly indicator: Indicator<I, O>, capacity: number) {
+    this.history = new Series<O>(capacity);
+  }
+
+  get ready(): boolean {
+    return this.indicator.ready;
+  }
+
+  get value(): O | undefined {
+    return this.indicator.value;
+  }
+
+  update(input: I): O | undefined {
+    const value = this.indicator.update(input);
+    if (value !== undefined) {
+      this.history.push(value);
+    }
+    return value;
+  }
+
+  /**
+   * 获取 ago 个周期之前的指标值
+   */
+  get(ago = 0): O | undefined {
+    return this.history.get(ago);
+  }
+
+  reset(): void {
+    this.indicator.reset();
+    this.history.clear();
+  }
+}
+
+/**
+ * 串联两个指标，前者的输出作为后者的输入（如 RSI 的 EMA）
+ */
+export function pipe<A, B, C>(first: Indicator<A, B>, second: Indicator<B, C>): Indicator<A, C> {
+  return {
+    get ready() {
+      return second.ready;
+    },
+    get value() {
+      return second.value;
+    },
+    update(input: A) {
+      const intermediate = first.update(input);
+      return intermediate === undefined ? second.value : second.update(intermediate);
+    },
+    reset() {
+      first.reset();
+      second.reset();
+    }
+  };
+}
+
+/**
+ * 转换指标输入（如从K线取收盘价）
+ */
+export function mapInput<S, I, O>(indicator: Indicator<I, O>, select: (source: S) => I): Indicator<S, O> {
+  return {
+    get ready() {
+      return indicator.ready;
+    },
+    get value() {
+      return indicator.value;
+    },
+    update(source: S) {
+      return indicator.update(select(source));
+    },
+    reset() {
+      indicator.reset();
+    }
+  };
+}
+
+/**
+ * 保留最近 capacity 个值的指标历史
+ */
+export function withHistory<I, O>(indicator: Indicator<I, O>, capacity: number): IndicatorSeries<I, O> {
+  return new IndicatorSeries(indicator, capacity);
+}
@@ -0,0 +1,305 @@
+/**
+ * 交易品种注册中心
+ * 缓存各交易所的品种元数据，提供交易对互转与下单价格、数量取整，并在刷新时识别上下架
+ */
+
+import { EventEmitter } from 'events';
+import { InstrumentInfo, InstrumentProvider } from '../interfaces/instrument';
+import { matchSymbolPattern, normalizeSymbol, roundToStep, RoundingMode } from './symbol';
+
+export interface InstrumentRegistryConfig {
+  /** 元数据缓存有效期（毫秒），默认1小时 */
+  ttl?: number;
+}
+
+/**
+ * 两次加载之间的品种上下架变化
+ */
+export interface InstrumentListingChange {
+  exchange: string;
+  /** 新上架或恢复交易的品种 */
+  listed: InstrumentInfo[];
+  /** 已下架或暂停交易的品种 */
+  delisted: InstrumentInfo[];
+}
+
+interface ListingWatcher {
+  patterns: string[];
+  listener: (change: InstrumentListingChange) => void;
+}
+
+interface ExchangeInstruments {
+  bySymbol: Map<string, InstrumentInfo>;
+  byExchangeSymbol: Map<string, InstrumentInfo>;
+  loadedAt: number;
+}
+
+/**
+ * 交易品种注册中心
+ *
+ * 事件：
+ * - loaded(exchange, count) 从数据源加载完成
+ * - loadFailed(exchange, error) 加载失败，保留旧缓存
+ * - listingChange(change) 重新加载后有品种上架或下架，首次加载不触发
+ */
+export class InstrumentRegistry extends EventEmitter {
+  private readonly ttl: number;
+  private readonly providers = new Map<string, InstrumentProvider>();
+  private readonly instruments = new Map<string, ExchangeInstruments>();
+  private readonly pending = new Map<string, Promise<InstrumentInfo[]>>();
+  private readonly watchers = new Set<ListingWatcher>();
+  private refreshTimer?: NodeJS.Timeout;
+
+  constructor(config: InstrumentRegistryConfig = {}) {
+    super();
+    this.ttl = config.ttl ?? 60 * 60 * 1000;
+  }
+
+  /**
+   * 注册品种数据源
+   */
+  addProvider(provider: InstrumentProvider): void {
+    this.providers.set(provider.exchange, provider);
+  }
+
+  /**
+   * 直接注册品种元数据，同一交易所的同名品种会被覆盖
+   * 注册不算作加载，之后的load仍会向数据源请求
+   */
+  register(instruments: InstrumentInfo[]): void {
+    for (const instrument of instruments) {
+      this.index(this.getEntry(instrument.exchange), instrument);
+    }
+  }
+
+  /**
+   * 加载交易所品种，缓存未过期时直接返回
+   * 并发调用共享同一次请求
+   */
+  async load(exchange: string, force = false): Promise<InstrumentInfo[]> {
+    const cached = this.instruments.get(exchange);
+    if (!force && cached && Date.now() - cached.loadedAt < this.ttl) {
+      return Array.from(cached.bySymbol.values());
+    }
+
+    const inflight = this.pending.get(exchange);
+    if (inflight) {
+      return inflight;
+    }
+
+    const provider = this.providers.get(exchange);
+    if (!provider) {
+      throw new Error(`No instrument provider registered for ${exchange}`);
+    }
+
+    const request = provider.fetchInstruments()
+      .then(instruments => {
+        // 先建好新索引再替换，解析失败时保留旧缓存
+        const entry: ExchangeInstruments = { bySymbol: new Map(), byExchangeSymbol: new Map(), loadedAt: Date.now() };
+        for (const instrument of instruments) {
+          this.index(entry, instrument);
+        }
+        const previous = this.instruments.get(exchange);
+        this.instruments.set(exchange, entry);
+        this.emit('loaded', exchange, instruments.length);
+        if (previous) {
+          this.detectListingChanges(exchange, previous);
+        }
+        return instruments;
+      })
+      .catch(error => {
+        this.emit('loadFailed', exchange, error);
+        throw error;
+      })
+      .finally(() => {
+        this.pending.delete(exchange);
+      });
+
+    this.pending.set(exchange, request);
+    return request;
+  }
+
+  /**
+   * 定时从所有数据源重新加载，默认间隔与缓存有效期相同
+   * 加载失败通过loadFailed事件通知，不影响下次刷新
+   */
+  startAutoRefresh(interval: number = this.ttl): void {
+    this.stopAutoRefresh();
+    this.refreshTimer = setInterval(() => {
+      for (const exchange of this.providers.keys()) {
+        this.load(exchange, true).catch(() => undefined);
+      }
+    }, interval);
+    this.refreshTimer.unref?.();
+  }
+
+  stopAutoRefresh(): void {
+    if (this.refreshTimer) {
+      clearInterval(this.refreshTimer);
+      this.refreshTimer = undefined;
+    }
+  }
+
+  /**
+   * 关注匹配交易对模式（如 *USDT、BTC/*）的品种上下架
+   * 模式同时与标准交易对和交易所原始交易对比较，不区分大小写
+   * 返回取消关注的函数
+   */
+  watch(patterns: string[], listener: (change: InstrumentListingChange) => void): () => void {
+    const watcher: ListingWatcher = { patterns, listener };
+    this.watchers.add(watcher);
+    return () => {
+      this.watchers.delete(watcher);
+    };
+  }
+
+  /**
+   * 查询品种，支持标准交易对或交易所原始交易对
+   */
+  get(exchange: string, symbol: string): InstrumentInfo | undefined {
+    const entry = this.instruments.get(exchange);
+    if (!entry) {
+      return undefined;
+    }
+
+    return entry.byExchangeSymbol.get(symbol.toUpperCase())
+      ?? entry.bySymbol.get(symbol.toUpperCase())
+      ?? entry.bySymbol.get(normalizeSymbol(symbol));
+  }
+
+  /**
+   * 查询品种，不存在时抛出错误
+   */
+  require(exchange: string, symbol: string): InstrumentInfo {
+    const instrument = this.get(exchange, symbol);
+    if (!instrument) {
+      throw new Error(`Unknown instrument ${symbol} on ${exchange}`);
+    }
+    return instrument;
+  }
+
+  /**
+   * 列出品种
+   */
+  list(exchange?: string): InstrumentInfo[] {
+    const entries = exchange
+      ? [this.instruments.get(exchange)].filter((entry): entry is ExchangeInstruments => !!entry)
+      : Array.from(this.instruments.values());
+    return entries.flatMap(entry => Array.from(entry.bySymbol.values()));
+  }
+
+  /**
+   * 转换为标准交易对，未注册的品种按通用规则推断
+   */
+  normalize(exchange: string, symbol: string): string {
+    return this.get(exchange, symbol)?.symbol ?? normalizeSymbol(symbol);
+  }
+
+  /**
+   * 转换为交易所原始交易对
+   */
+  toExchangeSymbol(exchange: string, symbol: string): string | undefined {
+    return this.get(exchange, symbol)?.exchangeSymbol;
+  }
+
+  /**
+   * 按价格步长取整，默认四舍五入
+   */
+  roundPrice(exchange: string, symbol: string, price: number, mode: RoundingMode = 'round'): number {
+    return roundToStep(price, this.require(exchange, symbol).tickSize, mode);
+  }
+
+  /**
+   * 按数量步长取整，默认向下取整以免超出可用余额
+   */
+  roundQuantity(exchange: string, symbol: string, quantity: number, mode: RoundingMode = 'floor'): number {
+    return roundToStep(quantity, this.require(exchange, symbol).lotSize, mode);
+  }
+
+  /**
+   * 校验下单价格和数量，返回错误列表
+   */
+  validateOrder(exchange: string, symbol: string, price: number, quantity: number): string[] {
+    const instrument = this.require(exchange, symbol);
+    const errors: string[] = [];
+
+    if (roundToStep(price, instrument.tickSize) !== price) {
+      errors.push(`Price ${price} is not a multiple of tick size ${instrument.tickSize}`);
+    }
+    if (roundToStep(quantity, instrument.lotSize) !== quantity) {
+      errors.push(`Quantity ${quantity} is not a multiple of lot size ${instrument.lotSize}`);
+    }
+    if (instrument.minQuantity !== undefined && quantity < instrument.minQuantity) {
+      errors.push(`Quantity ${quantity} is below minimum ${instrument.minQuantity}`);
+    }
+
+    const notional = price * quantity * (instrument.contractMultiplier ?? 1);
+    if (instrument.minNotional !== undefined && notional < instrument.minNotional) {
+      errors.push(`Notional ${notional} is below minimum ${instrument.minNotional}`);
+    }
+    if (instrument.active === false) {
+      errors.push(`Instrument ${instrument.symbol} is not trading`);
+    }
+
+    return errors;
+  }
+
+  /**
+   * 清空缓存
+   */
+  clear(exchange?: string): void {
+    if (exchange) {
+      this.instruments.delete(exchange);
+    } else {
+      this.instruments.clear();
+    }
+  }
+
+  /**
+   * 与上次加载的结果比较，可交易状态的变化同样视为上下架
+   */
+  private detectListingChanges(exchange: string, previous: ExchangeInstruments): void {
+    const current = this.instruments.get(exchange)?.bySymbol ?? new Map<string, InstrumentInfo>();
+    const trading = (instrument?: InstrumentInfo) => instrument !== undefined && instrument.active !== false;
+
+    const listed = Array.from(current.values()).filter(instrument =>
+      trading(instrument) && !trading(previous.bySymbol.get(instrument.symbol))
+    );
+    const delisted = Array.from(previous.bySymbol.values())
+      .filter(instrument => trading(instrument) && !trading(current.get(instrument.symbol)))
+      .map(instrument => current.get(instrument.symbol) ?? instrument);
+
+    if (listed.length === 0 && delisted.length === 0) {
+      return;
+    }
+
+    this.emit('listingChange', { exchange, listed, delisted });
+
+    for (const { patterns, listener } of this.watchers) {
+      const matches = (instrument: InstrumentInfo) => patterns.some(pattern =>
+        matchSymbolPattern(pattern, instrument.symbol) || matchSymbolPattern(pattern, instrument.exchangeSymbol)
+      );
+      const change = { exchange, listed: listed.filter(matches), delisted: delisted.filter(matches) };
+      if (change.listed.length > 0 || change.delisted.length > 0) {
+        listener(change);
+      }
+    }
+  }
+
+  private index(entry: ExchangeInstruments, instrument: InstrumentInfo): void {
+    entry.bySymbol.set(instrument.symbol, instrument);
+    entry.byExchangeSymbol.set(instrument.exchangeSymbol.toUpperCase(), instrument);
+  }
+
+  /**
+   * 获取或创建交易所缓存
+   */
+  private getEntry(exchange: string): ExchangeInstruments {
+    let entry = this.instruments.get(exchange);
+    if (!entry) {
+      entry = { bySymbol: new Map(), byExchangeSymbol: new Map(), loadedAt: 0 };
+      this.instruments.set(exchange, entry);
+    }
+    return entry;
+  }
+}
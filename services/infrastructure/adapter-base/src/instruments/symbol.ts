@@ -0,0 +1,120 @@
+/**
+ * 交易对标准化与精度取整工具
+ */
+
+/** 交易所历史资产代码别名 */
+export const ASSET_ALIASES: Record<string, string> = {
+  XBT: 'BTC',
+  XDG: 'DOGE'
+};
+
+/** 无分隔符交易对拆分时识别的计价币种，按长度优先匹配 */
+export const COMMON_QUOTES = ['FDUSD', 'USDT', 'USDC', 'BUSD', 'TUSD', 'USD', 'EUR', 'GBP', 'JPY', 'TRY', 'BTC', 'ETH', 'BNB'];
+
+export interface ParsedSymbol {
+  base: string;
+  quote: string;
+  /** 结算币种，仅合约存在 */
+  settle?: string;
+}
+
+/**
+ * 解析交易对
+ * 支持 BTC/USDT、BTC-USDT、BTCUSDT、XBT/USD、XXBTZUSD 以及合约后缀 :USDT / -SWAP
+ */
+export function parseSymbol(raw: string): ParsedSymbol | undefined {
+  const upper = raw.trim().toUpperCase();
+  const alias = (asset: string) => ASSET_ALIASES[asset] ?? asset;
+
+  const [pair, settle] = upper.split(':');
+  let parts: string[];
+
+  if (pair.includes('/')) {
+    parts = pair.split('/');
+  } else if (pair.includes('-')) {
+    parts = pair.split('-');
+    if (parts[2] === 'SWAP') {
+      // OKX永续：U本位以计价币结算，币本位以基础币结算
+      return {
+        base: alias(parts[0]),
+        quote: alias(parts[1]),
+        settle: settle ?? (['USDT', 'USDC'].includes(parts[1]) ? parts[1] : alias(parts[0]))
+      };
+    }
+  } else if (/^[XZ][A-Z]{3}[XZ][A-Z]{3}$/.test(pair)) {
+    // Kraken REST旧格式，如 XXBTZUSD
+    parts = [pair.slice(1, 4), pair.slice(5)];
+  } else {
+    const quote = COMMON_QUOTES.find(candidate => pair.endsWith(candidate) && pair.length > candidate.length);
+    if (!quote) {
+      return undefined;
+    }
+    parts = [pair.slice(0, -quote.length), quote];
+  }
+
+  if (parts.length < 2 || !parts[0] || !parts[1]) {
+    return undefined;
+  }
+
+  return {
+    base: alias(parts[0]),
+    quote: alias(parts[1]),
+    ...(settle ? { settle: alias(settle) } : {})
+  };
+}
+
+/**
+ * 标准化交易对，无法识别时返回大写原值
+ */
+export function normalizeSymbol(raw: string): string {
+  const parsed = parseSymbol(raw);
+  if (!parsed) {
+    return raw.trim().toUpperCase();
+  }
+  return formatSymbol(parsed);
+}
+
+/**
+ * 格式化为标准交易对
+ */
+export function formatSymbol(parsed: ParsedSymbol): string {
+  const pair = `${parsed.base}/${parsed.quote}`;
+  return parsed.settle ? `${pair}:${parsed.settle}` : pair;
+}
+
+/**
+ * 交易对模式匹配，* 匹配任意字符，? 匹配单个字符，不区分大小写
+ */
+export function matchSymbolPattern(pattern: string, symbol: string): boolean {
+  const source = pattern.trim().replace(/[.+^${}()|[\]\\/]/g, '\\$&').replace(/\*/g, '.*').replace(/\?/g, '.');
+  return new RegExp(`^${source}$`, 'i').test(symbol);
+}
+
+/** 取整方式 */
+export type RoundingMode = 'floor' | 'ceil' | 'round';
+
+/**
+ * 步长的小数位数，兼容科学计数法（如 1e-8）
+ */
+export function stepDecimals(step: number): number {
+  const text = step.toString();
+  const [mantissa, exponent] = text.split('e-');
+  const mantissaDecimals = (mantissa.split('.')[1] ?? '').length;
+  return exponent ? mantissaDecimals + parseInt(exponent, 10) : mantissaDecimals;
+}
+
+/**
+ * 按步长取整
+ * 先判断是否已在步长网格上，避免 0.3 / 0.1 = 2.9999999999999996 之类的浮点误差被向下取整
+ */
+export function roundToStep(value: number, step: number, mode: RoundingMode = 'round'): number {
+  if (!(step > 0)) {
+    return value;
+  }
+
+  const units = value / step;
+  const nearest = Math.round(units);
+  const steps = Math.abs(units - nearest) < 1e-9 ? nearest : Math[mode](units);
+
+  return Number((steps * step).toFixed(stepDecimals(step)));
+}
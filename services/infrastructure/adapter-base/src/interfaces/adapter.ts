@@ -3,6 +3,9 @@
  */
 
 import { EventEmitter } from 'events';
+import { ReconnectStrategy } from './connection';
+import type { ProxyPoolOptions, ProxyServerConfig } from '@pixiu/shared-core';
+import type { OrderBook } from '../orderbook/order-book';
 
 export enum AdapterStatus {
   DISCONNECTED = 'disconnected',
@@ -37,6 +40,10 @@ export interface AdapterConfig {
     maxRetries: number;
     retryInterval: number;
     heartbeatInterval: number;
+    /** 重连退避策略 */
+    reconnectStrategy?: ReconnectStrategy;
+    /** 是否协商permessage-deflate压缩 */
+    enableCompression?: boolean;
   };
   /** 认证配置 */
   auth?: {
@@ -44,12 +51,9 @@ export interface AdapterConfig {
     apiSecret?: string;
   };
   /** 代理配置 */
-  proxy?: {
-    host: string;
-    port: number;
-    username?: string;
-    password?: string;
-  };
+  proxy?: ProxyServerConfig;
+  /** 代理池配置，优先于proxy，REST与WebSocket共用 */
+  proxyPool?: ProxyPoolOptions;
   /** 自定义标签 */
   labels?: Record<string, string>;
 }
@@ -86,6 +90,17 @@ export interface AdapterMetrics {
   averageLatency: number;
   /** 数据质量分数 */
   dataQualityScore: number;
+  /** REST接口限流使用情况 */
+  restRateLimit?: RestRateLimitUsage;
+}
+
+export interface RestRateLimitUsage {
+  /** 当前窗口已使用额度 */
+  used: number;
+  /** 窗口总额度 */
+  limit: number;
+  /** 更新时间 */
+  updatedAt: number;
 }
 
 export interface AdapterEventMap {
@@ -107,6 +122,10 @@ export interface AdapterEventMap {
   subscribed: (subscription: SubscriptionInfo) => void;
   /** 取消订阅 */
   unsubscribed: (subscription: SubscriptionInfo) => void;
+  /** 代理连续失败被暂停使用，url已隐藏密码 */
+  proxyDown: (url: string, failures: number) => void;
+  /** 代理恢复 */
+  proxyUp: (url: string) => void;
 }
 
 export interface SubscriptionInfo {
@@ -195,8 +214,49 @@ export interface DepthData {
   asks: Array<[number, number]>; // [price, quantity]
   /** 更新时间 */
   updateTime: number;
+  /** 首个更新ID（增量深度） */
+  firstUpdateId?: number;
+  /** 最后更新ID（增量深度） */
+  finalUpdateId?: number;
+}
+
+export interface TradingCapabilities {
+  /** 现货交易 */
+  spot: boolean;
+  /** 杠杆交易 */
+  margin: boolean;
+  /** 合约交易 */
+  futures: boolean;
+  /** OCO订单 */
+  oco: boolean;
+  /** 只做Maker（post-only）订单 */
+  postOnly: boolean;
+}
+
+export interface AdapterCapabilities {
+  /** 支持的数据类型 */
+  dataTypes: DataType[];
+  /** 是否支持WebSocket行情推送 */
+  websocket: boolean;
+  /** 是否支持WebSocket用户数据流 */
+  userDataStream: boolean;
+  /** 是否支持组合流（单连接多订阅） */
+  combinedStreams: boolean;
+  /** 单连接最大订阅数 */
+  maxSubscriptionsPerConnection?: number;
+  /** 订单簿是否提供校验和 */
+  orderBookChecksum: boolean;
+  /** 交易能力 */
+  trading: TradingCapabilities;
 }
 
+/**
+ * 适配器能力声明，未声明的字段使用默认值
+ */
+export type AdapterCapabilitiesDeclaration = Partial<Omit<AdapterCapabilities, 'trading'>> & {
+  trading?: Partial<TradingCapabilities>;
+};
+
 /**
  * 交易适配器接口
  */
@@ -213,6 +273,9 @@ export interface ExchangeAdapter extends EventEmitter {
   /** 获取指标 */
   getMetrics(): AdapterMetrics;
   
+  /** 获取适配器能力 */
+  getCapabilities(): AdapterCapabilities;
+  
   /** 初始化适配器 */
   initialize(config: AdapterConfig): Promise<void>;
   
@@ -233,6 +296,9 @@ export interface ExchangeAdapter extends EventEmitter {
   
   /** 获取活跃订阅 */
   getSubscriptions(): SubscriptionInfo[];
+
+  /** 获取本地维护的订单簿，未订阅深度时返回undefined */
+  getOrderBook?(symbol: string): OrderBook | undefined;
   
   /** 发送心跳 */
   sendHeartbeat(): Promise<void>;
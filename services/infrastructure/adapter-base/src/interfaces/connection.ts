@@ -3,6 +3,7 @@
  */
 
 import { EventEmitter } from 'events';
+import type { ProxyPool, ProxyServerConfig } from '@pixiu/shared-core';
 
 export enum ConnectionState {
   IDLE = 'idle',
@@ -14,6 +15,25 @@ export enum ConnectionState {
   ERROR = 'error'
 }
 
+export interface ReconnectStrategy {
+  /** 指数退避基数 */
+  backoffBase?: number;
+  /** 最大重连间隔（毫秒） */
+  maxRetryInterval?: number;
+  /** 是否添加随机抖动 */
+  jitter?: boolean;
+}
+
+/**
+ * 应用层心跳消息
+ */
+export interface HeartbeatMessage {
+  /** 心跳请求，字符串原样发送，对象序列化为JSON */
+  ping: string | Record<string, unknown>;
+  /** 判断收到的消息是否为心跳响应，心跳响应不会作为message事件转发 */
+  isPong: (message: any) => boolean;
+}
+
 export interface ConnectionConfig {
   /** 连接URL */
   url: string;
@@ -27,17 +47,20 @@ export interface ConnectionConfig {
   heartbeatInterval: number;
   /** 心跳超时（毫秒） */
   heartbeatTimeout: number;
+  /** 允许连续丢失的心跳次数，超过后强制重连 */
+  maxMissedHeartbeats?: number;
+  /** 应用层心跳，交易所要求以消息而非WebSocket ping帧保活时配置 */
+  heartbeatMessage?: HeartbeatMessage;
+  /** 重连退避策略 */
+  reconnectStrategy?: ReconnectStrategy;
   /** 是否启用压缩 */
   enableCompression?: boolean;
   /** 自定义头部 */
   headers?: Record<string, string>;
   /** 代理配置 */
-  proxy?: {
-    host: string;
-    port: number;
-    username?: string;
-    password?: string;
-  };
+  proxy?: ProxyServerConfig;
+  /** 代理池，优先于proxy，每次连接轮换到健康的代理 */
+  proxyPool?: ProxyPool;
 }
 
 export interface ConnectionMetrics {
@@ -47,6 +70,8 @@ export interface ConnectionMetrics {
   connectedAt?: number;
   /** 最后活动时间 */
   lastActivity: number;
+  /** 最后心跳响应时间 */
+  lastHeartbeat?: number;
   /** 发送字节数 */
   bytesSent: number;
   /** 接收字节数 */
@@ -0,0 +1,52 @@
+/**
+ * 历史数据接口定义
+ */
+
+import { KlineData, TradeData } from './adapter';
+
+export interface HistoricalKlineQuery {
+  /** 交易对 */
+  symbol: string;
+  /** K线周期 */
+  interval: string;
+  /** 起始时间（包含） */
+  startTime: number;
+  /** 结束时间（包含） */
+  endTime?: number;
+  /** 单页最大条数 */
+  limit?: number;
+}
+
+export interface HistoricalTradeQuery {
+  /** 交易对 */
+  symbol: string;
+  /** 起始时间（包含），未指定fromId时生效 */
+  startTime?: number;
+  /** 结束时间（包含） */
+  endTime?: number;
+  /** 从指定成交ID开始（包含） */
+  fromId?: string;
+  /** 单页最大条数 */
+  limit?: number;
+}
+
+/**
+ * 历史数据源
+ * 每次调用返回一页按时间升序排列的数据，分页由调用方推进
+ */
+export interface HistoricalDataSource {
+  /** 交易所名称 */
+  readonly exchange: string;
+  /** 单页最大条数 */
+  readonly maxPageSize: number;
+
+  /**
+   * 获取一页历史K线
+   */
+  fetchKlines(query: HistoricalKlineQuery): Promise<KlineData[]>;
+
+  /**
+   * 获取一页历史成交
+   */
+  fetchTrades(query: HistoricalTradeQuery): Promise<TradeData[]>;
+}
@@ -0,0 +1,49 @@
+/**
+ * 交易品种元数据接口定义
+ */
+
+/** 品种类型 */
+export type InstrumentType = 'spot' | 'swap' | 'future';
+
+export interface InstrumentInfo {
+  /** 交易所名称 */
+  exchange: string;
+  /** 标准交易对，如 BTC/USDT、BTC/USDT:USDT */
+  symbol: string;
+  /** 交易所原始交易对，如 BTCUSDT、BTC-USDT-SWAP */
+  exchangeSymbol: string;
+  /** 基础币种 */
+  base: string;
+  /** 计价币种 */
+  quote: string;
+  /** 结算币种（合约） */
+  settle?: string;
+  /** 品种类型 */
+  type: InstrumentType;
+  /** 价格最小变动单位 */
+  tickSize: number;
+  /** 数量最小变动单位 */
+  lotSize: number;
+  /** 最小下单数量 */
+  minQuantity?: number;
+  /** 最小下单金额 */
+  minNotional?: number;
+  /** 合约乘数（每张合约对应的基础币种数量），现货为1 */
+  contractMultiplier?: number;
+  /** 是否可交易 */
+  active?: boolean;
+}
+
+/**
+ * 品种元数据来源
+ * 通常由交易所适配器通过exchangeInfo类接口实现
+ */
+export interface InstrumentProvider {
+  /** 交易所名称 */
+  readonly exchange: string;
+
+  /**
+   * 获取交易所全部品种
+   */
+  fetchInstruments(): Promise<InstrumentInfo[]>;
+}
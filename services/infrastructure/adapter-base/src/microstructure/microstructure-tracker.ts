@@ -0,0 +1,240 @@
+/**
+ * 市场微观结构特征
+ * 基于L2订单簿与逐笔成交计算滚动窗口内的失衡度、价差与短周期已实现波动率
+ */
+
+import { TradeData } from '../interfaces/adapter';
+
+/** 订单簿前N档 [价格, 数量]，买盘价格降序，卖盘价格升序 */
+export interface BookLevels {
+  bids: Array<[number, number]>;
+  asks: Array<[number, number]>;
+}
+
+export interface MicrostructureFeatures {
+  /** 交易对 */
+  symbol: string;
+  /** 计算时间 */
+  timestamp: number;
+  /** 中间价 */
+  mid?: number;
+  /** 按买一卖一数量加权的微观价格 */
+  microprice?: number;
+  /** 买卖价差 */
+  spread?: number;
+  /** 价差占中间价的基点数 */
+  spreadBps?: number;
+  /** 买一卖一数量失衡度，取值[-1, 1]，正值表示买盘更强 */
+  queueImbalance?: number;
+  /** 前N档数量失衡度，取值[-1, 1] */
+  depthImbalance?: number;
+  /** 窗口内主动买卖成交量失衡度，取值[-1, 1]，正值表示主动买入更多 */
+  tradeFlowImbalance?: number;
+  /** 窗口内成交笔数 */
+  tradeCount: number;
+  /** 窗口内成交量 */
+  tradeVolume: number;
+  /** 窗口内中间价对数收益率的已实现波动率（未年化） */
+  realizedVolatility?: number;
+}
+
+export interface MicrostructureTrackerConfig {
+  /** 滚动窗口（毫秒），默认10秒 */
+  window?: number;
+  /** 计算深度失衡度的档位数，默认5 */
+  depthLevels?: number;
+}
+
+interface TradeSample {
+  timestamp: number;
+  quantity: number;
+  side: 'buy' | 'sell';
+}
+
+interface MidSample {
+  timestamp: number;
+  mid: number;
+}
+
+interface SymbolState {
+  book?: BookLevels;
+  bookTimestamp: number;
+  trades: TradeSample[];
+  mids: MidSample[];
+}
+
+/**
+ * 微观结构特征跟踪器
+ * 不持有定时器，窗口在写入与查询时按时间戳裁剪
+ */
+export class MicrostructureTracker {
+  private readonly window: number;
+  private readonly depthLevels: number;
+  private readonly symbols = new Map<string, SymbolState>();
+
+  constructor(config: MicrostructureTrackerConfig = {}) {
+    this.window = config.window ?? 10000;
+    this.depthLevels = config.depthLevels ?? 5;
+    if (!(this.window > 0)) {
+      throw new Error(`Invalid microstructure window: ${config.window}`);
+    }
+  }
+
+  /**
+   * 更新订单簿前N档，返回最新特征
+   * 中间价变化时记入波动率样本
+   */
+  updateBook(symbol: string, book: BookLevels, timestamp = Date.now()): MicrostructureFeatures {
+    const state = this.getState(symbol);
+    state.book = book;
+    state.bookTimestamp = timestamp;
+
+    const mid = this.mid(book);
+    const last = state.mids[state.mids.length - 1];
+    if (mid !== undefined && (!last || last.mid !== mid)) {
+      state.mids.push({ timestamp, mid });
+    }
+
+    return this.getFeatures(symbol, timestamp)!;
+  }
+
+  /**
+   * 输入一笔成交
+   */
+  addTrade(symbol: string, trade: TradeData): void {
+    const state = this.getState(symbol);
+    state.trades.push({ timestamp: trade.timestamp, quantity: trade.quantity, side: trade.side });
+    this.evict(state, trade.timestamp);
+  }
+
+  /**
+   * 计算当前特征，未收到任何数据时返回undefined
+   */
+  getFeatures(symbol: string, now = Date.now()): MicrostructureFeatures | undefined {
+    const state = this.symbols.get(symbol);
+    if (!state) {
+      return undefined;
+    }
+    this.evict(state, now);
+
+    const features: MicrostructureFeatures = {
+      symbol,
+      timestamp: now,
+      tradeCount: state.trades.length,
+      tradeVolume: 0
+    };
+
+    let buyVolume = 0;
+    for (const trade of state.trades) {
+      features.tradeVolume += trade.quantity;
+      if (trade.side === 'buy') {
+        buyVolume += trade.quantity;
+      }
+    }
+    if (features.tradeVolume > 0) {
+      features.tradeFlowImbalance = (2 * buyVolume - features.tradeVolume) / features.tradeVolume;
+    }
+
+    const book = state.book;
+    const bid = book?.bids[0];
+    const ask = book?.asks[0];
+    if (book && bid && ask) {
+      const [bidPrice, bidQuantity] = bid;
+      const [askPrice, askQuantity] = ask;
+      features.mid = (bidPrice + askPrice) / 2;
+      features.spread = askPrice - bidPrice;
+      features.spreadBps = features.mid > 0 ? (features.spread / features.mid) * 10000 : undefined;
+      features.queueImbalance = this.imbalance(bidQuantity, askQuantity);
+      // 买盘数量大时价格更可能上行，微观价格向卖一靠拢
+      features.microprice = bidQuantity + askQuantity > 0
+        ? (bidPrice * askQuantity + askPrice * bidQuantity) / (bidQuantity + askQuantity)
+        : features.mid;
+      features.depthImbalance = this.imbalance(
+        this.sumQuantity(book.bids.slice(0, this.depthLevels)),
+        this.sumQuantity(book.asks.slice(0, this.depthLevels))
+      );
+    }
+
+    features.realizedVolatility = this.realizedVolatility(state.mids);
+    return features;
+  }
+
+  /**
+   * 已跟踪的交易对
+   */
+  getSymbols(): string[] {
+    return Array.from(this.symbols.keys());
+  }
+
+  /**
+   * 清除交易对状态，未指定时全部清除
+   */
+  clear(symbol?: string): void {
+    if (symbol) {
+      this.symbols.delete(symbol);
+    } else {
+      this.symbols.clear();
+    }
+  }
+
+  private getState(symbol: string): SymbolState {
+    let state = this.symbols.get(symbol);
+    if (!state) {
+      state = { bookTimestamp: 0, trades: [], mids: [] };
+      this.symbols.set(symbol, state);
+    }
+    return state;
+  }
+
+  /**
+   * 裁剪窗口外的样本
+   * 波动率保留窗口起点前的最后一个中间价，作为窗口内第一个收益率的基准
+   */
+  private evict(state: SymbolState, now: number): void {
+    const cutoff = now - this.window;
+
+    let expiredTrades = 0;
+    while (expiredTrades < state.trades.length && state.trades[expiredTrades].timestamp <= cutoff) {
+      expiredTrades++;
+    }
+    if (expiredTrades > 0) {
+      state.trades.splice(0, expiredTrades);
+    }
+
+    let expiredMids = 0;
+    while (expiredMids + 1 < state.mids.length && state.mids[expiredMids + 1].timestamp <= cutoff) {
+      expiredMids++;
+    }
+    if (expiredMids > 0) {
+      state.mids.splice(0, expiredMids);
+    }
+  }
+
+  private realizedVolatility(mids: MidSample[]): number | undefined {
+    if (mids.length < 2) {
+      return undefined;
+    }
+
+    let sumSquares = 0;
+    for (let i = 1; i < mids.length; i++) {
+      const logReturn = Math.log(mids[i].mid / mids[i - 1].mid);
+      sumSquares += logReturn * logReturn;
+    }
+    return Math.sqrt(sumSquares);
+  }
+
+  private mid(book: BookLevels): number | undefined {
+    const bid = book.bids[0];
+    const ask = book.asks[0];
+    return bid && ask ? (bid[0] + ask[0]) / 2 : undefined;
+  }
+
+  private imbalance(bidQuantity: number, askQuantity: number): number | undefined {
+    const total = bidQuantity + askQuantity;
+    return total > 0 ? (bidQuantity - askQuantity) / total : undefined;
+  }
+
+  private sumQuantity(levels: Array<[number, number]>): number {
+    return levels.reduce((sum, [, quantity]) => sum + quantity, 0);
+  }
+}
@@ -0,0 +1,74 @@
+/**
+ * 订单簿校验和算法
+ * 实现Kraken与OKX的CRC32校验和规则
+ */
+
+import { OrderBookEntry } from './order-book';
+
+const CRC32_TABLE = buildCrc32Table();
+
+/**
+ * 构建CRC32查找表
+ */
+function buildCrc32Table(): Uint32Array {
+  const table = new Uint32Array(256);
+  for (let i = 0; i < 256; i++) {
+    let c = i;
+    for (let k = 0; k < 8; k++) {
+      c = c & 1 ? 0xedb88320 ^ (c >>> 1) : c >>> 1;
+    }
+    table[i] = c >>> 0;
+  }
+  return table;
+}
+
+/**
+ * 计算字符串的CRC32（无符号）
+ */
+export function crc32(input: string): number {
+  const bytes = Buffer.from(input, 'utf8');
+  let crc = 0xffffffff;
+  for (const byte of bytes) {
+    crc = CRC32_TABLE[(crc ^ byte) & 0xff] ^ (crc >>> 8);
+  }
+  return (crc ^ 0xffffffff) >>> 0;
+}
+
+/**
+ * Kraken校验和
+ * 取前10档卖盘（升序）和前10档买盘（降序），价格和数量去掉小数点及前导零后依次拼接
+ */
+export function krakenChecksum(bids: OrderBookEntry[], asks: OrderBookEntry[]): number {
+  const format = (value: string) => value.replace('.', '').replace(/^0+/, '');
+  const parts: string[] = [];
+
+  for (const level of asks.slice(0, 10)) {
+    parts.push(format(level.rawPrice), format(level.rawQuantity));
+  }
+  for (const level of bids.slice(0, 10)) {
+    parts.push(format(level.rawPrice), format(level.rawQuantity));
+  }
+
+  return crc32(parts.join(''));
+}
+
+/**
+ * OKX校验和
+ * 前25档买卖盘交替拼接为 price:size，结果为有符号32位整数
+ */
+export function okxChecksum(bids: OrderBookEntry[], asks: OrderBookEntry[]): number {
+  const parts: string[] = [];
+
+  for (let i = 0; i < 25; i++) {
+    const bid = bids[i];
+    const ask = asks[i];
+    if (bid) {
+      parts.push(`${bid.rawPrice}:${bid.rawQuantity}`);
+    }
+    if (ask) {
+      parts.push(`${ask.rawPrice}:${ask.rawQuantity}`);
+    }
+  }
+
+  return crc32(parts.join(':')) | 0;
+}
@@ -0,0 +1,521 @@
+/**
+ * L2订单簿重建
+ * 基于快照与增量维护有序买卖盘，支持序列缺口检测、校验和验证与自动重新快照
+ */
+
+import { EventEmitter } from 'events';
+import { DepthData } from '../interfaces/adapter';
+
+/** 价格档位输入（交易所原始字符串或数值） */
+export type OrderBookLevelInput = [string | number, string | number];
+
+export interface OrderBookEntry {
+  /** 价格 */
+  price: number;
+  /** 数量 */
+  quantity: number;
+  /** 交易所原始价格字符串（用于校验和） */
+  rawPrice: string;
+  /** 交易所原始数量字符串（用于校验和） */
+  rawQuantity: string;
+}
+
+export interface OrderBookSnapshot {
+  /** 快照对应的最后更新ID */
+  lastUpdateId: number;
+  /** 买盘 */
+  bids: OrderBookLevelInput[];
+  /** 卖盘 */
+  asks: OrderBookLevelInput[];
+  /** 快照时间 */
+  timestamp?: number;
+}
+
+export interface OrderBookDelta {
+  /** 本次增量的首个更新ID */
+  firstUpdateId?: number;
+  /** 本次增量的最后更新ID */
+  finalUpdateId?: number;
+  /** 上一次增量的最后更新ID（部分交易所提供，如Binance合约的pu字段） */
+  prevFinalUpdateId?: number;
+  /** 买盘变更，数量为0表示删除该档位 */
+  bids: OrderBookLevelInput[];
+  /** 卖盘变更，数量为0表示删除该档位 */
+  asks: OrderBookLevelInput[];
+  /** 交易所提供的校验和 */
+  checksum?: number;
+  /** 更新时间 */
+  timestamp?: number;
+}
+
+/** 校验和计算函数，输入为按优先级排序的买卖盘 */
+export type OrderBookChecksumFn = (bids: OrderBookEntry[], asks: OrderBookEntry[]) => number;
+
+export interface OrderBookConfig {
+  /** 交易对 */
+  symbol: string;
+  /** 最大维护档位数（不设置则不截断） */
+  maxDepth?: number;
+  /** 校验和算法，增量携带checksum时用于验证 */
+  checksum?: OrderBookChecksumFn;
+  /** 参与校验和计算的档位数 */
+  checksumDepth?: number;
+  /** 快照获取函数，检测到缺口时自动调用 */
+  snapshotProvider?: (symbol: string) => Promise<OrderBookSnapshot>;
+  /** 等待快照期间最大缓存的增量数 */
+  maxBufferedDeltas?: number;
+  /** 两次重新快照之间的最小间隔（毫秒），连续失败时每次翻倍，默认1秒 */
+  resyncRetryInterval?: number;
+  /** 连续失败时重新快照间隔的上限（毫秒），默认30秒 */
+  maxResyncRetryInterval?: number;
+}
+
+/**
+ * 单边有序档位
+ */
+class BookSide {
+  private prices: number[] = [];
+  private levels = new Map<number, OrderBookEntry>();
+
+  constructor(private readonly descending: boolean) {}
+
+  get size(): number {
+    return this.prices.length;
+  }
+
+  set(entry: OrderBookEntry): void {
+    if (!this.levels.has(entry.price)) {
+      this.prices.splice(this.findIndex(entry.price), 0, entry.price);
+    }
+    this.levels.set(entry.price, entry);
+  }
+
+  remove(price: number): void {
+    if (!this.levels.delete(price)) {
+      return;
+    }
+    this.prices.splice(this.findIndex(price), 1);
+  }
+
+  best(): OrderBookEntry | undefined {
+    return this.prices.length > 0 ? this.levels.get(this.prices[0]) : undefined;
+  }
+
+  top(count?: number): OrderBookEntry[] {
+    const prices = count === undefined ? this.prices : this.prices.slice(0, count);
+    return prices.map(price => this.levels.get(price)!);
+  }
+
+  truncate(depth: number): void {
+    for (const price of this.prices.splice(depth)) {
+      this.levels.delete(price);
+    }
+  }
+
+  clear(): void {
+    this.prices = [];
+    this.levels.clear();
+  }
+
+  /**
+   * 二分查找价格应处的位置
+   */
+  private findIndex(price: number): number {
+    let low = 0;
+    let high = this.prices.length;
+    while (low < high) {
+      const mid = (low + high) >>> 1;
+      const before = this.descending ? this.prices[mid] > price : this.prices[mid] < price;
+      if (before) {
+        low = mid + 1;
+      } else {
+        high = mid;
+      }
+    }
+    return low;
+  }
+}
+
+/**
+ * L2订单簿
+ *
+ * 未同步时收到的增量会被缓存，快照到达后按更新ID重放；
+ * 检测到序列缺口或校验和不一致时清空订单簿并重新获取快照。
+ * 快照请求失败，或未配置快照函数时发出resyncRequired后仍未收到快照，按退避间隔再次尝试，直到快照到达。
+ */
+export class OrderBook extends EventEmitter {
+  public readonly symbol: string;
+
+  private readonly config: OrderBookConfig;
+  private readonly bids = new BookSide(true);
+  private readonly asks = new BookSide(false);
+  private buffer: OrderBookDelta[] = [];
+  private lastUpdateId = -1;
+  private synced = false;
+  private awaitingFirstDelta = false;
+  private resyncing = false;
+  private lastResyncAttempt = 0;
+  private resyncAttempts = 0;
+  private resyncTimer?: NodeJS.Timeout;
+  private updatedAt = 0;
+
+  constructor(config: OrderBookConfig) {
+    super();
+    this.config = config;
+    this.symbol = config.symbol;
+  }
+
+  /**
+   * 应用全量快照，并重放快照之后的缓存增量
+   */
+  applySnapshot(snapshot: OrderBookSnapshot): void {
+    this.cancelResync();
+    this.resyncAttempts = 0;
+    this.bids.clear();
+    this.asks.clear();
+    this.applyLevels(this.bids, snapshot.bids);
+    this.applyLevels(this.asks, snapshot.asks);
+    this.truncate();
+
+    this.lastUpdateId = snapshot.lastUpdateId;
+    this.updatedAt = snapshot.timestamp ?? Date.now();
+    this.synced = true;
+    this.awaitingFirstDelta = true;
+
+    this.emit('snapshot', this);
+
+    const buffered = this.buffer;
+    this.buffer = [];
+    for (const delta of buffered) {
+      if (!this.synced) {
+        this.bufferDelta(delta);
+        continue;
+      }
+      this.applyDelta(delta);
+    }
+  }
+
+  /**
+   * 应用增量更新
+   * @returns 增量是否已应用到订单簿
+   */
+  applyDelta(delta: OrderBookDelta): boolean {
+    if (!this.synced) {
+      this.bufferDelta(delta);
+      this.requestResync();
+      return false;
+    }
+
+    if (delta.finalUpdateId !== undefined) {
+      // 快照已包含的旧增量
+      if (delta.finalUpdateId <= this.lastUpdateId) {
+        return false;
+      }
+
+      if (!this.isContinuous(delta)) {
+        this.emit('gap', {
+          symbol: this.symbol,
+          expected: this.lastUpdateId + 1,
+          received: delta.firstUpdateId ?? delta.finalUpdateId
+        });
+        this.invalidate();
+        this.bufferDelta(delta);
+        this.requestResync();
+        return false;
+      }
+
+      this.lastUpdateId = delta.finalUpdateId;
+    }
+
+    this.applyLevels(this.bids, delta.bids);
+    this.applyLevels(this.asks, delta.asks);
+    this.truncate();
+    this.updatedAt = delta.timestamp ?? Date.now();
+    this.awaitingFirstDelta = false;
+
+    if (delta.checksum !== undefined && this.config.checksum && !this.verifyChecksum(delta.checksum)) {
+      this.invalidate();
+      this.requestResync();
+      return false;
+    }
+
+    this.emit('update', this);
+    return true;
+  }
+
+  /**
+   * 请求重新快照
+   */
+  resync(): void {
+    this.invalidate();
+    this.requestResync();
+  }
+
+  /**
+   * 清空订单簿并丢弃缓存
+   */
+  reset(): void {
+    this.invalidate();
+    this.cancelResync();
+    this.resyncAttempts = 0;
+    this.buffer = [];
+  }
+
+  /**
+   * 是否已与交易所同步
+   */
+  isSynced(): boolean {
+    return this.synced;
+  }
+
+  /**
+   * 获取最后更新ID
+   */
+  getLastUpdateId(): number {
+    return this.lastUpdateId;
+  }
+
+  /**
+   * 获取最后更新时间
+   */
+  getUpdatedAt(): number {
+    return this.updatedAt;
+  }
+
+  /**
+   * 最优买价
+   */
+  bestBid(): OrderBookEntry | undefined {
+    return this.bids.best();
+  }
+
+  /**
+   * 最优卖价
+   */
+  bestAsk(): OrderBookEntry | undefined {
+    return this.asks.best();
+  }
+
+  /**
+   * 中间价
+   */
+  mid(): number | undefined {
+    const bid = this.bids.best();
+    const ask = this.asks.best();
+    if (!bid || !ask) {
+      return undefined;
+    }
+    return (bid.price + ask.price) / 2;
+  }
+
+  /**
+   * 买卖价差
+   */
+  spread(): number | undefined {
+    const bid = this.bids.best();
+    const ask = this.asks.best();
+    if (!bid || !ask) {
+      return undefined;
+    }
+    return ask.price - bid.price;
+  }
+
+  /**
+   * 前N档买卖量失衡度，取值范围[-1, 1]，正值表示买盘更强
+   */
+  imbalance(levels = 5): number | undefined {
+    const bidVolume = this.sumQuantity(this.bids.top(levels));
+    const askVolume = this.sumQuantity(this.asks.top(levels));
+    const total = bidVolume + askVolume;
+    if (total === 0) {
+      return undefined;
+    }
+    return (bidVolume - askVolume) / total;
+  }
+
+  /**
+   * 获取买盘（价格降序）
+   */
+  getBids(levels?: number): OrderBookEntry[] {
+    return this.bids.top(levels);
+  }
+
+  /**
+   * 获取卖盘（价格升序）
+   */
+  getAsks(levels?: number): OrderBookEntry[] {
+    return this.asks.top(levels);
+  }
+
+  /**
+   * 转换为深度数据
+   */
+  toDepthData(levels?: number): DepthData {
+    return {
+      bids: this.bids.top(levels).map(level => [level.price, level.quantity]),
+      asks: this.asks.top(levels).map(level => [level.price, level.quantity]),
+      updateTime: this.updatedAt
+    };
+  }
+
+  /**
+   * 检查增量是否与当前更新ID连续
+   */
+  private isContinuous(delta: OrderBookDelta): boolean {
+    const expected = this.lastUpdateId + 1;
+
+    if (this.awaitingFirstDelta && delta.firstUpdateId !== undefined) {
+      // 快照后的第一条增量需覆盖 lastUpdateId + 1
+      return delta.firstUpdateId <= expected;
+    }
+
+    if (delta.prevFinalUpdateId !== undefined) {
+      return delta.prevFinalUpdateId === this.lastUpdateId;
+    }
+
+    return (delta.firstUpdateId ?? expected) === expected;
+  }
+
+  /**
+   * 验证校验和
+   */
+  private verifyChecksum(expected: number): boolean {
+    const depth = this.config.checksumDepth ?? 25;
+    const actual = this.config.checksum!(this.bids.top(depth), this.asks.top(depth));
+    if ((actual | 0) === (expected | 0)) {
+      return true;
+    }
+
+    this.emit('checksumMismatch', {
+      symbol: this.symbol,
+      expected,
+      actual
+    });
+    return false;
+  }
+
+  /**
+   * 应用档位变更
+   */
+  private applyLevels(side: BookSide, levels: OrderBookLevelInput[]): void {
+    for (const [rawPrice, rawQuantity] of levels) {
+      const price = typeof rawPrice === 'number' ? rawPrice : parseFloat(rawPrice);
+      const quantity = typeof rawQuantity === 'number' ? rawQuantity : parseFloat(rawQuantity);
+
+      if (quantity === 0) {
+        side.remove(price);
+      } else {
+        side.set({
+          price,
+          quantity,
+          rawPrice: String(rawPrice),
+          rawQuantity: String(rawQuantity)
+        });
+      }
+    }
+  }
+
+  /**
+   * 按最大深度截断
+   */
+  private truncate(): void {
+    if (this.config.maxDepth) {
+      this.bids.truncate(this.config.maxDepth);
+      this.asks.truncate(this.config.maxDepth);
+    }
+  }
+
+  /**
+   * 缓存增量，超过上限时丢弃最旧的增量
+   */
+  private bufferDelta(delta: OrderBookDelta): void {
+    this.buffer.push(delta);
+    const maxBuffered = this.config.maxBufferedDeltas ?? 1000;
+    if (this.buffer.length > maxBuffered) {
+      this.buffer.splice(0, this.buffer.length - maxBuffered);
+    }
+  }
+
+  /**
+   * 标记为未同步并清空档位
+   */
+  private invalidate(): void {
+    this.synced = false;
+    this.awaitingFirstDelta = false;
+    this.lastUpdateId = -1;
+    this.bids.clear();
+    this.asks.clear();
+  }
+
+  /**
+   * 发起快照请求
+   */
+  private requestResync(): void {
+    if (this.resyncing || this.resyncTimer) {
+      return;
+    }
+
+    const delay = this.lastResyncAttempt + this.getResyncInterval() - Date.now();
+    if (delay > 0) {
+      this.resyncTimer = setTimeout(() => {
+        this.resyncTimer = undefined;
+        if (!this.synced) {
+          this.requestResync();
+        }
+      }, delay);
+      this.resyncTimer.unref?.();
+      return;
+    }
+
+    this.lastResyncAttempt = Date.now();
+    this.resyncAttempts++;
+
+    const provider = this.config.snapshotProvider;
+    if (!provider) {
+      // 由调用方重新订阅获取快照，到下次间隔仍未收到快照时再次通知
+      this.emit('resyncRequired', this.symbol);
+      if (!this.synced) {
+        this.requestResync();
+      }
+      return;
+    }
+
+    this.resyncing = true;
+    provider(this.symbol)
+      .then(snapshot => {
+        this.resyncing = false;
+        this.applySnapshot(snapshot);
+        this.emit('resynced', this);
+      })
+      .catch(error => {
+        this.resyncing = false;
+        this.emit('resyncFailed', error);
+        if (!this.synced) {
+          this.requestResync();
+        }
+      });
+  }
+
+  /**
+   * 距上次尝试的最小间隔，连续尝试未能同步时指数退避
+   */
+  private getResyncInterval(): number {
+    const interval = this.config.resyncRetryInterval ?? 1000;
+    const backoff = interval * 2 ** Math.max(0, this.resyncAttempts - 1);
+    return Math.min(backoff, this.config.maxResyncRetryInterval ?? 30000);
+  }
+
+  private cancelResync(): void {
+    if (this.resyncTimer) {
+      clearTimeout(this.resyncTimer);
+      this.resyncTimer = undefined;
+    }
+  }
+
+  /**
+   * 汇总档位数量
+   */
+  private sumQuantity(levels: OrderBookEntry[]): number {
+    return levels.reduce((sum, level) => sum + level.quantity, 0);
+  }
+}
@@ -0,0 +1,158 @@
+/**
+ * CandleAggregator单元测试
+ * 覆盖时间K线、成交量K线、笔数K线以及迟到成交处理
+ */
+
+import { CandleAggregator, TradeData, parseCandleInterval } from '../src';
+
+function trade(timestamp: number, price: number, quantity = 1): TradeData {
+  return { id: `${timestamp}-${price}`, price, quantity, side: 'buy', timestamp };
+}
+
+describe('CandleAggregator', () => {
+  let aggregator: CandleAggregator;
+
+  afterEach(() => {
+    aggregator?.destroy();
+  });
+
+  describe('周期解析', () => {
+    it('应该解析秒到天的周期', () => {
+      expect(parseCandleInterval('1s')).toBe(1000);
+      expect(parseCandleInterval('15m')).toBe(15 * 60 * 1000);
+      expect(parseCandleInterval('4h')).toBe(4 * 60 * 60 * 1000);
+      expect(parseCandleInterval('1d')).toBe(24 * 60 * 60 * 1000);
+    });
+
+    it('应该拒绝无效的周期', () => {
+      expect(() => parseCandleInterval('0m')).toThrow('Invalid candle interval');
+      expect(() => new CandleAggregator({ specs: [{ kind: 'time', interval: '1w' }] })).toThrow();
+    });
+  });
+
+  describe('时间K线', () => {
+    it('应该在新周期的首笔成交到达时收盘', () => {
+      aggregator = new CandleAggregator({ specs: [{ kind: 'time', interval: '1m' }] });
+      const closed = jest.fn();
+      aggregator.on('candleClosed', closed);
+
+      aggregator.addTrade('BTC/USDT', trade(60000, 100, 1));
+      aggregator.addTrade('BTC/USDT', trade(70000, 105, 2));
+      aggregator.addTrade('BTC/USDT', trade(80000, 95, 1));
+      aggregator.addTrade('BTC/USDT', trade(119999, 101, 1));
+      aggregator.addTrade('BTC/USDT', trade(120000, 102, 1));
+
+      expect(closed).toHaveBeenCalledTimes(1);
+      expect(closed.mock.calls[0][0]).toMatchObject({
+        symbol: 'BTC/USDT',
+        interval: '1m',
+        open: 100,
+        high: 105,
+        low: 95,
+        close: 101,
+        volume: 5,
+        trades: 4,
+        openTime: 60000,
+        closeTime: 119999,
+        closed: true
+      });
+      expect(aggregator.getCurrentCandle('BTC/USDT', { kind: 'time', interval: '1m' })).toMatchObject({
+        open: 102,
+        openTime: 120000
+      });
+    });
+
+    it('应该在周期结束后由定时检查收盘', () => {
+      aggregator = new CandleAggregator({ specs: [{ kind: 'time', interval: '1s' }] });
+      const closed = jest.fn();
+      aggregator.on('candleClosed', closed);
+
+      aggregator.addTrade('BTC/USDT', trade(1000, 100));
+      aggregator.flush(1999);
+      expect(closed).not.toHaveBeenCalled();
+
+      aggregator.flush(2000);
+      expect(closed).toHaveBeenCalledTimes(1);
+    });
+
+    it('启用补齐时应该为无成交周期生成平盘K线', () => {
+      aggregator = new CandleAggregator({ specs: [{ kind: 'time', interval: '1s' }], fillGaps: true });
+      const closed = jest.fn();
+      aggregator.on('candleClosed', closed);
+
+      aggregator.addTrade('BTC/USDT', trade(1000, 100));
+      aggregator.addTrade('BTC/USDT', trade(4500, 110));
+
+      expect(closed).toHaveBeenCalledTimes(3);
+      expect(closed.mock.calls[1][0]).toMatchObject({ openTime: 2000, open: 100, close: 100, volume: 0, trades: 0 });
+      expect(closed.mock.calls[2][0]).toMatchObject({ openTime: 3000, volume: 0 });
+    });
+
+    it('默认应该丢弃迟到成交', () => {
+      aggregator = new CandleAggregator({ specs: [{ kind: 'time', interval: '1s' }] });
+      const late = jest.fn();
+      aggregator.on('lateTrade', late);
+
+      aggregator.addTrade('BTC/USDT', trade(1000, 100));
+      aggregator.addTrade('BTC/USDT', trade(2000, 101));
+      aggregator.addTrade('BTC/USDT', trade(1500, 99));
+
+      expect(late).toHaveBeenCalledWith('BTC/USDT', expect.objectContaining({ price: 99 }), '1s');
+      expect(aggregator.getDroppedTradeCount()).toBe(1);
+      expect(aggregator.getLastClosedCandle('BTC/USDT', { kind: 'time', interval: '1s' })?.low).toBe(100);
+    });
+
+    it('revise策略下应该在允许窗口内修正已收盘K线', () => {
+      aggregator = new CandleAggregator({
+        specs: [{ kind: 'time', interval: '1s' }],
+        lateTradePolicy: 'revise',
+        allowedLateness: 5000
+      });
+      const revised = jest.fn();
+      aggregator.on('candleRevised', revised);
+
+      aggregator.addTrade('BTC/USDT', trade(1000, 100));
+      aggregator.addTrade('BTC/USDT', trade(2000, 101));
+      aggregator.addTrade('BTC/USDT', trade(1500, 99, 3));
+
+      expect(revised).toHaveBeenCalledWith(expect.objectContaining({ openTime: 1000, low: 99, close: 99, volume: 4 }));
+      expect(aggregator.getDroppedTradeCount()).toBe(0);
+    });
+  });
+
+  describe('成交量与笔数K线', () => {
+    it('累计成交量达到阈值时应该收盘', () => {
+      aggregator = new CandleAggregator({ specs: [{ kind: 'volume', threshold: 10 }] });
+      const closed = jest.fn();
+      aggregator.on('candleClosed', closed);
+
+      aggregator.addTrade('ETH/USDT', trade(1, 10, 4));
+      aggregator.addTrade('ETH/USDT', trade(2, 12, 4));
+      expect(closed).not.toHaveBeenCalled();
+
+      aggregator.addTrade('ETH/USDT', trade(3, 11, 5));
+      expect(closed).toHaveBeenCalledWith(expect.objectContaining({
+        interval: 'volume_10',
+        open: 10,
+        high: 12,
+        close: 11,
+        volume: 13,
+        openTime: 1,
+        closeTime: 3
+      }));
+    });
+
+    it('成交笔数达到设定值时应该收盘', () => {
+      aggregator = new CandleAggregator({ specs: [{ kind: 'tick', count: 2 }] });
+      const closed = jest.fn();
+      aggregator.on('candleClosed', closed);
+
+      aggregator.addTrade('ETH/USDT', trade(1, 10));
+      aggregator.addTrade('ETH/USDT', trade(2, 11));
+      aggregator.addTrade('ETH/USDT', trade(3, 12));
+
+      expect(closed).toHaveBeenCalledTimes(1);
+      expect(closed.mock.calls[0][0]).toMatchObject({ interval: 'tick_2', trades: 2, close: 11 });
+    });
+  });
+});
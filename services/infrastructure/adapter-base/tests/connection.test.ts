@@ -0,0 +1,195 @@
+/**
+ * BaseConnectionManager单元测试
+ * 覆盖指数退避重连、心跳和断线恢复逻辑
+ */
+
+import { Agent } from 'http';
+import { ProxyPool } from '@pixiu/shared-core';
+import { BaseConnectionManager, ConnectionConfig, ConnectionState } from '../src';
+
+jest.mock('ws', () => {
+  const { EventEmitter } = require('events');
+
+  class MockWebSocket extends EventEmitter {
+    static OPEN = 1;
+    static instances: any[] = [];
+
+    public readyState = 0;
+    public sent: any[] = [];
+
+    constructor(public url: string, public options?: any) {
+      super();
+      MockWebSocket.instances.push(this);
+      setImmediate(() => {
+        this.readyState = 1;
+        this.emit('open');
+      });
+    }
+
+    send(data: any, callback?: (error?: Error) => void) {
+      this.sent.push(data);
+      callback?.();
+    }
+
+    ping() {
+      setImmediate(() => this.emit('pong'));
+    }
+
+    close(code?: number, reason?: string) {
+      this.readyState = 3;
+      this.emit('close', code ?? 1005, Buffer.from(reason ?? ''));
+    }
+  }
+
+  return { __esModule: true, default: MockWebSocket };
+});
+
+const MockWebSocket = (jest.requireMock('ws') as any).default;
+
+class TestConnectionManager extends BaseConnectionManager {
+  getReconnectDelay(attempt: number): number {
+    return this.calculateReconnectDelay(attempt);
+  }
+}
+
+describe('BaseConnectionManager', () => {
+  let manager: TestConnectionManager;
+  const config: ConnectionConfig = {
+    url: 'wss://example.com/ws',
+    timeout: 1000,
+    maxRetries: 3,
+    retryInterval: 10,
+    heartbeatInterval: 0,
+    heartbeatTimeout: 100
+  };
+
+  beforeEach(() => {
+    MockWebSocket.instances = [];
+    manager = new TestConnectionManager();
+  });
+
+  afterEach(async () => {
+    await manager.destroy();
+  });
+
+  describe('重连退避', () => {
+    it('未配置退避策略时应使用固定重连间隔', async () => {
+      await manager.connect(config);
+
+      expect(manager.getReconnectDelay(0)).toBe(10);
+      expect(manager.getReconnectDelay(5)).toBe(10);
+    });
+
+    it('应该按指数退避计算重连间隔并限制最大值', async () => {
+      await manager.connect({
+        ...config,
+        reconnectStrategy: { backoffBase: 2, maxRetryInterval: 50 }
+      });
+
+      expect(manager.getReconnectDelay(0)).toBe(10);
+      expect(manager.getReconnectDelay(1)).toBe(20);
+      expect(manager.getReconnectDelay(2)).toBe(40);
+      expect(manager.getReconnectDelay(3)).toBe(50);
+    });
+
+    it('启用抖动时重连间隔应落在半区间内', async () => {
+      await manager.connect({
+        ...config,
+        reconnectStrategy: { backoffBase: 2, maxRetryInterval: 1000, jitter: true }
+      });
+
+      const delay = manager.getReconnectDelay(2);
+      expect(delay).toBeGreaterThanOrEqual(20);
+      expect(delay).toBeLessThanOrEqual(40);
+    });
+  });
+
+  describe('断线恢复', () => {
+    it('非正常关闭后应该自动重连', async () => {
+      await manager.connect(config);
+
+      const reconnected = new Promise<void>(resolve => manager.once('reconnected', resolve));
+      MockWebSocket.instances[0].emit('close', 1006, Buffer.from('abnormal closure'));
+
+      await reconnected;
+
+      expect(manager.getState()).toBe(ConnectionState.CONNECTED);
+      expect(MockWebSocket.instances).toHaveLength(2);
+      expect(manager.getMetrics().reconnectAttempts).toBe(1);
+    });
+
+    it('主动断开应取消已调度的重连', async () => {
+      await manager.connect({ ...config, retryInterval: 50 });
+
+      const reconnecting = jest.fn();
+      manager.on('reconnecting', reconnecting);
+      MockWebSocket.instances[0].emit('close', 1006, Buffer.from('abnormal closure'));
+
+      await manager.disconnect();
+      await new Promise(resolve => setTimeout(resolve, 100));
+
+      expect(reconnecting).not.toHaveBeenCalled();
+      expect(manager.getState()).toBe(ConnectionState.DISCONNECTED);
+    });
+  });
+
+  describe('心跳', () => {
+    it('收到pong后应该更新心跳时间和延迟', async () => {
+      await manager.connect(config);
+
+      const latency = await manager.ping();
+
+      expect(latency).toBeGreaterThanOrEqual(0);
+      expect(manager.getMetrics().lastHeartbeat).toBeDefined();
+    });
+
+    it('配置应用层心跳时应该发送心跳消息并消费心跳响应', async () => {
+      await manager.connect({ ...config, heartbeatMessage: { ping: 'ping', isPong: message => message === 'pong' } });
+      const ws = MockWebSocket.instances[0];
+      const messages = jest.fn();
+      manager.on('message', messages);
+
+      const pending = manager.ping();
+      expect(ws.sent).toEqual(['ping']);
+      ws.emit('message', Buffer.from('pong'));
+
+      expect(await pending).toBeGreaterThanOrEqual(0);
+      expect(manager.getMetrics().lastHeartbeat).toBeDefined();
+      expect(messages).not.toHaveBeenCalled();
+    });
+
+    it('应用层心跳未收到响应时应该超时', async () => {
+      await manager.connect({ ...config, heartbeatMessage: { ping: { op: 'ping' }, isPong: message => message?.op === 'pong' } });
+
+      await expect(manager.ping()).rejects.toThrow('Ping timeout');
+      expect(MockWebSocket.instances[0].sent).toEqual(['{"op":"ping"}']);
+    });
+  });
+
+  describe('压缩', () => {
+    it('启用压缩时应该协商permessage-deflate', async () => {
+      await manager.connect({ ...config, enableCompression: true });
+
+      expect(MockWebSocket.instances[0].options.perMessageDeflate).toBe(true);
+    });
+
+    it('未配置压缩时应该沿用ws默认行为', async () => {
+      await manager.connect(config);
+
+      expect(MockWebSocket.instances[0].options).not.toHaveProperty('perMessageDeflate');
+    });
+  });
+
+  describe('代理', () => {
+    it('应该经代理池的Agent建立连接并记录成功', async () => {
+      const pool = new ProxyPool({ proxies: ['http://10.0.0.1:3128', 'http://10.0.0.2:3128'], createAgent: () => new Agent() });
+
+      await manager.connect({ ...config, proxyPool: pool });
+
+      expect(MockWebSocket.instances[0].options.agent).toBeInstanceOf(Agent);
+      expect(pool.getStats().map(proxy => proxy.requests)).toEqual([1, 0]);
+      expect(pool.getStats()[0].failures).toBe(0);
+      pool.destroy();
+    });
+  });
+});
@@ -0,0 +1,144 @@
+/**
+ * 技术指标单元测试
+ */
+
+import { SMA, EMA, RSI, MACD, BollingerBands, ATR, VWAP, Series, pipe, mapInput, withHistory, Bar } from '../src';
+
+const PRICES = [
+  44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+  45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64
+];
+
+function feed<O>(indicator: { update(input: number): O | undefined }, inputs: number[]): Array<O | undefined> {
+  return inputs.map(input => indicator.update(input));
+}
+
+describe('Series', () => {
+  it('应该按容量淘汰最旧值', () => {
+    const series = new Series<number>(3);
+    [1, 2, 3].forEach(value => series.push(value));
+
+    expect(series.push(4)).toBe(1);
+    expect(series.get(0)).toBe(4);
+    expect(series.get(2)).toBe(2);
+    expect(series.get(3)).toBeUndefined();
+    expect(series.toArray()).toEqual([2, 3, 4]);
+  });
+});
+
+describe('移动平均', () => {
+  it('SMA应该在预热期后输出窗口均值', () => {
+    const values = feed(new SMA(3), [1, 2, 3, 4, 5]);
+
+    expect(values).toEqual([undefined, undefined, 2, 3, 4]);
+  });
+
+  it('EMA应该以简单平均作为初始值', () => {
+    const ema = new EMA(10);
+    const values = feed(ema, PRICES);
+
+    expect(values[8]).toBeUndefined();
+    expect(values[9]).toBeCloseTo(PRICES.slice(0, 10).reduce((sum, price) => sum + price, 0) / 10, 10);
+    expect(ema.value).toBeCloseTo(45.870366, 6);
+  });
+});
+
+describe('RSI', () => {
+  it('应该与Wilder经典示例一致', () => {
+    const values = feed(new RSI(14), PRICES);
+
+    expect(values[13]).toBeUndefined();
+    expect(values[14]).toBeCloseTo(70.4641, 4);
+    expect(values[19]).toBeCloseTo(57.915, 3);
+  });
+
+  it('价格不变时应该为50', () => {
+    const values = feed(new RSI(3), [10, 10, 10, 10]);
+
+    expect(values[3]).toBe(50);
+  });
+});
+
+describe('MACD', () => {
+  it('应该等于快慢EMA之差及其信号线', () => {
+    const macd = new MACD(3, 6, 4);
+    const fast = new EMA(3);
+    const slow = new EMA(6);
+    const signal = new EMA(4);
+
+    let expected: number | undefined;
+    for (const price of PRICES) {
+      macd.update(price);
+      const f = fast.update(price);
+      const s = slow.update(price);
+      if (f !== undefined && s !== undefined) {
+        expected = signal.update(f - s);
+      }
+    }
+
+    expect(macd.value!.signal).toBeCloseTo(expected!, 10);
+    expect(macd.value!.histogram).toBeCloseTo(macd.value!.macd - macd.value!.signal, 10);
+  });
+
+  it('快线周期不小于慢线时应该报错', () => {
+    expect(() => new MACD(26, 12)).toThrow('fast period must be shorter');
+  });
+});
+
+describe('BollingerBands', () => {
+  it('应该按总体标准差计算上下轨', () => {
+    const bands = new BollingerBands(20, 2);
+    feed(bands, PRICES);
+
+    expect(bands.value!.middle).toBeCloseTo(45.409, 6);
+    expect(bands.value!.upper).toBeCloseTo(47.115328, 6);
+    expect(bands.value!.lower).toBeCloseTo(43.702672, 6);
+  });
+});
+
+describe('ATR', () => {
+  it('应该使用前收盘价计算真实波幅', () => {
+    const atr = new ATR(2);
+    const bars: Bar[] = [
+      { high: 10, low: 8, close: 9, volume: 1 },
+      { high: 12, low: 10, close: 11, volume: 1 },
+      { high: 11, low: 7, close: 8, volume: 1 }
+    ];
+
+    // TR: 2, max(2, 3, 1) = 3, max(4, 0, 4) = 4
+    expect(bars.map(bar => atr.update(bar))).toEqual([undefined, 2.5, 3.25]);
+  });
+});
+
+describe('VWAP', () => {
+  it('应该在交易时段切换时重置', () => {
+    const day = 86400000;
+    const vwap = new VWAP(day);
+
+    vwap.update({ high: 11, low: 9, close: 10, volume: 1, openTime: 0 });
+    expect(vwap.update({ high: 22, low: 18, close: 20, volume: 3, openTime: 60000 })).toBeCloseTo(17.5, 10);
+    expect(vwap.update({ high: 31, low: 29, close: 30, volume: 2, openTime: day })).toBeCloseTo(30, 10);
+  });
+});
+
+describe('指标组合', () => {
+  it('pipe应该串联指标', () => {
+    const smoothed = pipe(new SMA(2), new SMA(2));
+
+    expect(feed(smoothed, [1, 3, 5, 7])).toEqual([undefined, undefined, 3, 5]);
+    expect(smoothed.ready).toBe(true);
+  });
+
+  it('应该支持从K线取值并保留历史', () => {
+    const closes = withHistory(mapInput(new SMA(2), (bar: Bar) => bar.close), 2);
+    [1, 2, 3, 4].forEach(close => closes.update({ high: close, low: close, close, volume: 1 }));
+
+    expect(closes.get(0)).toBe(3.5);
+    expect(closes.get(1)).toBe(2.5);
+    expect(closes.get(2)).toBeUndefined();
+
+    closes.reset();
+    expect(closes.ready).toBe(false);
+    expect(closes.history.length).toBe(0);
+  });
+});
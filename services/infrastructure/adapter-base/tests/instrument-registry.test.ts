@@ -0,0 +1,234 @@
+/**
+ * InstrumentRegistry单元测试
+ * 覆盖交易对标准化、步长取整以及品种缓存
+ */
+
+import { InstrumentRegistry, InstrumentInfo, InstrumentListingChange, InstrumentProvider, matchSymbolPattern, normalizeSymbol, roundToStep } from '../src';
+
+function instrument(overrides: Partial<InstrumentInfo> = {}): InstrumentInfo {
+  return {
+    exchange: 'binance',
+    symbol: 'BTC/USDT',
+    exchangeSymbol: 'BTCUSDT',
+    base: 'BTC',
+    quote: 'USDT',
+    type: 'spot',
+    tickSize: 0.01,
+    lotSize: 0.00001,
+    minQuantity: 0.00001,
+    minNotional: 5,
+    ...overrides
+  };
+}
+
+describe('交易对标准化', () => {
+  it('应该识别不同交易所的交易对写法', () => {
+    expect(normalizeSymbol('BTCUSDT')).toBe('BTC/USDT');
+    expect(normalizeSymbol('btc-usd')).toBe('BTC/USD');
+    expect(normalizeSymbol('XBT/USD')).toBe('BTC/USD');
+    expect(normalizeSymbol('XXBTZUSD')).toBe('BTC/USD');
+    expect(normalizeSymbol('ETHFDUSD')).toBe('ETH/FDUSD');
+  });
+
+  it('应该保留合约的结算币种', () => {
+    expect(normalizeSymbol('BTC-USDT-SWAP')).toBe('BTC/USDT:USDT');
+    expect(normalizeSymbol('BTC-USD-SWAP')).toBe('BTC/USD:BTC');
+    expect(normalizeSymbol('BTC/USDT:USDT')).toBe('BTC/USDT:USDT');
+  });
+
+  it('无法识别时应该返回大写原值', () => {
+    expect(normalizeSymbol('foo')).toBe('FOO');
+  });
+});
+
+describe('交易对模式匹配', () => {
+  it('应该支持通配符且不区分大小写', () => {
+    expect(matchSymbolPattern('*USDT', 'BTCUSDT')).toBe(true);
+    expect(matchSymbolPattern('*/usdt', 'ETH/USDT')).toBe(true);
+    expect(matchSymbolPattern('BTC-USDT-?WAP', 'BTC-USDT-SWAP')).toBe(true);
+    expect(matchSymbolPattern('*USDT', 'BTCUSDC')).toBe(false);
+    expect(matchSymbolPattern('BTC.USDT', 'BTCXUSDT')).toBe(false);
+  });
+});
+
+describe('步长取整', () => {
+  it('应该避免浮点误差导致的错误取整', () => {
+    expect(roundToStep(0.3, 0.1, 'floor')).toBe(0.3);
+    expect(roundToStep(1.005, 0.01, 'floor')).toBe(1);
+    expect(roundToStep(0.123456789, 1e-8, 'floor')).toBe(0.12345678);
+  });
+
+  it('应该支持向上取整与四舍五入', () => {
+    expect(roundToStep(101.234, 0.5, 'ceil')).toBe(101.5);
+    expect(roundToStep(101.234, 0.5)).toBe(101);
+    expect(roundToStep(1234, 10, 'floor')).toBe(1230);
+  });
+});
+
+describe('InstrumentRegistry', () => {
+  let registry: InstrumentRegistry;
+
+  beforeEach(() => {
+    registry = new InstrumentRegistry({ ttl: 1000 });
+  });
+
+  it('应该按标准交易对或原始交易对查询品种', () => {
+    registry.register([instrument()]);
+
+    expect(registry.get('binance', 'BTCUSDT')?.symbol).toBe('BTC/USDT');
+    expect(registry.get('binance', 'btc/usdt')?.exchangeSymbol).toBe('BTCUSDT');
+    expect(registry.toExchangeSymbol('binance', 'BTC-USDT')).toBe('BTCUSDT');
+    expect(registry.get('okx', 'BTCUSDT')).toBeUndefined();
+    expect(() => registry.require('binance', 'ETHUSDT')).toThrow('Unknown instrument');
+  });
+
+  it('应该按品种步长取整价格和数量', () => {
+    registry.register([instrument()]);
+
+    expect(registry.roundPrice('binance', 'BTC/USDT', 42000.126)).toBe(42000.13);
+    expect(registry.roundQuantity('binance', 'BTC/USDT', 0.123456)).toBe(0.12345);
+  });
+
+  it('应该校验下单参数', () => {
+    registry.register([instrument(), instrument({
+      exchange: 'okx', symbol: 'BTC/USDT:USDT', exchangeSymbol: 'BTC-USDT-SWAP', type: 'swap',
+      settle: 'USDT', tickSize: 0.1, lotSize: 1, minQuantity: 1, minNotional: undefined, contractMultiplier: 0.01
+    })]);
+
+    expect(registry.validateOrder('binance', 'BTC/USDT', 42000.12, 0.001)).toEqual([]);
+    const errors = registry.validateOrder('binance', 'BTC/USDT', 42000.125, 0.0001);
+    expect(errors).toHaveLength(2);
+    expect(errors[0]).toBe('Price 42000.125 is not a multiple of tick size 0.01');
+    expect(errors[1]).toContain('is below minimum 5');
+    expect(registry.validateOrder('okx', 'BTC-USDT-SWAP', 42000.1, 0.5)).toEqual([
+      'Quantity 0.5 is not a multiple of lot size 1',
+      'Quantity 0.5 is below minimum 1'
+    ]);
+  });
+
+  it('应该缓存数据源结果并合并并发请求', async () => {
+    const provider: InstrumentProvider = {
+      exchange: 'binance',
+      fetchInstruments: jest.fn().mockResolvedValue([instrument()])
+    };
+    registry.addProvider(provider);
+
+    const [first, second] = await Promise.all([registry.load('binance'), registry.load('binance')]);
+    await registry.load('binance');
+
+    expect(first).toEqual(second);
+    expect(provider.fetchInstruments).toHaveBeenCalledTimes(1);
+
+    await registry.load('binance', true);
+    expect(provider.fetchInstruments).toHaveBeenCalledTimes(2);
+  });
+
+  it('直接注册的品种不应该让load跳过数据源', async () => {
+    const provider: InstrumentProvider = {
+      exchange: 'binance',
+      fetchInstruments: jest.fn().mockResolvedValue([instrument(), instrument({ symbol: 'ETH/USDT', exchangeSymbol: 'ETHUSDT', base: 'ETH' })])
+    };
+    registry.addProvider(provider);
+    registry.register([instrument()]);
+
+    expect(await registry.load('binance')).toHaveLength(2);
+    expect(provider.fetchInstruments).toHaveBeenCalledTimes(1);
+  });
+
+  it('加载失败时应该保留旧缓存', async () => {
+    const fetchInstruments = jest.fn()
+      .mockResolvedValueOnce([instrument()])
+      .mockRejectedValueOnce(new Error('HTTP 503'));
+    registry.addProvider({ exchange: 'binance', fetchInstruments });
+    const failures: Error[] = [];
+    registry.on('loadFailed', (_exchange, error) => failures.push(error));
+
+    await registry.load('binance');
+    await expect(registry.load('binance', true)).rejects.toThrow('HTTP 503');
+
+    expect(failures).toHaveLength(1);
+    expect(registry.get('binance', 'BTCUSDT')).toBeDefined();
+  });
+
+  it('新数据解析失败时应该保留旧缓存', async () => {
+    const fetchInstruments = jest.fn()
+      .mockResolvedValueOnce([instrument()])
+      .mockResolvedValueOnce([instrument({ symbol: 'ETH/USDT', exchangeSymbol: 'ETHUSDT' }), { ...instrument(), exchangeSymbol: undefined } as any]);
+    registry.addProvider({ exchange: 'binance', fetchInstruments });
+
+    await registry.load('binance');
+    await expect(registry.load('binance', true)).rejects.toThrow();
+
+    expect(registry.get('binance', 'BTCUSDT')).toBeDefined();
+    expect(registry.get('binance', 'ETHUSDT')).toBeUndefined();
+  });
+
+  it('未注册数据源时应该报错', async () => {
+    await expect(registry.load('kraken')).rejects.toThrow('No instrument provider registered for kraken');
+  });
+
+  describe('上下架识别', () => {
+    const eth = instrument({ symbol: 'ETH/USDT', exchangeSymbol: 'ETHUSDT', base: 'ETH' });
+    const ethBtc = instrument({ symbol: 'ETH/BTC', exchangeSymbol: 'ETHBTC', base: 'ETH', quote: 'BTC' });
+    let fetchInstruments: jest.Mock;
+
+    beforeEach(() => {
+      fetchInstruments = jest.fn().mockResolvedValue([instrument(), eth]);
+      registry.addProvider({ exchange: 'binance', fetchInstruments });
+    });
+
+    afterEach(() => {
+      registry.stopAutoRefresh();
+    });
+
+    it('首次加载不应视为上架', async () => {
+      const changes: InstrumentListingChange[] = [];
+      registry.on('listingChange', change => changes.push(change));
+
+      await registry.load('binance');
+
+      expect(changes).toEqual([]);
+    });
+
+    it('重新加载时应该识别新上架、下架和暂停交易的品种', async () => {
+      const changes: InstrumentListingChange[] = [];
+      registry.on('listingChange', change => changes.push(change));
+      await registry.load('binance');
+
+      fetchInstruments.mockResolvedValueOnce([{ ...instrument(), active: false }, ethBtc]);
+      await registry.load('binance', true);
+
+      expect(changes).toHaveLength(1);
+      expect(changes[0].listed.map(item => item.symbol)).toEqual(['ETH/BTC']);
+      expect(changes[0].delisted.map(item => item.symbol)).toEqual(['BTC/USDT', 'ETH/USDT']);
+      expect(changes[0].delisted[0].active).toBe(false);
+    });
+
+    it('应该只通知匹配关注模式的变化', async () => {
+      const usdt = jest.fn();
+      const btc = jest.fn();
+      registry.watch(['*USDT'], usdt);
+      const unwatch = registry.watch(['*BTC'], btc);
+      await registry.load('binance');
+
+      fetchInstruments.mockResolvedValueOnce([instrument(), eth, ethBtc]);
+      await registry.load('binance', true);
+      unwatch();
+      fetchInstruments.mockResolvedValueOnce([instrument()]);
+      await registry.load('binance', true);
+
+      expect(btc).toHaveBeenCalledTimes(1);
+      expect(btc.mock.calls[0][0].listed.map((item: InstrumentInfo) => item.symbol)).toEqual(['ETH/BTC']);
+      expect(usdt).toHaveBeenCalledTimes(1);
+      expect(usdt.mock.calls[0][0]).toEqual({ exchange: 'binance', listed: [], delisted: [eth] });
+    });
+
+    it('应该定时刷新全部数据源', async () => {
+      registry.startAutoRefresh(10);
+      await new Promise(resolve => setTimeout(resolve, 35));
+      registry.stopAutoRefresh();
+
+      expect(fetchInstruments.mock.calls.length).toBeGreaterThanOrEqual(2);
+    });
+  });
+});
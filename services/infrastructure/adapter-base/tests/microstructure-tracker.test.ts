@@ -0,0 +1,64 @@
+/**
+ * MicrostructureTracker单元测试
+ * 覆盖订单簿失衡度、成交流失衡度与滚动窗口波动率
+ */
+
+import { MicrostructureTracker, TradeData } from '../src';
+
+function trade(timestamp: number, quantity: number, side: 'buy' | 'sell'): TradeData {
+  return { id: `${timestamp}`, price: 100, quantity, side, timestamp };
+}
+
+describe('MicrostructureTracker', () => {
+  let tracker: MicrostructureTracker;
+
+  beforeEach(() => {
+    tracker = new MicrostructureTracker({ window: 1000, depthLevels: 2 });
+  });
+
+  it('应该计算价差、微观价格与订单簿失衡度', () => {
+    const features = tracker.updateBook('BTC/USDT', {
+      bids: [[100, 3], [99, 1], [98, 100]],
+      asks: [[101, 1], [102, 1]]
+    }, 0);
+
+    expect(features.mid).toBe(100.5);
+    expect(features.spread).toBe(1);
+    expect(features.spreadBps).toBeCloseTo(99.5, 1);
+    expect(features.queueImbalance).toBe(0.5);
+    // 只统计前2档，第3档的大单不计入
+    expect(features.depthImbalance).toBeCloseTo(1 / 3);
+    expect(features.microprice).toBe(100.75);
+  });
+
+  it('应该按窗口统计主动买卖成交量失衡度', () => {
+    tracker.addTrade('BTC/USDT', trade(0, 3, 'buy'));
+    tracker.addTrade('BTC/USDT', trade(600, 1, 'sell'));
+
+    expect(tracker.getFeatures('BTC/USDT', 900)).toMatchObject({ tradeCount: 2, tradeVolume: 4, tradeFlowImbalance: 0.5 });
+
+    const later = tracker.getFeatures('BTC/USDT', 1200)!;
+    expect(later).toMatchObject({ tradeCount: 1, tradeVolume: 1, tradeFlowImbalance: -1 });
+    expect(later.mid).toBeUndefined();
+  });
+
+  it('应该计算窗口内中间价的已实现波动率', () => {
+    const book = (bid: number) => ({ bids: [[bid, 1]] as Array<[number, number]>, asks: [[bid + 2, 1]] as Array<[number, number]> });
+
+    expect(tracker.updateBook('BTC/USDT', book(99), 0).realizedVolatility).toBeUndefined();
+    tracker.updateBook('BTC/USDT', book(99), 100);
+    tracker.updateBook('BTC/USDT', book(109), 200);
+    const features = tracker.updateBook('BTC/USDT', book(99), 300);
+
+    const step = Math.log(110 / 100);
+    expect(features.realizedVolatility).toBeCloseTo(Math.sqrt(2 * step * step));
+
+    // 窗口外只保留最后一个中间价作为基准
+    expect(tracker.getFeatures('BTC/USDT', 5000)!.realizedVolatility).toBeUndefined();
+  });
+
+  it('未收到数据的交易对应该返回undefined', () => {
+    expect(tracker.getFeatures('ETH/USDT')).toBeUndefined();
+    expect(() => new MicrostructureTracker({ window: 0 })).toThrow('Invalid microstructure window');
+  });
+});
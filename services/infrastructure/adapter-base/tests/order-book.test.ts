@@ -0,0 +1,268 @@
+/**
+ * OrderBook单元测试
+ * 覆盖档位排序、序列缺口检测、自动重新快照和校验和验证
+ */
+
+import { OrderBook, OrderBookSnapshot, krakenChecksum, okxChecksum } from '../src';
+
+const flushPromises = () => new Promise(resolve => setImmediate(resolve));
+
+function createSnapshot(overrides: Partial<OrderBookSnapshot> = {}): OrderBookSnapshot {
+  return {
+    lastUpdateId: 100,
+    bids: [['100.0', '1'], ['99.5', '2'], ['99.0', '3']],
+    asks: [['100.5', '1'], ['101.0', '2'], ['101.5', '3']],
+    ...overrides
+  };
+}
+
+describe('OrderBook', () => {
+  describe('档位维护', () => {
+    it('应该按价格排序买卖盘并计算最优价', () => {
+      const book = new OrderBook({ symbol: 'BTC/USDT' });
+      book.applySnapshot(createSnapshot());
+
+      expect(book.isSynced()).toBe(true);
+      expect(book.getBids().map(level => level.price)).toEqual([100, 99.5, 99]);
+      expect(book.getAsks().map(level => level.price)).toEqual([100.5, 101, 101.5]);
+      expect(book.bestBid()?.price).toBe(100);
+      expect(book.bestAsk()?.price).toBe(100.5);
+      expect(book.mid()).toBe(100.25);
+      expect(book.spread()).toBe(0.5);
+    });
+
+    it('数量为0的增量应该删除档位', () => {
+      const book = new OrderBook({ symbol: 'BTC/USDT' });
+      book.applySnapshot(createSnapshot());
+
+      const applied = book.applyDelta({
+        firstUpdateId: 101,
+        finalUpdateId: 102,
+        bids: [['100.0', '0'], ['99.8', '4']],
+        asks: [['100.2', '1']]
+      });
+
+      expect(applied).toBe(true);
+      expect(book.getLastUpdateId()).toBe(102);
+      expect(book.bestBid()).toMatchObject({ price: 99.8, quantity: 4 });
+      expect(book.bestAsk()?.price).toBe(100.2);
+    });
+
+    it('应该计算前N档买卖量失衡度', () => {
+      const book = new OrderBook({ symbol: 'BTC/USDT' });
+      book.applySnapshot(createSnapshot({
+        bids: [['100', '3'], ['99', '1']],
+        asks: [['101', '1'], ['102', '1']]
+      }));
+
+      expect(book.imbalance(1)).toBe(0.5);
+      expect(book.imbalance(2)).toBeCloseTo(2 / 6);
+    });
+
+    it('应该按最大深度截断档位', () => {
+      const book = new OrderBook({ symbol: 'BTC/USDT', maxDepth: 2 });
+      book.applySnapshot(createSnapshot());
+
+      expect(book.getBids()).toHaveLength(2);
+      expect(book.getAsks()).toHaveLength(2);
+    });
+  });
+
+  describe('序列检测', () => {
+    it('应该忽略快照已包含的旧增量', () => {
+      const book = new OrderBook({ symbol: 'BTC/USDT' });
+      book.applySnapshot(createSnapshot());
+
+      const applied = book.applyDelta({
+        firstUpdateId: 90,
+        finalUpdateId: 100,
+        bids: [['100.0', '9']],
+        asks: []
+      });
+
+      expect(applied).toBe(false);
+      expect(book.bestBid()?.quantity).toBe(1);
+    });
+
+    it('快照后的首条增量允许与快照重叠', () => {
+      const book = new OrderBook({ symbol: 'BTC/USDT' });
+      book.applySnapshot(createSnapshot());
+
+      expect(book.applyDelta({ firstUpdateId: 95, finalUpdateId: 105, bids: [], asks: [] })).toBe(true);
+      expect(book.applyDelta({ firstUpdateId: 106, finalUpdateId: 110, bids: [], asks: [] })).toBe(true);
+      expect(book.getLastUpdateId()).toBe(110);
+    });
+
+    it('检测到缺口时应该重新快照并重放缓存增量', async () => {
+      const snapshotProvider = jest.fn().mockResolvedValue(createSnapshot({ lastUpdateId: 120 }));
+      const book = new OrderBook({ symbol: 'BTC/USDT', snapshotProvider });
+      book.applySnapshot(createSnapshot());
+
+      const gapListener = jest.fn();
+      book.on('gap', gapListener);
+
+      expect(book.applyDelta({ firstUpdateId: 110, finalUpdateId: 115, bids: [], asks: [] })).toBe(false);
+      expect(book.isSynced()).toBe(false);
+      expect(gapListener).toHaveBeenCalledWith({ symbol: 'BTC/USDT', expected: 101, received: 110 });
+
+      book.applyDelta({ firstUpdateId: 116, finalUpdateId: 125, bids: [['99.9', '5']], asks: [] });
+
+      const resynced = new Promise(resolve => book.once('resynced', resolve));
+      await resynced;
+
+      expect(snapshotProvider).toHaveBeenCalledTimes(1);
+      expect(snapshotProvider).toHaveBeenCalledWith('BTC/USDT');
+      expect(book.isSynced()).toBe(true);
+      expect(book.getLastUpdateId()).toBe(125);
+      expect(book.getBids().map(level => level.price)).toContain(99.9);
+    });
+
+    it('提供prevFinalUpdateId时应该按其校验连续性', () => {
+      const book = new OrderBook({ symbol: 'BTC/USDT' });
+      book.applySnapshot(createSnapshot());
+
+      book.applyDelta({ firstUpdateId: 98, finalUpdateId: 104, prevFinalUpdateId: 97, bids: [], asks: [] });
+      const applied = book.applyDelta({ firstUpdateId: 106, finalUpdateId: 108, prevFinalUpdateId: 104, bids: [], asks: [] });
+
+      expect(applied).toBe(true);
+      expect(book.getLastUpdateId()).toBe(108);
+    });
+
+    it('只有prevFinalUpdateId时快照后的首条增量也应校验连续性', () => {
+      const book = new OrderBook({ symbol: 'BTC/USDT' });
+      book.applySnapshot(createSnapshot());
+
+      const applied = book.applyDelta({ finalUpdateId: 110, prevFinalUpdateId: 105, bids: [], asks: [] });
+
+      expect(applied).toBe(false);
+      expect(book.isSynced()).toBe(false);
+    });
+
+    it('未配置快照函数时应该发出resyncRequired事件', () => {
+      const book = new OrderBook({ symbol: 'BTC/USDT' });
+      const listener = jest.fn();
+      book.on('resyncRequired', listener);
+
+      book.applyDelta({ firstUpdateId: 1, finalUpdateId: 2, bids: [], asks: [] });
+
+      expect(listener).toHaveBeenCalledWith('BTC/USDT');
+    });
+
+    it('快照失败时应该发出resyncFailed事件', async () => {
+      const error = new Error('snapshot unavailable');
+      const book = new OrderBook({
+        symbol: 'BTC/USDT',
+        snapshotProvider: jest.fn().mockRejectedValue(error)
+      });
+      const listener = jest.fn();
+      book.on('resyncFailed', listener);
+
+      book.applyDelta({ firstUpdateId: 1, finalUpdateId: 2, bids: [], asks: [] });
+      await flushPromises();
+
+      expect(listener).toHaveBeenCalledWith(error);
+      expect(book.isSynced()).toBe(false);
+      book.reset();
+    });
+
+    describe('重试退避', () => {
+      beforeEach(() => {
+        jest.useFakeTimers();
+      });
+
+      afterEach(() => {
+        jest.useRealTimers();
+      });
+
+      it('快照失败后应该按退避间隔重试，无需等待新的增量', async () => {
+        const snapshotProvider = jest.fn()
+          .mockRejectedValueOnce(new Error('HTTP 429'))
+          .mockRejectedValueOnce(new Error('HTTP 429'))
+          .mockResolvedValueOnce(createSnapshot({ lastUpdateId: 1 }));
+        const book = new OrderBook({ symbol: 'BTC/USDT', snapshotProvider, resyncRetryInterval: 1000 });
+        book.on('resyncFailed', () => undefined);
+
+        book.applyDelta({ firstUpdateId: 1, finalUpdateId: 2, bids: [], asks: [] });
+        await jest.advanceTimersByTimeAsync(0);
+        expect(snapshotProvider).toHaveBeenCalledTimes(1);
+
+        await jest.advanceTimersByTimeAsync(1000);
+        expect(snapshotProvider).toHaveBeenCalledTimes(2);
+
+        await jest.advanceTimersByTimeAsync(1999);
+        expect(snapshotProvider).toHaveBeenCalledTimes(2);
+        await jest.advanceTimersByTimeAsync(1);
+
+        expect(snapshotProvider).toHaveBeenCalledTimes(3);
+        expect(book.isSynced()).toBe(true);
+        expect(book.getLastUpdateId()).toBe(2);
+      });
+
+      it('未配置快照函数时应该按退避间隔重复通知，直到收到快照', async () => {
+        const book = new OrderBook({ symbol: 'BTC/USDT', resyncRetryInterval: 1000 });
+        const listener = jest.fn();
+        book.on('resyncRequired', listener);
+
+        book.applyDelta({ firstUpdateId: 1, finalUpdateId: 2, bids: [], asks: [] });
+        book.applyDelta({ firstUpdateId: 3, finalUpdateId: 4, bids: [], asks: [] });
+        expect(listener).toHaveBeenCalledTimes(1);
+
+        await jest.advanceTimersByTimeAsync(1000);
+        expect(listener).toHaveBeenCalledTimes(2);
+
+        book.applySnapshot(createSnapshot({ lastUpdateId: 4 }));
+        await jest.advanceTimersByTimeAsync(10000);
+        expect(listener).toHaveBeenCalledTimes(2);
+      });
+    });
+  });
+
+  describe('校验和', () => {
+    it('应该按OKX规则计算校验和', () => {
+      const book = new OrderBook({ symbol: 'ETH/USDT' });
+      book.applySnapshot({
+        lastUpdateId: 1,
+        bids: [['3366.1', '7.0'], ['3366', '6']],
+        asks: [['3366.8', '9'], ['3368', '8']]
+      });
+
+      expect(okxChecksum(book.getBids(), book.getAsks())).toBe(-561727013);
+    });
+
+    it('应该按Kraken规则计算校验和', () => {
+      const book = new OrderBook({ symbol: 'BTC/USD' });
+      book.applySnapshot({
+        lastUpdateId: 1,
+        bids: [['5541.2', '1.52900000']],
+        asks: [['5541.3', '2.50700000']]
+      });
+
+      expect(krakenChecksum(book.getBids(), book.getAsks())).toBe(511877251);
+    });
+
+    it('校验和不一致时应该重新快照', async () => {
+      const snapshotProvider = jest.fn().mockResolvedValue(createSnapshot({ lastUpdateId: 200 }));
+      const book = new OrderBook({ symbol: 'BTC/USDT', checksum: okxChecksum, snapshotProvider });
+      book.applySnapshot(createSnapshot());
+
+      const mismatch = jest.fn();
+      book.on('checksumMismatch', mismatch);
+
+      const applied = book.applyDelta({
+        firstUpdateId: 101,
+        finalUpdateId: 101,
+        bids: [['100.0', '2']],
+        asks: [],
+        checksum: 12345
+      });
+
+      expect(applied).toBe(false);
+      expect(mismatch).toHaveBeenCalledWith(expect.objectContaining({ symbol: 'BTC/USDT', expected: 12345 }));
+
+      await flushPromises();
+
+      expect(snapshotProvider).toHaveBeenCalledTimes(1);
+      expect(book.getLastUpdateId()).toBe(200);
+    });
+  });
+});
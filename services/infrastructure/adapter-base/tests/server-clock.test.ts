@@ -0,0 +1,67 @@
+/**
+ * ServerClock单元测试
+ */
+
+import { ServerClock } from '../src';
+
+describe('ServerClock', () => {
+  it('首次校准应该直接采用样本偏差', async () => {
+    const clock = new ServerClock({ fetchServerTime: async () => Date.now() + 1000 });
+
+    expect(clock.isCalibrated()).toBe(false);
+    const offset = await clock.calibrate();
+
+    expect(clock.isCalibrated()).toBe(true);
+    expect(Math.abs(offset - 1000)).toBeLessThan(20);
+    expect(Math.abs(clock.now() - Date.now() - 1000)).toBeLessThan(20);
+  });
+
+  it('后续校准应该按指数加权平均平滑偏差', async () => {
+    const offsets = [1000, 2000];
+    const clock = new ServerClock({ fetchServerTime: async () => Date.now() + offsets.shift()!, alpha: 0.5 });
+
+    await clock.calibrate();
+    const offset = await clock.calibrate();
+
+    expect(Math.abs(offset - 1500)).toBeLessThan(20);
+  });
+
+  it('reset时应该丢弃历史偏差', async () => {
+    const offsets = [1000, -3000];
+    const clock = new ServerClock({ fetchServerTime: async () => Date.now() + offsets.shift()! });
+
+    await clock.calibrate();
+    const offset = await clock.calibrate(true);
+
+    expect(Math.abs(offset + 3000)).toBeLessThan(20);
+  });
+
+  it('应该丢弃往返时间过长的样本并合并并发校准', async () => {
+    const fetchServerTime = jest.fn(() => new Promise<number>(resolve => setTimeout(() => resolve(Date.now()), 50)));
+    const clock = new ServerClock({ fetchServerTime, maxRoundTrip: 10 });
+
+    const results = await Promise.allSettled([clock.calibrate(), clock.calibrate()]);
+
+    expect(fetchServerTime).toHaveBeenCalledTimes(1);
+    expect(results.every(result => result.status === 'rejected')).toBe(true);
+    expect((results[0] as PromiseRejectedResult).reason.message).toContain('above the 10 ms limit');
+    expect(clock.isCalibrated()).toBe(false);
+  });
+  it('首次校准失败时应该继续定时校准', async () => {
+    jest.useFakeTimers();
+    const fetchServerTime = jest.fn()
+      .mockRejectedValueOnce(new Error('unreachable'))
+      .mockImplementation(async () => Date.now() + 500);
+    const clock = new ServerClock({ fetchServerTime, interval: 1000 });
+
+    await expect(clock.start()).rejects.toThrow('unreachable');
+    jest.advanceTimersByTime(1000);
+    await Promise.resolve();
+    await Promise.resolve();
+
+    expect(fetchServerTime).toHaveBeenCalledTimes(2);
+    expect(clock.isCalibrated()).toBe(true);
+    clock.stop();
+    jest.useRealTimers();
+  });
+});
@@ -26,6 +26,7 @@ export const ENV_MAPPINGS = [
   { env: 'PORT', path: 'service.server.port', type: 'number' as const },
   { env: 'HOST', path: 'service.server.host', type: 'string' as const },
   { env: 'NODE_ENV', path: 'service.environment', type: 'string' as const },
+  { env: 'SHUTDOWN_TIMEOUT', path: 'service.server.shutdownTimeout', type: 'number' as const },
   
   // 日志配置
   { env: 'LOG_LEVEL', path: 'logging.level', type: 'string' as const },
@@ -59,6 +60,7 @@ export const DEFAULT_CONFIG_VALUES = {
       host: '0.0.0.0',
       enableCors: true,
       timeout: 30000,
+      shutdownTimeout: 30000,
     },
   },
   
@@ -177,6 +179,21 @@ export const ENVIRONMENT_OVERRIDES = {
   },
 } as const;
 
+/**
+ * 热更新时不允许变更的配置路径（支持 * 匹配单级路径）
+ * 这些配置涉及监听端口、外部连接或凭证，变更后需要重启服务
+ */
+export const HOT_RELOAD_IMMUTABLE_PATHS = [
+  'service.server.port',
+  'service.server.host',
+  'websocket.port',
+  'pubsub.projectId',
+  'pubsub.useEmulator',
+  'pubsub.emulatorHost',
+  'adapters.*.config.endpoints',
+  'adapters.*.config.auth',
+] as const;
+
 /**
  * 配置路径工具函数
  */
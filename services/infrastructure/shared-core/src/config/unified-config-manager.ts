@@ -1,12 +1,14 @@
 import { EventEmitter } from 'eventemitter3';
 import { join, resolve } from 'path';
 import { readFileSync, existsSync, watchFile, unwatchFile } from 'fs';
+import { hostname } from 'os';
 import { parse as parseYaml } from 'yaml';
 import Joi from 'joi';
-import { merge } from 'lodash';
+import { cloneDeep, isPlainObject, mergeWith } from 'lodash';
 import Ajv from 'ajv';
 import addFormats from 'ajv-formats';
-import { DEFAULT_CONFIG_VALUES, CONFIG_VALIDATION_RULES } from './config-constants';
+import { DEFAULT_CONFIG_VALUES, CONFIG_VALIDATION_RULES, HOT_RELOAD_IMMUTABLE_PATHS, ENV_MAPPINGS } from './config-constants';
+import type { TracingConfig } from '../monitoring/tracing';
 
 /**
  * 统一配置接口定义
@@ -43,6 +45,8 @@ export interface ServiceConfig {
     host: string;
     enableCors: boolean;
     timeout?: number;
+    /** 优雅关闭的最长等待时间（毫秒），超时后强制退出 */
+    shutdownTimeout?: number;
   };
 }
 
@@ -104,6 +108,8 @@ export interface UnifiedMonitoringConfig {
     port: number;
     path: string;
   };
+  /** 分布式追踪 */
+  tracing?: TracingConfig;
 }
 
 export interface UnifiedPubSubConfig {
@@ -126,6 +132,8 @@ export interface UnifiedPubSubConfig {
 
 export interface LoggingConfig {
   level: 'error' | 'warn' | 'info' | 'debug' | 'silly';
+  /** 按组件覆盖日志级别，支持热更新 */
+  componentLevels?: Record<string, 'error' | 'warn' | 'info' | 'debug' | 'trace'>;
   format: 'json' | 'simple' | 'combined';
   output: 'console' | 'file' | 'both';
   file?: {
@@ -156,6 +164,27 @@ export interface ConfigChange {
   timestamp: Date;
 }
 
+/**
+ * 配置重新加载结果
+ */
+export interface ConfigReloadResult {
+  /** 新配置是否已生效 */
+  applied: boolean;
+  /** 与当前配置相比的变更 */
+  changes: ConfigChange[];
+  /** 因涉及不可热更新的配置而被拒绝的变更 */
+  rejected: ConfigChange[];
+  /** 错误信息 */
+  errors: string[];
+}
+
+/**
+ * 配置值来源
+ * 键为叶子路径（如 service.server.port），值为最终生效的来源：
+ * default、配置文件路径或 env:<变量名>
+ */
+export type ConfigProvenance = Record<string, string>;
+
 /**
  * 统一配置管理器
  * 提供配置加载、合并、验证、热更新等功能
@@ -164,6 +193,11 @@ export class UnifiedConfigManager extends EventEmitter {
   private currentConfig: UnifiedConfig | null = null;
   private configSources: Map<string, any> = new Map();
   private watchedFiles: Set<string> = new Set();
+  private fileSourceKeys: Map<string, string> = new Map();
+  private sourceLabels: Map<string, string> = new Map();
+  private immutablePaths: string[] = [...HOT_RELOAD_IMMUTABLE_PATHS];
+  private reloadSignalHandler?: () => void;
+  private reloadSignal?: NodeJS.Signals;
   private validationSchema: Joi.ObjectSchema;
   private jsonSchemaValidator: Ajv | null = null;
 
@@ -181,11 +215,14 @@ export class UnifiedConfigManager extends EventEmitter {
 
     // 清理之前的配置源
     this.configSources.clear();
+    this.fileSourceKeys.clear();
+    this.sourceLabels.clear();
     this.stopWatching();
 
     // 加载默认配置
     const defaultConfig = this.getDefaultConfiguration();
     this.configSources.set('default', defaultConfig);
+    this.sourceLabels.set('default', 'default');
 
     // 按优先级加载配置文件
     paths.forEach((path, index) => {
@@ -193,6 +230,8 @@ export class UnifiedConfigManager extends EventEmitter {
         try {
           const config = this.loadConfigFile(path);
           this.configSources.set(`file-${index}`, config);
+          this.fileSourceKeys.set(path, `file-${index}`);
+          this.sourceLabels.set(`file-${index}`, path);
           
           // 监听配置文件变化
           this.watchConfigFile(path);
@@ -229,9 +268,52 @@ export class UnifiedConfigManager extends EventEmitter {
 
   /**
    * 合并多个配置对象
+   * 对象逐层合并，数组和标量整体替换，后面的配置优先
    */
   mergeConfigurations(...configs: Partial<UnifiedConfig>[]): UnifiedConfig {
-    return merge({}, ...configs) as UnifiedConfig;
+    return mergeWith({}, ...configs, (_target: any, source: any) =>
+      Array.isArray(source) ? cloneDeep(source) : undefined
+    ) as UnifiedConfig;
+  }
+
+  /**
+   * 获取已加载的配置文件，按合并顺序排列，不存在的文件不包含在内
+   */
+  getLoadedConfigFiles(): string[] {
+    return Array.from(this.fileSourceKeys.keys());
+  }
+
+  /**
+   * 获取每个配置值的来源
+   * 按合并顺序遍历各配置源，记录最后写入每个叶子路径的来源，数组视为叶子
+   */
+  getConfigProvenance(): ConfigProvenance {
+    const provenance: ConfigProvenance = {};
+
+    const visit = (value: any, path: string, label: string | ((path: string) => string)) => {
+      if (isPlainObject(value)) {
+        // 对象覆盖标量时，标量的来源不再有效
+        delete provenance[path];
+        for (const [key, child] of Object.entries(value)) {
+          visit(child, path ? `${path}.${key}` : key, label);
+        }
+        return;
+      }
+
+      // 标量或数组覆盖对象时，对象下的来源不再有效
+      for (const existing of Object.keys(provenance)) {
+        if (existing.startsWith(`${path}.`)) {
+          delete provenance[existing];
+        }
+      }
+      provenance[path] = typeof label === 'function' ? label(path) : label;
+    };
+
+    for (const [key, source] of this.configSources) {
+      visit(source, '', key === 'environment' ? path => this.getEnvironmentLabel(path) : this.sourceLabels.get(key) ?? key);
+    }
+
+    return provenance;
   }
 
   /**
@@ -337,6 +419,95 @@ export class UnifiedConfigManager extends EventEmitter {
     this.emit('configChanged', this.currentConfig, [change]);
   }
 
+  /**
+   * 重新加载配置文件
+   * 重新解析所有已加载的配置文件并与当前配置比较，验证通过且不涉及不可热更新路径时才生效
+   */
+  reloadConfiguration(): ConfigReloadResult {
+    const result: ConfigReloadResult = { applied: false, changes: [], rejected: [], errors: [] };
+
+    if (!this.currentConfig) {
+      result.errors.push('No configuration loaded');
+      return result;
+    }
+
+    const sources = new Map(this.configSources);
+    for (const [filePath, key] of this.fileSourceKeys) {
+      try {
+        sources.set(key, existsSync(filePath) ? this.loadConfigFile(filePath) : {});
+      } catch (error) {
+        result.errors.push(`Failed to parse ${filePath}: ${error instanceof Error ? error.message : String(error)}`);
+      }
+    }
+
+    const envConfig = this.loadEnvironmentConfig();
+    if (Object.keys(envConfig).length > 0) {
+      sources.set('environment', envConfig);
+    }
+
+    if (result.errors.length === 0) {
+      const mergedConfig = this.mergeConfigurations(...Array.from(sources.values()));
+      const validation = this.validateConfiguration(mergedConfig);
+
+      if (!validation.valid) {
+        result.errors.push(...validation.errors);
+      } else {
+        result.changes = this.calculateChanges(this.currentConfig, mergedConfig);
+        result.rejected = result.changes.filter(change => this.isImmutableChange(change));
+
+        if (result.rejected.length > 0) {
+          const paths = result.rejected.map(change => change.path).join(', ');
+          result.errors.push(`Configuration changes require a restart and were not applied: ${paths}`);
+        } else {
+          this.configSources = sources;
+          this.currentConfig = mergedConfig;
+          result.applied = true;
+
+          if (result.changes.length > 0) {
+            this.emit('configChanged', mergedConfig, result.changes);
+          }
+        }
+      }
+    }
+
+    if (!result.applied) {
+      this.emit('configReloadRejected', result);
+    }
+
+    return result;
+  }
+
+  /**
+   * 设置不可热更新的配置路径
+   */
+  setImmutablePaths(paths: string[]): void {
+    this.immutablePaths = [...paths];
+  }
+
+  /**
+   * 收到指定信号时重新加载配置
+   */
+  enableSignalReload(signal: NodeJS.Signals = 'SIGHUP'): void {
+    this.disableSignalReload();
+
+    this.reloadSignal = signal;
+    this.reloadSignalHandler = () => {
+      this.reloadConfiguration();
+    };
+    process.on(signal, this.reloadSignalHandler);
+  }
+
+  /**
+   * 取消信号触发的配置重新加载
+   */
+  disableSignalReload(): void {
+    if (this.reloadSignal && this.reloadSignalHandler) {
+      process.off(this.reloadSignal, this.reloadSignalHandler);
+    }
+    this.reloadSignal = undefined;
+    this.reloadSignalHandler = undefined;
+  }
+
   /**
    * 订阅配置变更
    */
@@ -357,9 +528,11 @@ export class UnifiedConfigManager extends EventEmitter {
    */
   destroy(): void {
     this.stopWatching();
+    this.disableSignalReload();
     this.removeAllListeners();
     this.currentConfig = null;
     this.configSources.clear();
+    this.sourceLabels.clear();
   }
 
   // ====== 私有方法 ======
@@ -369,10 +542,21 @@ export class UnifiedConfigManager extends EventEmitter {
     return [
       resolve(basePath, 'config', 'default.yaml'),
       resolve(basePath, 'config', `${environment}.yaml`),
+      resolve(basePath, 'config', 'hosts', `${process.env.PIXIU_HOST || hostname()}.yaml`),
       resolve(basePath, 'config', 'local.yaml')
     ];
   }
 
+  /**
+   * 环境变量来源标记为具体的变量名
+   */
+  private getEnvironmentLabel(path: string): string {
+    const mapping = ENV_MAPPINGS.find(candidate =>
+      process.env[candidate.env] !== undefined && (path === candidate.path || path.startsWith(`${candidate.path}.`))
+    );
+    return mapping ? `env:${mapping.env}` : 'environment';
+  }
+
   private loadConfigFile(filePath: string): any {
     const content = readFileSync(filePath, 'utf-8');
     const ext = filePath.split('.').pop()?.toLowerCase();
@@ -401,31 +585,7 @@ export class UnifiedConfigManager extends EventEmitter {
 
     this.watchedFiles.add(filePath);
     watchFile(filePath, { interval: 1000 }, () => {
-      try {
-        const newConfig = this.loadConfigFile(filePath);
-        const fileName = filePath.split('/').pop();
-        this.configSources.set(`file-${fileName}`, newConfig);
-        
-        // 重新合并配置
-        const mergedConfig = this.mergeConfigurations(...Array.from(this.configSources.values()));
-        
-        // 验证新配置
-        const validation = this.validateConfiguration(mergedConfig);
-        if (validation.valid) {
-          const oldConfig = this.currentConfig;
-          this.currentConfig = mergedConfig;
-          
-          // 计算变更
-          const changes = this.calculateChanges(oldConfig!, mergedConfig);
-          if (changes.length > 0) {
-            this.emit('configChanged', mergedConfig, changes);
-          }
-        } else {
-          console.error('Config file change validation failed:', validation.errors);
-        }
-      } catch (error) {
-        console.error(`Error reloading config file ${filePath}:`, error);
-      }
+      this.reloadConfiguration();
     });
   }
 
@@ -533,6 +693,10 @@ export class UnifiedConfigManager extends EventEmitter {
       
       logging: Joi.object({
         level: Joi.string().valid('error', 'warn', 'info', 'debug', 'silly').required(),
+        componentLevels: Joi.object().pattern(
+          Joi.string(),
+          Joi.string().valid('error', 'warn', 'info', 'debug', 'trace')
+        ).optional(),
         format: Joi.string().valid('json', 'simple', 'combined').required(),
         output: Joi.string().valid('console', 'file', 'both').required(),
         file: Joi.object({
@@ -591,6 +755,53 @@ export class UnifiedConfigManager extends EventEmitter {
     target[lastKey] = value;
   }
 
+  /**
+   * 判断变更是否涉及不可热更新的路径
+   * 变更路径位于受保护路径之下，或变更的父级对象中包含受保护路径的值变化时均视为不可热更新
+   */
+  private isImmutableChange(change: ConfigChange): boolean {
+    const changeParts = change.path.split('.');
+
+    return this.immutablePaths.some(pattern => {
+      const patternParts = pattern.split('.');
+      const common = Math.min(changeParts.length, patternParts.length);
+
+      for (let i = 0; i < common; i++) {
+        if (patternParts[i] !== '*' && patternParts[i] !== changeParts[i]) {
+          return false;
+        }
+      }
+
+      if (changeParts.length >= patternParts.length) {
+        return true;
+      }
+
+      const remaining = patternParts.slice(changeParts.length);
+      return this.hasWildcardValueChange(change.oldValue, change.newValue, remaining);
+    });
+  }
+
+  /**
+   * 比较新旧对象在（可含通配符的）子路径上的值是否不同
+   */
+  private hasWildcardValueChange(oldValue: any, newValue: any, pathParts: string[]): boolean {
+    if (pathParts.length === 0) {
+      return JSON.stringify(oldValue) !== JSON.stringify(newValue);
+    }
+
+    const [head, ...rest] = pathParts;
+    const keys = head === '*'
+      ? new Set([...Object.keys(oldValue || {}), ...Object.keys(newValue || {})])
+      : new Set([head]);
+
+    for (const key of keys) {
+      if (this.hasWildcardValueChange(oldValue?.[key], newValue?.[key], rest)) {
+        return true;
+      }
+    }
+    return false;
+  }
+
   private calculateChanges(oldConfig: UnifiedConfig, newConfig: UnifiedConfig): ConfigChange[] {
     const changes: ConfigChange[] = [];
     const timestamp = new Date();
@@ -0,0 +1,332 @@
+/**
+ * 进程内事件总线
+ * 按主题解耦生产者与消费者，每个订阅者拥有独立队列与缓冲策略，慢消费者不会拖慢其他订阅者
+ */
+
+import { EventEmitter } from 'events';
+import { BaseMonitor } from '../monitoring/base-monitor';
+
+/**
+ * 队列已满时的处理策略
+ * - drop-oldest：丢弃最旧事件
+ * - block：发布方等待队列腾出空间
+ * - coalesce：相同键的事件只保留最新一条（如同一交易对的行情快照）
+ */
+export type BufferPolicy = 'drop-oldest' | 'block' | 'coalesce';
+
+export type EventHandler<T> = (event: T) => void | Promise<void>;
+
+export interface SubscribeOptions<T> {
+  /** 订阅者名称，用于指标标签 */
+  name?: string;
+  /** 缓冲策略，默认drop-oldest */
+  policy?: BufferPolicy;
+  /** 队列容量，默认1000 */
+  capacity?: number;
+  /** coalesce策略的合并键 */
+  coalesceKey?: (event: T) => string;
+}
+
+export interface SubscriberStats {
+  topic: string;
+  name: string;
+  policy: BufferPolicy;
+  depth: number;
+  capacity: number;
+  delivered: number;
+  dropped: number;
+  coalesced: number;
+  errors: number;
+}
+
+export interface Subscription {
+  unsubscribe(): void;
+}
+
+export interface EventBusOptions {
+  /** 注册队列深度与丢弃计数指标 */
+  monitor?: BaseMonitor;
+}
+
+class Subscriber<T> {
+  readonly stats: SubscriberStats;
+
+  private readonly queue: T[] = [];
+  private readonly keyed = new Map<string, T>();
+  private readonly waiters: Array<() => void> = [];
+  private readonly idleWaiters: Array<() => void> = [];
+  private draining = false;
+  private closed = false;
+
+  constructor(
+    topic: string,
+    private readonly handler: EventHandler<T>,
+    private readonly options: Required<Pick<SubscribeOptions<T>, 'name' | 'policy' | 'capacity'>> & Pick<SubscribeOptions<T>, 'coalesceKey'>,
+    private readonly onError: (error: Error, subscriber: Subscriber<T>) => void,
+    private readonly onDispatch: (subscriber: Subscriber<T>) => void = () => undefined
+  ) {
+    this.stats = {
+      topic,
+      name: options.name,
+      policy: options.policy,
+      depth: 0,
+      capacity: options.capacity,
+      delivered: 0,
+      dropped: 0,
+      coalesced: 0,
+      errors: 0
+    };
+  }
+
+  /**
+   * 放入队列，block策略在队列满时等待
+   */
+  async offer(event: T): Promise<void> {
+    if (this.closed) {
+      return;
+    }
+
+    if (this.options.policy === 'coalesce') {
+      this.offerCoalesced(event);
+    } else {
+      while (this.options.policy === 'block' && this.size() >= this.options.capacity && !this.closed) {
+        await new Promise<void>(resolve => this.waiters.push(resolve));
+      }
+      if (this.closed) {
+        return;
+      }
+      if (this.size() >= this.options.capacity) {
+        this.queue.shift();
+        this.stats.dropped++;
+      }
+      this.queue.push(event);
+    }
+
+    this.stats.depth = this.size();
+    this.drain();
+  }
+
+  /**
+   * 等待队列清空
+   */
+  async idle(): Promise<void> {
+    if (!this.draining && this.size() === 0) {
+      return;
+    }
+    await new Promise<void>(resolve => this.idleWaiters.push(resolve));
+  }
+
+  close(): void {
+    this.closed = true;
+    this.queue.length = 0;
+    this.keyed.clear();
+    this.stats.depth = 0;
+    this.waiters.splice(0).forEach(resolve => resolve());
+    this.idleWaiters.splice(0).forEach(resolve => resolve());
+  }
+
+  private offerCoalesced(event: T): void {
+    const key = this.options.coalesceKey!(event);
+    if (this.keyed.has(key)) {
+      // Map保留原位置，事件替换为最新值
+      this.keyed.set(key, event);
+      this.stats.coalesced++;
+      return;
+    }
+
+    if (this.keyed.size >= this.options.capacity) {
+      const oldest = this.keyed.keys().next().value as string;
+      this.keyed.delete(oldest);
+      this.stats.dropped++;
+    }
+    this.keyed.set(key, event);
+  }
+
+  private size(): number {
+    return this.options.policy === 'coalesce' ? this.keyed.size : this.queue.length;
+  }
+
+  private take(): T | undefined {
+    if (this.options.policy === 'coalesce') {
+      const next = this.keyed.entries().next();
+      if (next.done) {
+        return undefined;
+      }
+      this.keyed.delete(next.value[0]);
+      return next.value[1];
+    }
+    return this.queue.shift();
+  }
+
+  /**
+   * 按顺序逐个投递，保证单个订阅者内的事件顺序
+   */
+  private async drain(): Promise<void> {
+    if (this.draining) {
+      return;
+    }
+    this.draining = true;
+
+    try {
+      while (!this.closed && this.size() > 0) {
+        const event = this.take() as T;
+        this.stats.depth = this.size();
+        this.waiters.shift()?.();
+        this.onDispatch(this);
+
+        try {
+          await this.handler(event);
+          this.stats.delivered++;
+        } catch (error) {
+          this.stats.errors++;
+          this.onError(error as Error, this);
+        }
+      }
+    } finally {
+      this.draining = false;
+      this.idleWaiters.splice(0).forEach(resolve => resolve());
+    }
+  }
+}
+
+const registeredMonitors = new WeakSet<BaseMonitor>();
+
+/**
+ * 类型化事件总线
+ *
+ * 事件：
+ * - error(error, stats) 订阅者处理失败
+ */
+export class EventBus<Topics extends Record<string, any>> extends EventEmitter {
+  private readonly subscribers = new Map<keyof Topics, Set<Subscriber<any>>>();
+  private readonly monitor?: BaseMonitor;
+  private sequence = 0;
+
+  constructor(options: EventBusOptions = {}) {
+    super();
+    this.monitor = options.monitor;
+
+    const monitor = options.monitor;
+    if (monitor && !registeredMonitors.has(monitor)) {
+      registeredMonitors.add(monitor);
+      monitor.registerMetric({
+        name: 'eventbus_queue_depth',
+        description: 'Events waiting in an event bus subscriber queue',
+        type: 'gauge',
+        labels: ['topic', 'subscriber']
+      });
+      monitor.registerMetric({
+        name: 'eventbus_events_dropped_total',
+        description: 'Events dropped because a subscriber queue was full',
+        type: 'counter',
+        labels: ['topic', 'subscriber']
+      });
+    }
+  }
+
+  /**
+   * 订阅主题
+   */
+  subscribe<K extends keyof Topics & string>(
+    topic: K,
+    handler: EventHandler<Topics[K]>,
+    options: SubscribeOptions<Topics[K]> = {}
+  ): Subscription {
+    const policy = options.policy ?? 'drop-oldest';
+    if (policy === 'coalesce' && !options.coalesceKey) {
+      throw new Error(`Subscriber on ${topic} uses coalesce policy without coalesceKey`);
+    }
+
+    const subscriber = new Subscriber<Topics[K]>(
+      topic,
+      handler,
+      {
+        name: options.name ?? `${topic}-${++this.sequence}`,
+        policy,
+        capacity: options.capacity ?? 1000,
+        coalesceKey: options.coalesceKey
+      },
+      (error, failed) => {
+        if (this.listenerCount('error') > 0) {
+          this.emit('error', error, { ...failed.stats });
+        }
+      },
+      // 出队后同步队列深度，积压消化时指标随之回落
+      dispatched => this.recordQueueDepth(dispatched)
+    );
+
+    if (!this.subscribers.has(topic)) {
+      this.subscribers.set(topic, new Set());
+    }
+    this.subscribers.get(topic)!.add(subscriber);
+
+    return {
+      unsubscribe: () => {
+        subscriber.close();
+        this.subscribers.get(topic)?.delete(subscriber);
+      }
+    };
+  }
+
+  /**
+   * 发布事件
+   * 仅当存在block策略的订阅者且其队列已满时才需要等待
+   */
+  async publish<K extends keyof Topics & string>(topic: K, event: Topics[K]): Promise<void> {
+    const subscribers = this.subscribers.get(topic);
+    if (!subscribers || subscribers.size === 0) {
+      return;
+    }
+
+    const pending: Promise<void>[] = [];
+    for (const subscriber of subscribers) {
+      const dropped = subscriber.stats.dropped;
+      pending.push(subscriber.offer(event).then(() => this.recordMetrics(subscriber, dropped)));
+    }
+    await Promise.all(pending);
+  }
+
+  /**
+   * 订阅者统计
+   */
+  getStats(topic?: keyof Topics & string): SubscriberStats[] {
+    const topics = topic ? [topic] : Array.from(this.subscribers.keys());
+    return topics.flatMap(name => Array.from(this.subscribers.get(name) ?? []).map(subscriber => ({ ...subscriber.stats })));
+  }
+
+  /**
+   * 等待所有订阅者处理完已入队的事件
+   */
+  async drain(): Promise<void> {
+    const all = Array.from(this.subscribers.values()).flatMap(set => Array.from(set));
+    await Promise.all(all.map(subscriber => subscriber.idle()));
+  }
+
+  /**
+   * 关闭总线，未处理的事件会被丢弃
+   */
+  close(): void {
+    for (const set of this.subscribers.values()) {
+      set.forEach(subscriber => subscriber.close());
+    }
+    this.subscribers.clear();
+  }
+
+  private recordMetrics(subscriber: Subscriber<any>, droppedBefore: number): void {
+    if (!this.monitor) {
+      return;
+    }
+    this.recordQueueDepth(subscriber);
+    if (subscriber.stats.dropped > droppedBefore) {
+      const labels = { topic: subscriber.stats.topic, subscriber: subscriber.stats.name };
+      this.monitor.incrementCounter('eventbus_events_dropped_total', subscriber.stats.dropped - droppedBefore, labels);
+    }
+  }
+
+  private recordQueueDepth(subscriber: Subscriber<any>): void {
+    this.monitor?.updateMetric('eventbus_queue_depth', subscriber.stats.depth, {
+      topic: subscriber.stats.topic,
+      subscriber: subscriber.stats.name
+    });
+  }
+}
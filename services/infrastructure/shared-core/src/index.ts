@@ -30,14 +30,26 @@ export { BaseErrorHandler } from './error/base-handler';
 // 监控系统
 export * from './monitoring/types';
 export * from './monitoring/base-monitor';
+export * from './monitoring/tracing';
 
 // Pub/Sub消息系统
 export * from './pubsub/types';
 export * from './pubsub/client';
 
+// 进程内事件总线
+export * from './eventbus/event-bus';
+
+// 密钥管理
+export * from './secrets/types';
+export * from './secrets/providers';
+export * from './secrets/resolver';
+
 // 通用工具
 export * from './utils/retry';
 export * from './utils/cache';
+export * from './utils/rate-limiter';
+export * from './utils/http-policy';
+export * from './utils/proxy-pool';
 
 // 版本信息
 export const VERSION = '1.0.0';
\ No newline at end of file
@@ -12,6 +12,8 @@ import {
   HealthCheckResult,
   HealthCheckDefinition,
   LogEntry,
+  LogContext,
+  LogLevel,
   MonitoringConfig,
   AlertRule,
   Alert,
@@ -20,6 +22,69 @@ import {
   AlertHandler
 } from './types';
 
+type LogLevelName = LogLevel['level'];
+
+const LOG_LEVEL_PRIORITY: Record<LogLevelName, number> = {
+  error: 0,
+  warn: 1,
+  info: 2,
+  debug: 3,
+  trace: 4
+};
+
+/**
+ * 组件日志记录器
+ * 绑定组件名与关联上下文（交易所、策略ID、客户端订单ID等），每条日志自动携带
+ */
+export class ComponentLogger {
+  constructor(
+    private readonly monitor: BaseMonitor,
+    readonly component: string,
+    private readonly bindings: LogContext = {}
+  ) {}
+
+  /**
+   * 派生子记录器，合并额外的关联上下文
+   */
+  child(bindings: LogContext): ComponentLogger {
+    return new ComponentLogger(this.monitor, this.component, { ...this.bindings, ...bindings });
+  }
+
+  /**
+   * 按指定级别记录日志
+   */
+  log(level: LogLevelName, message: string, context?: LogContext): void {
+    this.monitor.writeLog(this.component, level, message, context ? { ...this.bindings, ...context } : this.bindings);
+  }
+
+  error(message: string, context?: LogContext): void {
+    this.log('error', message, context);
+  }
+
+  warn(message: string, context?: LogContext): void {
+    this.log('warn', message, context);
+  }
+
+  info(message: string, context?: LogContext): void {
+    this.log('info', message, context);
+  }
+
+  debug(message: string, context?: LogContext): void {
+    this.log('debug', message, context);
+  }
+
+  trace(message: string, context?: LogContext): void {
+    this.log('trace', message, context);
+  }
+
+  /**
+   * 当前组件是否会输出该级别的日志
+   */
+  isLevelEnabled(level: LogLevelName): boolean {
+    return this.monitor.isLevelEnabled(level, this.component);
+  }
+}
+
 export class BaseMonitor extends EventEmitter {
   private metrics: Map<string, any> = new Map();
   private healthChecks: Map<string, HealthCheckDefinition> = new Map();
@@ -28,9 +93,13 @@ export class BaseMonitor extends EventEmitter {
   private logger!: winston.Logger;
   private healthCheckInterval?: NodeJS.Timeout;
   private alertCheckInterval?: NodeJS.Timeout;
+  private logLevel: LogLevelName;
+  private componentLevels: Map<string, LogLevelName>;
 
   constructor(private config: MonitoringConfig) {
     super();
+    this.logLevel = config.logging.level;
+    this.componentLevels = new Map(Object.entries(config.logging.componentLevels ?? {}));
     this.setupLogger();
     this.setupMetrics();
     this.startHealthChecks();
@@ -250,18 +319,91 @@ export class BaseMonitor extends EventEmitter {
   /**
    * 记录日志
    */
-  log(level: 'error' | 'warn' | 'info' | 'debug' | 'trace', message: string, context?: Record<string, any>): void {
+  log(level: LogLevelName, message: string, context?: Record<string, any>): void {
+    this.writeLog('monitor', level, message, context);
+  }
+
+  /**
+   * 创建组件日志记录器，绑定的上下文会附加到该组件的每条日志
+   */
+  child(component: string, bindings: LogContext = {}): ComponentLogger {
+    return new ComponentLogger(this, component, bindings);
+  }
+
+  /**
+   * 运行时调整日志级别，指定组件时仅影响该组件及其子组件（如 binance 影响 binance.websocket）
+   */
+  setLogLevel(level: LogLevelName, component?: string): void {
+    if (component === undefined) {
+      this.logLevel = level;
+    } else {
+      this.componentLevels.set(component, level);
+    }
+  }
+
+  /**
+   * 整体替换全局与组件日志级别，用于配置热更新；统一配置中的 silly 视为 trace
+   */
+  configureLogLevels(level: LogLevelName | 'silly', componentLevels: Record<string, LogLevelName> = {}): void {
+    this.logLevel = level === 'silly' ? 'trace' : level;
+    this.componentLevels = new Map(Object.entries(componentLevels));
+  }
+
+  /**
+   * 移除组件的日志级别覆盖，恢复继承上级组件或全局级别
+   */
+  resetLogLevel(component: string): void {
+    this.componentLevels.delete(component);
+  }
+
+  /**
+   * 获取组件生效的日志级别
+   */
+  getLogLevel(component?: string): LogLevelName {
+    if (component !== undefined) {
+      const segments = component.split('.');
+      for (let i = segments.length; i > 0; i--) {
+        const level = this.componentLevels.get(segments.slice(0, i).join('.'));
+        if (level) {
+          return level;
+        }
+      }
+    }
+    return this.logLevel;
+  }
+
+  /**
+   * 组件是否会输出该级别的日志
+   */
+  isLevelEnabled(level: LogLevelName, component?: string): boolean {
+    // 未知级别（如统一配置中的 silly）按最详细的 trace 处理
+    const threshold = LOG_LEVEL_PRIORITY[this.getLogLevel(component)] ?? LOG_LEVEL_PRIORITY.trace;
+    return LOG_LEVEL_PRIORITY[level] <= threshold;
+  }
+
+  /**
+   * 写入日志条目，关联字段提升到条目顶层
+   */
+  writeLog(component: string, level: LogLevelName, message: string, context?: LogContext): void {
+    if (!this.isLevelEnabled(level, component)) {
+      return;
+    }
+
     const entry: LogEntry = {
       timestamp: Date.now(),
       level,
       message,
-      component: 'monitor',
+      component,
       context,
       traceId: context?.traceId,
-      requestId: context?.requestId
+      requestId: context?.requestId,
+      exchange: context?.exchange,
+      strategyId: context?.strategyId,
+      clientOrderId: context?.clientOrderId
     };
 
-    this.logger.log(level, message, context);
+    // winston 默认级别中没有 trace，对应其最详细的 silly
+    this.logger.log(level === 'trace' ? 'silly' : level, message, { component, ...context });
     this.emit('log', entry);
   }
 
@@ -308,7 +450,7 @@ export class BaseMonitor extends EventEmitter {
    * 设置日志记录器
    */
   private setupLogger(): void {
-    const { level, format, output, file } = this.config.logging;
+    const { format, output, file } = this.config.logging;
 
     const transports: winston.transport[] = [];
 
@@ -338,8 +480,9 @@ export class BaseMonitor extends EventEmitter {
       }));
     }
 
+    // 级别过滤由 writeLog 按组件完成，winston 不再二次过滤
     this.logger = winston.createLogger({
-      level,
+      level: 'silly',
       transports
     });
   }
@@ -0,0 +1,426 @@
+/**
+ * 分布式追踪
+ * 兼容W3C Trace Context的轻量实现，按OTLP/HTTP JSON格式导出到Jaeger、Tempo等后端
+ */
+
+import { randomBytes } from 'crypto';
+
+/**
+ * 追踪配置
+ */
+export interface TracingConfig {
+  /** 是否启用 */
+  enabled: boolean;
+  /** 服务名称，对应OTLP资源属性service.name */
+  serviceName: string;
+  /** 采样比例（0-1），默认1 */
+  sampleRatio?: number;
+  /** OTLP导出配置 */
+  exporter?: {
+    /** OTLP/HTTP地址，如 http://localhost:4318 */
+    endpoint: string;
+    /** 附加请求头，如认证信息 */
+    headers?: Record<string, string>;
+    /** 批量导出间隔（毫秒），默认5000 */
+    flushInterval?: number;
+    /** 单批最大Span数，默认512 */
+    maxBatchSize?: number;
+    /** 待导出队列上限，超出后丢弃，默认2048 */
+    maxQueueSize?: number;
+  };
+}
+
+/**
+ * Span上下文
+ */
+export interface SpanContext {
+  traceId: string;
+  spanId: string;
+  /** 01表示已采样 */
+  traceFlags: number;
+}
+
+export type SpanKind = 'internal' | 'server' | 'client' | 'producer' | 'consumer';
+
+export type SpanAttributeValue = string | number | boolean;
+
+export interface SpanOptions {
+  /** 父Span上下文或traceparent头 */
+  parent?: SpanContext | string;
+  kind?: SpanKind;
+  attributes?: Record<string, SpanAttributeValue>;
+  /** 开始时间（毫秒），默认当前时间 */
+  startTime?: number;
+}
+
+/**
+ * 已结束的Span
+ */
+export interface FinishedSpan {
+  name: string;
+  kind: SpanKind;
+  context: SpanContext;
+  parentSpanId?: string;
+  startTime: number;
+  endTime: number;
+  attributes: Record<string, SpanAttributeValue>;
+  status: { code: 'unset' | 'ok' | 'error'; message?: string };
+  events: Array<{ name: string; time: number; attributes: Record<string, SpanAttributeValue> }>;
+}
+
+/**
+ * Span导出器
+ */
+export interface SpanExporter {
+  export(spans: FinishedSpan[]): void;
+  shutdown(): Promise<void>;
+}
+
+/**
+ * 解析traceparent头
+ */
+export function parseTraceparent(header: string | undefined): SpanContext | undefined {
+  const match = header && /^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$/.exec(header.trim());
+  if (!match || /^0+$/.test(match[1]) || /^0+$/.test(match[2])) {
+    return undefined;
+  }
+  return { traceId: match[1], spanId: match[2], traceFlags: parseInt(match[3], 16) };
+}
+
+/**
+ * 格式化traceparent头
+ */
+export function formatTraceparent(context: SpanContext): string {
+  return `00-${context.traceId}-${context.spanId}-${context.traceFlags.toString(16).padStart(2, '0')}`;
+}
+
+/**
+ * 追踪Span
+ * 未采样的Span仍会生成上下文以便向下游传播，但结束时不会导出
+ */
+export class Span {
+  readonly context: SpanContext;
+  private readonly attributes: Record<string, SpanAttributeValue>;
+  private readonly events: FinishedSpan['events'] = [];
+  private status: FinishedSpan['status'] = { code: 'unset' };
+  private readonly startTime: number;
+  private ended = false;
+
+  constructor(
+    readonly name: string,
+    private readonly tracer: Tracer,
+    context: SpanContext,
+    private readonly parentSpanId: string | undefined,
+    private readonly kind: SpanKind,
+    attributes: Record<string, SpanAttributeValue>,
+    startTime: number
+  ) {
+    this.context = context;
+    this.attributes = { ...attributes };
+    this.startTime = startTime;
+  }
+
+  /**
+   * 是否已采样
+   */
+  isRecording(): boolean {
+    return (this.context.traceFlags & 1) === 1 && !this.ended;
+  }
+
+  setAttribute(key: string, value: SpanAttributeValue): this {
+    this.attributes[key] = value;
+    return this;
+  }
+
+  addEvent(name: string, attributes: Record<string, SpanAttributeValue> = {}): this {
+    this.events.push({ name, time: Date.now(), attributes });
+    return this;
+  }
+
+  setStatus(code: 'ok' | 'error', message?: string): this {
+    this.status = { code, message };
+    return this;
+  }
+
+  /**
+   * 记录异常并将状态置为错误
+   */
+  recordException(error: Error): this {
+    this.addEvent('exception', { 'exception.type': error.name, 'exception.message': error.message });
+    return this.setStatus('error', error.message);
+  }
+
+  /**
+   * 获取用于传播的traceparent头
+   */
+  traceparent(): string {
+    return formatTraceparent(this.context);
+  }
+
+  end(endTime = Date.now()): void {
+    if (this.ended) {
+      return;
+    }
+    const sampled = this.isRecording();
+    this.ended = true;
+
+    if (sampled) {
+      this.tracer.onEnd({
+        name: this.name,
+        kind: this.kind,
+        context: this.context,
+        parentSpanId: this.parentSpanId,
+        startTime: this.startTime,
+        endTime,
+        attributes: this.attributes,
+        status: this.status,
+        events: this.events
+      });
+    }
+  }
+}
+
+/**
+ * 未启用追踪时返回的空Span，不生成ID也不记录属性与事件
+ */
+class NoopSpan extends Span {
+  setAttribute(): this {
+    return this;
+  }
+
+  addEvent(): this {
+    return this;
+  }
+
+  setStatus(): this {
+    return this;
+  }
+}
+
+const NOOP_CONTEXT: SpanContext = { traceId: '0'.repeat(32), spanId: '0'.repeat(16), traceFlags: 0 };
+
+/**
+ * 追踪器
+ */
+export class Tracer {
+  private readonly sampleRatio: number;
+  private readonly noopSpan: Span;
+
+  constructor(
+    private readonly config: TracingConfig = { enabled: false, serviceName: 'pixiu' },
+    private readonly exporter?: SpanExporter
+  ) {
+    this.sampleRatio = config.enabled ? config.sampleRatio ?? 1 : 0;
+    this.noopSpan = new NoopSpan('noop', this, NOOP_CONTEXT, undefined, 'internal', {}, 0);
+    if (!this.exporter && config.enabled && config.exporter) {
+      this.exporter = new OtlpHttpExporter(config.serviceName, config.exporter);
+    }
+  }
+
+  /**
+   * 开始Span
+   * 存在父上下文时沿用父级的采样决定；未启用时返回共享的空Span，热路径上没有额外开销
+   */
+  startSpan(name: string, options: SpanOptions = {}): Span {
+    if (!this.config.enabled) {
+      return this.noopSpan;
+    }
+
+    const parent = typeof options.parent === 'string' ? parseTraceparent(options.parent) : options.parent;
+    const sampled = parent ? (parent.traceFlags & 1) === 1 : Math.random() < this.sampleRatio;
+
+    const context: SpanContext = {
+      traceId: parent?.traceId ?? randomBytes(16).toString('hex'),
+      spanId: randomBytes(8).toString('hex'),
+      traceFlags: sampled ? 1 : 0
+    };
+
+    return new Span(name, this, context, parent?.spanId, options.kind ?? 'internal', options.attributes ?? {}, options.startTime ?? Date.now());
+  }
+
+  /**
+   * 在Span中执行异步函数，自动记录异常并结束Span
+   */
+  async withSpan<T>(name: string, options: SpanOptions, fn: (span: Span) => Promise<T>): Promise<T> {
+    const span = this.startSpan(name, options);
+    try {
+      const result = await fn(span);
+      span.setStatus('ok');
+      return result;
+    } catch (error) {
+      span.recordException(error as Error);
+      throw error;
+    } finally {
+      span.end();
+    }
+  }
+
+  /**
+   * Span结束回调
+   */
+  onEnd(span: FinishedSpan): void {
+    this.exporter?.export([span]);
+  }
+
+  /**
+   * 导出剩余Span并停止
+   */
+  async shutdown(): Promise<void> {
+    await this.exporter?.shutdown();
+  }
+}
+
+const OTLP_SPAN_KIND: Record<SpanKind, number> = {
+  internal: 1,
+  server: 2,
+  client: 3,
+  producer: 4,
+  consumer: 5
+};
+
+const OTLP_STATUS_CODE = { unset: 0, ok: 1, error: 2 };
+
+/**
+ * OTLP/HTTP JSON导出器
+ * Span先进入队列，按间隔或批量大小发送到 {endpoint}/v1/traces
+ */
+export class OtlpHttpExporter implements SpanExporter {
+  private queue: FinishedSpan[] = [];
+  private timer?: NodeJS.Timeout;
+  private inflight: Promise<void> = Promise.resolve();
+  private droppedSpans = 0;
+
+  constructor(
+    private readonly serviceName: string,
+    private readonly options: NonNullable<TracingConfig['exporter']>
+  ) {
+    this.timer = setInterval(() => this.flush(), options.flushInterval ?? 5000);
+    this.timer.unref();
+  }
+
+  export(spans: FinishedSpan[]): void {
+    const maxQueueSize = this.options.maxQueueSize ?? 2048;
+    for (const span of spans) {
+      if (this.queue.length >= maxQueueSize) {
+        this.droppedSpans++;
+        continue;
+      }
+      this.queue.push(span);
+    }
+
+    if (this.queue.length >= (this.options.maxBatchSize ?? 512)) {
+      this.flush();
+    }
+  }
+
+  /**
+   * 发送队列中的Span，导出失败只记录丢弃数，不影响业务流程
+   */
+  flush(): Promise<void> {
+    if (this.queue.length === 0) {
+      return this.inflight;
+    }
+
+    const batch = this.queue.splice(0, this.options.maxBatchSize ?? 512);
+    this.inflight = this.inflight.then(async () => {
+      try {
+        const response = await fetch(`${this.options.endpoint.replace(/\/$/, '')}/v1/traces`, {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json', ...this.options.headers },
+          body: JSON.stringify(this.toOtlp(batch))
+        });
+        if (!response.ok) {
+          this.droppedSpans += batch.length;
+        }
+      } catch {
+        this.droppedSpans += batch.length;
+      }
+    });
+    return this.inflight;
+  }
+
+  /**
+   * 丢弃的Span数量
+   */
+  getDroppedSpans(): number {
+    return this.droppedSpans;
+  }
+
+  async shutdown(): Promise<void> {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = undefined;
+    }
+    while (this.queue.length > 0) {
+      await this.flush();
+    }
+    await this.inflight;
+  }
+
+  /**
+   * 转换为OTLP ExportTraceServiceRequest
+   */
+  toOtlp(spans: FinishedSpan[]): any {
+    return {
+      resourceSpans: [{
+        resource: { attributes: toOtlpAttributes({ 'service.name': this.serviceName }) },
+        scopeSpans: [{
+          scope: { name: '@pixiu/shared-core' },
+          spans: spans.map(span => ({
+            traceId: span.context.traceId,
+            spanId: span.context.spanId,
+            ...(span.parentSpanId ? { parentSpanId: span.parentSpanId } : {}),
+            name: span.name,
+            kind: OTLP_SPAN_KIND[span.kind],
+            startTimeUnixNano: toUnixNano(span.startTime),
+            endTimeUnixNano: toUnixNano(span.endTime),
+            attributes: toOtlpAttributes(span.attributes),
+            events: span.events.map(event => ({
+              name: event.name,
+              timeUnixNano: toUnixNano(event.time),
+              attributes: toOtlpAttributes(event.attributes)
+            })),
+            status: {
+              code: OTLP_STATUS_CODE[span.status.code],
+              ...(span.status.message ? { message: span.status.message } : {})
+            }
+          }))
+        }]
+      }]
+    };
+  }
+}
+
+function toUnixNano(ms: number): string {
+  return (BigInt(Math.round(ms * 1000)) * 1000n).toString();
+}
+
+function toOtlpAttributes(attributes: Record<string, SpanAttributeValue>): any[] {
+  return Object.entries(attributes).map(([key, value]) => {
+    if (typeof value === 'boolean') {
+      return { key, value: { boolValue: value } };
+    }
+    if (typeof value === 'number') {
+      return Number.isInteger(value)
+        ? { key, value: { intValue: String(value) } }
+        : { key, value: { doubleValue: value } };
+    }
+    return { key, value: { stringValue: value } };
+  });
+}
+
+let globalTracer = new Tracer();
+
+/**
+ * 获取全局追踪器，未设置时为不采样的空实现
+ */
+export function getGlobalTracer(): Tracer {
+  return globalTracer;
+}
+
+/**
+ * 设置全局追踪器
+ */
+export function setGlobalTracer(tracer: Tracer): void {
+  globalTracer = tracer;
+}
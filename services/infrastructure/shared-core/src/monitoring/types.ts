@@ -37,6 +37,18 @@ export interface LogLevel {
   level: 'error' | 'warn' | 'info' | 'debug' | 'trace';
 }
 
+/**
+ * 日志上下文，关联字段会被提升到日志条目顶层，便于按订单或策略检索
+ */
+export interface LogContext {
+  exchange?: string;
+  strategyId?: string;
+  clientOrderId?: string;
+  traceId?: string;
+  requestId?: string;
+  [key: string]: any;
+}
+
 export interface LogEntry {
   timestamp: number;
   level: LogLevel['level'];
@@ -46,6 +58,9 @@ export interface LogEntry {
   error?: Error;
   traceId?: string;
   requestId?: string;
+  exchange?: string;
+  strategyId?: string;
+  clientOrderId?: string;
 }
 
 export interface MonitoringConfig {
@@ -65,6 +80,8 @@ export interface MonitoringConfig {
   };
   logging: {
     level: LogLevel['level'];
+    /** 按组件覆盖日志级别，如 { 'binance.websocket': 'debug' } */
+    componentLevels?: Record<string, LogLevel['level']>;
     format: 'json' | 'text';
     output: 'console' | 'file' | 'both';
     file?: {
@@ -0,0 +1,283 @@
+/**
+ * 内置密钥数据源
+ * 环境变量、HashiCorp Vault、AWS Secrets Manager以及AES加密的本地文件
+ */
+
+import * as crypto from 'crypto';
+import * as fs from 'fs';
+import { SecretProvider, SecretReference } from './types';
+
+/**
+ * 环境变量：env://BINANCE_API_KEY
+ */
+export class EnvSecretProvider implements SecretProvider {
+  readonly scheme = 'env';
+
+  async fetch(reference: SecretReference): Promise<string> {
+    const value = process.env[reference.path];
+    if (value === undefined) {
+      throw new Error(`Environment variable ${reference.path} is not set`);
+    }
+    return value;
+  }
+}
+
+export interface VaultSecretProviderOptions {
+  /** Vault地址，默认读取VAULT_ADDR */
+  address?: string;
+  /** 访问令牌，默认读取VAULT_TOKEN */
+  token?: string;
+  /** 企业版命名空间，默认读取VAULT_NAMESPACE */
+  namespace?: string;
+  /** KV引擎版本，默认2 */
+  kvVersion?: 1 | 2;
+}
+
+/**
+ * HashiCorp Vault KV引擎：vault://<mount>/<path>#field
+ */
+export class VaultSecretProvider implements SecretProvider {
+  readonly scheme = 'vault';
+
+  constructor(private readonly options: VaultSecretProviderOptions = {}) {}
+
+  async fetch(reference: SecretReference): Promise<Record<string, any>> {
+    const address = (this.options.address ?? process.env.VAULT_ADDR)?.replace(/\/$/, '');
+    const token = this.options.token ?? process.env.VAULT_TOKEN;
+    if (!address || !token) {
+      throw new Error('Vault address and token must be configured (VAULT_ADDR, VAULT_TOKEN)');
+    }
+
+    const [mount, ...rest] = reference.path.split('/');
+    const kvVersion = this.options.kvVersion ?? 2;
+    const path = kvVersion === 2 ? `${mount}/data/${rest.join('/')}` : reference.path;
+
+    const headers: Record<string, string> = { 'X-Vault-Token': token };
+    const namespace = this.options.namespace ?? process.env.VAULT_NAMESPACE;
+    if (namespace) {
+      headers['X-Vault-Namespace'] = namespace;
+    }
+
+    const response = await fetch(`${address}/v1/${path}`, { headers });
+    if (!response.ok) {
+      throw new Error(`Vault request for ${reference.path} failed: HTTP ${response.status}`);
+    }
+
+    const body: any = await response.json();
+    return kvVersion === 2 ? body.data.data : body.data;
+  }
+}
+
+export interface AwsCredentials {
+  accessKeyId: string;
+  secretAccessKey: string;
+  sessionToken?: string;
+}
+
+export interface AwsSignRequest {
+  method: string;
+  host: string;
+  path: string;
+  /** 已编码的查询字符串 */
+  query?: string;
+  headers: Record<string, string>;
+  body: string;
+  region: string;
+  service: string;
+  credentials: AwsCredentials;
+  now?: Date;
+}
+
+/**
+ * AWS Signature Version 4签名，返回需附加到请求上的头部
+ */
+export function signAwsRequest(request: AwsSignRequest): Record<string, string> {
+  const amzDate = (request.now ?? new Date()).toISOString().replace(/[-:]/g, '').replace(/\.\d{3}/, '');
+  const date = amzDate.slice(0, 8);
+  const sha256 = (data: string) => crypto.createHash('sha256').update(data, 'utf8').digest('hex');
+  const hmac = (key: crypto.BinaryLike, data: string) => crypto.createHmac('sha256', key).update(data, 'utf8').digest();
+
+  const headers: Record<string, string> = {
+    ...request.headers,
+    host: request.host,
+    'x-amz-date': amzDate
+  };
+  if (request.credentials.sessionToken) {
+    headers['x-amz-security-token'] = request.credentials.sessionToken;
+  }
+
+  const names = Object.keys(headers).map(name => name.toLowerCase()).sort();
+  const lowered = Object.fromEntries(Object.entries(headers).map(([name, value]) => [name.toLowerCase(), value]));
+  const signedHeaders = names.join(';');
+  const canonicalRequest = [
+    request.method,
+    request.path,
+    request.query ?? '',
+    names.map(name => `${name}:${lowered[name].trim()}\n`).join(''),
+    signedHeaders,
+    sha256(request.body)
+  ].join('\n');
+
+  const scope = `${date}/${request.region}/${request.service}/aws4_request`;
+  const stringToSign = ['AWS4-HMAC-SHA256', amzDate, scope, sha256(canonicalRequest)].join('\n');
+
+  const signingKey = [date, request.region, request.service, 'aws4_request']
+    .reduce<crypto.BinaryLike>((key, part) => hmac(key, part), `AWS4${request.credentials.secretAccessKey}`);
+  const signature = crypto.createHmac('sha256', signingKey).update(stringToSign, 'utf8').digest('hex');
+
+  const { host: _host, ...extra } = headers;
+  return {
+    ...extra,
+    Authorization: `AWS4-HMAC-SHA256 Credential=${request.credentials.accessKeyId}/${scope}, SignedHeaders=${signedHeaders}, Signature=${signature}`
+  };
+}
+
+export interface AwsSecretsManagerProviderOptions {
+  /** 区域，默认读取AWS_REGION */
+  region?: string;
+  /** 访问凭证，默认读取AWS_ACCESS_KEY_ID等环境变量 */
+  credentials?: AwsCredentials;
+}
+
+/**
+ * AWS Secrets Manager：aws-sm://<secret-id>#field
+ * JSON格式的SecretString会被解析为对象
+ */
+export class AwsSecretsManagerProvider implements SecretProvider {
+  readonly scheme = 'aws-sm';
+
+  constructor(private readonly options: AwsSecretsManagerProviderOptions = {}) {}
+
+  async fetch(reference: SecretReference): Promise<string | Record<string, any>> {
+    const region = this.options.region ?? process.env.AWS_REGION ?? process.env.AWS_DEFAULT_REGION;
+    const credentials = this.options.credentials ?? this.credentialsFromEnv();
+    if (!region || !credentials) {
+      throw new Error('AWS region and credentials must be configured (AWS_REGION, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY)');
+    }
+
+    const host = `secretsmanager.${region}.amazonaws.com`;
+    const body = JSON.stringify({ SecretId: reference.path });
+    const headers = signAwsRequest({
+      method: 'POST',
+      host,
+      path: '/',
+      headers: {
+        'content-type': 'application/x-amz-json-1.1',
+        'x-amz-target': 'secretsmanager.GetSecretValue'
+      },
+      body,
+      region,
+      service: 'secretsmanager',
+      credentials
+    });
+
+    const response = await fetch(`https://${host}/`, { method: 'POST', headers, body });
+    if (!response.ok) {
+      throw new Error(`AWS Secrets Manager request for ${reference.path} failed: HTTP ${response.status}`);
+    }
+
+    const result: any = await response.json();
+    if (result.SecretString === undefined) {
+      throw new Error(`Secret ${reference.path} has no SecretString`);
+    }
+
+    try {
+      return JSON.parse(result.SecretString);
+    } catch {
+      return result.SecretString;
+    }
+  }
+
+  private credentialsFromEnv(): AwsCredentials | undefined {
+    const { AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN } = process.env;
+    if (!AWS_ACCESS_KEY_ID || !AWS_SECRET_ACCESS_KEY) {
+      return undefined;
+    }
+    return { accessKeyId: AWS_ACCESS_KEY_ID, secretAccessKey: AWS_SECRET_ACCESS_KEY, sessionToken: AWS_SESSION_TOKEN };
+  }
+}
+
+/**
+ * 加密文件格式
+ */
+interface EncryptedSecretFile {
+  version: 1;
+  salt: string;
+  iv: string;
+  tag: string;
+  ciphertext: string;
+}
+
+/**
+ * 使用口令加密键值对，返回可写入文件的内容
+ * 密钥由scrypt派生，内容使用AES-256-GCM加密
+ */
+export function encryptSecrets(secrets: Record<string, any>, passphrase: string): string {
+  const salt = crypto.randomBytes(16);
+  const iv = crypto.randomBytes(12);
+  const cipher = crypto.createCipheriv('aes-256-gcm', crypto.scryptSync(passphrase, salt, 32), iv);
+  const ciphertext = Buffer.concat([cipher.update(JSON.stringify(secrets), 'utf8'), cipher.final()]);
+
+  const file: EncryptedSecretFile = {
+    version: 1,
+    salt: salt.toString('base64'),
+    iv: iv.toString('base64'),
+    tag: cipher.getAuthTag().toString('base64'),
+    ciphertext: ciphertext.toString('base64')
+  };
+  return JSON.stringify(file, null, 2);
+}
+
+/**
+ * 解密encryptSecrets生成的内容
+ */
+export function decryptSecrets(content: string, passphrase: string): Record<string, any> {
+  const file: EncryptedSecretFile = JSON.parse(content);
+  if (file.version !== 1) {
+    throw new Error(`Unsupported encrypted secret file version: ${file.version}`);
+  }
+
+  const key = crypto.scryptSync(passphrase, Buffer.from(file.salt, 'base64'), 32);
+  const decipher = crypto.createDecipheriv('aes-256-gcm', key, Buffer.from(file.iv, 'base64'));
+  decipher.setAuthTag(Buffer.from(file.tag, 'base64'));
+
+  try {
+    const plaintext = Buffer.concat([decipher.update(Buffer.from(file.ciphertext, 'base64')), decipher.final()]);
+    return JSON.parse(plaintext.toString('utf8'));
+  } catch {
+    throw new Error('Failed to decrypt secret file: wrong passphrase or corrupted content');
+  }
+}
+
+export interface EncryptedFileSecretProviderOptions {
+  /** 解密口令，默认读取PIXIU_SECRETS_PASSPHRASE */
+  passphrase?: string;
+}
+
+/**
+ * AES加密的本地文件：encfile:///etc/pixiu/secrets.enc#binance
+ */
+export class EncryptedFileSecretProvider implements SecretProvider {
+  readonly scheme = 'encfile';
+
+  private readonly files = new Map<string, Record<string, any>>();
+
+  constructor(private readonly options: EncryptedFileSecretProviderOptions = {}) {}
+
+  async fetch(reference: SecretReference): Promise<Record<string, any>> {
+    const cached = this.files.get(reference.path);
+    if (cached) {
+      return cached;
+    }
+
+    const passphrase = this.options.passphrase ?? process.env.PIXIU_SECRETS_PASSPHRASE;
+    if (!passphrase) {
+      throw new Error('Secret file passphrase must be configured (PIXIU_SECRETS_PASSPHRASE)');
+    }
+
+    const content = await fs.promises.readFile(reference.path, 'utf-8');
+    const secrets = decryptSecrets(content, passphrase);
+    this.files.set(reference.path, secrets);
+    return secrets;
+  }
+}
@@ -0,0 +1,139 @@
+/**
+ * 密钥引用解析器
+ * 将配置中的 scheme://path#field 字符串替换为对应数据源中的密钥
+ */
+
+import { SecretProvider, SecretReference } from './types';
+import {
+  AwsSecretsManagerProvider,
+  EncryptedFileSecretProvider,
+  EnvSecretProvider,
+  VaultSecretProvider
+} from './providers';
+
+export class SecretResolver {
+  private readonly providers = new Map<string, SecretProvider>();
+  private readonly cache = new Map<string, any>();
+
+  /**
+   * 注册数据源，同一协议的数据源会被覆盖
+   */
+  register(provider: SecretProvider): this {
+    this.providers.set(provider.scheme, provider);
+    return this;
+  }
+
+  /**
+   * 解析引用，未注册协议（如 wss://）的字符串不视为密钥引用
+   */
+  parse(value: unknown): SecretReference | undefined {
+    if (typeof value !== 'string') {
+      return undefined;
+    }
+
+    const match = /^([a-z][a-z0-9+-]*):\/\/([^#]+)(?:#(.+))?$/.exec(value);
+    if (!match || !this.providers.has(match[1])) {
+      return undefined;
+    }
+
+    return { uri: value, scheme: match[1], path: match[2], field: match[3] };
+  }
+
+  /**
+   * 读取单个引用
+   */
+  async resolveValue(uri: string): Promise<any> {
+    const reference = this.parse(uri);
+    if (!reference) {
+      throw new Error(`Not a secret reference: ${uri}`);
+    }
+
+    if (this.cache.has(uri)) {
+      return this.cache.get(uri);
+    }
+
+    const secret = await this.providers.get(reference.scheme)!.fetch(reference);
+    const value = reference.field === undefined ? secret : this.extractField(reference, secret);
+    this.cache.set(uri, value);
+    return value;
+  }
+
+  /**
+   * 递归替换配置中的全部引用，返回新对象
+   * 引用整个密钥时节点会被替换为键值对象，如 auth: vault://kv/binance-main
+   */
+  async resolve<T>(config: T): Promise<T> {
+    if (this.parse(config)) {
+      return this.resolveValue(config as unknown as string);
+    }
+
+    if (Array.isArray(config)) {
+      return Promise.all(config.map(item => this.resolve(item))) as unknown as T;
+    }
+
+    if (config && typeof config === 'object') {
+      const entries = await Promise.all(
+        Object.entries(config).map(async ([key, value]) => [key, await this.resolve(value)] as const)
+      );
+      return Object.fromEntries(entries) as T;
+    }
+
+    return config;
+  }
+
+  /**
+   * 仅使用已缓存的密钥替换引用，用于配置热更新时同步恢复凭证
+   */
+  resolveCached<T>(config: T): T {
+    if (typeof config === 'string') {
+      return this.cache.has(config) ? this.cache.get(config) : config;
+    }
+
+    if (Array.isArray(config)) {
+      return config.map(item => this.resolveCached(item)) as unknown as T;
+    }
+
+    if (config && typeof config === 'object') {
+      return Object.fromEntries(
+        Object.entries(config).map(([key, value]) => [key, this.resolveCached(value)])
+      ) as T;
+    }
+
+    return config;
+  }
+
+  /**
+   * 清空缓存，下次解析时重新读取数据源
+   */
+  clearCache(): void {
+    this.cache.clear();
+  }
+
+  private extractField(reference: SecretReference, secret: string | Record<string, any>): any {
+    let object = secret;
+    if (typeof object === 'string') {
+      try {
+        object = JSON.parse(object);
+      } catch {
+        throw new Error(`Secret ${reference.scheme}://${reference.path} is not a key/value secret`);
+      }
+    }
+
+    const value = (object as Record<string, any>)[reference.field!];
+    if (value === undefined) {
+      throw new Error(`Field ${reference.field} not found in secret ${reference.scheme}://${reference.path}`);
+    }
+    return value;
+  }
+}
+
+/**
+ * 创建注册了全部内置数据源的解析器
+ */
+export function createDefaultSecretResolver(): SecretResolver {
+  return new SecretResolver()
+    .register(new EnvSecretProvider())
+    .register(new VaultSecretProvider())
+    .register(new AwsSecretsManagerProvider())
+    .register(new EncryptedFileSecretProvider());
+}
@@ -0,0 +1,28 @@
+/**
+ * 密钥管理类型定义
+ */
+
+/**
+ * 解析后的密钥引用
+ * 格式：scheme://path#field，如 vault://kv/binance-main#apiKey
+ */
+export interface SecretReference {
+  /** 原始引用 */
+  uri: string;
+  /** 数据源协议 */
+  scheme: string;
+  /** 数据源内部路径 */
+  path: string;
+  /** 取出的字段，缺省时返回整个密钥 */
+  field?: string;
+}
+
+/**
+ * 密钥数据源
+ */
+export interface SecretProvider {
+  /** 处理的协议，如 vault、aws-sm */
+  readonly scheme: string;
+  /** 读取密钥，返回字符串或键值对象 */
+  fetch(reference: SecretReference): Promise<string | Record<string, any>>;
+}
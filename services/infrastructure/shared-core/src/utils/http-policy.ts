@@ -0,0 +1,323 @@
+/**
+ * 交易所HTTP请求策略
+ * 统一处理限流、幂等请求的抖动重试、按接口熔断，以及经代理池发送
+ */
+
+import { EventEmitter } from 'events';
+import { BaseMonitor } from '../monitoring/base-monitor';
+import { RateLimitUsage, RequestPriority, WeightedRateLimiter } from './rate-limiter';
+import { PooledProxy, ProxyPool, fetchWithAgent } from './proxy-pool';
+
+export type CircuitState = 'closed' | 'open' | 'half-open';
+
+export interface CircuitBreakerOptions {
+  /** 连续失败多少次后熔断，默认5 */
+  failureThreshold?: number;
+  /** 熔断后多久允许试探请求（毫秒），默认30秒 */
+  resetTimeout?: number;
+}
+
+/**
+ * 熔断期间拒绝请求
+ */
+export class CircuitOpenError extends Error {
+  constructor(readonly endpoint: string, readonly retryAt: number) {
+    super(`Circuit for ${endpoint} is open until ${new Date(retryAt).toISOString()}`);
+    this.name = 'CircuitOpenError';
+  }
+}
+
+/**
+ * 熔断器
+ * 熔断时间结束后只放行一个试探请求，成功则恢复，失败则重新熔断
+ *
+ * 事件：
+ * - stateChange(state, previous)
+ */
+export class CircuitBreaker extends EventEmitter {
+  private readonly failureThreshold: number;
+  private readonly resetTimeout: number;
+  private state: CircuitState = 'closed';
+  private failures = 0;
+  private openedAt = 0;
+  private probing = false;
+
+  constructor(options: CircuitBreakerOptions = {}) {
+    super();
+    this.failureThreshold = options.failureThreshold ?? 5;
+    this.resetTimeout = options.resetTimeout ?? 30000;
+  }
+
+  getState(): CircuitState {
+    if (this.state === 'open' && Date.now() - this.openedAt >= this.resetTimeout) {
+      this.transition('half-open');
+    }
+    return this.state;
+  }
+
+  /**
+   * 申请发送请求，返回false表示应拒绝
+   */
+  tryAcquire(): boolean {
+    const state = this.getState();
+    if (state === 'closed') {
+      return true;
+    }
+    if (state === 'half-open' && !this.probing) {
+      this.probing = true;
+      return true;
+    }
+    return false;
+  }
+
+  /**
+   * 允许再次请求的时间
+   */
+  retryAt(): number {
+    return this.openedAt + this.resetTimeout;
+  }
+
+  recordSuccess(): void {
+    this.failures = 0;
+    this.probing = false;
+    this.transition('closed');
+  }
+
+  /**
+   * 放行的请求未实际发出（如限流排队失败或调用方取消），不计成败，释放试探名额
+   */
+  release(): void {
+    this.probing = false;
+  }
+
+  recordFailure(): void {
+    this.failures++;
+    this.probing = false;
+    if (this.state === 'half-open' || this.failures >= this.failureThreshold) {
+      this.openedAt = Date.now();
+      this.transition('open');
+    }
+  }
+
+  private transition(state: CircuitState): void {
+    if (state !== this.state) {
+      const previous = this.state;
+      this.state = state;
+      this.emit('stateChange', state, previous);
+    }
+  }
+}
+
+export interface HttpRetryOptions {
+  /** 最大重试次数，默认2 */
+  maxRetries?: number;
+  /** 首次重试的延迟上限（毫秒），默认500 */
+  initialDelay?: number;
+  /** 延迟上限（毫秒），默认10秒 */
+  maxDelay?: number;
+}
+
+export interface HttpPolicyOptions {
+  /** 名称，通常为交易所，用作指标标签 */
+  name: string;
+  retry?: HttpRetryOptions;
+  breaker?: CircuitBreakerOptions;
+  /** 限流器，每次尝试前申请权重，并根据响应头校准 */
+  rateLimiter?: WeightedRateLimiter;
+  /** 注册熔断状态与重试次数指标 */
+  monitor?: BaseMonitor;
+  /** 代理池，每次尝试轮换代理；代理设置了独立配额时替代rateLimiter记账 */
+  proxyPool?: ProxyPool;
+  /** 请求权重记入的配额名称，需存在于rateLimiter与代理池rateLimits中，默认weight */
+  weightBucket?: string;
+}
+
+export interface PolicyRequestInit extends RequestInit {
+  /** 熔断与指标使用的接口标识，默认为 方法 + 路径 */
+  endpoint?: string;
+  /** 请求权重，默认1 */
+  weight?: number;
+  /** 排队优先级，默认QUERY */
+  priority?: RequestPriority;
+  /** 是否可以安全重试，默认GET/HEAD/PUT/DELETE可重试 */
+  idempotent?: boolean;
+}
+
+const IDEMPOTENT_METHODS = new Set(['GET', 'HEAD', 'PUT', 'DELETE', 'OPTIONS']);
+
+const CIRCUIT_STATE_VALUES: Record<CircuitState, number> = { closed: 0, 'half-open': 1, open: 2 };
+
+const registeredMonitors = new WeakSet<BaseMonitor>();
+
+/**
+ * HTTP请求策略
+ * 5xx、418与网络错误计入熔断；429与418按Retry-After暂停限流器
+ * 仅幂等请求在5xx、429和网络错误时重试，418表示IP已被封禁，不重试
+ * 经代理发送时，网络错误、407与418计为该代理的失败
+ *
+ * 事件：
+ * - retry(endpoint, attempt, reason)
+ * - circuitStateChange(endpoint, state, previous)
+ */
+export class HttpPolicy extends EventEmitter {
+  private readonly breakers = new Map<string, CircuitBreaker>();
+  private readonly maxRetries: number;
+  private readonly initialDelay: number;
+  private readonly maxDelay: number;
+  private readonly weightBucket: string;
+
+  constructor(private readonly options: HttpPolicyOptions) {
+    super();
+    this.maxRetries = options.retry?.maxRetries ?? 2;
+    this.initialDelay = options.retry?.initialDelay ?? 500;
+    this.maxDelay = options.retry?.maxDelay ?? 10000;
+    this.weightBucket = options.weightBucket ?? 'weight';
+
+    const limitedBuckets = [
+      options.rateLimiter?.getUsage(),
+      options.proxyPool?.getStats()[0].usage
+    ].filter((usage): usage is RateLimitUsage[] => usage !== undefined && usage.length > 0);
+    for (const usage of limitedBuckets) {
+      if (!usage.some(bucket => bucket.name === this.weightBucket)) {
+        throw new Error(`Rate limiter has no ${this.weightBucket} bucket for request weights`);
+      }
+    }
+
+    const monitor = options.monitor;
+    if (monitor && !registeredMonitors.has(monitor)) {
+      registeredMonitors.add(monitor);
+      monitor.registerMetric({
+        name: 'http_circuit_state',
+        description: 'Circuit breaker state per exchange endpoint (0 closed, 1 half-open, 2 open)',
+        type: 'gauge',
+        labels: ['exchange', 'endpoint']
+      });
+      monitor.registerMetric({
+        name: 'http_request_retries_total',
+        description: 'Exchange HTTP requests retried by the request policy',
+        type: 'counter',
+        labels: ['exchange', 'endpoint', 'reason']
+      });
+    }
+  }
+
+  /**
+   * 发送请求
+   * 重试耗尽后返回最后一次响应，由调用方按状态码处理；网络错误则抛出
+   */
+  async fetch(url: string, init: PolicyRequestInit = {}): Promise<Response> {
+    const { endpoint: endpointName, weight = 1, priority = RequestPriority.QUERY, idempotent, ...requestInit } = init;
+    const method = (requestInit.method ?? 'GET').toUpperCase();
+    const endpoint = endpointName ?? `${method} ${new URL(url).pathname}`;
+    const retryable = idempotent ?? IDEMPOTENT_METHODS.has(method);
+    const breaker = this.getBreaker(endpoint);
+
+    for (let attempt = 0; ; attempt++) {
+      if (!breaker.tryAcquire()) {
+        throw new CircuitOpenError(endpoint, breaker.retryAt());
+      }
+
+      let proxy: PooledProxy | undefined;
+      let rateLimiter: WeightedRateLimiter | undefined;
+      try {
+        proxy = this.options.proxyPool?.next();
+        rateLimiter = proxy?.rateLimiter ?? this.options.rateLimiter;
+        await rateLimiter?.acquire({ weight: { [this.weightBucket]: weight }, priority });
+      } catch (error) {
+        // 没有可用代理或限流拒绝时请求未发出
+        breaker.release();
+        throw error;
+      }
+
+      let response: Response;
+      try {
+        if (proxy) {
+          response = await fetchWithAgent(url, requestInit, proxy.agent);
+        } else {
+          response = await (Object.keys(requestInit).length > 0 ? fetch(url, requestInit) : fetch(url));
+        }
+      } catch (error) {
+        // 调用方取消或超时不是接口或代理的故障，也不再重试
+        if (requestInit.signal?.aborted) {
+          breaker.release();
+          throw error;
+        }
+        if (proxy) {
+          this.options.proxyPool!.recordFailure(proxy);
+        }
+        breaker.recordFailure();
+        if (!retryable || attempt >= this.maxRetries) {
+          throw error;
+        }
+        await this.backoff(endpoint, attempt, 'network');
+        continue;
+      }
+
+      rateLimiter?.syncFromHeaders(response.headers);
+
+      if (response.status === 429 || response.status === 418) {
+        const retryAfter = parseInt(response.headers.get('retry-after') ?? '', 10);
+        rateLimiter?.pauseFor((Number.isNaN(retryAfter) ? 60 : retryAfter) * 1000);
+      }
+
+      if (proxy) {
+        if (response.status === 407 || response.status === 418) {
+          this.options.proxyPool!.recordFailure(proxy);
+        } else {
+          this.options.proxyPool!.recordSuccess(proxy);
+        }
+      }
+
+      if (response.status >= 500 || response.status === 418) {
+        breaker.recordFailure();
+      } else {
+        breaker.recordSuccess();
+      }
+
+      const shouldRetry = response.status >= 500 || response.status === 429;
+      if (!shouldRetry || !retryable || attempt >= this.maxRetries || breaker.getState() === 'open') {
+        return response;
+      }
+
+      // 丢弃的响应体不读完时连接无法复用，取消后再重试
+      await response.body?.cancel().catch(() => undefined);
+
+      // 限流时由限流器等待Retry-After，这里只做抖动
+      await this.backoff(endpoint, attempt, String(response.status));
+    }
+  }
+
+  /**
+   * 接口熔断状态
+   */
+  getCircuitStates(): Record<string, CircuitState> {
+    return Object.fromEntries(Array.from(this.breakers.entries()).map(([endpoint, breaker]) => [endpoint, breaker.getState()]));
+  }
+
+  private getBreaker(endpoint: string): CircuitBreaker {
+    let breaker = this.breakers.get(endpoint);
+    if (!breaker) {
+      breaker = new CircuitBreaker(this.options.breaker);
+      breaker.on('stateChange', (state: CircuitState, previous: CircuitState) => {
+        this.options.monitor?.updateMetric('http_circuit_state', CIRCUIT_STATE_VALUES[state], {
+          exchange: this.options.name,
+          endpoint
+        });
+        this.emit('circuitStateChange', endpoint, state, previous);
+      });
+      this.breakers.set(endpoint, breaker);
+    }
+    return breaker;
+  }
+
+  /**
+   * 指数退避加全抖动，避免同时失败的请求同时重试
+   */
+  private async backoff(endpoint: string, attempt: number, reason: string): Promise<void> {
+    this.options.monitor?.incrementCounter('http_request_retries_total', 1, { exchange: this.options.name, endpoint, reason });
+    this.emit('retry', endpoint, attempt + 1, reason);
+
+    const ceiling = Math.min(this.maxDelay, this.initialDelay * Math.pow(2, attempt));
+    await new Promise(resolve => setTimeout(resolve, Math.random() * ceiling));
+  }
+}
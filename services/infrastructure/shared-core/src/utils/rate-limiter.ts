@@ -0,0 +1,396 @@
+/**
+ * 交易所限流器
+ * 按交易所的权重/配额规则记账，支持根据响应头校准、按优先级排队和丢弃请求
+ */
+
+import { EventEmitter } from 'events';
+
+/**
+ * 请求优先级，数值越小优先级越高
+ */
+export enum RequestPriority {
+  CANCEL = 0,
+  ORDER = 1,
+  QUERY = 2
+}
+
+export interface RateLimitBucketConfig {
+  /** 配额名称 */
+  name: string;
+  /** 窗口内允许的总权重 */
+  limit: number;
+  /** 窗口长度（毫秒），窗口按整点对齐 */
+  windowMs: number;
+  /** 返回已用额度的响应头（小写） */
+  usageHeader?: string;
+}
+
+export interface WeightedRateLimiterOptions {
+  /** 配额定义 */
+  buckets: RateLimitBucketConfig[];
+  /** 最大排队请求数，超出时丢弃优先级最低的请求 */
+  maxQueueSize?: number;
+  /** 默认排队超时（毫秒） */
+  defaultTimeout?: number;
+}
+
+export interface RateLimitRequest {
+  /** 各配额消耗的权重，数字表示所有配额使用相同权重 */
+  weight?: number | Record<string, number>;
+  /** 优先级 */
+  priority?: RequestPriority;
+  /** 排队超时（毫秒） */
+  timeout?: number;
+}
+
+export interface RateLimitUsage {
+  /** 配额名称 */
+  name: string;
+  /** 当前窗口已用权重 */
+  used: number;
+  /** 窗口总权重 */
+  limit: number;
+  /** 当前窗口重置时间 */
+  resetAt: number;
+}
+
+/**
+ * 限流拒绝错误
+ */
+export class RateLimitExceededError extends Error {
+  constructor(message: string, public readonly reason: 'shed' | 'timeout' | 'destroyed' | 'oversize') {
+    super(message);
+    this.name = 'RateLimitExceededError';
+  }
+}
+
+interface BucketState {
+  config: RateLimitBucketConfig;
+  windowStart: number;
+  used: number;
+}
+
+interface QueuedRequest {
+  weights: Record<string, number>;
+  priority: RequestPriority;
+  resolve: () => void;
+  reject: (error: Error) => void;
+  timer?: NodeJS.Timeout;
+}
+
+/**
+ * 常用交易所的限流规则
+ */
+export const EXCHANGE_RATE_LIMITS: Record<string, RateLimitBucketConfig[]> = {
+  binance: [
+    { name: 'weight', limit: 6000, windowMs: 60 * 1000, usageHeader: 'x-mbx-used-weight-1m' },
+    { name: 'orders', limit: 100, windowMs: 10 * 1000, usageHeader: 'x-mbx-order-count-10s' },
+    { name: 'orders_daily', limit: 200000, windowMs: 24 * 60 * 60 * 1000, usageHeader: 'x-mbx-order-count-1d' }
+  ]
+};
+
+/**
+ * 权重限流器
+ *
+ * 事件：
+ * - throttled: 请求进入排队
+ * - shed: 请求因队列已满被丢弃
+ * - synced: 配额根据响应头校准
+ */
+export class WeightedRateLimiter extends EventEmitter {
+  private readonly buckets = new Map<string, BucketState>();
+  private readonly options: WeightedRateLimiterOptions;
+  private queue: QueuedRequest[] = [];
+  private blockedUntil = 0;
+  private drainTimer?: NodeJS.Timeout;
+
+  constructor(options: WeightedRateLimiterOptions) {
+    super();
+    this.options = options;
+
+    for (const config of options.buckets) {
+      this.buckets.set(config.name, { config, windowStart: 0, used: 0 });
+    }
+  }
+
+  /**
+   * 获取额度，额度不足时按优先级排队
+   * 权重超过配额上限的请求永远无法放行，直接拒绝，避免堵住队列
+   */
+  acquire(request: RateLimitRequest = {}): Promise<void> {
+    const weights = this.resolveWeights(request.weight);
+    const priority = request.priority ?? RequestPriority.QUERY;
+
+    const oversized = this.findOversizedBucket(weights);
+    if (oversized) {
+      return Promise.reject(new RateLimitExceededError(
+        `Request weight ${weights[oversized.name]} exceeds ${oversized.name} limit ${oversized.limit}`,
+        'oversize'
+      ));
+    }
+
+    if (this.queue.length === 0 && this.tryConsume(weights)) {
+      return Promise.resolve();
+    }
+
+    return new Promise<void>((resolve, reject) => {
+      const queued: QueuedRequest = { weights, priority, resolve, reject };
+
+      if (!this.enqueue(queued)) {
+        reject(new RateLimitExceededError('Rate limit queue is full', 'shed'));
+        this.emit('shed', priority);
+        return;
+      }
+
+      const timeout = request.timeout ?? this.options.defaultTimeout;
+      if (timeout !== undefined) {
+        queued.timer = setTimeout(() => {
+          this.removeFromQueue(queued);
+          reject(new RateLimitExceededError(`Rate limit wait exceeded ${timeout}ms`, 'timeout'));
+        }, timeout);
+      }
+
+      this.emit('throttled', priority, this.queue.length);
+      this.scheduleDrain();
+    });
+  }
+
+  /**
+   * 尝试立即获取额度，不排队
+   */
+  tryAcquire(request: RateLimitRequest = {}): boolean {
+    return this.queue.length === 0 && this.tryConsume(this.resolveWeights(request.weight));
+  }
+
+  /**
+   * 根据响应头校准已用额度
+   */
+  syncFromHeaders(headers: Record<string, string | string[] | undefined> | { get(name: string): string | null }): void {
+    const now = Date.now();
+    let synced = false;
+
+    for (const bucket of this.buckets.values()) {
+      const header = bucket.config.usageHeader;
+      if (!header) {
+        continue;
+      }
+
+      const raw = typeof (headers as any).get === 'function'
+        ? (headers as { get(name: string): string | null }).get(header)
+        : (headers as Record<string, string | string[] | undefined>)[header];
+      const value = parseInt(Array.isArray(raw) ? raw[0] : raw ?? '', 10);
+      if (Number.isNaN(value)) {
+        continue;
+      }
+
+      this.rollWindow(bucket, now);
+      bucket.used = value;
+      synced = true;
+    }
+
+    if (synced) {
+      this.emit('synced', this.getUsage());
+      this.scheduleDrain();
+    }
+  }
+
+  /**
+   * 在指定时间内暂停所有请求（用于处理429/418响应）
+   */
+  pauseFor(durationMs: number): void {
+    this.blockedUntil = Math.max(this.blockedUntil, Date.now() + durationMs);
+    this.scheduleDrain();
+  }
+
+  /**
+   * 获取各配额使用情况
+   */
+  getUsage(): RateLimitUsage[] {
+    const now = Date.now();
+    return Array.from(this.buckets.values()).map(bucket => {
+      this.rollWindow(bucket, now);
+      return {
+        name: bucket.config.name,
+        used: bucket.used,
+        limit: bucket.config.limit,
+        resetAt: bucket.windowStart + bucket.config.windowMs
+      };
+    });
+  }
+
+  /**
+   * 获取排队请求数
+   */
+  getQueueLength(): number {
+    return this.queue.length;
+  }
+
+  /**
+   * 销毁限流器，拒绝所有排队请求
+   */
+  destroy(): void {
+    if (this.drainTimer) {
+      clearTimeout(this.drainTimer);
+      this.drainTimer = undefined;
+    }
+
+    for (const queued of this.queue) {
+      if (queued.timer) {
+        clearTimeout(queued.timer);
+      }
+      queued.reject(new RateLimitExceededError('Rate limiter destroyed', 'destroyed'));
+    }
+    this.queue = [];
+    this.removeAllListeners();
+  }
+
+  /**
+   * 将请求权重展开到各配额
+   */
+  private resolveWeights(weight: RateLimitRequest['weight']): Record<string, number> {
+    if (typeof weight === 'object') {
+      for (const name of Object.keys(weight)) {
+        if (!this.buckets.has(name)) {
+          throw new Error(`Unknown rate limit bucket: ${name}`);
+        }
+      }
+      return weight;
+    }
+
+    const value = weight ?? 1;
+    const weights: Record<string, number> = {};
+    for (const name of this.buckets.keys()) {
+      weights[name] = value;
+    }
+    return weights;
+  }
+
+  /**
+   * 查找请求权重超过上限的配额
+   */
+  private findOversizedBucket(weights: Record<string, number>): RateLimitBucketConfig | undefined {
+    for (const [name, weight] of Object.entries(weights)) {
+      const config = this.buckets.get(name)!.config;
+      if (weight > config.limit) {
+        return config;
+      }
+    }
+    return undefined;
+  }
+
+  /**
+   * 额度充足时扣减
+   */
+  private tryConsume(weights: Record<string, number>): boolean {
+    const now = Date.now();
+    if (now < this.blockedUntil) {
+      return false;
+    }
+
+    for (const [name, weight] of Object.entries(weights)) {
+      const bucket = this.buckets.get(name)!;
+      this.rollWindow(bucket, now);
+      if (bucket.used + weight > bucket.config.limit) {
+        return false;
+      }
+    }
+
+    for (const [name, weight] of Object.entries(weights)) {
+      this.buckets.get(name)!.used += weight;
+    }
+    return true;
+  }
+
+  /**
+   * 窗口到期时重置已用额度
+   */
+  private rollWindow(bucket: BucketState, now: number): void {
+    const windowStart = Math.floor(now / bucket.config.windowMs) * bucket.config.windowMs;
+    if (windowStart !== bucket.windowStart) {
+      bucket.windowStart = windowStart;
+      bucket.used = 0;
+    }
+  }
+
+  /**
+   * 按优先级插入队列，队列已满时丢弃优先级最低的请求
+   */
+  private enqueue(queued: QueuedRequest): boolean {
+    const maxQueueSize = this.options.maxQueueSize ?? Infinity;
+
+    if (this.queue.length >= maxQueueSize) {
+      const lowest = this.queue[this.queue.length - 1];
+      if (!lowest || lowest.priority <= queued.priority) {
+        return false;
+      }
+
+      this.removeFromQueue(lowest);
+      lowest.reject(new RateLimitExceededError('Request shed by higher priority request', 'shed'));
+      this.emit('shed', lowest.priority);
+    }
+
+    const index = this.queue.findIndex(item => item.priority > queued.priority);
+    if (index === -1) {
+      this.queue.push(queued);
+    } else {
+      this.queue.splice(index, 0, queued);
+    }
+    return true;
+  }
+
+  /**
+   * 从队列中移除请求
+   */
+  private removeFromQueue(queued: QueuedRequest): void {
+    const index = this.queue.indexOf(queued);
+    if (index !== -1) {
+      this.queue.splice(index, 1);
+    }
+    if (queued.timer) {
+      clearTimeout(queued.timer);
+    }
+  }
+
+  /**
+   * 依次放行队首请求
+   */
+  private drain(): void {
+    this.drainTimer = undefined;
+
+    while (this.queue.length > 0 && this.tryConsume(this.queue[0].weights)) {
+      const queued = this.queue.shift()!;
+      if (queued.timer) {
+        clearTimeout(queued.timer);
+      }
+      queued.resolve();
+    }
+
+    this.scheduleDrain();
+  }
+
+  /**
+   * 在最近的窗口重置或暂停结束时尝试放行
+   */
+  private scheduleDrain(): void {
+    if (this.queue.length === 0 || this.drainTimer) {
+      return;
+    }
+
+    const now = Date.now();
+    let wakeAt = this.blockedUntil > now ? this.blockedUntil : Infinity;
+    const head = this.queue[0];
+
+    if (wakeAt === Infinity) {
+      for (const [name, weight] of Object.entries(head.weights)) {
+        const bucket = this.buckets.get(name)!;
+        this.rollWindow(bucket, now);
+        if (bucket.used + weight > bucket.config.limit) {
+          wakeAt = Math.min(wakeAt, bucket.windowStart + bucket.config.windowMs);
+        }
+      }
+    }
+
+    const delay = wakeAt === Infinity ? 0 : Math.max(wakeAt - now, 0);
+    this.drainTimer = setTimeout(() => this.drain(), delay);
+  }
+}
@@ -0,0 +1,163 @@
+/**
+ * EventBus单元测试
+ */
+
+import { EventBus } from '../src';
+
+interface Topics {
+  trade: { symbol: string; price: number };
+  ticker: { symbol: string; last: number };
+}
+
+function deferred() {
+  let resolve!: () => void;
+  const promise = new Promise<void>(r => (resolve = r));
+  return { promise, resolve };
+}
+
+describe('EventBus', () => {
+  let bus: EventBus<Topics>;
+
+  beforeEach(() => {
+    bus = new EventBus<Topics>();
+  });
+
+  afterEach(() => {
+    bus.close();
+  });
+
+  it('应该按顺序投递给每个订阅者', async () => {
+    const first: number[] = [];
+    const second: number[] = [];
+    bus.subscribe('trade', event => { first.push(event.price); });
+    bus.subscribe('trade', async event => { second.push(event.price); });
+
+    for (const price of [1, 2, 3]) {
+      await bus.publish('trade', { symbol: 'BTCUSDT', price });
+    }
+    await bus.drain();
+
+    expect(first).toEqual([1, 2, 3]);
+    expect(second).toEqual([1, 2, 3]);
+  });
+
+  it('drop-oldest策略应该在队列满时丢弃最旧事件且不影响其他订阅者', async () => {
+    const gate = deferred();
+    const slow: number[] = [];
+    const fast: number[] = [];
+    bus.subscribe('trade', async event => { await gate.promise; slow.push(event.price); }, { name: 'slow', capacity: 2 });
+    bus.subscribe('trade', event => { fast.push(event.price); }, { name: 'fast' });
+
+    for (const price of [1, 2, 3, 4, 5]) {
+      await bus.publish('trade', { symbol: 'BTCUSDT', price });
+    }
+    expect(fast).toEqual([1, 2, 3, 4, 5]);
+    expect(bus.getStats('trade').find(stats => stats.name === 'slow')).toMatchObject({ depth: 2, dropped: 2 });
+
+    gate.resolve();
+    await bus.drain();
+    // 1 已在处理中，2、3 被丢弃
+    expect(slow).toEqual([1, 4, 5]);
+  });
+
+  it('coalesce策略应该只保留同一键的最新事件', async () => {
+    const gate = deferred();
+    const received: Array<Topics['ticker']> = [];
+    bus.subscribe('ticker', async event => { await gate.promise; received.push(event); }, {
+      policy: 'coalesce',
+      coalesceKey: event => event.symbol
+    });
+
+    await bus.publish('ticker', { symbol: 'BTCUSDT', last: 1 });
+    await bus.publish('ticker', { symbol: 'BTCUSDT', last: 2 });
+    await bus.publish('ticker', { symbol: 'ETHUSDT', last: 10 });
+    await bus.publish('ticker', { symbol: 'BTCUSDT', last: 3 });
+    gate.resolve();
+    await bus.drain();
+
+    expect(received).toEqual([
+      { symbol: 'BTCUSDT', last: 1 },
+      { symbol: 'BTCUSDT', last: 3 },
+      { symbol: 'ETHUSDT', last: 10 }
+    ]);
+    expect(bus.getStats('ticker')[0].coalesced).toBe(1);
+  });
+
+  it('block策略应该让发布方等待队列腾出空间', async () => {
+    const gate = deferred();
+    bus.subscribe('trade', async () => { await gate.promise; }, { policy: 'block', capacity: 1 });
+
+    await bus.publish('trade', { symbol: 'BTCUSDT', price: 1 });
+    await bus.publish('trade', { symbol: 'BTCUSDT', price: 2 });
+    let published = false;
+    const third = bus.publish('trade', { symbol: 'BTCUSDT', price: 3 }).then(() => { published = true; });
+
+    await new Promise(resolve => setTimeout(resolve, 0));
+    expect(published).toBe(false);
+
+    gate.resolve();
+    await third;
+    expect(published).toBe(true);
+    await bus.drain();
+    expect(bus.getStats('trade')[0]).toMatchObject({ delivered: 3, dropped: 0 });
+  });
+
+  it('处理失败时应该发出错误并继续投递', async () => {
+    const errors: string[] = [];
+    bus.on('error', (error, stats) => errors.push(`${stats.name}: ${error.message}`));
+    bus.subscribe('trade', event => {
+      if (event.price === 1) {
+        throw new Error('boom');
+      }
+    }, { name: 'recorder' });
+
+    await bus.publish('trade', { symbol: 'BTCUSDT', price: 1 });
+    await bus.publish('trade', { symbol: 'BTCUSDT', price: 2 });
+    await bus.drain();
+
+    expect(errors).toEqual(['recorder: boom']);
+    expect(bus.getStats('trade')[0]).toMatchObject({ delivered: 1, errors: 1 });
+  });
+
+  it('取消订阅后不应该再收到事件', async () => {
+    const received: number[] = [];
+    const subscription = bus.subscribe('trade', event => { received.push(event.price); });
+
+    await bus.publish('trade', { symbol: 'BTCUSDT', price: 1 });
+    subscription.unsubscribe();
+    await bus.publish('trade', { symbol: 'BTCUSDT', price: 2 });
+
+    expect(received).toEqual([1]);
+    expect(bus.getStats()).toEqual([]);
+  });
+
+  it('积压消化后队列深度指标应该回落', async () => {
+    const monitor = { registerMetric: jest.fn(), updateMetric: jest.fn(), incrementCounter: jest.fn() };
+    const monitored = new EventBus<Topics>({ monitor: monitor as any });
+    const gate = deferred();
+    monitored.subscribe('trade', async () => { await gate.promise; }, { name: 'slow' });
+
+    for (const price of [1, 2, 3]) {
+      await monitored.publish('trade', { symbol: 'BTCUSDT', price });
+    }
+    expect(monitor.updateMetric).toHaveBeenLastCalledWith('eventbus_queue_depth', 2, { topic: 'trade', subscriber: 'slow' });
+
+    gate.resolve();
+    await monitored.drain();
+    expect(monitor.updateMetric).toHaveBeenLastCalledWith('eventbus_queue_depth', 0, { topic: 'trade', subscriber: 'slow' });
+    monitored.close();
+  });
+
+  it('共用监控的多个事件总线只应该注册一次指标', () => {
+    const monitor = { registerMetric: jest.fn(), updateMetric: jest.fn(), incrementCounter: jest.fn() };
+
+    new EventBus<Topics>({ monitor: monitor as any }).close();
+    new EventBus<Topics>({ monitor: monitor as any }).close();
+
+    expect(monitor.registerMetric).toHaveBeenCalledTimes(2);
+  });
+
+  it('coalesce策略缺少合并键时应该报错', () => {
+    expect(() => bus.subscribe('ticker', () => undefined, { policy: 'coalesce' })).toThrow('without coalesceKey');
+  });
+});
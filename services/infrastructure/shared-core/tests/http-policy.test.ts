@@ -0,0 +1,159 @@
+/**
+ * HttpPolicy与CircuitBreaker单元测试
+ */
+
+import { CircuitBreaker, CircuitOpenError, HttpPolicy, WeightedRateLimiter, globalCache } from '../src';
+
+describe('CircuitBreaker', () => {
+  it('连续失败达到阈值后应该熔断', () => {
+    const breaker = new CircuitBreaker({ failureThreshold: 2, resetTimeout: 1000 });
+
+    breaker.recordFailure();
+    expect(breaker.getState()).toBe('closed');
+    breaker.recordFailure();
+    expect(breaker.getState()).toBe('open');
+    expect(breaker.tryAcquire()).toBe(false);
+  });
+
+  it('熔断超时后应该只放行一个试探请求', async () => {
+    const breaker = new CircuitBreaker({ failureThreshold: 1, resetTimeout: 20 });
+    const states: string[] = [];
+    breaker.on('stateChange', state => states.push(state));
+
+    breaker.recordFailure();
+    await new Promise(resolve => setTimeout(resolve, 30));
+
+    expect(breaker.tryAcquire()).toBe(true);
+    expect(breaker.tryAcquire()).toBe(false);
+    breaker.recordSuccess();
+
+    expect(breaker.tryAcquire()).toBe(true);
+    expect(states).toEqual(['open', 'half-open', 'closed']);
+  });
+
+  it('试探请求失败应该重新熔断', async () => {
+    const breaker = new CircuitBreaker({ failureThreshold: 3, resetTimeout: 20 });
+    breaker.recordFailure();
+    breaker.recordFailure();
+    breaker.recordFailure();
+    await new Promise(resolve => setTimeout(resolve, 30));
+
+    expect(breaker.tryAcquire()).toBe(true);
+    breaker.recordFailure();
+    expect(breaker.getState()).toBe('open');
+  });
+});
+
+describe('HttpPolicy', () => {
+  const originalFetch = global.fetch;
+  const respond = (status: number, headers: Record<string, string> = {}) => ({
+    ok: status >= 200 && status < 300,
+    status,
+    headers: new Headers(headers)
+  });
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  it('幂等请求遇到5xx应该重试并返回成功响应', async () => {
+    global.fetch = jest.fn()
+      .mockResolvedValueOnce(respond(503))
+      .mockResolvedValueOnce(respond(200)) as any;
+    const policy = new HttpPolicy({ name: 'test', retry: { initialDelay: 1 } });
+    const retries: string[] = [];
+    policy.on('retry', (_endpoint, _attempt, reason) => retries.push(reason));
+
+    const response = await policy.fetch('https://api.example.com/v1/time');
+
+    expect(response.status).toBe(200);
+    expect(global.fetch).toHaveBeenCalledTimes(2);
+    expect(retries).toEqual(['503']);
+  });
+
+  it('重试前应该取消被丢弃响应的响应体', async () => {
+    const discarded = new Response('{"code":-1003}', { status: 503 });
+    global.fetch = jest.fn()
+      .mockResolvedValueOnce(discarded)
+      .mockResolvedValueOnce(respond(200)) as any;
+    const policy = new HttpPolicy({ name: 'test', retry: { initialDelay: 1 } });
+
+    const response = await policy.fetch('https://api.example.com/v1/time');
+
+    expect(response.status).toBe(200);
+    expect(discarded.bodyUsed).toBe(true);
+    await expect(discarded.text()).rejects.toThrow();
+  });
+
+  it('应该重试网络错误，重试耗尽后抛出', async () => {
+    global.fetch = jest.fn().mockRejectedValue(new Error('ECONNRESET')) as any;
+    const policy = new HttpPolicy({ name: 'test', retry: { maxRetries: 2, initialDelay: 1 } });
+
+    await expect(policy.fetch('https://api.example.com/v1/time')).rejects.toThrow('ECONNRESET');
+    expect(global.fetch).toHaveBeenCalledTimes(3);
+  });
+
+  it('非幂等请求与418不应该重试', async () => {
+    global.fetch = jest.fn()
+      .mockResolvedValueOnce(respond(503))
+      .mockResolvedValueOnce(respond(418, { 'retry-after': '0' })) as any;
+    const policy = new HttpPolicy({ name: 'test', retry: { initialDelay: 1 } });
+
+    expect((await policy.fetch('https://api.example.com/v1/order', { method: 'POST' })).status).toBe(503);
+    expect((await policy.fetch('https://api.example.com/v1/time')).status).toBe(418);
+    expect(global.fetch).toHaveBeenCalledTimes(2);
+  });
+
+  it('其他4xx不应该计入熔断', async () => {
+    global.fetch = jest.fn().mockResolvedValue(respond(400)) as any;
+    const policy = new HttpPolicy({ name: 'test', breaker: { failureThreshold: 1 } });
+
+    await policy.fetch('https://api.example.com/v1/order?id=1');
+    await policy.fetch('https://api.example.com/v1/order?id=2');
+
+    expect(policy.getCircuitStates()).toEqual({ 'GET /v1/order': 'closed' });
+  });
+
+  it('熔断后应该按接口拒绝请求', async () => {
+    global.fetch = jest.fn().mockResolvedValue(respond(500)) as any;
+    const policy = new HttpPolicy({ name: 'test', retry: { maxRetries: 0 }, breaker: { failureThreshold: 2 } });
+
+    await policy.fetch('https://api.example.com/v1/depth');
+    await policy.fetch('https://api.example.com/v1/depth');
+
+    await expect(policy.fetch('https://api.example.com/v1/depth')).rejects.toBeInstanceOf(CircuitOpenError);
+    expect((await policy.fetch('https://api.example.com/v1/time')).status).toBe(500);
+    expect(global.fetch).toHaveBeenCalledTimes(3);
+  });
+
+  it('试探请求在限流排队时被拒绝应该释放试探名额', async () => {
+    global.fetch = jest.fn().mockResolvedValueOnce(respond(500)).mockResolvedValue(respond(200)) as any;
+    const rateLimiter = new WeightedRateLimiter({ buckets: [{ name: 'weight', limit: 10, windowMs: 60000 }] });
+    const policy = new HttpPolicy({ name: 'test', rateLimiter, retry: { maxRetries: 0 }, breaker: { failureThreshold: 1, resetTimeout: 20 } });
+
+    await policy.fetch('https://api.example.com/v1/depth');
+    await new Promise(resolve => setTimeout(resolve, 30));
+
+    await expect(policy.fetch('https://api.example.com/v1/depth', { weight: 11 })).rejects.toMatchObject({ reason: 'oversize' });
+    expect((await policy.fetch('https://api.example.com/v1/depth')).status).toBe(200);
+    expect(policy.getCircuitStates()).toEqual({ 'GET /v1/depth': 'closed' });
+    rateLimiter.destroy();
+  });
+
+  it('调用方取消的请求不应该重试也不计入熔断', async () => {
+    const controller = new AbortController();
+    global.fetch = jest.fn(async () => {
+      controller.abort();
+      throw new Error('The operation was aborted');
+    }) as any;
+    const policy = new HttpPolicy({ name: 'test', retry: { maxRetries: 2, initialDelay: 1 }, breaker: { failureThreshold: 1 } });
+
+    await expect(policy.fetch('https://api.example.com/v1/time', { signal: controller.signal })).rejects.toThrow('aborted');
+    expect(global.fetch).toHaveBeenCalledTimes(1);
+    expect(policy.getCircuitStates()).toEqual({ 'GET /v1/time': 'closed' });
+  });
+});
@@ -0,0 +1,103 @@
+/**
+ * BaseMonitor结构化日志单元测试
+ */
+
+import { BaseMonitor, LogEntry, globalCache } from '../src';
+
+describe('BaseMonitor 结构化日志', () => {
+  let monitor: BaseMonitor;
+  let entries: LogEntry[];
+
+  beforeEach(() => {
+    monitor = new BaseMonitor({
+      metrics: { enabled: false, endpoint: 'localhost', port: 9090, path: '/metrics' },
+      healthCheck: { enabled: false, endpoint: 'localhost', port: 8080, path: '/health', interval: 0 },
+      logging: {
+        level: 'info',
+        componentLevels: { 'binance.websocket': 'debug' },
+        format: 'json',
+        output: 'console'
+      }
+    });
+    entries = [];
+    monitor.on('log', (entry: LogEntry) => entries.push(entry));
+  });
+
+  afterEach(() => {
+    monitor.destroy();
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  describe('关联上下文', () => {
+    it('应该在每条日志中携带绑定的关联字段', () => {
+      const logger = monitor.child('orders', { exchange: 'binance' }).child({ strategyId: 'grid-1', clientOrderId: 'abc123' });
+
+      logger.info('Order submitted', { symbol: 'BTCUSDT' });
+
+      expect(entries).toHaveLength(1);
+      expect(entries[0]).toMatchObject({
+        component: 'orders',
+        exchange: 'binance',
+        strategyId: 'grid-1',
+        clientOrderId: 'abc123',
+        context: { exchange: 'binance', strategyId: 'grid-1', clientOrderId: 'abc123', symbol: 'BTCUSDT' }
+      });
+    });
+
+    it('应该允许单条日志覆盖绑定字段', () => {
+      const logger = monitor.child('orders', { clientOrderId: 'first' });
+
+      logger.warn('Order replaced', { clientOrderId: 'second' });
+
+      expect(entries[0].clientOrderId).toBe('second');
+    });
+
+    it('直接调用 log 时组件应为 monitor', () => {
+      monitor.log('info', 'Plain message');
+
+      expect(entries[0].component).toBe('monitor');
+    });
+  });
+
+  describe('日志级别', () => {
+    it('应该按全局级别过滤日志', () => {
+      monitor.log('debug', 'Hidden');
+      monitor.log('info', 'Shown');
+
+      expect(entries.map(entry => entry.message)).toEqual(['Shown']);
+    });
+
+    it('子组件应该继承上级组件的级别', () => {
+      monitor.setLogLevel('trace', 'binance');
+
+      monitor.child('binance.rest').trace('Rest trace');
+      monitor.child('binance.websocket').trace('WebSocket trace');
+      monitor.child('okx').debug('Okx debug');
+
+      // binance.websocket 自身配置为 debug，优先于上级 binance
+      expect(entries.map(entry => entry.message)).toEqual(['Rest trace']);
+    });
+
+    it('应该支持运行时调整与重置组件级别', () => {
+      const logger = monitor.child('binance.websocket');
+      expect(logger.isLevelEnabled('debug')).toBe(true);
+
+      monitor.setLogLevel('error', 'binance.websocket');
+      expect(logger.isLevelEnabled('warn')).toBe(false);
+
+      monitor.resetLogLevel('binance.websocket');
+      expect(monitor.getLogLevel('binance.websocket')).toBe('info');
+    });
+
+    it('应该整体替换级别配置', () => {
+      monitor.configureLogLevels('silly', { pubsub: 'error' });
+
+      expect(monitor.getLogLevel()).toBe('trace');
+      expect(monitor.getLogLevel('binance.websocket')).toBe('trace');
+      expect(monitor.isLevelEnabled('warn', 'pubsub')).toBe(false);
+    });
+  });
+});
@@ -0,0 +1,147 @@
+/**
+ * WeightedRateLimiter单元测试
+ */
+
+import { WeightedRateLimiter, RequestPriority, RateLimitExceededError, globalCache } from '../src';
+
+describe('WeightedRateLimiter', () => {
+  let limiter: WeightedRateLimiter;
+
+  beforeEach(() => {
+    jest.useFakeTimers();
+    jest.setSystemTime(new Date('2024-01-01T00:00:00.000Z'));
+    limiter = new WeightedRateLimiter({
+      buckets: [
+        { name: 'weight', limit: 10, windowMs: 60000, usageHeader: 'x-mbx-used-weight-1m' },
+        { name: 'orders', limit: 2, windowMs: 10000, usageHeader: 'x-mbx-order-count-10s' }
+      ],
+      maxQueueSize: 2
+    });
+  });
+
+  afterEach(() => {
+    limiter.destroy();
+    jest.useRealTimers();
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  describe('权重记账', () => {
+    it('应该按配额扣减权重', async () => {
+      await limiter.acquire({ weight: { weight: 4 } });
+      await limiter.acquire({ weight: { weight: 1, orders: 1 } });
+
+      const usage = limiter.getUsage();
+      expect(usage.find(bucket => bucket.name === 'weight')?.used).toBe(5);
+      expect(usage.find(bucket => bucket.name === 'orders')?.used).toBe(1);
+    });
+
+    it('任一配额不足时不应放行', () => {
+      expect(limiter.tryAcquire({ weight: { weight: 1, orders: 2 } })).toBe(true);
+      expect(limiter.tryAcquire({ weight: { weight: 1, orders: 1 } })).toBe(false);
+      expect(limiter.tryAcquire({ weight: { weight: 1 } })).toBe(true);
+    });
+
+    it('未知配额应该抛出错误', () => {
+      expect(() => limiter.tryAcquire({ weight: { unknown: 1 } })).toThrow('Unknown rate limit bucket');
+    });
+
+    it('窗口重置后应该放行排队请求', async () => {
+      await limiter.acquire({ weight: { weight: 10 } });
+      const pending = jest.fn();
+      limiter.acquire({ weight: { weight: 5 } }).then(pending);
+
+      await Promise.resolve();
+      expect(pending).not.toHaveBeenCalled();
+
+      await jest.advanceTimersByTimeAsync(60000);
+      expect(pending).toHaveBeenCalled();
+    });
+
+    it('权重超过配额上限的请求应该立即拒绝且不阻塞队列', async () => {
+      const error = await limiter.acquire({ weight: { weight: 11 } }).catch(e => e);
+
+      expect(error).toBeInstanceOf(RateLimitExceededError);
+      expect(error.reason).toBe('oversize');
+      expect(limiter.getQueueLength()).toBe(0);
+      expect(limiter.tryAcquire({ weight: { orders: 3 } })).toBe(false);
+      await limiter.acquire({ weight: { weight: 1 } });
+    });
+  });
+
+  describe('响应头校准', () => {
+    it('应该以响应头中的已用额度为准', () => {
+      limiter.syncFromHeaders({ 'x-mbx-used-weight-1m': '9' });
+
+      expect(limiter.getUsage().find(bucket => bucket.name === 'weight')?.used).toBe(9);
+      expect(limiter.tryAcquire({ weight: { weight: 2 } })).toBe(false);
+    });
+
+    it('应该支持带get方法的响应头对象', () => {
+      const headers = new Map([['x-mbx-order-count-10s', '2']]);
+      limiter.syncFromHeaders({ get: (name: string) => headers.get(name) ?? null });
+
+      expect(limiter.tryAcquire({ weight: { orders: 1 } })).toBe(false);
+    });
+
+    it('暂停期间应该拒绝立即获取', async () => {
+      limiter.pauseFor(5000);
+      expect(limiter.tryAcquire()).toBe(false);
+
+      await jest.advanceTimersByTimeAsync(5000);
+      expect(limiter.tryAcquire()).toBe(true);
+    });
+  });
+
+  describe('优先级排队', () => {
+    it('应该优先放行高优先级请求', async () => {
+      await limiter.acquire({ weight: { weight: 10 } });
+      const order: string[] = [];
+
+      const query = limiter.acquire({ weight: { weight: 1 }, priority: RequestPriority.QUERY }).then(() => order.push('query'));
+      const cancel = limiter.acquire({ weight: { weight: 1 }, priority: RequestPriority.CANCEL }).then(() => order.push('cancel'));
+
+      await jest.advanceTimersByTimeAsync(60000);
+      await Promise.all([query, cancel]);
+
+      expect(order).toEqual(['cancel', 'query']);
+    });
+
+    it('队列已满时应该丢弃最低优先级请求', async () => {
+      await limiter.acquire({ weight: { weight: 10 } });
+
+      const query = limiter.acquire({ weight: { weight: 1 }, priority: RequestPriority.QUERY });
+      const order = limiter.acquire({ weight: { weight: 1 }, priority: RequestPriority.ORDER });
+      const cancel = limiter.acquire({ weight: { weight: 1 }, priority: RequestPriority.CANCEL });
+
+      await expect(query).rejects.toBeInstanceOf(RateLimitExceededError);
+      expect(limiter.getQueueLength()).toBe(2);
+
+      await jest.advanceTimersByTimeAsync(60000);
+      await expect(order).resolves.toBeUndefined();
+      await expect(cancel).resolves.toBeUndefined();
+    });
+
+    it('队列已满且新请求优先级不高于队尾时应该拒绝新请求', async () => {
+      await limiter.acquire({ weight: { weight: 10 } });
+
+      limiter.acquire({ weight: { weight: 1 }, priority: RequestPriority.ORDER }).catch(() => undefined);
+      limiter.acquire({ weight: { weight: 1 }, priority: RequestPriority.ORDER }).catch(() => undefined);
+
+      await expect(limiter.acquire({ weight: { weight: 1 }, priority: RequestPriority.QUERY }))
+        .rejects.toMatchObject({ reason: 'shed' });
+    });
+
+    it('排队超时应该拒绝请求', async () => {
+      await limiter.acquire({ weight: { weight: 10 } });
+
+      const pending = limiter.acquire({ weight: { weight: 1 }, timeout: 1000 });
+      jest.advanceTimersByTime(1000);
+
+      await expect(pending).rejects.toMatchObject({ reason: 'timeout' });
+      expect(limiter.getQueueLength()).toBe(0);
+    });
+  });
+});
@@ -0,0 +1,139 @@
+/**
+ * 密钥管理单元测试
+ */
+
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import {
+  SecretResolver,
+  SecretProvider,
+  EnvSecretProvider,
+  VaultSecretProvider,
+  EncryptedFileSecretProvider,
+  encryptSecrets,
+  decryptSecrets,
+  signAwsRequest
+} from '../src';
+
+describe('SecretResolver', () => {
+  let resolver: SecretResolver;
+  let fetchSecret: jest.Mock;
+
+  beforeEach(() => {
+    fetchSecret = jest.fn().mockResolvedValue({ apiKey: 'key-1', apiSecret: 'secret-1' });
+    const provider: SecretProvider = { scheme: 'vault', fetch: fetchSecret };
+    resolver = new SecretResolver().register(provider).register(new EnvSecretProvider());
+  });
+
+  afterEach(() => {
+    delete process.env.PIXIU_TEST_SECRET;
+  });
+
+  it('应该替换配置中的密钥引用并保留其他字符串', async () => {
+    process.env.PIXIU_TEST_SECRET = 'from-env';
+
+    const resolved = await resolver.resolve({
+      endpoints: { ws: 'wss://stream.binance.com:9443/ws' },
+      auth: { apiKey: 'vault://kv/binance-main#apiKey', apiSecret: 'env://PIXIU_TEST_SECRET' },
+      symbols: ['BTCUSDT']
+    });
+
+    expect(resolved).toEqual({
+      endpoints: { ws: 'wss://stream.binance.com:9443/ws' },
+      auth: { apiKey: 'key-1', apiSecret: 'from-env' },
+      symbols: ['BTCUSDT']
+    });
+    expect(fetchSecret).toHaveBeenCalledWith({ uri: 'vault://kv/binance-main#apiKey', scheme: 'vault', path: 'kv/binance-main', field: 'apiKey' });
+  });
+
+  it('引用整个密钥时应该替换为键值对象', async () => {
+    const resolved = await resolver.resolve({ auth: 'vault://kv/binance-main' });
+
+    expect(resolved.auth).toEqual({ apiKey: 'key-1', apiSecret: 'secret-1' });
+  });
+
+  it('应该缓存已解析的引用并支持同步替换', async () => {
+    await resolver.resolve({ apiKey: 'vault://kv/binance-main#apiKey' });
+    await resolver.resolveValue('vault://kv/binance-main#apiKey');
+
+    expect(fetchSecret).toHaveBeenCalledTimes(1);
+    expect(resolver.resolveCached({ apiKey: 'vault://kv/binance-main#apiKey', other: 'vault://kv/other' }))
+      .toEqual({ apiKey: 'key-1', other: 'vault://kv/other' });
+  });
+
+  it('字段或环境变量不存在时应该报错', async () => {
+    await expect(resolver.resolveValue('vault://kv/binance-main#passphrase')).rejects.toThrow('Field passphrase not found');
+    await expect(resolver.resolveValue('env://PIXIU_TEST_SECRET')).rejects.toThrow('PIXIU_TEST_SECRET is not set');
+  });
+});
+
+describe('VaultSecretProvider', () => {
+  const originalFetch = global.fetch;
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  it('应该读取KV v2引擎', async () => {
+    global.fetch = jest.fn().mockResolvedValue({
+      ok: true,
+      status: 200,
+      json: async () => ({ data: { data: { apiKey: 'key-1' }, metadata: { version: 3 } } })
+    }) as any;
+
+    const provider = new VaultSecretProvider({ address: 'https://vault.internal:8200/', token: 's.token' });
+    const secret = await provider.fetch({ uri: 'vault://kv/exchanges/binance', scheme: 'vault', path: 'kv/exchanges/binance' });
+
+    expect(secret).toEqual({ apiKey: 'key-1' });
+    expect(global.fetch).toHaveBeenCalledWith('https://vault.internal:8200/v1/kv/data/exchanges/binance', {
+      headers: { 'X-Vault-Token': 's.token' }
+    });
+  });
+});
+
+describe('加密文件', () => {
+  it('应该使用口令加解密', () => {
+    const content = encryptSecrets({ binance: { apiKey: 'key-1' } }, 'correct horse');
+
+    expect(content).not.toContain('key-1');
+    expect(decryptSecrets(content, 'correct horse')).toEqual({ binance: { apiKey: 'key-1' } });
+    expect(() => decryptSecrets(content, 'wrong')).toThrow('wrong passphrase');
+  });
+
+  it('应该通过encfile引用读取字段', async () => {
+    const file = path.join(os.tmpdir(), `pixiu-secrets-${process.pid}.enc`);
+    fs.writeFileSync(file, encryptSecrets({ binance: { apiKey: 'key-1' } }, 'correct horse'));
+
+    try {
+      const resolver = new SecretResolver().register(new EncryptedFileSecretProvider({ passphrase: 'correct horse' }));
+      expect(await resolver.resolve({ auth: `encfile://${file}#binance` })).toEqual({ auth: { apiKey: 'key-1' } });
+    } finally {
+      fs.unlinkSync(file);
+    }
+  });
+});
+
+describe('signAwsRequest', () => {
+  it('应该与AWS官方示例签名一致', () => {
+    const headers = signAwsRequest({
+      method: 'GET',
+      host: 'iam.amazonaws.com',
+      path: '/',
+      query: 'Action=ListUsers&Version=2010-05-08',
+      headers: { 'Content-Type': 'application/x-www-form-urlencoded; charset=utf-8' },
+      body: '',
+      region: 'us-east-1',
+      service: 'iam',
+      credentials: { accessKeyId: 'AKIDEXAMPLE', secretAccessKey: 'wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY' },
+      now: new Date('2015-08-30T12:36:00Z')
+    });
+
+    expect(headers['x-amz-date']).toBe('20150830T123600Z');
+    expect(headers.Authorization).toBe(
+      'AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, ' +
+      'SignedHeaders=content-type;host;x-amz-date, ' +
+      'Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7'
+    );
+  });
+});
@@ -0,0 +1,127 @@
+/**
+ * 分布式追踪单元测试
+ */
+
+import {
+  Tracer,
+  OtlpHttpExporter,
+  FinishedSpan,
+  SpanExporter,
+  parseTraceparent,
+  formatTraceparent
+} from '../src';
+
+describe('traceparent', () => {
+  it('应该解析并格式化W3C traceparent', () => {
+    const header = '00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01';
+    const context = parseTraceparent(header);
+
+    expect(context).toEqual({ traceId: '4bf92f3577b34da6a3ce929d0e0e4736', spanId: '00f067aa0ba902b7', traceFlags: 1 });
+    expect(formatTraceparent(context!)).toBe(header);
+  });
+
+  it('应该拒绝格式错误或全零的ID', () => {
+    expect(parseTraceparent('00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7')).toBeUndefined();
+    expect(parseTraceparent('00-00000000000000000000000000000000-00f067aa0ba902b7-01')).toBeUndefined();
+    expect(parseTraceparent(undefined)).toBeUndefined();
+  });
+});
+
+describe('Tracer', () => {
+  let exported: FinishedSpan[];
+  let exporter: SpanExporter;
+
+  beforeEach(() => {
+    exported = [];
+    exporter = { export: spans => exported.push(...spans), shutdown: async () => undefined };
+  });
+
+  it('子Span应该沿用父级的traceId与采样决定', () => {
+    const tracer = new Tracer({ enabled: true, serviceName: 'test', sampleRatio: 0 }, exporter);
+
+    const child = tracer.startSpan('publish', { parent: '00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01' });
+    child.end();
+    tracer.startSpan('unsampled').end();
+
+    expect(exported).toHaveLength(1);
+    expect(exported[0]).toMatchObject({
+      name: 'publish',
+      parentSpanId: '00f067aa0ba902b7',
+      context: { traceId: '4bf92f3577b34da6a3ce929d0e0e4736', traceFlags: 1 }
+    });
+  });
+
+  it('未启用时不应该导出', () => {
+    const tracer = new Tracer({ enabled: false, serviceName: 'test' }, exporter);
+    const span = tracer.startSpan('noop');
+
+    expect(span.isRecording()).toBe(false);
+    span.end();
+    expect(exported).toHaveLength(0);
+    expect(tracer.startSpan('other', { parent: '00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01' })).toBe(span);
+  });
+
+  it('withSpan应该记录异常并只结束一次', async () => {
+    const tracer = new Tracer({ enabled: true, serviceName: 'test' }, exporter);
+
+    await expect(tracer.withSpan('fail', {}, async span => {
+      span.setAttribute('exchange', 'binance');
+      throw new Error('boom');
+    })).rejects.toThrow('boom');
+
+    expect(exported).toHaveLength(1);
+    expect(exported[0].status).toEqual({ code: 'error', message: 'boom' });
+    expect(exported[0].events[0]).toMatchObject({ name: 'exception', attributes: { 'exception.message': 'boom' } });
+  });
+});
+
+describe('OtlpHttpExporter', () => {
+  const originalFetch = global.fetch;
+
+  afterEach(() => {
+    global.fetch = originalFetch;
+  });
+
+  it('应该按OTLP JSON格式发送到/v1/traces', async () => {
+    global.fetch = jest.fn().mockResolvedValue({ ok: true, status: 200 }) as any;
+    const exporter = new OtlpHttpExporter('exchange-collector', {
+      endpoint: 'http://localhost:4318/',
+      headers: { Authorization: 'Bearer token' }
+    });
+    const tracer = new Tracer({ enabled: true, serviceName: 'exchange-collector' }, exporter);
+
+    tracer.startSpan('market_data.publish', { kind: 'producer', startTime: 1700000000000, attributes: { 'market.symbol': 'BTCUSDT', depth: 20 } })
+      .end(1700000000002.5);
+    await exporter.shutdown();
+
+    const [url, init] = (global.fetch as jest.Mock).mock.calls[0];
+    const body = JSON.parse(init.body);
+    const span = body.resourceSpans[0].scopeSpans[0].spans[0];
+
+    expect(url).toBe('http://localhost:4318/v1/traces');
+    expect(init.headers).toEqual({ 'Content-Type': 'application/json', Authorization: 'Bearer token' });
+    expect(body.resourceSpans[0].resource.attributes).toEqual([{ key: 'service.name', value: { stringValue: 'exchange-collector' } }]);
+    expect(span).toMatchObject({
+      name: 'market_data.publish',
+      kind: 4,
+      startTimeUnixNano: '1700000000000000000',
+      endTimeUnixNano: '1700000000002500000',
+      attributes: [
+        { key: 'market.symbol', value: { stringValue: 'BTCUSDT' } },
+        { key: 'depth', value: { intValue: '20' } }
+      ]
+    });
+  });
+
+  it('导出失败时应该计入丢弃数', async () => {
+    global.fetch = jest.fn().mockRejectedValue(new Error('ECONNREFUSED')) as any;
+    const exporter = new OtlpHttpExporter('test', { endpoint: 'http://localhost:4318', maxQueueSize: 1 });
+    const tracer = new Tracer({ enabled: true, serviceName: 'test' }, exporter);
+
+    tracer.startSpan('a').end();
+    tracer.startSpan('b').end();
+    await exporter.shutdown();
+
+    expect(exporter.getDroppedSpans()).toBe(2);
+  });
+});
@@ -0,0 +1,165 @@
+/**
+ * UnifiedConfigManager热更新单元测试
+ */
+
+import { mkdtempSync, rmSync, writeFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { UnifiedConfigManager, globalCache } from '../src';
+
+describe('UnifiedConfigManager', () => {
+  let configManager: UnifiedConfigManager;
+  let configDir: string;
+  let configPath: string;
+
+  const writeConfig = (content: string) => writeFileSync(configPath, content, 'utf-8');
+
+  beforeEach(() => {
+    configDir = mkdtempSync(join(tmpdir(), 'pixiu-config-'));
+    configPath = join(configDir, 'test.yaml');
+    writeConfig('monitoring:\n  metricsInterval: 10000\n');
+
+    configManager = new UnifiedConfigManager();
+    configManager.loadConfiguration('test', [configPath]);
+  });
+
+  afterEach(() => {
+    configManager.destroy();
+    rmSync(configDir, { recursive: true, force: true });
+  });
+
+  afterAll(() => {
+    globalCache.destroy();
+  });
+
+  describe('配置重新加载', () => {
+    it('应该应用可热更新的配置变更', () => {
+      const listener = jest.fn();
+      configManager.subscribeToChanges(listener);
+
+      writeConfig('monitoring:\n  metricsInterval: 20000\n');
+      const result = configManager.reloadConfiguration();
+
+      expect(result.applied).toBe(true);
+      expect(result.changes.map(change => change.path)).toEqual(['monitoring.metricsInterval']);
+      expect(configManager.getCurrentConfiguration()?.monitoring.metricsInterval).toBe(20000);
+      expect(listener).toHaveBeenCalledTimes(1);
+    });
+
+    it('配置未变化时不应发出变更事件', () => {
+      const listener = jest.fn();
+      configManager.subscribeToChanges(listener);
+
+      const result = configManager.reloadConfiguration();
+
+      expect(result.applied).toBe(true);
+      expect(result.changes).toHaveLength(0);
+      expect(listener).not.toHaveBeenCalled();
+    });
+
+    it('应该拒绝不可热更新的配置变更并保留当前配置', () => {
+      const rejected = jest.fn();
+      configManager.on('configReloadRejected', rejected);
+
+      writeConfig('monitoring:\n  metricsInterval: 20000\nservice:\n  server:\n    port: 18099\n');
+      const result = configManager.reloadConfiguration();
+
+      expect(result.applied).toBe(false);
+      expect(result.rejected.map(change => change.path)).toEqual(['service.server.port']);
+      expect(result.errors[0]).toContain('service.server.port');
+      expect(configManager.getCurrentConfiguration()?.monitoring.metricsInterval).toBe(10000);
+      expect(rejected).toHaveBeenCalledWith(result);
+    });
+
+    it('应该拒绝未通过验证的配置', () => {
+      writeConfig('logging:\n  level: verbose\n');
+      const result = configManager.reloadConfiguration();
+
+      expect(result.applied).toBe(false);
+      expect(result.errors.length).toBeGreaterThan(0);
+      expect(configManager.getCurrentConfiguration()?.logging.level).not.toBe('verbose');
+    });
+
+    it('应该拒绝无法解析的配置文件', () => {
+      writeConfig('monitoring: [unclosed\n');
+      const result = configManager.reloadConfiguration();
+
+      expect(result.applied).toBe(false);
+      expect(result.errors[0]).toContain(configPath);
+    });
+
+    it('不可热更新路径应该支持通配符', () => {
+      configManager.setImmutablePaths(['dataflow.*.maxMemoryUsage']);
+
+      writeConfig('dataflow:\n  performance:\n    maxMemoryUsage: 1073741824\n');
+      const result = configManager.reloadConfiguration();
+
+      expect(result.applied).toBe(false);
+      expect(result.rejected.map(change => change.path)).toEqual(['dataflow.performance.maxMemoryUsage']);
+    });
+  });
+
+  describe('信号触发', () => {
+    it('收到SIGHUP时应该重新加载配置', () => {
+      configManager.enableSignalReload();
+
+      writeConfig('monitoring:\n  metricsInterval: 30000\n');
+      process.emit('SIGHUP', 'SIGHUP');
+
+      expect(configManager.getCurrentConfiguration()?.monitoring.metricsInterval).toBe(30000);
+    });
+
+    it('取消后不应再响应信号', () => {
+      configManager.enableSignalReload();
+      configManager.disableSignalReload();
+
+      writeConfig('monitoring:\n  metricsInterval: 30000\n');
+      process.emit('SIGHUP', 'SIGHUP');
+
+      expect(configManager.getCurrentConfiguration()?.monitoring.metricsInterval).toBe(10000);
+    });
+  });
+
+  describe('配置分层与来源', () => {
+    let overlayPath: string;
+
+    beforeEach(() => {
+      writeConfig('monitoring:\n  metricsInterval: 10000\nsymbols: [BTCUSDT, ETHUSDT, SOLUSDT]\n');
+      overlayPath = join(configDir, 'overlay.yaml');
+      writeFileSync(overlayPath, 'monitoring:\n  metricsInterval: 20000\nsymbols: [BNBUSDT]\n', 'utf-8');
+    });
+
+    afterEach(() => {
+      delete process.env.LOG_LEVEL;
+    });
+
+    it('后加载的配置应该覆盖前面的配置，数组整体替换', () => {
+      const config = configManager.loadConfiguration('test', [configPath, overlayPath]) as any;
+
+      expect(config.monitoring.metricsInterval).toBe(20000);
+      expect(config.symbols).toEqual(['BNBUSDT']);
+    });
+
+    it('应该记录每个配置值最终生效的来源', () => {
+      process.env.LOG_LEVEL = 'debug';
+      configManager.loadConfiguration('test', [configPath, overlayPath, join(configDir, 'missing.yaml')]);
+
+      const provenance = configManager.getConfigProvenance();
+
+      expect(provenance['monitoring.metricsInterval']).toBe(overlayPath);
+      expect(provenance['symbols']).toBe(overlayPath);
+      expect(provenance['logging.level']).toBe('env:LOG_LEVEL');
+      expect(provenance['service.server.port']).toBe('default');
+      expect(Object.values(provenance)).not.toContain(join(configDir, 'missing.yaml'));
+    });
+
+    it('重新加载后来源应该随之更新', () => {
+      configManager.loadConfiguration('test', [configPath, overlayPath]);
+
+      writeFileSync(overlayPath, 'symbols: [BNBUSDT]\n', 'utf-8');
+      configManager.reloadConfiguration();
+
+      expect(configManager.getConfigProvenance()['monitoring.metricsInterval']).toBe(configPath);
+    });
+  });
+});
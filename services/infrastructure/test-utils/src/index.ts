@@ -55,6 +55,11 @@ export {
   CONSOLE_MOCK_CONFIGS
 } from './mocks/console-mock';
 
+// 模拟交易所
+export {
+  MockExchangeServer
+} from './mock-exchange/mock-exchange-server';
+
 // 类型导出
 export type {
   UnifiedSetupConfig
@@ -71,5 +76,16 @@ export type {
   MockWebSocket
 } from './mocks/websocket-mock';
 
+export type {
+  MockExchangeOptions,
+  MockOrder,
+  MockOrderSide,
+  MockOrderType,
+  MockOrderStatus,
+  MockPriceLevel,
+  MockNetworkConditions,
+  MockNetworkStats
+} from './mock-exchange/mock-exchange-server';
+
 // 版本信息
 export const VERSION = '1.0.0';
\ No newline at end of file
@@ -0,0 +1,778 @@
+/**
+ * 模拟交易所服务器
+ * 基于Binance协议格式提供REST与WebSocket接口，用于在CI中端到端测试适配器
+ *
+ * REST（以 getRestUrl() 为前缀）：
+ * - GET    /v3/ping、/v3/time
+ * - GET    /v3/depth
+ * - POST   /v3/order、DELETE /v3/order、GET /v3/openOrders
+ * - POST   /v3/userDataStream、PUT/DELETE /v3/userDataStream
+ *
+ * WebSocket（以 getWsUrl() 为前缀）：
+ * - /ws、/ws/<stream>、/stream?streams=<a>/<b>
+ * - /ws/<listenKey> 用户数据流
+ *
+ * 可通过 network 选项或 setNetworkConditions() 注入延迟、丢包、乱序与重复回报以及强制断线，
+ * 指定seed时随机序列固定，便于在CI中稳定复现
+ */
+
+import { EventEmitter } from 'events';
+import { createServer, IncomingMessage, Server, ServerResponse } from 'http';
+import { AddressInfo } from 'net';
+import { URL } from 'url';
+import { randomBytes } from 'crypto';
+import WebSocket, { WebSocketServer } from 'ws';
+
+export type MockOrderSide = 'BUY' | 'SELL';
+export type MockOrderType = 'LIMIT' | 'MARKET';
+export type MockOrderStatus = 'NEW' | 'PARTIALLY_FILLED' | 'FILLED' | 'CANCELED';
+
+/** 价格档位 [价格, 数量] */
+export type MockPriceLevel = [number, number];
+
+export interface MockExchangeOptions {
+  /** 监听端口，0表示随机端口 */
+  port?: number;
+  /** 监听地址 */
+  host?: string;
+  /** 每分钟请求权重上限，超出后返回429 */
+  weightLimit?: number;
+  /** 模拟网络状况 */
+  network?: MockNetworkConditions;
+}
+
+export interface MockNetworkConditions {
+  /** REST响应与WebSocket推送的延迟（毫秒），数组表示在区间内随机 */
+  latency?: number | [number, number];
+  /** WebSocket推送的丢弃概率（0-1），订阅应答不受影响 */
+  dropRate?: number;
+  /** executionReport额外延迟、被后续回报超越的概率（0-1） */
+  reorderRate?: number;
+  /** 乱序回报的额外延迟（毫秒），默认50 */
+  reorderDelay?: number;
+  /** executionReport重复推送的概率（0-1） */
+  duplicateRate?: number;
+  /** 每隔多久强制断开所有WebSocket连接（毫秒） */
+  disconnectInterval?: number;
+  /** 随机种子，指定后结果可复现 */
+  seed?: number;
+}
+
+export interface MockNetworkStats {
+  dropped: number;
+  duplicated: number;
+  reordered: number;
+  disconnects: number;
+}
+
+export interface MockOrder {
+  orderId: number;
+  clientOrderId: string;
+  symbol: string;
+  side: MockOrderSide;
+  type: MockOrderType;
+  price: number;
+  origQty: number;
+  executedQty: number;
+  status: MockOrderStatus;
+  time: number;
+}
+
+interface MockBook {
+  bids: Map<number, number>;
+  asks: Map<number, number>;
+  lastUpdateId: number;
+}
+
+interface MockClient {
+  socket: WebSocket;
+  streams: Set<string>;
+  combined: boolean;
+  listenKey?: string;
+  /** 最近一条消息的计划送达时间，保证同一连接按序送达 */
+  deliverAt: number;
+  /** 尚未送达的按序消息数 */
+  queued: number;
+}
+
+/**
+ * 模拟交易所服务器
+ *
+ * 事件：
+ * - order: 收到新订单
+ * - fill: 订单成交
+ * - cancel: 订单撤销
+ * - connection: WebSocket客户端连接
+ */
+export class MockExchangeServer extends EventEmitter {
+  private readonly options: MockExchangeOptions;
+  private httpServer?: Server;
+  private wsServer?: WebSocketServer;
+  private readonly books = new Map<string, MockBook>();
+  private readonly orders = new Map<number, MockOrder>();
+  private readonly listenKeys = new Set<string>();
+  private readonly clients = new Set<MockClient>();
+  private nextOrderId = 1;
+  private usedWeight = 0;
+  private weightWindowStart = 0;
+  private network: MockNetworkConditions = {};
+  private randomState = 0;
+  private networkStats: MockNetworkStats = { dropped: 0, duplicated: 0, reordered: 0, disconnects: 0 };
+  private disconnectTimer?: NodeJS.Timeout;
+  private readonly pendingDeliveries = new Set<NodeJS.Timeout>();
+
+  constructor(options: MockExchangeOptions = {}) {
+    super();
+    this.options = options;
+    this.setNetworkConditions(options.network ?? {});
+  }
+
+  /**
+   * 启动服务器
+   */
+  async start(): Promise<void> {
+    this.httpServer = createServer((req, res) => {
+      this.delay(this.nextLatency())
+        .then(() => this.handleRequest(req, res))
+        .catch(error => {
+          this.sendJson(res, 500, { code: -1000, msg: (error as Error).message });
+        });
+    });
+
+    this.wsServer = new WebSocketServer({ server: this.httpServer });
+    this.wsServer.on('connection', (socket, req) => this.handleConnection(socket, req));
+
+    await new Promise<void>((resolve, reject) => {
+      this.httpServer!.once('error', reject);
+      this.httpServer!.listen(this.options.port ?? 0, this.options.host ?? '127.0.0.1', () => resolve());
+    });
+  }
+
+  /**
+   * 停止服务器
+   */
+  async stop(): Promise<void> {
+    this.clearNetworkTimers();
+    this.disconnectAll();
+
+    if (this.wsServer) {
+      await new Promise<void>(resolve => this.wsServer!.close(() => resolve()));
+      this.wsServer = undefined;
+    }
+
+    if (this.httpServer) {
+      await new Promise<void>(resolve => this.httpServer!.close(() => resolve()));
+      this.httpServer = undefined;
+    }
+  }
+
+  /**
+   * REST接口地址
+   */
+  getRestUrl(): string {
+    return `http://${this.getHost()}/api`;
+  }
+
+  /**
+   * WebSocket接口地址
+   */
+  getWsUrl(): string {
+    return `ws://${this.getHost()}/ws`;
+  }
+
+  /**
+   * 设置订单簿，用于REST快照
+   */
+  setOrderBook(symbol: string, bids: MockPriceLevel[], asks: MockPriceLevel[]): void {
+    const book = this.getBook(symbol);
+    book.bids = new Map(bids);
+    book.asks = new Map(asks);
+    book.lastUpdateId++;
+  }
+
+  /**
+   * 更新订单簿档位并推送深度增量，数量为0表示删除档位
+   */
+  updateOrderBook(symbol: string, bids: MockPriceLevel[], asks: MockPriceLevel[]): void {
+    const book = this.getBook(symbol);
+    const firstUpdateId = book.lastUpdateId + 1;
+
+    for (const [price, quantity] of bids) {
+      this.setLevel(book.bids, price, quantity);
+    }
+    for (const [price, quantity] of asks) {
+      this.setLevel(book.asks, price, quantity);
+    }
+    book.lastUpdateId++;
+
+    this.publish(`${this.streamSymbol(symbol)}@depth`, {
+      e: 'depthUpdate',
+      E: Date.now(),
+      s: this.restSymbol(symbol),
+      U: firstUpdateId,
+      u: book.lastUpdateId,
+      b: bids.map(level => this.formatLevel(level)),
+      a: asks.map(level => this.formatLevel(level))
+    });
+  }
+
+  /**
+   * 推送一笔公开成交，并按价格优先、时间优先撮合与之价格交叉的挂单
+   * 各挂单成交数量之和不超过该笔成交的数量
+   */
+  publishTrade(symbol: string, price: number, quantity: number, isBuyerMaker = false): void {
+    this.publish(`${this.streamSymbol(symbol)}@trade`, {
+      e: 'trade',
+      E: Date.now(),
+      s: this.restSymbol(symbol),
+      t: Date.now(),
+      p: price.toString(),
+      q: quantity.toString(),
+      T: Date.now(),
+      m: isBuyerMaker
+    });
+
+    const crossed = this.getOpenOrders(symbol)
+      .filter(order => order.side === 'BUY' ? price <= order.price : price >= order.price)
+      .sort((a, b) => (a.side === 'BUY' ? b.price - a.price : a.price - b.price) || a.orderId - b.orderId);
+
+    let remaining = quantity;
+    for (const order of crossed) {
+      if (remaining <= 0) {
+        break;
+      }
+      const fillQty = Math.min(remaining, order.origQty - order.executedQty);
+      this.fillOrder(order.orderId, fillQty, order.price);
+      remaining -= fillQty;
+    }
+  }
+
+  /**
+   * 向订阅了指定流的客户端推送数据
+   */
+  publish(stream: string, data: any): void {
+    for (const client of this.clients) {
+      if (client.streams.has(stream)) {
+        this.sendToClient(client, stream, data);
+      }
+    }
+  }
+
+  /**
+   * 手动成交订单
+   */
+  fillOrder(orderId: number, quantity?: number, price?: number): MockOrder {
+    const order = this.orders.get(orderId);
+    if (!order) {
+      throw new Error(`Unknown order: ${orderId}`);
+    }
+
+    const remaining = order.origQty - order.executedQty;
+    const fillQty = Math.min(quantity ?? remaining, remaining);
+    const fillPrice = price ?? order.price;
+    if (fillQty <= 0) {
+      return { ...order };
+    }
+
+    order.executedQty += fillQty;
+    order.status = order.executedQty >= order.origQty ? 'FILLED' : 'PARTIALLY_FILLED';
+
+    this.emitExecutionReport(order, 'TRADE', fillQty, fillPrice);
+    this.emit('fill', { ...order }, fillQty, fillPrice);
+    return { ...order };
+  }
+
+  /**
+   * 获取挂单
+   */
+  getOpenOrders(symbol?: string): MockOrder[] {
+    return Array.from(this.orders.values()).filter(order =>
+      (order.status === 'NEW' || order.status === 'PARTIALLY_FILLED') &&
+      (!symbol || order.symbol === this.restSymbol(symbol))
+    );
+  }
+
+  /**
+   * 获取订单
+   */
+  getOrder(orderId: number): MockOrder | undefined {
+    const order = this.orders.get(orderId);
+    return order ? { ...order } : undefined;
+  }
+
+  /**
+   * 当前WebSocket连接数
+   */
+  getConnectionCount(): number {
+    return this.clients.size;
+  }
+
+  /**
+   * 断开所有WebSocket连接，用于测试重连
+   */
+  disconnectAll(code = 1006): void {
+    for (const client of this.clients) {
+      if (code === 1006) {
+        client.socket.terminate();
+      } else {
+        client.socket.close(code);
+      }
+    }
+    this.clients.clear();
+  }
+
+  /**
+   * 设置网络状况，替换之前的设置并重置随机序列
+   */
+  setNetworkConditions(conditions: MockNetworkConditions): void {
+    this.network = { ...conditions };
+    this.randomState = conditions.seed ?? Math.floor(Math.random() * 0xffffffff);
+
+    if (this.disconnectTimer) {
+      clearInterval(this.disconnectTimer);
+      this.disconnectTimer = undefined;
+    }
+    if (conditions.disconnectInterval && conditions.disconnectInterval > 0) {
+      this.disconnectTimer = setInterval(() => {
+        if (this.clients.size > 0) {
+          this.networkStats.disconnects++;
+          this.disconnectAll();
+        }
+      }, conditions.disconnectInterval);
+    }
+  }
+
+  /**
+   * 网络模拟统计
+   */
+  getNetworkStats(): MockNetworkStats {
+    return { ...this.networkStats };
+  }
+
+  /**
+   * 重置所有状态，网络状况恢复正常
+   */
+  reset(): void {
+    this.books.clear();
+    this.orders.clear();
+    this.listenKeys.clear();
+    this.nextOrderId = 1;
+    this.usedWeight = 0;
+    this.weightWindowStart = 0;
+    this.clearNetworkTimers();
+    this.setNetworkConditions({});
+    this.networkStats = { dropped: 0, duplicated: 0, reordered: 0, disconnects: 0 };
+  }
+
+  // ====== REST处理 ======
+
+  private async handleRequest(req: IncomingMessage, res: ServerResponse): Promise<void> {
+    const url = new URL(req.url ?? '/', `http://${req.headers.host}`);
+    const params = { ...Object.fromEntries(url.searchParams), ...(await this.readBody(req)) };
+    const route = `${req.method} ${url.pathname.replace(/^\/api/, '')}`;
+
+    if (!this.consumeWeight(route === 'GET /v3/depth' ? this.getDepthWeight(params.limit) : 1, res)) {
+      this.sendJson(res, 429, { code: -1003, msg: 'Too many requests' });
+      return;
+    }
+
+    switch (route) {
+      case 'GET /v3/ping':
+        return this.sendJson(res, 200, {});
+      case 'GET /v3/time':
+        return this.sendJson(res, 200, { serverTime: Date.now() });
+      case 'GET /v3/depth':
+        return this.handleDepth(params, res);
+      case 'POST /v3/order':
+        return this.handlePlaceOrder(params, res);
+      case 'DELETE /v3/order':
+        return this.handleCancelOrder(params, res);
+      case 'GET /v3/openOrders':
+        return this.sendJson(res, 200, this.getOpenOrders(params.symbol).map(order => this.formatOrder(order)));
+      case 'POST /v3/userDataStream': {
+        const listenKey = randomBytes(16).toString('hex');
+        this.listenKeys.add(listenKey);
+        return this.sendJson(res, 200, { listenKey });
+      }
+      case 'PUT /v3/userDataStream':
+      case 'DELETE /v3/userDataStream':
+        if (!this.listenKeys.has(params.listenKey)) {
+          return this.sendJson(res, 400, { code: -1125, msg: 'This listenKey does not exist.' });
+        }
+        if (req.method === 'DELETE') {
+          this.listenKeys.delete(params.listenKey);
+        }
+        return this.sendJson(res, 200, {});
+      default:
+        return this.sendJson(res, 404, { code: -1, msg: `Unknown endpoint: ${route}` });
+    }
+  }
+
+  private handleDepth(params: Record<string, string>, res: ServerResponse): void {
+    if (!params.symbol) {
+      return this.sendJson(res, 400, { code: -1102, msg: "Mandatory parameter 'symbol' was not sent." });
+    }
+
+    const book = this.getBook(params.symbol);
+    const limit = parseInt(params.limit ?? '100', 10);
+    this.sendJson(res, 200, {
+      lastUpdateId: book.lastUpdateId,
+      bids: this.sortedLevels(book.bids, 'desc').slice(0, limit).map(level => this.formatLevel(level)),
+      asks: this.sortedLevels(book.asks, 'asc').slice(0, limit).map(level => this.formatLevel(level))
+    });
+  }
+
+  private handlePlaceOrder(params: Record<string, string>, res: ServerResponse): void {
+    const side = params.side as MockOrderSide;
+    const type = (params.type ?? 'LIMIT') as MockOrderType;
+    const quantity = parseFloat(params.quantity);
+    const price = parseFloat(params.price ?? '0');
+
+    if (!params.symbol || (side !== 'BUY' && side !== 'SELL') || !(quantity > 0) || (type === 'LIMIT' && !(price > 0))) {
+      return this.sendJson(res, 400, { code: -1102, msg: 'Invalid order parameters' });
+    }
+
+    const orderId = this.nextOrderId++;
+    const order: MockOrder = {
+      orderId,
+      clientOrderId: params.newClientOrderId ?? `mock-${orderId}`,
+      symbol: this.restSymbol(params.symbol),
+      side,
+      type,
+      price,
+      origQty: quantity,
+      executedQty: 0,
+      status: 'NEW',
+      time: Date.now()
+    };
+    this.orders.set(order.orderId, order);
+
+    this.emitExecutionReport(order, 'NEW', 0, 0);
+    this.emit('order', { ...order });
+    this.matchAgainstBook(order);
+
+    if (order.type === 'MARKET' && order.status !== 'FILLED') {
+      // 市价单未成交部分直接撤销
+      order.status = 'CANCELED';
+      this.emitExecutionReport(order, 'CANCELED', 0, 0);
+    }
+
+    this.sendJson(res, 200, this.formatOrder(order));
+  }
+
+  private handleCancelOrder(params: Record<string, string>, res: ServerResponse): void {
+    const order = this.orders.get(parseInt(params.orderId, 10));
+    if (!order || order.status === 'FILLED' || order.status === 'CANCELED') {
+      return this.sendJson(res, 400, { code: -2011, msg: 'Unknown order sent.' });
+    }
+
+    order.status = 'CANCELED';
+    this.emitExecutionReport(order, 'CANCELED', 0, 0);
+    this.emit('cancel', { ...order });
+    this.sendJson(res, 200, this.formatOrder(order));
+  }
+
+  /**
+   * 订单与对手盘撮合，吃掉的档位会以深度增量推送
+   */
+  private matchAgainstBook(order: MockOrder): void {
+    const book = this.getBook(order.symbol);
+    const opposite = order.side === 'BUY' ? book.asks : book.bids;
+    const levels = this.sortedLevels(opposite, order.side === 'BUY' ? 'asc' : 'desc');
+    const consumed: MockPriceLevel[] = [];
+
+    for (const [levelPrice, levelQty] of levels) {
+      const remaining = order.origQty - order.executedQty;
+      if (remaining <= 0) {
+        break;
+      }
+      if (order.type === 'LIMIT' && (order.side === 'BUY' ? levelPrice > order.price : levelPrice < order.price)) {
+        break;
+      }
+
+      const fillQty = Math.min(remaining, levelQty);
+      consumed.push([levelPrice, levelQty - fillQty]);
+      this.fillOrder(order.orderId, fillQty, levelPrice);
+    }
+
+    if (consumed.length > 0) {
+      this.updateOrderBook(order.symbol, order.side === 'SELL' ? consumed : [], order.side === 'BUY' ? consumed : []);
+    }
+  }
+
+  private consumeWeight(weight: number, res: ServerResponse): boolean {
+    const windowStart = Math.floor(Date.now() / 60000) * 60000;
+    if (windowStart !== this.weightWindowStart) {
+      this.weightWindowStart = windowStart;
+      this.usedWeight = 0;
+    }
+
+    const limit = this.options.weightLimit ?? Infinity;
+    const allowed = this.usedWeight + weight <= limit;
+    if (allowed) {
+      this.usedWeight += weight;
+    } else {
+      res.setHeader('Retry-After', Math.ceil((windowStart + 60000 - Date.now()) / 1000).toString());
+    }
+
+    res.setHeader('x-mbx-used-weight-1m', this.usedWeight.toString());
+    return allowed;
+  }
+
+  private getDepthWeight(limit?: string): number {
+    const value = parseInt(limit ?? '100', 10);
+    if (value <= 100) return 5;
+    if (value <= 500) return 25;
+    if (value <= 1000) return 50;
+    return 250;
+  }
+
+  private async readBody(req: IncomingMessage): Promise<Record<string, string>> {
+    const chunks: Buffer[] = [];
+    for await (const chunk of req) {
+      chunks.push(chunk as Buffer);
+    }
+
+    const body = Buffer.concat(chunks).toString('utf-8');
+    if (!body) {
+      return {};
+    }
+
+    if ((req.headers['content-type'] ?? '').includes('application/json')) {
+      return JSON.parse(body);
+    }
+    return Object.fromEntries(new URLSearchParams(body));
+  }
+
+  private sendJson(res: ServerResponse, status: number, body: any): void {
+    res.writeHead(status, { 'Content-Type': 'application/json' });
+    res.end(JSON.stringify(body));
+  }
+
+  // ====== WebSocket处理 ======
+
+  private handleConnection(socket: WebSocket, req: IncomingMessage): void {
+    const url = new URL(req.url ?? '/', `http://${req.headers.host}`);
+    const client: MockClient = { socket, streams: new Set(), combined: url.pathname.startsWith('/stream'), deliverAt: 0, queued: 0 };
+
+    if (client.combined) {
+      for (const stream of (url.searchParams.get('streams') ?? '').split('/').filter(Boolean)) {
+        client.streams.add(stream);
+      }
+    } else {
+      const target = url.pathname.replace(/^\/ws\/?/, '');
+      if (this.listenKeys.has(target)) {
+        client.listenKey = target;
+      } else if (target) {
+        client.streams.add(target);
+      }
+    }
+
+    this.clients.add(client);
+    socket.on('message', raw => this.handleClientMessage(client, raw.toString()));
+    socket.on('close', () => this.clients.delete(client));
+    this.emit('connection', Array.from(client.streams));
+  }
+
+  private handleClientMessage(client: MockClient, raw: string): void {
+    let message: any;
+    try {
+      message = JSON.parse(raw);
+    } catch {
+      return;
+    }
+
+    const params: string[] = Array.isArray(message.params) ? message.params : [];
+    switch (message.method) {
+      case 'SUBSCRIBE':
+        params.forEach(stream => client.streams.add(stream));
+        client.socket.send(JSON.stringify({ result: null, id: message.id }));
+        break;
+      case 'UNSUBSCRIBE':
+        params.forEach(stream => client.streams.delete(stream));
+        client.socket.send(JSON.stringify({ result: null, id: message.id }));
+        break;
+      case 'LIST_SUBSCRIPTIONS':
+        client.socket.send(JSON.stringify({ result: Array.from(client.streams), id: message.id }));
+        break;
+    }
+  }
+
+  private sendToClient(client: MockClient, stream: string, data: any): void {
+    if (client.socket.readyState !== WebSocket.OPEN) {
+      return;
+    }
+    if (this.network.dropRate && this.random() < this.network.dropRate) {
+      this.networkStats.dropped++;
+      return;
+    }
+
+    const payload = JSON.stringify(client.combined ? { stream, data } : data);
+    const isExecutionReport = data.e === 'executionReport';
+
+    if (isExecutionReport && this.network.reorderRate && this.random() < this.network.reorderRate) {
+      // 不占用连接的顺序队列，使后续回报先于本条送达
+      this.networkStats.reordered++;
+      this.deliver(client, payload, Date.now() + this.nextLatency() + (this.network.reorderDelay ?? 50), false);
+    } else {
+      this.deliver(client, payload, Date.now() + this.nextLatency(), true);
+    }
+
+    if (isExecutionReport && this.network.duplicateRate && this.random() < this.network.duplicateRate) {
+      this.networkStats.duplicated++;
+      this.deliver(client, payload, Date.now() + this.nextLatency(), true);
+    }
+  }
+
+  /**
+   * 按计划时间送达消息
+   * 按序消息不早于同一连接的前一条；无延迟且没有排队消息时同步发送
+   */
+  private deliver(client: MockClient, payload: string, at: number, ordered: boolean): void {
+    if (ordered) {
+      at = Math.max(at, client.deliverAt);
+      client.deliverAt = at;
+    }
+
+    if (at <= Date.now() && (!ordered || client.queued === 0)) {
+      client.socket.send(payload);
+      return;
+    }
+
+    if (ordered) {
+      client.queued++;
+    }
+    const timer = setTimeout(() => {
+      this.pendingDeliveries.delete(timer);
+      if (ordered) {
+        client.queued--;
+      }
+      if (client.socket.readyState === WebSocket.OPEN) {
+        client.socket.send(payload);
+      }
+    }, Math.max(0, at - Date.now()));
+    this.pendingDeliveries.add(timer);
+  }
+
+  private emitExecutionReport(order: MockOrder, executionType: string, lastQty: number, lastPrice: number): void {
+    const report = {
+      e: 'executionReport',
+      E: Date.now(),
+      s: order.symbol,
+      c: order.clientOrderId,
+      S: order.side,
+      o: order.type,
+      q: order.origQty.toString(),
+      p: order.price.toString(),
+      x: executionType,
+      X: order.status,
+      i: order.orderId,
+      l: lastQty.toString(),
+      z: order.executedQty.toString(),
+      L: lastPrice.toString(),
+      T: Date.now()
+    };
+
+    for (const client of this.clients) {
+      if (client.listenKey) {
+        this.sendToClient(client, client.listenKey, report);
+      }
+    }
+  }
+
+  // ====== 网络模拟 ======
+
+  private nextLatency(): number {
+    const latency = this.network.latency ?? 0;
+    if (Array.isArray(latency)) {
+      const [min, max] = latency;
+      return min + this.random() * (max - min);
+    }
+    return latency;
+  }
+
+  /**
+   * mulberry32伪随机数，种子相同时序列相同
+   */
+  private random(): number {
+    this.randomState = (this.randomState + 0x6d2b79f5) >>> 0;
+    let t = this.randomState;
+    t = Math.imul(t ^ (t >>> 15), t | 1);
+    t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+    return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+  }
+
+  private delay(ms: number): Promise<void> {
+    return ms > 0 ? new Promise(resolve => setTimeout(resolve, ms)) : Promise.resolve();
+  }
+
+  private clearNetworkTimers(): void {
+    if (this.disconnectTimer) {
+      clearInterval(this.disconnectTimer);
+      this.disconnectTimer = undefined;
+    }
+    for (const timer of this.pendingDeliveries) {
+      clearTimeout(timer);
+    }
+    this.pendingDeliveries.clear();
+  }
+
+  // ====== 辅助方法 ======
+
+  private getBook(symbol: string): MockBook {
+    const key = this.restSymbol(symbol);
+    let book = this.books.get(key);
+    if (!book) {
+      book = { bids: new Map(), asks: new Map(), lastUpdateId: 0 };
+      this.books.set(key, book);
+    }
+    return book;
+  }
+
+  private setLevel(side: Map<number, number>, price: number, quantity: number): void {
+    if (quantity > 0) {
+      side.set(price, quantity);
+    } else {
+      side.delete(price);
+    }
+  }
+
+  private sortedLevels(side: Map<number, number>, order: 'asc' | 'desc'): MockPriceLevel[] {
+    return Array.from(side.entries()).sort((a, b) => (order === 'asc' ? a[0] - b[0] : b[0] - a[0]));
+  }
+
+  private formatLevel([price, quantity]: MockPriceLevel): [string, string] {
+    return [price.toString(), quantity.toString()];
+  }
+
+  private formatOrder(order: MockOrder): Record<string, any> {
+    return {
+      symbol: order.symbol,
+      orderId: order.orderId,
+      clientOrderId: order.clientOrderId,
+      price: order.price.toString(),
+      origQty: order.origQty.toString(),
+      executedQty: order.executedQty.toString(),
+      status: order.status,
+      type: order.type,
+      side: order.side,
+      transactTime: order.time
+    };
+  }
+
+  private restSymbol(symbol: string): string {
+    return symbol.replace('/', '').toUpperCase();
+  }
+
+  private streamSymbol(symbol: string): string {
+    return symbol.replace('/', '').toLowerCase();
+  }
+
+  private getHost(): string {
+    if (!this.httpServer) {
+      throw new Error('Mock exchange server is not started');
+    }
+    const address = this.httpServer.address() as AddressInfo;
+    return `${address.address}:${address.port}`;
+  }
+}
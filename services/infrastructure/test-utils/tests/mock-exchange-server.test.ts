@@ -0,0 +1,220 @@
+/**
+ * MockExchangeServer单元测试
+ */
+
+import WebSocket from 'ws';
+import { MockExchangeServer } from '../src';
+
+describe('MockExchangeServer', () => {
+  let server: MockExchangeServer;
+
+  const placeOrder = async (params: Record<string, string>) => {
+    const response = await fetch(`${server.getRestUrl()}/v3/order`, {
+      method: 'POST',
+      body: new URLSearchParams({ symbol: 'BTCUSDT', type: 'LIMIT', ...params })
+    });
+    return response.json();
+  };
+
+  const connect = (path: string): Promise<{ socket: WebSocket; messages: any[] }> => new Promise((resolve, reject) => {
+    const socket = new WebSocket(`${server.getWsUrl()}${path}`);
+    const messages: any[] = [];
+    socket.on('message', raw => messages.push(JSON.parse(raw.toString())));
+    socket.once('open', () => resolve({ socket, messages }));
+    socket.once('error', reject);
+  });
+
+  const waitFor = async (condition: () => boolean, timeout = 1000) => {
+    const deadline = Date.now() + timeout;
+    while (!condition()) {
+      if (Date.now() > deadline) {
+        throw new Error('Timed out waiting for condition');
+      }
+      await new Promise(resolve => setTimeout(resolve, 5));
+    }
+  };
+
+  beforeEach(async () => {
+    server = new MockExchangeServer({ weightLimit: 10 });
+    await server.start();
+  });
+
+  afterEach(async () => {
+    await server.stop();
+  });
+
+  describe('撮合', () => {
+    it('公开成交应该按价格优先撮合挂单，且总成交量不超过成交数量', async () => {
+      const low = await placeOrder({ side: 'BUY', price: '99', quantity: '1' });
+      const high = await placeOrder({ side: 'BUY', price: '100', quantity: '1' });
+      const other = await placeOrder({ side: 'BUY', price: '98', quantity: '1' });
+
+      server.publishTrade('BTCUSDT', 98, 1.5);
+
+      expect(server.getOrder(high.orderId)).toMatchObject({ status: 'FILLED', executedQty: 1 });
+      expect(server.getOrder(low.orderId)).toMatchObject({ status: 'PARTIALLY_FILLED', executedQty: 0.5 });
+      expect(server.getOrder(other.orderId)).toMatchObject({ status: 'NEW', executedQty: 0 });
+    });
+
+    it('未交叉的成交不应该触发成交', async () => {
+      const order = await placeOrder({ side: 'SELL', price: '101', quantity: '1' });
+
+      server.publishTrade('BTCUSDT', 100, 5);
+
+      expect(server.getOrder(order.orderId)!.status).toBe('NEW');
+    });
+
+    it('市价单应该吃掉对手盘并撤销未成交部分', async () => {
+      server.setOrderBook('BTCUSDT', [[99, 1]], [[100, 1], [101, 1]]);
+
+      const order = await placeOrder({ side: 'BUY', type: 'MARKET', quantity: '3' });
+
+      expect(order).toMatchObject({ status: 'CANCELED', executedQty: '2' });
+      const depth = await (await fetch(`${server.getRestUrl()}/v3/depth?symbol=BTCUSDT`)).json();
+      expect(depth.asks).toEqual([]);
+    });
+
+    it('应该通过用户数据流推送执行回报', async () => {
+      const { listenKey } = await (await fetch(`${server.getRestUrl()}/v3/userDataStream`, { method: 'POST' })).json();
+      const { socket, messages } = await connect(`/${listenKey}`);
+
+      const order = await placeOrder({ side: 'BUY', price: '100', quantity: '1' });
+      server.publishTrade('BTCUSDT', 100, 1);
+      await waitFor(() => messages.length >= 2);
+
+      expect(messages.map(report => [report.i, report.x, report.X])).toEqual([
+        [order.orderId, 'NEW', 'NEW'],
+        [order.orderId, 'TRADE', 'FILLED']
+      ]);
+      socket.close();
+    });
+  });
+
+  describe('限流', () => {
+    it('超过每分钟权重上限应该返回429', async () => {
+      const depth = await fetch(`${server.getRestUrl()}/v3/depth?symbol=BTCUSDT`);
+      expect(depth.headers.get('x-mbx-used-weight-1m')).toBe('5');
+
+      for (let i = 0; i < 5; i++) {
+        expect((await fetch(`${server.getRestUrl()}/v3/ping`)).status).toBe(200);
+      }
+      const limited = await fetch(`${server.getRestUrl()}/v3/ping`);
+
+      expect(limited.status).toBe(429);
+      expect(Number(limited.headers.get('retry-after'))).toBeGreaterThan(0);
+    });
+
+    it('reset()应该清空权重窗口', async () => {
+      for (let i = 0; i < 10; i++) {
+        await fetch(`${server.getRestUrl()}/v3/ping`);
+      }
+      expect((await fetch(`${server.getRestUrl()}/v3/ping`)).status).toBe(429);
+
+      server.reset();
+      const response = await fetch(`${server.getRestUrl()}/v3/ping`);
+
+      expect(response.status).toBe(200);
+      expect(response.headers.get('x-mbx-used-weight-1m')).toBe('1');
+    });
+  });
+  describe('网络模拟', () => {
+    const openUserDataStream = async () => {
+      const { listenKey } = await (await fetch(`${server.getRestUrl()}/v3/userDataStream`, { method: 'POST' })).json();
+      return connect(`/${listenKey}`);
+    };
+
+    it('dropRate为1时应该丢弃全部推送，但不影响订阅应答', async () => {
+      server.setNetworkConditions({ dropRate: 1 });
+      const { socket, messages } = await connect('');
+
+      socket.send(JSON.stringify({ method: 'SUBSCRIBE', params: ['btcusdt@trade'], id: 1 }));
+      await waitFor(() => messages.length === 1);
+      server.publishTrade('BTCUSDT', 100, 1);
+      server.publishTrade('BTCUSDT', 101, 1);
+      await new Promise(resolve => setTimeout(resolve, 20));
+
+      expect(messages).toEqual([{ result: null, id: 1 }]);
+      expect(server.getNetworkStats().dropped).toBe(2);
+      socket.close();
+    });
+
+    it('相同seed应该得到相同的丢包序列', async () => {
+      const run = async () => {
+        server.reset();
+        server.setNetworkConditions({ dropRate: 0.5, seed: 42 });
+        const { socket, messages } = await connect('/btcusdt@trade');
+        for (let i = 0; i < 20; i++) {
+          server.publishTrade('BTCUSDT', 100 + i, 1);
+        }
+        await new Promise(resolve => setTimeout(resolve, 20));
+        socket.close();
+        return messages.map(trade => trade.p);
+      };
+
+      const first = await run();
+      const second = await run();
+
+      expect(first.length).toBeGreaterThan(0);
+      expect(first.length).toBeLessThan(20);
+      expect(second).toEqual(first);
+    });
+
+    it('应该延迟REST响应与WebSocket推送，且同一连接按序送达', async () => {
+      server.setNetworkConditions({ latency: [30, 60], seed: 1 });
+      const { socket, messages } = await connect('/btcusdt@trade');
+
+      const started = Date.now();
+      await fetch(`${server.getRestUrl()}/v3/ping`);
+      expect(Date.now() - started).toBeGreaterThanOrEqual(25);
+
+      for (let i = 0; i < 5; i++) {
+        server.publishTrade('BTCUSDT', 100 + i, 1);
+      }
+      expect(messages).toHaveLength(0);
+      await waitFor(() => messages.length === 5);
+
+      expect(messages.map(trade => trade.p)).toEqual(['100', '101', '102', '103', '104']);
+      socket.close();
+    });
+
+    it('乱序回报应该被后续回报超越', async () => {
+      server.setNetworkConditions({ reorderRate: 1, reorderDelay: 50 });
+      const { socket, messages } = await openUserDataStream();
+
+      const order = await placeOrder({ side: 'BUY', price: '100', quantity: '1' });
+      server.setNetworkConditions({});
+      server.publishTrade('BTCUSDT', 100, 1);
+      await waitFor(() => messages.length === 2);
+
+      expect(messages.map(report => [report.i, report.x])).toEqual([
+        [order.orderId, 'TRADE'],
+        [order.orderId, 'NEW']
+      ]);
+      expect(server.getNetworkStats().reordered).toBe(1);
+      socket.close();
+    });
+
+    it('应该重复推送执行回报', async () => {
+      server.setNetworkConditions({ duplicateRate: 1 });
+      const { socket, messages } = await openUserDataStream();
+
+      await placeOrder({ side: 'BUY', price: '100', quantity: '1' });
+      await waitFor(() => messages.length === 2);
+
+      expect(messages[1]).toEqual(messages[0]);
+      expect(server.getNetworkStats().duplicated).toBe(1);
+      socket.close();
+    });
+
+    it('应该按disconnectInterval强制断开连接', async () => {
+      server.setNetworkConditions({ disconnectInterval: 30 });
+      const { socket } = await connect('/btcusdt@trade');
+
+      const code = await new Promise<number>(resolve => socket.once('close', resolve));
+
+      expect(code).toBe(1006);
+      expect(server.getConnectionCount()).toBe(0);
+      expect(server.getNetworkStats().disconnects).toBe(1);
+    });
+  });
+});